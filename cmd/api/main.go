@@ -9,14 +9,24 @@ import (
 	"syscall"
 	"time"
 
+	"jingdezhen-ceramics-backend/internal/admin"
 	"jingdezhen-ceramics-backend/internal/api"
+	apimiddleware "jingdezhen-ceramics-backend/internal/api/middleware"
+	"jingdezhen-ceramics-backend/internal/audit"
+	"jingdezhen-ceramics-backend/internal/auth"
 	"jingdezhen-ceramics-backend/internal/ceramicstory"
 	"jingdezhen-ceramics-backend/internal/config"
+	"jingdezhen-ceramics-backend/internal/contact"
 	"jingdezhen-ceramics-backend/internal/course"
 	"jingdezhen-ceramics-backend/internal/engage"
 	"jingdezhen-ceramics-backend/internal/forum"
 	"jingdezhen-ceramics-backend/internal/gallery"
+	"jingdezhen-ceramics-backend/internal/migrate"
+	"jingdezhen-ceramics-backend/internal/migrations"
+	"jingdezhen-ceramics-backend/internal/outbox"
+	"jingdezhen-ceramics-backend/internal/permission"
 	"jingdezhen-ceramics-backend/internal/portfolio"
+	"jingdezhen-ceramics-backend/internal/ratelimit"
 	"jingdezhen-ceramics-backend/internal/user"
 	"jingdezhen-ceramics-backend/pkg/email"
 
@@ -32,9 +42,16 @@ func main() {
 	}
 
 	e := echo.New()
-	e.Logger.Fatal(e.Start(":1323"))
+	e.HTTPErrorHandler = api.HTTPErrorHandler // Errors returned by handlers become models.ErrorResponse with a code
+	// c.RealIP keys rate limits and audit events, so X-Forwarded-For is only believed from our own proxies
+	trustedProxies, err := api.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	e.IPExtractor = api.NewIPExtractor(trustedProxies)
 
 	// Middleware
+	e.Use(apimiddleware.RequestID(trustedProxies)) // Client-supplied request IDs are replaced; they are logged and audited
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{ // Configure CORS appropriately
@@ -60,31 +77,74 @@ func main() {
 	}
 	e.Logger.Info("Successfully connected to the database!")
 
+	if cfg.AutoMigrate {
+		migrator, err := migrate.New(dbPool, migrations.FS, log.Printf)
+		if err != nil {
+			log.Fatalf("Could not load migrations: %v\n", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Could not apply migrations: %v\n", err)
+		}
+	}
+
 	// Dependency injection
-	emailService := email.NewSMTPService(
-		cfg.SMTPServer, // Assuming these are in your config
+	// Services queue emails in the outbox; the worker delivers them through SMTP in the background.
+	smtpService := email.NewSMTPService(
+		cfg.SMTPServer,
 		cfg.SMTPPort,
 		cfg.SMTPUser,
 		cfg.SMTPPassword,
 		cfg.EmailFromAddress,
+		cfg.SMTPTLSMode,
 	)
+	// Templates are parsed and checked against their declared variables once, before anything can send.
+	emailTemplates, err := email.NewDefaultRegistry()
+	if err != nil {
+		log.Fatalf("Invalid email templates: %v\n", err)
+	}
+	outboxRepo := outbox.NewRepository(dbPool)
+	emailService := outbox.NewService(outboxRepo)
+	outboxHandler := outbox.NewHandler(emailService, emailTemplates)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	emailWorker := outbox.NewWorker(outboxRepo, smtpService, cfg.EmailWorkers)
+	emailWorker.Start(workerCtx)
+
+	auditRepo := audit.NewRepository(dbPool)
+	auditService := audit.NewService(auditRepo)
+	auditHandler := audit.NewHandler(auditService)
+
+	permissionRepo := permission.NewRepository(dbPool)
+	permissionService := permission.NewService(permissionRepo, auditService)
+	permissionHandler := permission.NewHandler(permissionService)
 
 	forumRepo := forum.NewRepository(dbPool)
-	forumService := forum.NewService(forumRepo)
+	forumService := forum.NewService(forumRepo, permissionService, auditService)
 	forumHandler := forum.NewHandler(forumService)
 
+	authRepo := auth.NewRepository(dbPool)
+
 	userRepo := user.NewRepository(dbPool)
-	userService := user.NewService(userRepo, forumService, emailService, cfg.AdminEmail)
+	userService := user.NewService(userRepo, forumService, auditService, authRepo) // authRepo ends sessions on role changes
 	userHandler := user.NewHandler(userService)
-	// You'll also need an admin handler if it's separate
-	// adminHandler := user.NewAdminHandler(userService, other admin services)
+
+	contactRepo := contact.NewRepository(dbPool)
+	contactService := contact.NewService(contactRepo, emailService, emailTemplates,
+		contact.NewKeywordScorer(contact.DefaultSpamKeywords, contact.DefaultMaxLinks),
+		cfg.JWTSecret, // Also signs contact form tokens
+		cfg.AdminEmail,
+	)
+	contactHandler := contact.NewHandler(contactService)
+
+	authService := auth.NewService(authRepo, userRepo, emailTemplates, cfg.ClientOrigin, cfg.JWTSecret, cfg.JWTExpiry, cfg.RefreshTokenExpiry)
+	authHandler := auth.NewHandler(authService)
 
 	ceramicStoryRepo := ceramicstory.NewRepository(dbPool)
 	ceramicStoryService := ceramicstory.NewService(ceramicStoryRepo)
 	ceramicStoryHandler := ceramicstory.NewHandler(ceramicStoryService)
 
 	galleryRepo := gallery.NewRepository(dbPool)
-	galleryService := gallery.NewService(galleryRepo) // galleryService might also need e.g. userRepo if favorites involve user data directly in service
+	galleryService := gallery.NewService(galleryRepo, userService) // userService creates the artwork notes
 	galleryHandler := gallery.NewHandler(galleryService)
 
 	engageRepo := engage.NewRepository(dbPool)
@@ -92,17 +152,33 @@ func main() {
 	engageHandler := engage.NewHandler(engageService)
 
 	courseRepo := course.NewRepository(dbPool)
-	courseService := course.NewService(courseRepo)
+	courseService := course.NewService(courseRepo, userService) // userService creates the chapter notes
 	courseHandler := course.NewHandler(courseService)
 
 	portfolioRepo := portfolio.NewRepository(dbPool)
-	portfolioService := portfolio.NewService(portfolioRepo)
+	portfolioService := portfolio.NewService(portfolioRepo, permissionService, auditService)
 	portfolioHandler := portfolio.NewHandler(portfolioService)
 
+	adminHandler := admin.NewHandler(forumService, courseService, portfolioService)
+
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitStore == "postgres" {
+		rateLimitStore = ratelimit.NewPostgresStore(dbPool)
+	}
+
 	// Initialize router, passing all handlers and other necessary dependencies
 	api.SetupRoutes(e, cfg.JWTSecret,
+		authService, // Revocation checks for every JWT middleware
+		authService, // Email verification checks for write actions
+		permissionService,
+		rateLimitStore,
+		authHandler,
 		userHandler,
-		// adminHandler, // Pass if you have a separate admin handler instance
+		adminHandler,
+		outboxHandler,
+		permissionHandler,
+		auditHandler,
+		contactHandler,
 		ceramicStoryHandler,
 		galleryHandler,
 		engageHandler,
@@ -129,5 +205,7 @@ func main() {
 	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatal("Server forced to shutdown:", err)
 	}
+	stopWorkers()
+	emailWorker.Wait() // Unsent emails stay in the outbox for the next start
 	log.Println("Server exiting")
 }
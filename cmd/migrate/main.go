@@ -0,0 +1,96 @@
+// Command migrate applies the embedded schema migrations to DATABASE_URL.
+//
+//	migrate up             apply every pending migration
+//	migrate down [N]       revert the newest N applied migrations (default 1)
+//	migrate to VERSION     apply or revert until VERSION is the newest applied (0 reverts everything)
+//	migrate status         list migrations and when they were applied
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"jingdezhen-ceramics-backend/internal/config"
+	"jingdezhen-ceramics-backend/internal/migrate"
+	"jingdezhen-ceramics-backend/internal/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = "usage: migrate up | down [N] | to VERSION | status"
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("Could not load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dbPool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Unable to create connection pool: %v", err)
+	}
+	defer dbPool.Close()
+
+	migrator, err := migrate.New(dbPool, migrations.FS, log.Printf)
+	if err != nil {
+		log.Fatalf("Could not load migrations: %v", err)
+	}
+
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "up":
+		n, err := migrator.Up(ctx)
+		report("applied", n, err)
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				log.Fatalf("down: N must be a positive number")
+			}
+		}
+		n, err := migrator.Down(ctx, steps)
+		report("reverted", n, err)
+	case "to":
+		if len(args) != 1 {
+			log.Fatal(usage)
+		}
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Fatalf("to: invalid version %q", args[0])
+		}
+		n, err := migrator.To(ctx, version)
+		report("applied or reverted", n, err)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d  %-19s  %s\n", s.Version, applied, s.Name)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func report(verb string, n int, err error) {
+	if err != nil {
+		log.Fatalf("%v (%s %d migrations before the failure)", err, verb, n)
+	}
+	log.Printf("%s %d migrations", verb, n)
+}
@@ -0,0 +1,69 @@
+// Command seed loads the fixture data of a profile into DATABASE_URL. It can be run any number of
+// times: rows are upserted by slug.
+//
+//	seed -profile dev            base timeline and categories plus a few artworks (default)
+//	seed -profile demo           the fuller sample gallery
+//	seed -profile test -migrate  apply pending migrations first
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"jingdezhen-ceramics-backend/internal/config"
+	"jingdezhen-ceramics-backend/internal/migrate"
+	"jingdezhen-ceramics-backend/internal/migrations"
+	"jingdezhen-ceramics-backend/internal/seed"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	profile := flag.String("profile", seed.ProfileDev, "fixture profile: dev, demo or test")
+	runMigrations := flag.Bool("migrate", false, "apply pending schema migrations before seeding")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("Could not load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dbPool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Unable to create connection pool: %v", err)
+	}
+	defer dbPool.Close()
+
+	if *runMigrations {
+		migrator, err := migrate.New(dbPool, migrations.FS, log.Printf)
+		if err != nil {
+			log.Fatalf("Could not load migrations: %v", err)
+		}
+		if _, err := migrator.Up(ctx); err != nil {
+			log.Fatalf("Could not apply migrations: %v", err)
+		}
+	}
+
+	result, err := seed.Run(ctx, dbPool, *profile)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	log.Printf("Seeded profile %q:", *profile)
+	for _, line := range []struct {
+		name   string
+		counts seed.Counts
+	}{
+		{"ceramic stories", result.CeramicStories},
+		{"forum categories", result.ForumCategories},
+		{"artists", result.Artists},
+		{"artworks", result.Artworks},
+	} {
+		log.Printf("  %-16s %d inserted, %d updated", line.name, line.counts.Inserted, line.counts.Updated)
+	}
+}
@@ -0,0 +1,148 @@
+package admin
+
+import (
+	"jingdezhen-ceramics-backend/internal/course"
+	"jingdezhen-ceramics-backend/internal/forum"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/portfolio"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler handles the /admin routes. It has no storage of its own and works through the
+// services of the sections it moderates; the router requires a permission per route.
+// User management stays on user.Handler.
+type Handler struct {
+	forumSvc     forum.ServiceInterface
+	courseSvc    course.ServiceInterface
+	portfolioSvc portfolio.ServiceInterface
+}
+
+// NewHandler creates a new admin handler.
+func NewHandler(forumSvc forum.ServiceInterface, courseSvc course.ServiceInterface, portfolioSvc portfolio.ServiceInterface) *Handler {
+	return &Handler{
+		forumSvc:     forumSvc,
+		courseSvc:    courseSvc,
+		portfolioSvc: portfolioSvc,
+	}
+}
+
+// --- Dashboard ---
+
+// GetStudentProgressDashboard returns enrollment and progress figures for every course.
+func (h *Handler) GetStudentProgressDashboard(c echo.Context) error {
+	dashboard, err := h.courseSvc.GetProgressDashboard(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, dashboard)
+}
+
+// GetCourseStudentProgress lists every enrolled student's progress in one course. Params: ?page=1&limit=20
+func (h *Handler) GetCourseStudentProgress(c echo.Context) error {
+	courseID, err := strconv.ParseInt(c.Param("course_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid course ID")
+	}
+	page, limit := utils.GetPageLimit(c)
+
+	students, total, err := h.courseSvc.ListStudentProgress(c.Request().Context(), courseID, page, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(students, page, limit, total))
+}
+
+// --- Forum Moderation ---
+
+// PinForumPost pins a post, or unpins it with {"pinned": false}.
+func (h *Handler) PinForumPost(c echo.Context) error {
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+	var req models.PinForumPostData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+
+	post, err := h.forumSvc.SetPostPinned(c.Request().Context(), adminID, postID, flagOrDefault(req.Pinned))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, post)
+}
+
+// ArchiveForumPost hides a post from listings, or restores it with {"archived": false}.
+func (h *Handler) ArchiveForumPost(c echo.Context) error {
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+	var req models.ArchiveForumPostData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+
+	post, err := h.forumSvc.SetPostArchived(c.Request().Context(), adminID, postID, flagOrDefault(req.Archived))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, post)
+}
+
+func (h *Handler) DeleteForumPostAsAdmin(c echo.Context) error {
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+
+	if err := h.forumSvc.DeletePost(c.Request().Context(), adminID, utils.GetUserRoleFromContext(c), postID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// --- Portfolio Moderation ---
+
+// HighlightPortfolioWork marks a work as editor's choice, or removes it with {"highlighted": false}.
+func (h *Handler) HighlightPortfolioWork(c echo.Context) error {
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	workID, err := strconv.ParseInt(c.Param("work_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid work ID")
+	}
+	var req models.HighlightPortfolioWorkData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+
+	work, err := h.portfolioSvc.SetHighlighted(c.Request().Context(), adminID, workID, flagOrDefault(req.Highlighted))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, work)
+}
+
+// flagOrDefault treats a missing flag as true, so the moderation routes can be called without a body.
+func flagOrDefault(flag *bool) bool {
+	return flag == nil || *flag
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// statusCodes gives errors echo raises itself (unknown route, wrong method, body too large...) a code.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            models.CodeBadRequest,
+	http.StatusUnauthorized:          models.CodeUnauthorized,
+	http.StatusForbidden:             models.CodeForbidden,
+	http.StatusNotFound:              models.CodeNotFound,
+	http.StatusMethodNotAllowed:      models.CodeMethodNotAllowed,
+	http.StatusConflict:              models.CodeConflict,
+	http.StatusRequestEntityTooLarge: models.CodePayloadTooLarge,
+	http.StatusTooManyRequests:       models.CodeRateLimited,
+	http.StatusServiceUnavailable:    models.CodeServiceUnavailable,
+}
+
+// HTTPErrorHandler is the echo.HTTPErrorHandler of the API. Every error a handler or middleware returns
+// ends up here and is written as a models.ErrorResponse; see models.ToAppError for the mapping.
+// Server errors are logged with their cause, which is never sent to the client.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	appErr := toAppError(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		c.Logger().Errorf("%s %s: %v", c.Request().Method, c.Path(), err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(appErr.Status)
+	} else {
+		err = c.JSON(appErr.Status, appErr.Response())
+	}
+	if err != nil {
+		c.Logger().Error("HTTPErrorHandler: ", err)
+	}
+}
+
+func toAppError(err error) *models.AppError {
+	var appErr *models.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code, ok := statusCodes[he.Code]
+		if !ok {
+			if he.Code < http.StatusInternalServerError {
+				code = models.CodeBadRequest
+			} else {
+				return models.InternalError(err)
+			}
+		}
+		message := http.StatusText(he.Code)
+		if m, ok := he.Message.(string); ok && m != "" {
+			message = m
+		} else if he.Message != nil {
+			message = fmt.Sprint(he.Message)
+		}
+		return models.NewAppError(he.Code, code, message).Wrap(err)
+	}
+	return models.ToAppError(err)
+}
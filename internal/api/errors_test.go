@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"app error", models.NotFoundError("Artwork not found"), http.StatusNotFound, models.CodeNotFound},
+		{"wrapped domain error", fmt.Errorf("service.UpdateUserProfile: %w", models.ErrNicknameTaken), http.StatusConflict, models.CodeNicknameTaken},
+		{"echo error", echo.ErrNotFound, http.StatusNotFound, models.CodeNotFound},
+		{"echo error without code", echo.NewHTTPError(http.StatusTeapot), http.StatusTeapot, models.CodeBadRequest},
+		{"unknown error", errors.New("pq: connection refused"), http.StatusInternalServerError, models.CodeInternal},
+	}
+
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+			HTTPErrorHandler(tt.err, c)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body models.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body %q: %v", rec.Body.String(), err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+			if body.Message == "" {
+				t.Error("message is empty")
+			}
+			// The cause of a server error must not reach the client
+			if strings.Contains(rec.Body.String(), "connection refused") {
+				t.Errorf("body leaks the cause: %s", rec.Body.String())
+			}
+		})
+	}
+}
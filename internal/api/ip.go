@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ParseTrustedProxies parses the TRUSTED_PROXIES CIDRs once for NewIPExtractor and middleware.RequestID.
+func ParseTrustedProxies(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("api.ParseTrustedProxies: trusted proxy %q: %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// NewIPExtractor returns the echo.IPExtractor behind c.RealIP(), which keys guest rate limits, contact
+// form throttling and the audit log. Headers are client input, so without trusted proxies the TCP peer
+// address is used. With them X-Forwarded-For is walked from the right and the first address outside
+// trustedProxies is the client; nothing else, not even loopback or private ranges, is trusted implicitly.
+func NewIPExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	opts := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, ipNet := range trustedProxies {
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...)
+}
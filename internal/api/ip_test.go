@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewIPExtractor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{"no proxies ignores the header", nil, "203.0.113.7:4000", "198.51.100.1", "203.0.113.7"},
+		{"untrusted peer ignores the header", []string{"10.0.0.0/8"}, "203.0.113.7:4000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.2:4000", "198.51.100.1", "198.51.100.1"},
+		// A client can prepend anything; only the hop our proxy appended counts
+		{"spoofed prefix", []string{"10.0.0.0/8"}, "10.0.0.2:4000", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"private peer is not trusted implicitly", []string{"10.0.0.0/8"}, "192.168.1.5:4000", "198.51.100.1", "192.168.1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trusted, err := ParseTrustedProxies(tt.trustedProxies)
+			if err != nil {
+				t.Fatalf("ParseTrustedProxies: %v", err)
+			}
+			extract := NewIPExtractor(trusted)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			if got := extract(req); got != tt.want {
+				t.Errorf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"not-a-cidr"}); err == nil {
+		t.Error("ParseTrustedProxies accepted an invalid CIDR")
+	}
+}
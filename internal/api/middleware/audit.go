@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"jingdezhen-ceramics-backend/internal/audit"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuditContext puts the request ID and client IP into the request context, where audit.Service.Record
+// picks them up. The request ID comes from the RequestID middleware, which must run first; the IP from
+// c.RealIP, which only believes X-Forwarded-For from trusted proxies.
+func AuditContext() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+			if requestID == "" {
+				requestID = c.Request().Header.Get(echo.HeaderXRequestID)
+			}
+			ctx := audit.WithRequest(c.Request().Context(), audit.RequestInfo{RequestID: requestID, IPAddress: c.RealIP()})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"jingdezhen-ceramics-backend/internal/models"
 	"net/http"
 
@@ -10,9 +12,26 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// RevocationChecker decides whether a validly signed token has been revoked server-side (e.g. after logout).
+// auth.Service implements it.
+type RevocationChecker interface {
+	IsAccessTokenRevoked(ctx context.Context, claims *models.JwtCustomClaims) (bool, error)
+}
+
+// PermissionChecker decides whether a role has a named permission. permission.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+// EmailVerificationChecker reports whether a user has confirmed their email address. auth.Service implements it.
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID string) (bool, error)
+}
+
 // JWTMAuth configures and returns Echo's JWT middleware.
-// It uses the jwtSecretKey from the config file (.env).
-func JWTMAuth(jwtSecretKey string) echo.MiddlewareFunc {
+// It uses the jwtSecretKey from the config file (.env). When revocation is not nil,
+// tokens it reports as revoked are rejected even though their signature and expiry are fine.
+func JWTMAuth(jwtSecretKey string, revocation RevocationChecker) echo.MiddlewareFunc {
 	config := echojwt.Config{
 		// NewClaimsFunc is required to specify the type of claims object to expect.
 		// The middleware will use this to parse the claims from the token.
@@ -28,14 +47,7 @@ func JWTMAuth(jwtSecretKey string) echo.MiddlewareFunc {
 		// SuccessHandler is called after a token is successfully validated.
 		// I use it here to extract our custom claims and put them into the context
 		SuccessHandler: func(c echo.Context) {
-			// "user" is the default context key used by echo-jwt
-			// c.Get("user") returns interface{}, so I need to type-assert it
-			userToken := c.Get("user").(*jwt.Token)
-			claims := userToken.Claims.(*models.JwtCustomClaims)
-
-			c.Set("userID", claims.UserID)
-			c.Set("userEmail", claims.Email)
-			c.Set("userRole", claims.Role)
+			claims := setClaimsInContext(c)
 			c.Logger().Infof("JWT Auth successful for user: %s, role: %s", claims.UserID, claims.Role)
 		},
 
@@ -46,59 +58,178 @@ func JWTMAuth(jwtSecretKey string) echo.MiddlewareFunc {
 
 			// Return a generic error message to the client
 			if errors.Is(err, echojwt.ErrJWTMissing) {
-				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: "Missing or malformed JWT"})
+				return models.UnauthorizedError("Missing or malformed JWT")
 			}
 			// Check for more specific errors from the golang-jwt library if wrapped
 			// For example, if err is of type *jwt.ValidationError
 			if errors.Is(err, jwt.ErrTokenMalformed) {
-				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: "Token is malformed"})
+				return models.UnauthorizedError("Token is malformed")
 			} else if errors.Is(err, jwt.ErrTokenExpired) {
-				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: "Token has expired"})
+				return models.UnauthorizedError("Token has expired")
 			} else if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
-				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: "Invalid token signature"})
+				return models.UnauthorizedError("Invalid token signature")
 			} else if err != nil {
-				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: "Token has expired"})
+				return models.UnauthorizedError("Token has expired")
 			}
 
-			return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: "Invalid or expired JWT"})
+			return models.UnauthorizedError("Invalid or expired JWT")
 		},
 		// ContextKey: "user", this is default
 	}
-	return echojwt.WithConfig(config)
+	jwtMiddleware := echojwt.WithConfig(config)
+	if revocation == nil {
+		return jwtMiddleware
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return jwtMiddleware(func(c echo.Context) error {
+			revoked, err := isRevoked(c, revocation)
+			if err != nil {
+				return models.InternalError(fmt.Errorf("JWT revocation check: %w", err))
+			}
+			if revoked {
+				return models.UnauthorizedError("Token has been revoked")
+			}
+			return next(c)
+		})
+	}
 }
 
-func AdminRequired() echo.MiddlewareFunc {
+// OptionalJWTMAuth is for public routes that personalise their response when the caller is logged in
+// (e.g. is_favorite on artworks). A valid token populates the context like JWTMAuth does;
+// a missing, invalid or revoked token is ignored and the request continues as a guest.
+func OptionalJWTMAuth(jwtSecretKey string, revocation RevocationChecker) echo.MiddlewareFunc {
+	config := echojwt.Config{
+		NewClaimsFunc: func(c echo.Context) jwt.Claims {
+			return new(models.JwtCustomClaims)
+		},
+		SigningKey: []byte(jwtSecretKey),
+		SuccessHandler: func(c echo.Context) {
+			setClaimsInContext(c)
+		},
+		ErrorHandler: func(c echo.Context, err error) error {
+			if !errors.Is(err, echojwt.ErrJWTMissing) {
+				c.Logger().Debugf("Optional JWT ignored: %v", err)
+			}
+			return nil
+		},
+		ContinueOnIgnoredError: true,
+	}
+	jwtMiddleware := echojwt.WithConfig(config)
+	if revocation == nil {
+		return jwtMiddleware
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return jwtMiddleware(func(c echo.Context) error {
+			if _, ok := c.Get("userID").(string); !ok {
+				return next(c) // Guest
+			}
+			revoked, err := isRevoked(c, revocation)
+			if err != nil || revoked {
+				if err != nil {
+					c.Logger().Errorf("JWT revocation check failed, continuing as guest: %v", err)
+				}
+				clearClaimsFromContext(c)
+			}
+			return next(c)
+		})
+	}
+}
+
+// isRevoked asks the checker about the token echo-jwt stored in the context.
+func isRevoked(c echo.Context, revocation RevocationChecker) (bool, error) {
+	userToken, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return false, nil
+	}
+	claims, ok := userToken.Claims.(*models.JwtCustomClaims)
+	if !ok {
+		return false, nil
+	}
+	return revocation.IsAccessTokenRevoked(c.Request().Context(), claims)
+}
+
+// setClaimsInContext copies our custom claims from the validated token into the Echo context.
+func setClaimsInContext(c echo.Context) *models.JwtCustomClaims {
+	// "user" is the default context key used by echo-jwt
+	// c.Get("user") returns interface{}, so I need to type-assert it
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(*models.JwtCustomClaims)
+
+	c.Set("userID", claims.UserID)
+	c.Set("userEmail", claims.Email)
+	c.Set("userRole", claims.Role)
+	return claims
+}
+
+// clearClaimsFromContext undoes setClaimsInContext so handlers see a guest.
+func clearClaimsFromContext(c echo.Context) {
+	c.Set("user", nil)
+	c.Set("userID", nil)
+	c.Set("userEmail", nil)
+	c.Set("userRole", nil)
+}
+
+// RequirePermission allows the request only if the caller's role has permission (see models.Perm*).
+// It must run after JWTMAuth. Roles map to permissions in the database, so granting e.g. forum.moderate
+// to moderators needs no code change.
+func RequirePermission(checker PermissionChecker, permission string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		// closure: this inner fucntion closes over "next" (the next middleware) from its surrounding lexical scope (the middle function). Even though the middle function is returned, the inner function has access to the "next" variable.
 		return func(c echo.Context) error {
 			userRole, ok := c.Get("userRole").(string)
 			if !ok {
-				// This case might happen if JWTMAuth's SuccessHandler somehow failed to set the role,
-				// or if AdminRequired is mistakenly used without JWTMAuth setting the context.
-				c.Logger().Error("userRole not found in context for AdminRequired middleware")
-				return c.JSON(http.StatusForbidden, models.ErrorResponse{Message: "Permission denied: Role not determined"})
+				c.Logger().Error("userRole not found in context for RequirePermission middleware")
+				return models.ForbiddenError("Permission denied: Role not determined")
+			}
+			allowed, err := checker.HasPermission(c.Request().Context(), userRole, permission)
+			if err != nil {
+				return models.InternalError(fmt.Errorf("permission check: %w", err))
+			}
+			if !allowed {
+				return models.ForbiddenError("Permission denied: " + permission + " required")
+			}
+			return next(c)
+		}
+	}
+}
+
+// EmailVerifiedRequired blocks write actions (e.g. forum posting) until the user has verified their email.
+// It must run after JWTMAuth. The flag is read from the database, so verifying takes effect immediately.
+func EmailVerifiedRequired(checker EmailVerificationChecker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("userID").(string)
+			if !ok || userID == "" {
+				c.Logger().Error("userID not found in context for EmailVerifiedRequired middleware")
+				return models.UnauthorizedError("Authentication required")
+			}
+			verified, err := checker.IsEmailVerified(c.Request().Context(), userID)
+			if err != nil {
+				// A deleted account is an authentication problem here, not a missing resource
+				if errors.Is(err, models.ErrNotFound) {
+					return models.UnauthorizedError("User no longer exists").Wrap(err)
+				}
+				return models.InternalError(fmt.Errorf("email verification check: %w", err))
 			}
-			if userRole != models.RoleAdmin { // Assuming models.RoleAdmin = "admin"
-				return c.JSON(http.StatusForbidden, models.ErrorResponse{Message: "Admin access required"})
+			if !verified {
+				return models.NewAppError(http.StatusForbidden, models.CodeEmailNotVerified, "Please verify your email address first")
 			}
 			return next(c)
 		}
 	}
 }
 
-// Might want a similar middleware for normal users if some routes are only for them
-// and not accessible by just any authenticated user (though often admin can do what normal user does)
-func NormalUserRequired() echo.MiddlewareFunc {
+// MemberRequired allows any logged-in user whatever their role, so staff such as teachers and curators
+// can use member features too; only guest tokens are turned away. It must run after JWTMAuth.
+func MemberRequired() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			userRole, ok := c.Get("userRole").(string)
 			if !ok {
-				c.Logger().Error("userRole not found in context for NormalUserRequired middleware")
-				return c.JSON(http.StatusForbidden, models.ErrorResponse{Message: "Permission denied: Role not determined"})
+				c.Logger().Error("userRole not found in context for MemberRequired middleware")
+				return models.ForbiddenError("Permission denied: Role not determined")
 			}
-			// Admins are often considered to also satisfy "normal user" requirements
-			if userRole != models.RoleNormalUser && userRole != models.RoleAdmin {
-				return c.JSON(http.StatusForbidden, models.ErrorResponse{Message: "Access restricted to normal users"})
+			if userRole == "" || userRole == models.RoleGuest {
+				return models.ForbiddenError("Access restricted to registered members")
 			}
 			return next(c)
 		}
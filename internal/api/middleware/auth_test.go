@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMemberRequired(t *testing.T) {
+	e := echo.New()
+	for _, role := range []string{models.RoleNormalUser, models.RoleTeacher, models.RoleCurator, models.RoleModerator, models.RoleAdmin, models.RoleGuest} {
+		t.Run(role, func(t *testing.T) {
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+			c.Set("userRole", role)
+			err := MemberRequired()(func(c echo.Context) error { return nil })(c)
+
+			var appErr *models.AppError
+			if role == models.RoleGuest {
+				if !errors.As(err, &appErr) || appErr.Status != http.StatusForbidden {
+					t.Errorf("error = %v, want 403", err)
+				}
+			} else if err != nil {
+				t.Errorf("error = %v, want access", err)
+			}
+		})
+	}
+}
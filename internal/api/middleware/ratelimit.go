@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimit limits requests with a token bucket per user, or per client IP for guests (c.RealIP, which
+// only believes X-Forwarded-For from trusted proxies, see api.NewIPExtractor). Place it after
+// JWTMAuth or OptionalJWTMAuth in a group so logged-in users get their own bucket instead of sharing
+// their IP's. Every response carries RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset;
+// rejected ones (429) also Retry-After. If the store fails the request is let through.
+func RateLimit(store ratelimit.Store, limit ratelimit.Limit) echo.MiddlewareFunc {
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(limit.Per.Seconds()))
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := limit.Name + ":ip:" + c.RealIP()
+			if userID, ok := c.Get("userID").(string); ok && userID != "" {
+				key = limit.Name + ":user:" + userID
+			}
+
+			result, err := store.Take(c.Request().Context(), key, limit)
+			if err != nil {
+				c.Logger().Error("RateLimit: ", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Policy", policy)
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+			if !result.Allowed {
+				header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				return models.NewAppError(http.StatusTooManyRequests, models.CodeRateLimited, "Too many requests, please try again later")
+			}
+			return next(c)
+		}
+	}
+}
+
+// ceilSeconds renders d as whole seconds, rounded up so clients never retry too early.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
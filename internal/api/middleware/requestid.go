@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+)
+
+// maxRequestIDLength bounds the request IDs accepted from upstream proxies.
+const maxRequestIDLength = 64
+
+// RequestID is echo's RequestID middleware, except that an incoming X-Request-ID is only kept if the TCP
+// peer is in trustedProxies (our own proxies, see api.ParseTrustedProxies) and the ID is at most
+// maxRequestIDLength letters, digits, '-', '_' or '.'; otherwise a fresh ID is generated. The ID ends up
+// in logs and audit events, so clients must not be able to choose it or put arbitrary text there.
+func RequestID(trustedProxies []*net.IPNet) echo.MiddlewareFunc {
+	requestID := echomw.RequestID()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		h := requestID(next)
+		return func(c echo.Context) error {
+			req := c.Request()
+			if id := req.Header.Get(echo.HeaderXRequestID); id != "" && (!fromTrustedProxy(req, trustedProxies) || !validRequestID(id)) {
+				req.Header.Del(echo.HeaderXRequestID)
+			}
+			return h(c)
+		}
+	}
+}
+
+// fromTrustedProxy reports whether the TCP peer of req, not any forwarded address, is in trustedProxies.
+func fromTrustedProxy(req *http.Request, trustedProxies []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func validRequestID(id string) bool {
+	if len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		incoming   string
+		keep       bool
+	}{
+		{"none", "10.0.0.2:4000", "", false},
+		{"trusted proxy", "10.0.0.2:4000", "req-7f3a_01.b", true},
+		{"direct client", "203.0.113.7:4000", "req-7f3a_01.b", false},
+		{"too long", "10.0.0.2:4000", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"bad characters", "10.0.0.2:4000", "id\r\nX-Admin: 1", false},
+	}
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.incoming != "" {
+				req.Header.Set(echo.HeaderXRequestID, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h := RequestID([]*net.IPNet{proxies})(func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+			if err := h(e.NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+
+			got := rec.Header().Get(echo.HeaderXRequestID)
+			if tt.keep && got != tt.incoming {
+				t.Errorf("request ID = %q, want the incoming %q", got, tt.incoming)
+			}
+			if !tt.keep && (got == "" || got == tt.incoming || !validRequestID(got)) {
+				t.Errorf("request ID = %q, want a fresh one", got)
+			}
+		})
+	}
+}
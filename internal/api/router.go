@@ -1,23 +1,51 @@
 package api
 
 import (
+	"jingdezhen-ceramics-backend/internal/admin"
 	"jingdezhen-ceramics-backend/internal/api/middleware"
+	"jingdezhen-ceramics-backend/internal/audit"
+	"jingdezhen-ceramics-backend/internal/auth"
 	"jingdezhen-ceramics-backend/internal/ceramicstory"
+	"jingdezhen-ceramics-backend/internal/contact"
 	"jingdezhen-ceramics-backend/internal/course"
 	"jingdezhen-ceramics-backend/internal/engage"
 	"jingdezhen-ceramics-backend/internal/forum"
 	"jingdezhen-ceramics-backend/internal/gallery"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/outbox"
+	"jingdezhen-ceramics-backend/internal/permission"
 	"jingdezhen-ceramics-backend/internal/portfolio"
+	"jingdezhen-ceramics-backend/internal/ratelimit"
 	"jingdezhen-ceramics-backend/internal/user"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
-// SetupRoutes configures the API routes.
+// Rate limits of the public and write-heavy route groups
+var (
+	authRateLimit       = ratelimit.Limit{Name: "auth", Requests: 10, Per: time.Minute}
+	contactRateLimit    = ratelimit.Limit{Name: "contact", Requests: 5, Per: 10 * time.Minute, Burst: 2}
+	forumWriteRateLimit = ratelimit.Limit{Name: "forum-write", Requests: 30, Per: 10 * time.Minute, Burst: 10}
+)
+
+// SetupRoutes configures the API routes. revocation is consulted by every JWT middleware so logged-out
+// sessions stop working before their access tokens expire; verification guards write actions;
+// permissions decides which roles may use each admin route; limiter holds the rate limit buckets.
 func SetupRoutes(
 	e *echo.Echo, jwtSecretKey string,
+	revocation middleware.RevocationChecker,
+	verification middleware.EmailVerificationChecker,
+	permissions middleware.PermissionChecker,
+	limiter ratelimit.Store,
+	authHandler *auth.Handler,
 	userHandler *user.Handler,
+	adminHandler *admin.Handler,
+	outboxHandler *outbox.Handler,
+	permissionHandler *permission.Handler,
+	auditHandler *audit.Handler,
+	contactHandler *contact.Handler,
 	csHandler *ceramicstory.Handler,
 	galleryHandler *gallery.Handler,
 	engageHandler *engage.Handler,
@@ -25,17 +53,35 @@ func SetupRoutes(
 	forumHandler *forum.Handler,
 	portfolioHandler *portfolio.Handler,
 ) {
+	e.Use(middleware.AuditContext()) // Request ID and IP for audit events
+
 	e.GET("/", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]string{"message": "Welcome to Jingdezhen Ceramics Learning and Communication Platform!"})
 	})
 
+	/* --- Auth (Public) --- */
+	authGroup := e.Group("/auth")
+	authGroup.Use(middleware.RateLimit(limiter, authRateLimit)) // Per IP: slows down credential stuffing
+	{
+		authGroup.POST("/register", authHandler.Register)
+		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/logout-all", authHandler.LogoutAll, middleware.JWTMAuth(jwtSecretKey, revocation))
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/verify-email/resend", authHandler.ResendVerification, middleware.JWTMAuth(jwtSecretKey, revocation))
+		authGroup.POST("/password-reset/request", authHandler.RequestPasswordReset)
+		authGroup.POST("/password-reset/confirm", authHandler.ResetPassword)
+	}
+	requireVerifiedEmail := middleware.EmailVerifiedRequired(verification)
+
 	/* --- Contact (send feedback) --- */
-	e.POST("/contact", userHandler.SubmitContactForm)
+	e.GET("/contact/form-token", contactHandler.GetFormToken)
+	e.POST("/contact", contactHandler.SubmitContactForm, middleware.RateLimit(limiter, contactRateLimit))
 
 	/* --- User Profile (Protected) --- */
-	// If need backend routes for auth (e.g., refresh token, logout initiated by backend), define here.
 	profileGroup := e.Group("/profile")
-	profileGroup.Use(middleware.JWTMAuth(jwtSecretKey))
+	profileGroup.Use(middleware.JWTMAuth(jwtSecretKey, revocation))
 	{
 		profileGroup.GET("", userHandler.GetProfile)
 		profileGroup.PUT("", userHandler.UpdateProfile)
@@ -61,6 +107,7 @@ func SetupRoutes(
 
 	/* --- Gallery (Public for viewing, Protected for actions) --- */
 	gGroup := e.Group("/gallery")
+	gGroup.Use(middleware.OptionalJWTMAuth(jwtSecretKey, revocation)) // Populates is_favorite for logged-in viewers
 	{
 		gGroup.GET("/artworks", galleryHandler.GetArtworks) // Params: ?category=...&artist=...
 		gGroup.GET("/artworks/:artwork_id", galleryHandler.GetArtworkByID)
@@ -70,7 +117,7 @@ func SetupRoutes(
 
 		// Protected actions for gallery
 		authGalleryGroup := gGroup.Group("")
-		authGalleryGroup.Use(middleware.JWTMAuth(jwtSecretKey))
+		authGalleryGroup.Use(middleware.JWTMAuth(jwtSecretKey, revocation))
 		{
 			authGalleryGroup.POST("/artworks/:artwork_id/favorite", galleryHandler.MarkAsFavorite)
 			authGalleryGroup.DELETE("/artworks/:artwork_id/favorite", galleryHandler.UnmarkAsFavorite)
@@ -81,12 +128,13 @@ func SetupRoutes(
 	/* --- Engage (Public) --- */
 	engageGroup := e.Group("/engage")
 	{
-		engageGroup.GET("", engageHandler.GetActivities)
+		engageGroup.GET("", engageHandler.GetActivities)                           // Params: ?type=...&when=upcoming|past
 		engageGroup.GET("/:activity_id_or_slug", engageHandler.GetActivityArticle) // For detailed article
 	}
 
 	/* --- Course (Mixed Public/Protected) --- */
 	cGroup := e.Group("/courses")
+	cGroup.Use(middleware.OptionalJWTMAuth(jwtSecretKey, revocation)) // Enrolled viewers can read past the free preview
 	{
 		cGroup.GET("", courseHandler.GetAllCourses)
 		cGroup.GET("/:course_id", courseHandler.GetCourseDetails)                       // Chapters list
@@ -94,14 +142,16 @@ func SetupRoutes(
 
 		// Protected access for full course and progress:
 		authCourseGroup := cGroup.Group("")
-		authCourseGroup.Use(middleware.JWTMAuth(jwtSecretKey))
-		authCourseGroup.Use(middleware.NormalUserRequired())
+		authCourseGroup.Use(middleware.JWTMAuth(jwtSecretKey, revocation))
+		authCourseGroup.Use(middleware.MemberRequired())
 		{
 			authCourseGroup.POST("/:course_id/enroll", courseHandler.EnrollCourse)
 			authCourseGroup.GET("/:course_id/chapters/:chapter_id/full", courseHandler.GetFullChapterContentForEnrolled)
 			authCourseGroup.POST("/:course_id/chapters/:chapter_id/progress", courseHandler.UpdateProgress)
 			authCourseGroup.POST("/:course_id/chapters/:chapter_id/notes", courseHandler.AddNoteToChapter)
+			authCourseGroup.GET("/:course_id/chapters/:chapter_id/quizzes/:quiz_id", courseHandler.GetQuiz)
 			authCourseGroup.POST("/:course_id/chapters/:chapter_id/quizzes/:quiz_id/submit", courseHandler.SubmitQuiz)
+			authCourseGroup.GET("/:course_id/chapters/:chapter_id/quizzes/:quiz_id/attempts", courseHandler.GetQuizAttempts)
 		}
 		// Video related endpoints if needed (e.g., video quiz submissions)
 	}
@@ -117,13 +167,14 @@ func SetupRoutes(
 
 		// Protected actions
 		authForumGroup := fGroup.Group("")
-		authForumGroup.Use(middleware.JWTMAuth(jwtSecretKey))
+		authForumGroup.Use(middleware.JWTMAuth(jwtSecretKey, revocation))
+		authForumGroup.Use(middleware.RateLimit(limiter, forumWriteRateLimit)) // Per user, so after JWTMAuth
 		{
-			authForumGroup.POST("/posts", forumHandler.CreatePost)
-			authForumGroup.PUT("/posts/:post_id", forumHandler.UpdatePost)    // Check ownership
-			authForumGroup.DELETE("/posts/:post_id", forumHandler.DeletePost) // Check ownership or admin
-			authForumGroup.POST("/posts/:post_id/comments", forumHandler.CreateComment)
-			authForumGroup.PUT("/comments/:comment_id", forumHandler.UpdateComment)
+			authForumGroup.POST("/posts", forumHandler.CreatePost, requireVerifiedEmail)
+			authForumGroup.PUT("/posts/:post_id", forumHandler.UpdatePost, requireVerifiedEmail) // Check ownership
+			authForumGroup.DELETE("/posts/:post_id", forumHandler.DeletePost)                    // Check ownership or admin
+			authForumGroup.POST("/posts/:post_id/comments", forumHandler.CreateComment, requireVerifiedEmail)
+			authForumGroup.PUT("/comments/:comment_id", forumHandler.UpdateComment, requireVerifiedEmail)
 			authForumGroup.DELETE("/comments/:comment_id", forumHandler.DeleteComment)
 			authForumGroup.POST("/posts/:post_id/like", forumHandler.LikePost)
 			authForumGroup.POST("/posts/:post_id/save", forumHandler.SavePost)
@@ -134,13 +185,14 @@ func SetupRoutes(
 
 	/* --- Portfolio (Public read, Protected kudos) --- */
 	pGroup := e.Group("/portfolio")
+	pGroup.Use(middleware.OptionalJWTMAuth(jwtSecretKey, revocation)) // Populates has_given_kudo for logged-in viewers
 	{
 		pGroup.GET("", portfolioHandler.GetWorks) // Params: ?page=1&category=...&sort=kudos
 		pGroup.GET("/:work_id", portfolioHandler.GetWorkByID)
 
 		// Protected actions
 		authPortfolioGroup := pGroup.Group("")
-		authPortfolioGroup.Use(middleware.JWTMAuth(jwtSecretKey))
+		authPortfolioGroup.Use(middleware.JWTMAuth(jwtSecretKey, revocation))
 		{
 			authPortfolioGroup.POST("/works", portfolioHandler.CreateWork)
 			authPortfolioGroup.PUT("/works/:work_id", portfolioHandler.UpdateWork)
@@ -149,16 +201,71 @@ func SetupRoutes(
 		}
 	}
 
-	/* --- Admin Routes (Protected by Admin Role) --- */
+	/* --- Admin Routes (Protected by named permissions, see models.Perm*) --- */
 	adminGroup := e.Group("/admin")
-	adminGroup.Use(middleware.JWTMAuth(jwtSecretKey))
-	adminGroup.Use(middleware.AdminRequired())
+	adminGroup.Use(middleware.JWTMAuth(jwtSecretKey, revocation))
 	{
-		adminGroup.GET("/dashboard/student-progress", adminHandler.GetStudentProgressDashboard)
-		adminGroup.POST("/forum/posts/:post_id/pin", adminHandler.PinForumPost)
-		adminGroup.POST("/forum/posts/:post_id/archive", adminHandler.ArchiveForumPost)
-		adminGroup.DELETE("/forum/posts/:post_id", adminHandler.DeleteForumPostAsAdmin)
-		adminGroup.POST("/portfolio/works/:work_id/highlight", adminHandler.HighlightPortfolioWork)
+		requireUsersManage := middleware.RequirePermission(permissions, models.PermUsersManage)
+		adminGroup.GET("/users", userHandler.AdminListUsers, requireUsersManage)
+		adminGroup.PUT("/users/:user_id/role", userHandler.AdminUpdateUserRole, requireUsersManage)
+		adminGroup.GET("/role-changes", userHandler.AdminListRoleChanges, requireUsersManage) // Params: ?page=1&limit=20&actor_id=1&user_id=2
+
+		requireRolesManage := middleware.RequirePermission(permissions, models.PermRolesManage)
+		adminGroup.GET("/roles", permissionHandler.GetRoles, requireRolesManage)
+		adminGroup.GET("/permissions", permissionHandler.GetPermissions, requireRolesManage)
+		adminGroup.PUT("/roles/:role_name/permissions", permissionHandler.UpdateRolePermissions, requireRolesManage)
+
+		requireAuditView := middleware.RequirePermission(permissions, models.PermAuditView)
+		adminGroup.GET("/audit-events", auditHandler.GetEvents, requireAuditView)           // Params: ?page=1&limit=20&actor_id=1&action=&entity_type=&entity_id=&from=2025-01-01&to=2025-01-31
+		adminGroup.GET("/audit-events/export", auditHandler.ExportEvents, requireAuditView) // Same filters, CSV without pagination
+
+		requireViewProgress := middleware.RequirePermission(permissions, models.PermCourseViewProgress)
+		adminGroup.GET("/dashboard/student-progress", adminHandler.GetStudentProgressDashboard, requireViewProgress)
+		adminGroup.GET("/dashboard/student-progress/courses/:course_id", adminHandler.GetCourseStudentProgress, requireViewProgress)
+
+		requireForumModerate := middleware.RequirePermission(permissions, models.PermForumModerate)
+		adminGroup.POST("/forum/posts/:post_id/pin", adminHandler.PinForumPost, requireForumModerate)
+		adminGroup.POST("/forum/posts/:post_id/archive", adminHandler.ArchiveForumPost, requireForumModerate)
+		adminGroup.DELETE("/forum/posts/:post_id", adminHandler.DeleteForumPostAsAdmin, requireForumModerate)
+
+		adminGroup.POST("/portfolio/works/:work_id/highlight", adminHandler.HighlightPortfolioWork,
+			middleware.RequirePermission(permissions, models.PermPortfolioModerate))
+
+		requireStoryEdit := middleware.RequirePermission(permissions, models.PermCeramicStoryEdit)
+		adminGroup.POST("/ceramicstory", csHandler.CreateCeramicStory, requireStoryEdit)
+		adminGroup.PUT("/ceramicstory/:story_id", csHandler.UpdateCeramicStory, requireStoryEdit)
+		adminGroup.DELETE("/ceramicstory/:story_id", csHandler.DeleteCeramicStory, requireStoryEdit)
+
+		requireGalleryEdit := middleware.RequirePermission(permissions, models.PermGalleryEdit)
+		adminGroup.POST("/gallery/artworks", galleryHandler.CreateArtwork, requireGalleryEdit)
+		adminGroup.PUT("/gallery/artworks/:artwork_id", galleryHandler.UpdateArtwork, requireGalleryEdit)
+		adminGroup.DELETE("/gallery/artworks/:artwork_id", galleryHandler.DeleteArtwork, requireGalleryEdit)
+		adminGroup.POST("/gallery/artists", galleryHandler.CreateArtist, requireGalleryEdit)
+		adminGroup.PUT("/gallery/artists/:artist_id", galleryHandler.UpdateArtist, requireGalleryEdit)
+		adminGroup.DELETE("/gallery/artists/:artist_id", galleryHandler.DeleteArtist, requireGalleryEdit)
+
+		requireCourseAuthor := middleware.RequirePermission(permissions, models.PermCourseAuthor)
+		adminGroup.POST("/courses", courseHandler.CreateCourse, requireCourseAuthor)
+		adminGroup.PUT("/courses/:course_id", courseHandler.UpdateCourse, requireCourseAuthor)
+		adminGroup.DELETE("/courses/:course_id", courseHandler.DeleteCourse, requireCourseAuthor)
+		adminGroup.POST("/courses/:course_id/chapters", courseHandler.CreateChapter, requireCourseAuthor)
+		adminGroup.PUT("/courses/:course_id/chapters/:chapter_id", courseHandler.UpdateChapter, requireCourseAuthor)
+		adminGroup.DELETE("/courses/:course_id/chapters/:chapter_id", courseHandler.DeleteChapter, requireCourseAuthor)
+		adminGroup.POST("/courses/:course_id/chapters/:chapter_id/quizzes", courseHandler.CreateQuiz, requireCourseAuthor)
+		adminGroup.PUT("/courses/:course_id/chapters/:chapter_id/quizzes/:quiz_id", courseHandler.UpdateQuiz, requireCourseAuthor)
+		adminGroup.DELETE("/courses/:course_id/chapters/:chapter_id/quizzes/:quiz_id", courseHandler.DeleteQuiz, requireCourseAuthor)
+
+		requireEmailsManage := middleware.RequirePermission(permissions, models.PermEmailsManage)
+		adminGroup.GET("/emails/failed", outboxHandler.GetFailedEmails, requireEmailsManage) // Params: ?page=1&limit=20
+		adminGroup.POST("/emails/:email_id/replay", outboxHandler.ReplayEmail, requireEmailsManage)
+		adminGroup.GET("/emails/templates", outboxHandler.GetEmailTemplates, requireEmailsManage)
+		adminGroup.GET("/emails/templates/:template_name/preview", outboxHandler.PreviewEmailTemplate, requireEmailsManage) // Params: ?locale=zh-CN&format=json|html|text
+
+		requireContactManage := middleware.RequirePermission(permissions, models.PermContactManage)
+		adminGroup.GET("/contact-messages", contactHandler.GetMessages, requireContactManage) // Params: ?page=1&limit=20&status=new&q=keyword
+		adminGroup.GET("/contact-messages/:message_id", contactHandler.GetMessage, requireContactManage)
+		adminGroup.PUT("/contact-messages/:message_id/status", contactHandler.UpdateMessageStatus, requireContactManage)
+		adminGroup.POST("/contact-messages/:message_id/reply", contactHandler.ReplyToMessage, requireContactManage)
 		// ... other admin functionalities
 	}
 }
@@ -0,0 +1,112 @@
+// Package audit records administrative and destructive actions in the audit_events table.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// Action names what was done. The form is <area>.<entity>.<verb>.
+type Action string
+
+const (
+	ActionForumPostDelete        Action = "forum.post.delete"
+	ActionForumPostPin           Action = "forum.post.pin"
+	ActionForumPostArchive       Action = "forum.post.archive"
+	ActionForumCommentDelete     Action = "forum.comment.delete"
+	ActionPortfolioWorkDelete    Action = "portfolio.work.delete"
+	ActionPortfolioWorkHighlight Action = "portfolio.work.highlight"
+	ActionUserRoleChange         Action = "user.role.change"
+	ActionRolePermissionsUpdate  Action = "role.permissions.update"
+)
+
+// Entity types the actions apply to.
+const (
+	EntityForumPost     = "forum_post"
+	EntityForumComment  = "forum_comment"
+	EntityPortfolioWork = "portfolio_work"
+	EntityUser          = "user"
+	EntityRole          = "role"
+)
+
+// Event is what a service reports. Before and After are any JSON-marshalable values, typically the
+// entity or a small map of the changed fields; Record keeps only the top-level fields that differ.
+// Leave Before nil for creations and After nil for deletions.
+type Event struct {
+	ActorID    string // Empty for system actions
+	Action     Action
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+// Recorder is what services depend on to write the audit log.
+type Recorder interface {
+	// Record stores event. Failures are logged, not returned: the action has already happened and an
+	// audit outage must not block moderation.
+	Record(ctx context.Context, event Event)
+}
+
+type requestKey struct{}
+
+// RequestInfo identifies the HTTP request an event came from.
+type RequestInfo struct {
+	RequestID string
+	IPAddress string
+}
+
+// WithRequest returns a context carrying info; middleware.AuditContext sets it for every request.
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey{}, info)
+}
+
+func requestFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestKey{}).(RequestInfo)
+	return info
+}
+
+// diff marshals before and after and drops the top-level fields that are equal in both. Values that
+// are not JSON objects are kept whole. A nil side stays nil.
+func diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	b, err := marshalOrNil(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := marshalOrNil(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b == nil || a == nil {
+		return b, a, nil
+	}
+
+	var bm, am map[string]any
+	if json.Unmarshal(b, &bm) != nil || json.Unmarshal(a, &am) != nil {
+		return b, a, nil
+	}
+	for k, bv := range bm {
+		if av, ok := am[k]; ok && reflect.DeepEqual(av, bv) {
+			delete(bm, k)
+			delete(am, k)
+		}
+	}
+	if b, err = json.Marshal(bm); err != nil {
+		return nil, nil, err
+	}
+	if a, err = json.Marshal(am); err != nil {
+		return nil, nil, err
+	}
+	return b, a, nil
+}
+
+func marshalOrNil(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
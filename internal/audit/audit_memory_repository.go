@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. Like the table it is
+// append-only; Events returns everything recorded so far.
+type MemoryRepository struct {
+	mu     sync.Mutex
+	events []models.AuditEvent
+	lastID int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory audit log.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+// Events returns every stored event in insertion order.
+func (r *MemoryRepository) Events() []models.AuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]models.AuditEvent, len(r.events))
+	for i, e := range r.events {
+		events[i] = copyEvent(e)
+	}
+	return events
+}
+
+// copyEvent detaches the JSON so callers cannot change stored events. An empty side is nil, as the
+// table stores it as NULL.
+func copyEvent(e models.AuditEvent) models.AuditEvent {
+	e.Before, e.After = cloneJSON(e.Before), cloneJSON(e.After)
+	return e
+}
+
+func cloneJSON(raw []byte) []byte {
+	if len(raw) == 0 {
+		return nil
+	}
+	return bytes.Clone(raw)
+}
+
+func (r *MemoryRepository) Insert(ctx context.Context, event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	event.ID = r.lastID
+	event.CreatedAt = time.Now()
+	r.events = append(r.events, copyEvent(*event))
+	return nil
+}
+
+// matching returns the events matching filter, newest first like the SQL.
+func (r *MemoryRepository) matching(filter models.AuditEventFilter) []models.AuditEvent {
+	var events []models.AuditEvent
+	for _, e := range r.events {
+		if (filter.ActorID != "" && e.ActorID != filter.ActorID) ||
+			(filter.Action != "" && e.Action != filter.Action) ||
+			(filter.EntityType != "" && e.EntityType != filter.EntityType) ||
+			(filter.EntityID != "" && e.EntityID != filter.EntityID) ||
+			(filter.From != nil && e.CreatedAt.Before(*filter.From)) ||
+			(filter.To != nil && !e.CreatedAt.Before(*filter.To)) {
+			continue
+		}
+		events = append(events, copyEvent(e))
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.After(events[j].CreatedAt)
+		}
+		return events[i].ID > events[j].ID
+	})
+	return events
+}
+
+func (r *MemoryRepository) List(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.matching(filter)
+	offset := (filter.Page - 1) * filter.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	page := []models.AuditEvent{}
+	for i := offset; i < len(events) && i < offset+filter.Limit; i++ {
+		page = append(page, events[i])
+	}
+	return page, len(events), nil
+}
+
+func (r *MemoryRepository) Each(ctx context.Context, filter models.AuditEventFilter, max int, fn func(models.AuditEvent) error) error {
+	r.mu.Lock()
+	events := r.matching(filter)
+	r.mu.Unlock() // fn may record events itself
+
+	for i, e := range events {
+		if i == max {
+			break
+		}
+		if err := fn(e); err != nil {
+			return fmt.Errorf("repository.EachAuditEvent: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines methods for the audit_events table. There is no update or delete:
+// the table rejects both.
+type RepositoryInterface interface {
+	Insert(ctx context.Context, event *models.AuditEvent) error
+	List(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, int, error)
+	// Each calls fn for up to max events matching filter, newest first, ignoring Page and Limit.
+	Each(ctx context.Context, filter models.AuditEventFilter, max int, fn func(models.AuditEvent) error) error
+}
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+const auditEventColumns = `id, COALESCE(actor_id::text, ''), action, entity_type, entity_id, before_data, after_data,
+	request_id, ip_address, created_at`
+
+func (r *Repository) Insert(ctx context.Context, event *models.AuditEvent) error {
+	var actorID *string
+	if event.ActorID != "" {
+		actorID = &event.ActorID
+	}
+	query := `INSERT INTO audit_events (actor_id, action, entity_type, entity_id, before_data, after_data, request_id, ip_address)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	          RETURNING id, created_at`
+	err := r.db.QueryRow(ctx, query,
+		actorID, event.Action, event.EntityType, event.EntityID, nullableJSON(event.Before), nullableJSON(event.After),
+		event.RequestID, event.IPAddress,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repository.InsertAuditEvent: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) List(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, int, error) {
+	whereClause, args := buildWhere(filter)
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM audit_events "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListAuditEvents.Count: %w", err)
+	}
+
+	argIdx := len(args) + 1
+	query := fmt.Sprintf(`SELECT %s FROM audit_events %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`,
+		auditEventColumns, whereClause, argIdx, argIdx+1)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	events := []models.AuditEvent{}
+	err := r.query(ctx, query, args, func(event models.AuditEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListAuditEvents: %w", err)
+	}
+	return events, total, nil
+}
+
+func (r *Repository) Each(ctx context.Context, filter models.AuditEventFilter, max int, fn func(models.AuditEvent) error) error {
+	whereClause, args := buildWhere(filter)
+	query := fmt.Sprintf(`SELECT %s FROM audit_events %s ORDER BY created_at DESC, id DESC LIMIT $%d`,
+		auditEventColumns, whereClause, len(args)+1)
+	args = append(args, max)
+
+	if err := r.query(ctx, query, args, fn); err != nil {
+		return fmt.Errorf("repository.EachAuditEvent: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) query(ctx context.Context, query string, args []interface{}, fn func(models.AuditEvent) error) error {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.AuditEvent
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.EntityType, &e.EntityID, &before, &after,
+			&e.RequestID, &e.IPAddress, &e.CreatedAt); err != nil {
+			return fmt.Errorf("scan: %w", err)
+		}
+		e.Before, e.After = before, after
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func buildWhere(filter models.AuditEventFilter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	argIdx := 1
+
+	add := func(clause string, value interface{}) {
+		where = append(where, fmt.Sprintf(clause, argIdx))
+		args = append(args, value)
+		argIdx++
+	}
+	if filter.ActorID != "" {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		add("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		add("entity_id = $%d", filter.EntityID)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	if len(where) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(where, " AND "), args
+}
+
+// nullableJSON stores an empty side as SQL NULL rather than a JSON null.
+func nullableJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty repository.
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewRepository(pgtest.NewDB(t)) })
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewMemoryRepository() })
+}
+
+// sameJSON compares JSON by value, since jsonb does not keep the original spacing or key order.
+func sameJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var av, bv any
+	return json.Unmarshal(a, &av) == nil && json.Unmarshal(b, &bv) == nil && reflect.DeepEqual(av, bv)
+}
+
+func testRepositoryContract(t *testing.T, newRepo func(t *testing.T) RepositoryInterface) {
+	ctx := context.Background()
+	insert := func(t *testing.T, repo RepositoryInterface, actorID string, action Action, entityID string) *models.AuditEvent {
+		t.Helper()
+		event := &models.AuditEvent{
+			ActorID: actorID, Action: string(action), EntityType: EntityForumPost, EntityID: entityID,
+			Before: json.RawMessage(`{"is_pinned":false}`), After: json.RawMessage(`{"is_pinned":true}`),
+			RequestID: "req-1", IPAddress: "203.0.113.7",
+		}
+		if err := repo.Insert(ctx, event); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+		return event
+	}
+	ids := func(events []models.AuditEvent) []int64 {
+		ids := []int64{}
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	t.Run("InsertAndList", func(t *testing.T) {
+		repo := newRepo(t)
+		first := insert(t, repo, "7", ActionForumPostPin, "1")
+		if first.ID == 0 || first.CreatedAt.IsZero() {
+			t.Fatalf("Insert = %+v, want ID and CreatedAt set", first)
+		}
+		system := &models.AuditEvent{Action: string(ActionForumPostDelete), EntityType: EntityForumPost, EntityID: "2",
+			Before: json.RawMessage(`{"title": "Kiln"}`)}
+		if err := repo.Insert(ctx, system); err != nil {
+			t.Fatalf("Insert(system): %v", err)
+		}
+
+		events, total, err := repo.List(ctx, models.AuditEventFilter{Page: 1, Limit: 10})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != 2 || !reflect.DeepEqual(ids(events), []int64{system.ID, first.ID}) {
+			t.Fatalf("List = %v (total %d), want [%d %d]", ids(events), total, system.ID, first.ID)
+		}
+		got := events[1]
+		if got.ActorID != "7" || got.Action != string(ActionForumPostPin) || got.EntityID != "1" ||
+			got.RequestID != "req-1" || got.IPAddress != "203.0.113.7" ||
+			!sameJSON(got.Before, first.Before) || !sameJSON(got.After, first.After) {
+			t.Errorf("List[1] = %+v, want the first event", got)
+		}
+		if events[0].ActorID != "" || events[0].After != nil || !sameJSON(events[0].Before, system.Before) {
+			t.Errorf("List[0] = %+v, want a system deletion without after data", events[0])
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		repo := newRepo(t)
+		pin := insert(t, repo, "7", ActionForumPostPin, "1")
+		archive := insert(t, repo, "7", ActionForumPostArchive, "1")
+		other := insert(t, repo, "8", ActionForumPostPin, "2")
+		hourAgo, inAnHour := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+		tests := []struct {
+			name   string
+			filter models.AuditEventFilter
+			want   []int64
+		}{
+			{"actor", models.AuditEventFilter{ActorID: "7"}, []int64{archive.ID, pin.ID}},
+			{"action", models.AuditEventFilter{Action: string(ActionForumPostPin)}, []int64{other.ID, pin.ID}},
+			{"entity", models.AuditEventFilter{EntityType: EntityForumPost, EntityID: "2"}, []int64{other.ID}},
+			{"other entity type", models.AuditEventFilter{EntityType: EntityUser}, []int64{}},
+			{"from", models.AuditEventFilter{From: &hourAgo}, []int64{other.ID, archive.ID, pin.ID}},
+			{"to is exclusive", models.AuditEventFilter{To: &hourAgo}, []int64{}},
+			{"window", models.AuditEventFilter{From: &hourAgo, To: &inAnHour, ActorID: "8"}, []int64{other.ID}},
+		}
+		for _, tt := range tests {
+			tt.filter.Page, tt.filter.Limit = 1, 10
+			events, total, err := repo.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List(%s): %v", tt.name, err)
+			}
+			if total != len(tt.want) || !reflect.DeepEqual(ids(events), tt.want) {
+				t.Errorf("List(%s) = %v (total %d), want %v", tt.name, ids(events), total, tt.want)
+			}
+		}
+
+		events, total, err := repo.List(ctx, models.AuditEventFilter{Page: 2, Limit: 2})
+		if err != nil || total != 3 || !reflect.DeepEqual(ids(events), []int64{pin.ID}) {
+			t.Errorf("List(page 2) = %v (total %d, %v), want [%d] of 3", ids(events), total, err, pin.ID)
+		}
+	})
+
+	t.Run("Each", func(t *testing.T) {
+		repo := newRepo(t)
+		first := insert(t, repo, "7", ActionForumPostPin, "1")
+		second := insert(t, repo, "7", ActionForumPostPin, "2")
+		insert(t, repo, "8", ActionForumPostPin, "3")
+
+		var seen []int64
+		err := repo.Each(ctx, models.AuditEventFilter{ActorID: "7", Page: 5, Limit: 1}, 10, func(e models.AuditEvent) error {
+			seen = append(seen, e.ID)
+			return nil
+		})
+		if err != nil || !reflect.DeepEqual(seen, []int64{second.ID, first.ID}) {
+			t.Errorf("Each = %v (%v), want [%d %d] regardless of Page and Limit", seen, err, second.ID, first.ID)
+		}
+
+		seen = nil
+		if err := repo.Each(ctx, models.AuditEventFilter{}, 2, func(e models.AuditEvent) error {
+			seen = append(seen, e.ID)
+			return nil
+		}); err != nil || len(seen) != 2 {
+			t.Errorf("Each(max 2) visited %v (%v), want 2 events", seen, err)
+		}
+
+		stop := errors.New("stop")
+		calls := 0
+		err = repo.Each(ctx, models.AuditEventFilter{}, 10, func(models.AuditEvent) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Each with a failing fn = %v after %d calls, want the error after 1", err, calls)
+		}
+	})
+}
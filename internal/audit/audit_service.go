@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"log"
+	"time"
+)
+
+// ExportLimit caps the rows of one CSV export; narrow the filter to get older entries.
+const ExportLimit = 50000
+
+// recordTimeout bounds the write when the request context is already cancelled or about to be.
+const recordTimeout = 5 * time.Second
+
+// ServiceInterface defines the audit log: recording for services, querying for admins.
+type ServiceInterface interface {
+	Recorder
+	List(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, int, error)
+	// Export calls fn for every event matching filter, newest first, up to ExportLimit.
+	Export(ctx context.Context, filter models.AuditEventFilter, fn func(models.AuditEvent) error) error
+}
+
+type Service struct {
+	repo RepositoryInterface
+}
+
+func NewService(repo RepositoryInterface) ServiceInterface {
+	return &Service{repo: repo}
+}
+
+// Record stores event with the request ID and client IP found in ctx. It detaches from ctx's
+// cancellation so an event is not lost because the client hung up right after the action.
+func (s *Service) Record(ctx context.Context, event Event) {
+	before, after, err := diff(event.Before, event.After)
+	if err != nil {
+		log.Printf("ERROR: audit: cannot encode %s on %s %s: %v", event.Action, event.EntityType, event.EntityID, err)
+		return
+	}
+	info := requestFrom(ctx)
+	stored := &models.AuditEvent{
+		ActorID:    event.ActorID,
+		Action:     string(event.Action),
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Before:     before,
+		After:      after,
+		RequestID:  info.RequestID,
+		IPAddress:  info.IPAddress,
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if err := s.repo.Insert(ctx, stored); err != nil {
+		log.Printf("ERROR: audit: cannot record %s on %s %s by user %q: %v",
+			event.Action, event.EntityType, event.EntityID, event.ActorID, err)
+	}
+}
+
+func (s *Service) List(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	events, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListAuditEvents: %w", err)
+	}
+	return events, total, nil
+}
+
+func (s *Service) Export(ctx context.Context, filter models.AuditEventFilter, fn func(models.AuditEvent) error) error {
+	if err := s.repo.Each(ctx, filter, ExportLimit, fn); err != nil {
+		return fmt.Errorf("service.ExportAuditEvents: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// registerAcceptedMessage is returned for every valid registration, whether or not the email was already taken.
+const registerAcceptedMessage = "Registration received. If the email can be used you can now log in."
+
+// Handler handles HTTP requests for authentication.
+type Handler struct {
+	service  ServiceInterface
+	validate *validator.Validate
+}
+
+// NewHandler creates a new auth handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Register creates an account. The response is the same for new and already registered
+// emails so the endpoint cannot be used to discover accounts.
+func (h *Handler) Register(c echo.Context) error {
+	var req models.RegisterData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	if err := h.service.Register(c.Request().Context(), req, c.Request().Header.Get("Accept-Language")); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, map[string]string{"message": registerAcceptedMessage})
+}
+
+func (h *Handler) Login(c echo.Context) error {
+	var req models.LoginData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	resp, err := h.service.Login(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair. The old refresh token stops working.
+func (h *Handler) Refresh(c echo.Context) error {
+	var req models.RefreshTokenData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	resp, err := h.service.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Logout ends the session the refresh token belongs to. It succeeds for unknown tokens too.
+func (h *Handler) Logout(c echo.Context) error {
+	var req models.RefreshTokenData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	if err := h.service.Logout(c.Request().Context(), req.RefreshToken); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LogoutAll ends every session of the logged-in user ("log out all devices"). Requires JWTMAuth.
+func (h *Handler) LogoutAll(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+
+	if err := h.service.LogoutAll(c.Request().Context(), userID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// --- Email Verification & Password Reset ---
+
+// passwordResetRequestedMessage is returned whether or not the email belongs to an account.
+const passwordResetRequestedMessage = "If an account uses this email, a password reset link has been sent."
+
+func (h *Handler) VerifyEmail(c echo.Context) error {
+	var req models.VerifyEmailData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	if err := h.service.VerifyEmail(c.Request().Context(), req.Token); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ResendVerification emails a new verification link to the logged-in user. Requires JWTMAuth.
+func (h *Handler) ResendVerification(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+
+	if err := h.service.ResendVerification(c.Request().Context(), userID, c.Request().Header.Get("Accept-Language")); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RequestPasswordReset always answers 202 so it cannot be used to discover accounts.
+func (h *Handler) RequestPasswordReset(c echo.Context) error {
+	var req models.PasswordResetRequestData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	if err := h.service.RequestPasswordReset(c.Request().Context(), req.Email, c.Request().Header.Get("Accept-Language")); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, map[string]string{"message": passwordResetRequestedMessage})
+}
+
+func (h *Handler) ResetPassword(c echo.Context) error {
+	var req models.PasswordResetConfirmData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	if err := h.service.ResetPassword(c.Request().Context(), req); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository; the emails CreateEmailToken would queue in the outbox are kept for Emails.
+type MemoryRepository struct {
+	mu            sync.Mutex
+	refreshTokens map[string]*models.RefreshToken // By hash
+	emailTokens   map[string]*memoryEmailToken    // By hash
+	emails        []email.Message
+	lastID        int64
+}
+
+type memoryEmailToken struct {
+	userID, purpose string
+	expiresAt       time.Time
+	usedAt          *time.Time
+	createdAt       time.Time
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory token repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		refreshTokens: map[string]*models.RefreshToken{},
+		emailTokens:   map[string]*memoryEmailToken{},
+	}
+}
+
+// Emails returns the messages queued by CreateEmailToken, oldest first.
+func (r *MemoryRepository) Emails() []email.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]email.Message(nil), r.emails...)
+}
+
+// copyToken detaches the timestamps so callers cannot change stored tokens.
+func copyToken(t models.RefreshToken) *models.RefreshToken {
+	if t.UsedAt != nil {
+		usedAt := *t.UsedAt
+		t.UsedAt = &usedAt
+	}
+	if t.RevokedAt != nil {
+		revokedAt := *t.RevokedAt
+		t.RevokedAt = &revokedAt
+	}
+	return &t
+}
+
+// insertRefreshToken stores token under tokenHash; the caller holds mu.
+func (r *MemoryRepository) insertRefreshToken(token *models.RefreshToken, tokenHash string) error {
+	if _, ok := r.refreshTokens[tokenHash]; ok {
+		return fmt.Errorf("token hash already stored") // token_hash is UNIQUE
+	}
+	r.lastID++
+	token.ID = r.lastID
+	r.refreshTokens[tokenHash] = copyToken(models.RefreshToken{
+		ID: token.ID, UserID: token.UserID, FamilyID: token.FamilyID, ExpiresAt: token.ExpiresAt,
+	})
+	return nil
+}
+
+func (r *MemoryRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.insertRefreshToken(token, tokenHash); err != nil {
+		return fmt.Errorf("repository.CreateRefreshToken: %w", err)
+	}
+	return nil
+}
+
+func (r *MemoryRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.refreshTokens[tokenHash]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return copyToken(*token), nil
+}
+
+func (r *MemoryRepository) RotateRefreshToken(ctx context.Context, oldID int64, next *models.RefreshToken, nextHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var old *models.RefreshToken
+	for _, token := range r.refreshTokens {
+		if token.ID == oldID {
+			old = token
+		}
+	}
+	if old == nil || old.UsedAt != nil || old.RevokedAt != nil {
+		return models.ErrConflict
+	}
+	if err := r.insertRefreshToken(next, nextHash); err != nil {
+		return fmt.Errorf("repository.RotateRefreshToken.Insert: %w", err)
+	}
+	now := time.Now()
+	old.UsedAt = &now
+	return nil
+}
+
+// revokeWhere revokes every unrevoked token match accepts.
+func (r *MemoryRepository) revokeWhere(match func(*models.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.refreshTokens {
+		if token.RevokedAt == nil && match(token) {
+			revokedAt := now
+			token.RevokedAt = &revokedAt
+		}
+	}
+}
+
+func (r *MemoryRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.revokeWhere(func(t *models.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (r *MemoryRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	r.revokeWhere(func(t *models.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+func (r *MemoryRepository) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil && token.ExpiresAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryRepository) CreateEmailToken(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time, msg *email.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.emailTokens[tokenHash]; ok {
+		return fmt.Errorf("repository.CreateEmailToken: token hash already stored")
+	}
+	if purpose != models.EmailTokenPurposeVerifyEmail && purpose != models.EmailTokenPurposeResetPassword {
+		return fmt.Errorf("repository.CreateEmailToken: unknown purpose %q", purpose) // The CHECK constraint
+	}
+	if err := msg.Validate(); err != nil {
+		return fmt.Errorf("repository.CreateEmailToken: outbox.Enqueue: %w", err)
+	}
+	r.emailTokens[tokenHash] = &memoryEmailToken{userID: userID, purpose: purpose, expiresAt: expiresAt, createdAt: time.Now()}
+	r.emails = append(r.emails, *msg)
+	return nil
+}
+
+func (r *MemoryRepository) CountEmailTokensSince(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, token := range r.emailTokens {
+		if token.userID == userID && token.purpose == purpose && !token.createdAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryRepository) ConsumeEmailToken(ctx context.Context, purpose, tokenHash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.emailTokens[tokenHash]
+	now := time.Now()
+	if !ok || token.purpose != purpose || token.usedAt != nil || !token.expiresAt.After(now) {
+		return "", models.ErrNotFound
+	}
+	token.usedAt = &now
+	return token.userID, nil
+}
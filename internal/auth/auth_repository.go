@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/outbox"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines methods for storing refresh and email tokens. Tokens are looked up by their hash only.
+type RepositoryInterface interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken, tokenHash string) error
+	FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// RotateRefreshToken marks oldID as used and stores next in the same transaction.
+	// It returns models.ErrConflict if oldID was already used or revoked in the meantime.
+	RotateRefreshToken(ctx context.Context, oldID int64, next *models.RefreshToken, nextHash string) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+	// IsFamilyActive reports whether the family still has a token that is neither revoked nor expired.
+	IsFamilyActive(ctx context.Context, familyID string) (bool, error)
+
+	// Email tokens (verification and password reset)
+	// CreateEmailToken stores the token and queues msg (the email carrying it) in one transaction.
+	CreateEmailToken(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time, msg *email.Message) error
+	CountEmailTokensSince(ctx context.Context, userID, purpose string, since time.Time) (int, error)
+	// ConsumeEmailToken marks the token used and returns its user. Unknown, expired and used tokens give models.ErrNotFound.
+	ConsumeEmailToken(ctx context.Context, purpose, tokenHash string) (string, error)
+}
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken, tokenHash string) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+	          VALUES ($1, $2, $3, $4) RETURNING id`
+	err := r.db.QueryRow(ctx, query, token.UserID, token.FamilyID, tokenHash, token.ExpiresAt).Scan(&token.ID)
+	if err != nil {
+		return fmt.Errorf("repository.CreateRefreshToken: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{}
+	query := `SELECT id, user_id::text, family_id::text, expires_at, used_at, revoked_at
+	          FROM refresh_tokens WHERE token_hash = $1`
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt,
+	)
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindRefreshTokenByHash: %w", err)
+	}
+	return token, nil
+}
+
+func (r *Repository) RotateRefreshToken(ctx context.Context, oldID int64, next *models.RefreshToken, nextHash string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.RotateRefreshToken.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// The conditional update makes two concurrent refreshes with the same token race for one row;
+	// the loser sees zero rows affected and is treated as reuse by the service.
+	cmdTag, err := tx.Exec(ctx,
+		`UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+		oldID)
+	if err != nil {
+		return fmt.Errorf("repository.RotateRefreshToken.MarkUsed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrConflict
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+		next.UserID, next.FamilyID, nextHash, next.ExpiresAt).Scan(&next.ID)
+	if err != nil {
+		return fmt.Errorf("repository.RotateRefreshToken.Insert: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.RotateRefreshToken.Commit: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return fmt.Errorf("repository.RevokeFamily: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) RevokeAllForUser(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("repository.RevokeAllForUser: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	var active bool
+	query := `SELECT EXISTS (
+	              SELECT 1 FROM refresh_tokens
+	              WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	          )`
+	if err := r.db.QueryRow(ctx, query, familyID).Scan(&active); err != nil {
+		return false, fmt.Errorf("repository.IsFamilyActive: %w", err)
+	}
+	return active, nil
+}
+
+// --- Email Tokens ---
+
+func (r *Repository) CreateEmailToken(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time, msg *email.Message) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.CreateEmailToken.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO email_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)`,
+		userID, purpose, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("repository.CreateEmailToken: %w", err)
+	}
+	if err := outbox.Enqueue(ctx, tx, msg); err != nil {
+		return fmt.Errorf("repository.CreateEmailToken: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.CreateEmailToken.Commit: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CountEmailTokensSince(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM email_tokens WHERE user_id = $1 AND purpose = $2 AND created_at >= $3`,
+		userID, purpose, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("repository.CountEmailTokensSince: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Repository) ConsumeEmailToken(ctx context.Context, purpose, tokenHash string) (string, error) {
+	var userID string
+	// A single conditional update keeps the token single-use even when the link is opened twice at once.
+	query := `UPDATE email_tokens SET used_at = NOW()
+	          WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+	          RETURNING user_id::text`
+	if err := r.db.QueryRow(ctx, query, tokenHash, purpose).Scan(&userID); err != nil {
+		if utils.IsNoRows(err) {
+			return "", models.ErrNotFound
+		}
+		return "", fmt.Errorf("repository.ConsumeEmailToken: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+	"jingdezhen-ceramics-backend/pkg/email"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty repository and a function creating users
+// for the user_id foreign keys.
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) (RepositoryInterface, func() string) {
+		db := pgtest.NewDB(t)
+		return NewRepository(db), func() string { return factory.User(t, db).ID }
+	})
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) (RepositoryInterface, func() string) {
+		lastUserID := 0
+		return NewMemoryRepository(), func() string { lastUserID++; return strconv.Itoa(lastUserID) }
+	})
+}
+
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func testRepositoryContract(t *testing.T, newRepo func(t *testing.T) (RepositoryInterface, func() string)) {
+	ctx := context.Background()
+	const family, otherFamily = "0b6f3c1e-2f5d-4a57-9a0e-6f1d2c3b4a51", "7e2d9a44-1c3b-4f6e-8d5a-2b1c0e9f8a72"
+	issue := func(t *testing.T, repo RepositoryInterface, userID, familyID, token string, ttl time.Duration) *models.RefreshToken {
+		t.Helper()
+		rt := &models.RefreshToken{UserID: userID, FamilyID: familyID, ExpiresAt: time.Now().Add(ttl)}
+		if err := repo.CreateRefreshToken(ctx, rt, hash(token)); err != nil {
+			t.Fatalf("CreateRefreshToken(%s): %v", token, err)
+		}
+		return rt
+	}
+	msg := &email.Message{To: []string{"potter@example.com"}, Subject: "Verify", TextBody: "Open the link."}
+
+	t.Run("RefreshTokens", func(t *testing.T) {
+		repo, newUser := newRepo(t)
+		userID := newUser()
+		first := issue(t, repo, userID, family, "first", time.Hour)
+		if first.ID == 0 {
+			t.Fatal("CreateRefreshToken set no ID")
+		}
+		found, err := repo.FindRefreshTokenByHash(ctx, hash("first"))
+		if err != nil {
+			t.Fatalf("FindRefreshTokenByHash: %v", err)
+		}
+		if found.ID != first.ID || found.UserID != userID || found.FamilyID != family || found.UsedAt != nil || found.RevokedAt != nil {
+			t.Errorf("FindRefreshTokenByHash = %+v, want the unused first token", found)
+		}
+		if _, err := repo.FindRefreshTokenByHash(ctx, hash("unknown")); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindRefreshTokenByHash(unknown): error = %v, want ErrNotFound", err)
+		}
+		if err := repo.CreateRefreshToken(ctx, &models.RefreshToken{UserID: userID, FamilyID: family, ExpiresAt: time.Now().Add(time.Hour)}, hash("first")); err == nil {
+			t.Error("CreateRefreshToken accepted a hash that is already stored")
+		}
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		repo, newUser := newRepo(t)
+		userID := newUser()
+		first := issue(t, repo, userID, family, "first", time.Hour)
+
+		next := &models.RefreshToken{UserID: userID, FamilyID: family, ExpiresAt: time.Now().Add(time.Hour)}
+		if err := repo.RotateRefreshToken(ctx, first.ID, next, hash("second")); err != nil {
+			t.Fatalf("RotateRefreshToken: %v", err)
+		}
+		if next.ID == 0 || next.ID == first.ID {
+			t.Errorf("rotated token ID = %d, want a new one", next.ID)
+		}
+		if used, err := repo.FindRefreshTokenByHash(ctx, hash("first")); err != nil || used.UsedAt == nil {
+			t.Errorf("first token after rotation = (%+v, %v), want it used", used, err)
+		}
+
+		again := &models.RefreshToken{UserID: userID, FamilyID: family, ExpiresAt: time.Now().Add(time.Hour)}
+		if err := repo.RotateRefreshToken(ctx, first.ID, again, hash("third")); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("RotateRefreshToken(used token): error = %v, want ErrConflict", err)
+		}
+		if _, err := repo.FindRefreshTokenByHash(ctx, hash("third")); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("failed rotation stored its token: error = %v, want ErrNotFound", err)
+		}
+
+		if err := repo.RevokeFamily(ctx, family); err != nil {
+			t.Fatalf("RevokeFamily: %v", err)
+		}
+		if err := repo.RotateRefreshToken(ctx, next.ID, again, hash("third")); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("RotateRefreshToken(revoked token): error = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		repo, newUser := newRepo(t)
+		userID, otherUserID := newUser(), newUser()
+		issue(t, repo, userID, family, "a", time.Hour)
+		issue(t, repo, userID, otherFamily, "b", time.Hour)
+		issue(t, repo, otherUserID, "4c1a7b0d-5e3f-4d2a-9b8c-7a6e5d4c3b21", "c", time.Hour)
+
+		for _, f := range []string{family, otherFamily} {
+			if active, err := repo.IsFamilyActive(ctx, f); err != nil || !active {
+				t.Fatalf("IsFamilyActive(%s) = (%v, %v), want true", f, active, err)
+			}
+		}
+		if err := repo.RevokeFamily(ctx, family); err != nil {
+			t.Fatalf("RevokeFamily: %v", err)
+		}
+		if active, _ := repo.IsFamilyActive(ctx, family); active {
+			t.Error("revoked family is still active")
+		}
+		if active, _ := repo.IsFamilyActive(ctx, otherFamily); !active {
+			t.Error("RevokeFamily revoked another family")
+		}
+
+		if err := repo.RevokeAllForUser(ctx, userID); err != nil {
+			t.Fatalf("RevokeAllForUser: %v", err)
+		}
+		for token, wantRevoked := range map[string]bool{"a": true, "b": true, "c": false} {
+			got, err := repo.FindRefreshTokenByHash(ctx, hash(token))
+			if err != nil || (got.RevokedAt != nil) != wantRevoked {
+				t.Errorf("token %s = (%+v, %v), want revoked %v", token, got, err, wantRevoked)
+			}
+		}
+	})
+
+	t.Run("ExpiredFamilyIsInactive", func(t *testing.T) {
+		repo, newUser := newRepo(t)
+		issue(t, repo, newUser(), family, "old", -time.Minute)
+		if active, err := repo.IsFamilyActive(ctx, family); err != nil || active {
+			t.Errorf("IsFamilyActive(expired) = (%v, %v), want false", active, err)
+		}
+		if active, err := repo.IsFamilyActive(ctx, otherFamily); err != nil || active {
+			t.Errorf("IsFamilyActive(unknown) = (%v, %v), want false", active, err)
+		}
+	})
+
+	t.Run("EmailTokens", func(t *testing.T) {
+		repo, newUser := newRepo(t)
+		userID := newUser()
+		verify, reset := models.EmailTokenPurposeVerifyEmail, models.EmailTokenPurposeResetPassword
+		expires := time.Now().Add(time.Hour)
+		for _, token := range []string{"v1", "v2"} {
+			if err := repo.CreateEmailToken(ctx, userID, verify, hash(token), expires, msg); err != nil {
+				t.Fatalf("CreateEmailToken(%s): %v", token, err)
+			}
+		}
+		if err := repo.CreateEmailToken(ctx, userID, reset, hash("r1"), time.Now().Add(-time.Minute), msg); err != nil {
+			t.Fatalf("CreateEmailToken(expired): %v", err)
+		}
+
+		if n, err := repo.CountEmailTokensSince(ctx, userID, verify, time.Now().Add(-time.Hour)); err != nil || n != 2 {
+			t.Errorf("CountEmailTokensSince = (%d, %v), want 2", n, err)
+		}
+		if n, _ := repo.CountEmailTokensSince(ctx, userID, verify, time.Now().Add(time.Hour)); n != 0 {
+			t.Errorf("CountEmailTokensSince(future) = %d, want 0", n)
+		}
+
+		if _, err := repo.ConsumeEmailToken(ctx, reset, hash("v1")); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("ConsumeEmailToken(wrong purpose): error = %v, want ErrNotFound", err)
+		}
+		if got, err := repo.ConsumeEmailToken(ctx, verify, hash("v1")); err != nil || got != userID {
+			t.Errorf("ConsumeEmailToken = (%q, %v), want %q", got, err, userID)
+		}
+		if _, err := repo.ConsumeEmailToken(ctx, verify, hash("v1")); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("ConsumeEmailToken(used): error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.ConsumeEmailToken(ctx, reset, hash("r1")); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("ConsumeEmailToken(expired): error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("EmailTokenNeedsValidMessage", func(t *testing.T) {
+		repo, newUser := newRepo(t)
+		userID := newUser()
+		err := repo.CreateEmailToken(ctx, userID, models.EmailTokenPurposeVerifyEmail, hash("v1"), time.Now().Add(time.Hour), &email.Message{Subject: "No recipient"})
+		if err == nil {
+			t.Fatal("CreateEmailToken accepted an email without recipients")
+		}
+		if _, err := repo.ConsumeEmailToken(ctx, models.EmailTokenPurposeVerifyEmail, hash("v1")); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("token of the failed CreateEmailToken: error = %v, want ErrNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,471 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/user"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultAccessTokenExpiry is used when Config.JWTExpiry is not set. Clients renew access tokens through /auth/refresh.
+const DefaultAccessTokenExpiry = 15 * time.Minute
+
+// DefaultRefreshTokenExpiry is used when Config.RefreshTokenExpiry is not set.
+const DefaultRefreshTokenExpiry = 30 * 24 * time.Hour
+
+const (
+	verifyEmailTokenExpiry   = 24 * time.Hour
+	resetPasswordTokenExpiry = time.Hour
+	// At most emailTokenRateLimit emails of one purpose are sent to an address per emailTokenRateWindow.
+	emailTokenRateLimit  = 3
+	emailTokenRateWindow = time.Hour
+)
+
+// ServiceInterface defines the methods for native email/password authentication.
+type ServiceInterface interface {
+	// Register creates a normal_user account and emails a verification link. It returns nil when
+	// the email is already registered so callers cannot tell the two cases apart.
+	// locale is an Accept-Language value choosing the email's language.
+	Register(ctx context.Context, data models.RegisterData, locale string) error
+	// Login returns models.ErrInvalidCredentials for an unknown email and for a wrong password alike.
+	// Each login starts a new session (refresh token family).
+	Login(ctx context.Context, data models.LoginData) (*models.AuthResponse, error)
+	// Refresh rotates the refresh token and issues a new access token. Presenting a token that was
+	// already rotated revokes its whole family, since either the client or an attacker holds a stolen copy.
+	Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error)
+	// Logout revokes the session of refreshToken. Unknown tokens are ignored.
+	Logout(ctx context.Context, refreshToken string) error
+	// LogoutAll revokes every session of the user, logging out all devices.
+	LogoutAll(ctx context.Context, userID string) error
+	// IsAccessTokenRevoked lets middleware.JWTMAuth reject access tokens whose session was revoked.
+	IsAccessTokenRevoked(ctx context.Context, claims *models.JwtCustomClaims) (bool, error)
+
+	// Email verification and password reset
+	VerifyEmail(ctx context.Context, token string) error
+	// ResendVerification returns models.ErrEmailRateLimited when too many links were sent recently.
+	ResendVerification(ctx context.Context, userID, locale string) error
+	// RequestPasswordReset returns nil for unknown and rate limited addresses alike.
+	RequestPasswordReset(ctx context.Context, emailAddr, locale string) error
+	// ResetPassword sets the new password and logs out every session of the user.
+	ResetPassword(ctx context.Context, data models.PasswordResetConfirmData) error
+	// IsEmailVerified lets middleware.EmailVerifiedRequired guard write actions.
+	IsEmailVerified(ctx context.Context, userID string) (bool, error)
+}
+
+// Service provides authentication and token issuance.
+type Service struct {
+	repo          RepositoryInterface
+	userRepo      user.RepositoryInterface
+	templates     *email.Registry
+	clientOrigin  string // Links in emails point at the frontend, e.g. https://example.com/verify-email?token=...
+	jwtSecret     []byte
+	tokenExpiry   time.Duration
+	refreshExpiry time.Duration
+	// dummyHash is compared against when the email is unknown so every failed login costs one bcrypt check.
+	dummyHash []byte
+}
+
+// NewService creates a new auth service. Zero expiries fall back to DefaultAccessTokenExpiry
+// and DefaultRefreshTokenExpiry.
+func NewService(
+	repo RepositoryInterface,
+	userRepo user.RepositoryInterface,
+	templates *email.Registry,
+	clientOrigin string,
+	jwtSecret string,
+	tokenExpiry, refreshExpiry time.Duration,
+) ServiceInterface {
+	if tokenExpiry <= 0 {
+		tokenExpiry = DefaultAccessTokenExpiry
+	}
+	if refreshExpiry <= 0 {
+		refreshExpiry = DefaultRefreshTokenExpiry
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+	if err != nil {
+		log.Fatalf("auth.NewService: could not prepare dummy hash: %v", err)
+	}
+	return &Service{
+		repo:          repo,
+		userRepo:      userRepo,
+		templates:     templates,
+		clientOrigin:  strings.TrimRight(clientOrigin, "/"),
+		jwtSecret:     []byte(jwtSecret),
+		tokenExpiry:   tokenExpiry,
+		refreshExpiry: refreshExpiry,
+		dummyHash:     dummyHash,
+	}
+}
+
+func (s *Service) Register(ctx context.Context, data models.RegisterData, locale string) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("service.Register.Hash: %w", err)
+	}
+
+	newUser := &models.User{
+		Nickname: strings.TrimSpace(data.Nickname),
+		Email:    normalizeEmail(data.Email),
+		Role:     models.RoleNormalUser,
+	}
+	created, err := s.userRepo.Create(ctx, newUser, string(passwordHash))
+	if err != nil {
+		if errors.Is(err, models.ErrConflict) {
+			log.Printf("INFO: service.Register: email already registered, nothing created")
+			return nil
+		}
+		return fmt.Errorf("service.Register: %w", err)
+	}
+
+	// The account exists either way; a failed token can be retried through ResendVerification.
+	if err := s.sendEmailToken(ctx, created, models.EmailTokenPurposeVerifyEmail, locale); err != nil {
+		log.Printf("ERROR: service.Register: verification email for user %s not queued: %v", created.ID, err)
+	}
+	return nil
+}
+
+func (s *Service) Login(ctx context.Context, data models.LoginData) (*models.AuthResponse, error) {
+	account, err := s.userRepo.FindByEmail(ctx, normalizeEmail(data.Email))
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("service.Login: %w", err)
+	}
+
+	if account == nil || account.PasswordHash == "" {
+		// Spend the same bcrypt time as a real check so response times do not reveal which emails exist.
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(data.Password))
+		return nil, models.ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(data.Password)); err != nil {
+		return nil, models.ErrInvalidCredentials
+	}
+
+	familyID, err := newFamilyID()
+	if err != nil {
+		return nil, fmt.Errorf("service.Login.Session: %w", err)
+	}
+	refreshToken, tokenHash, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("service.Login.Session: %w", err)
+	}
+	stored := &models.RefreshToken{UserID: account.ID, FamilyID: familyID, ExpiresAt: time.Now().Add(s.refreshExpiry)}
+	if err := s.repo.CreateRefreshToken(ctx, stored, tokenHash); err != nil {
+		return nil, fmt.Errorf("service.Login: %w", err)
+	}
+
+	resp, err := s.newAuthResponse(account, familyID, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("service.Login.Token: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	current, err := s.repo.FindRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, models.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("service.Refresh: %w", err)
+	}
+	if current.RevokedAt != nil || !current.ExpiresAt.After(time.Now()) {
+		return nil, models.ErrInvalidRefreshToken
+	}
+	if current.UsedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, current)
+	}
+
+	// Role and email may have changed since login, so the new access token is built from the stored user.
+	account, err := s.userRepo.FindByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, models.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("service.Refresh.User: %w", err)
+	}
+
+	nextToken, nextHash, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("service.Refresh.Session: %w", err)
+	}
+	next := &models.RefreshToken{UserID: current.UserID, FamilyID: current.FamilyID, ExpiresAt: time.Now().Add(s.refreshExpiry)}
+	if err := s.repo.RotateRefreshToken(ctx, current.ID, next, nextHash); err != nil {
+		if errors.Is(err, models.ErrConflict) {
+			// Another request rotated or revoked this token first.
+			return nil, s.revokeReusedFamily(ctx, current)
+		}
+		return nil, fmt.Errorf("service.Refresh: %w", err)
+	}
+
+	resp, err := s.newAuthResponse(account, current.FamilyID, nextToken)
+	if err != nil {
+		return nil, fmt.Errorf("service.Refresh.Token: %w", err)
+	}
+	return resp, nil
+}
+
+// revokeReusedFamily handles a refresh token that was presented after it had been rotated.
+// It always returns models.ErrInvalidRefreshToken unless the revocation itself fails.
+func (s *Service) revokeReusedFamily(ctx context.Context, token *models.RefreshToken) error {
+	log.Printf("WARN: service.Refresh: refresh token reuse detected for user %s, revoking session %s", token.UserID, token.FamilyID)
+	if err := s.repo.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return fmt.Errorf("service.Refresh.RevokeFamily: %w", err)
+	}
+	return models.ErrInvalidRefreshToken
+}
+
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.repo.FindRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("service.Logout: %w", err)
+	}
+	if err := s.repo.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return fmt.Errorf("service.Logout: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) LogoutAll(ctx context.Context, userID string) error {
+	if err := s.repo.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("service.LogoutAll: %w", err)
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked treats a token as revoked once its session has no live refresh token left.
+// Tokens without a session ID were not issued by this service and are left to their own expiry.
+func (s *Service) IsAccessTokenRevoked(ctx context.Context, claims *models.JwtCustomClaims) (bool, error) {
+	if claims.SessionID == "" {
+		return false, nil
+	}
+	active, err := s.repo.IsFamilyActive(ctx, claims.SessionID)
+	if err != nil {
+		return false, fmt.Errorf("service.IsAccessTokenRevoked: %w", err)
+	}
+	return !active, nil
+}
+
+// --- Email Verification & Password Reset ---
+
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.consumeEmailToken(ctx, models.EmailTokenPurposeVerifyEmail, token)
+	if err != nil {
+		return fmt.Errorf("service.VerifyEmail: %w", err)
+	}
+	if err := s.userRepo.SetEmailVerified(ctx, userID); err != nil {
+		return fmt.Errorf("service.VerifyEmail: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) ResendVerification(ctx context.Context, userID, locale string) error {
+	account, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service.ResendVerification: %w", err)
+	}
+	if account.EmailVerified {
+		return nil
+	}
+	if err := s.sendEmailToken(ctx, account, models.EmailTokenPurposeVerifyEmail, locale); err != nil {
+		return fmt.Errorf("service.ResendVerification: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) RequestPasswordReset(ctx context.Context, emailAddr, locale string) error {
+	account, err := s.userRepo.FindByEmail(ctx, normalizeEmail(emailAddr))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("service.RequestPasswordReset: %w", err)
+	}
+	if err := s.sendEmailToken(ctx, account, models.EmailTokenPurposeResetPassword, locale); err != nil {
+		if errors.Is(err, models.ErrEmailRateLimited) {
+			log.Printf("INFO: service.RequestPasswordReset: rate limited for user %s", account.ID)
+			return nil
+		}
+		return fmt.Errorf("service.RequestPasswordReset: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) ResetPassword(ctx context.Context, data models.PasswordResetConfirmData) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(data.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("service.ResetPassword.Hash: %w", err)
+	}
+	userID, err := s.consumeEmailToken(ctx, models.EmailTokenPurposeResetPassword, data.Token)
+	if err != nil {
+		return fmt.Errorf("service.ResetPassword: %w", err)
+	}
+	if err := s.userRepo.UpdatePasswordHash(ctx, userID, string(passwordHash)); err != nil {
+		return fmt.Errorf("service.ResetPassword: %w", err)
+	}
+	// Whoever knew the old password may still hold a session.
+	if err := s.repo.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("service.ResetPassword: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
+	account, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("service.IsEmailVerified: %w", err)
+	}
+	return account.EmailVerified, nil
+}
+
+// sendEmailToken stores a new token for purpose and emails its link to the account,
+// unless the address already received emailTokenRateLimit of them within emailTokenRateWindow.
+func (s *Service) sendEmailToken(ctx context.Context, account *models.User, purpose, locale string) error {
+	sent, err := s.repo.CountEmailTokensSince(ctx, account.ID, purpose, time.Now().Add(-emailTokenRateWindow))
+	if err != nil {
+		return err
+	}
+	if sent >= emailTokenRateLimit {
+		return models.ErrEmailRateLimited
+	}
+
+	template, path, expiry := email.TemplateVerifyEmail, "/verify-email", verifyEmailTokenExpiry
+	if purpose == models.EmailTokenPurposeResetPassword {
+		template, path, expiry = email.TemplateResetPassword, "/reset-password", resetPasswordTokenExpiry
+	}
+
+	token, err := s.newEmailToken(purpose)
+	if err != nil {
+		return err
+	}
+	msg, err := s.templates.Message(template, locale, email.TemplateData{
+		"Nickname":       account.Nickname,
+		"Link":           s.clientOrigin + path + "?token=" + url.QueryEscape(token),
+		"ExpiresInHours": int(expiry.Hours()),
+	}, account.Email)
+	if err != nil {
+		return err
+	}
+
+	// The email is queued in the same transaction as the token, so there is never a token without its email.
+	return s.repo.CreateEmailToken(ctx, account.ID, purpose, hashToken(token), time.Now().Add(expiry), msg)
+}
+
+// consumeEmailToken checks the signature before touching the database, so forged or
+// mistyped tokens are rejected cheaply, then marks the stored token used.
+func (s *Service) consumeEmailToken(ctx context.Context, purpose, token string) (string, error) {
+	if !s.validEmailTokenSignature(purpose, token) {
+		return "", models.ErrInvalidEmailToken
+	}
+	userID, err := s.repo.ConsumeEmailToken(ctx, purpose, hashToken(token))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return "", models.ErrInvalidEmailToken
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+// newEmailToken returns "<random>.<signature>", where the signature binds the random part to purpose
+// so a verification token cannot be replayed as a reset token.
+func (s *Service) newEmailToken(purpose string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(buf)
+	return nonce + "." + s.signEmailToken(purpose, nonce), nil
+}
+
+func (s *Service) validEmailTokenSignature(purpose, token string) bool {
+	nonce, signature, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.signEmailToken(purpose, nonce)))
+}
+
+func (s *Service) signEmailToken(purpose, nonce string) string {
+	mac := hmac.New(sha256.New, s.jwtSecret)
+	mac.Write([]byte(purpose + "." + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) newAuthResponse(account *models.User, familyID, refreshToken string) (*models.AuthResponse, error) {
+	accessToken, err := s.issueAccessToken(account, familyID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.tokenExpiry.Seconds()),
+		RefreshToken: refreshToken,
+		User:         account,
+	}, nil
+}
+
+// issueAccessToken signs the claims that middleware.JWTMAuth expects with HS256.
+func (s *Service) issueAccessToken(account *models.User, familyID string) (string, error) {
+	now := time.Now()
+	claims := &models.JwtCustomClaims{
+		UserID:    account.ID,
+		Email:     account.Email,
+		Role:      account.Role,
+		SessionID: familyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   account.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenExpiry)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// newRefreshToken returns a random opaque token for the client and the hash to store.
+func newRefreshToken() (token string, tokenHash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken is SHA-256 rather than bcrypt: refresh tokens are 256 random bits, so a fast
+// hash is safe and lets the token be looked up directly.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newFamilyID returns a random (version 4) UUID for the refresh_tokens.family_id column.
+func newFamilyID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,166 @@
+package ceramicstory
+
+import (
+	"context"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository: models.ErrNotFound for missing stories and models.ErrConflict for a taken slug or display order.
+type MemoryRepository struct {
+	mu      sync.Mutex
+	stories map[int64]models.CeramicStory
+	lastID  int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory ceramic story repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{stories: map[int64]models.CeramicStory{}}
+}
+
+// copyStory detaches the year pointers so callers cannot change stored stories.
+func copyStory(s models.CeramicStory) models.CeramicStory {
+	if s.StartYear != nil {
+		year := *s.StartYear
+		s.StartYear = &year
+	}
+	if s.EndYear != nil {
+		year := *s.EndYear
+		s.EndYear = &year
+	}
+	return s
+}
+
+// taken reports whether another story than id uses slug or displayOrder.
+func (r *MemoryRepository) taken(id int64, slug string, displayOrder int) bool {
+	for _, s := range r.stories {
+		if s.ID != id && (s.Slug == slug || s.DisplayOrder == displayOrder) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryRepository) FindAll(ctx context.Context) ([]models.CeramicStory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stories := make([]models.CeramicStory, 0, len(r.stories))
+	for _, s := range r.stories {
+		stories = append(stories, copyStory(s))
+	}
+	// Same order as the SQL: display_order, then start_year with NULLs last
+	sort.Slice(stories, func(i, j int) bool {
+		a, b := stories[i], stories[j]
+		if a.DisplayOrder != b.DisplayOrder {
+			return a.DisplayOrder < b.DisplayOrder
+		}
+		if a.StartYear == nil || b.StartYear == nil {
+			return a.StartYear != nil
+		}
+		return *a.StartYear < *b.StartYear
+	})
+	return stories, nil
+}
+
+func (r *MemoryRepository) FindByIDOrSlug(ctx context.Context, idOrSlug string) (*models.CeramicStory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id, err := strconv.ParseInt(idOrSlug, 10, 64); err == nil {
+		s, ok := r.stories[id]
+		if !ok {
+			return nil, models.ErrNotFound
+		}
+		s = copyStory(s)
+		return &s, nil
+	}
+	for _, s := range r.stories {
+		if s.Slug == idOrSlug {
+			s = copyStory(s)
+			return &s, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, data models.CreateCeramicStoryData) (*models.CeramicStory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.taken(0, data.Slug, data.DisplayOrder) {
+		return nil, models.ErrConflict
+	}
+	r.lastID++
+	s := copyStory(models.CeramicStory{
+		ID:                   r.lastID,
+		DynastyName:          data.DynastyName,
+		Slug:                 data.Slug,
+		Period:               data.Period,
+		StartYear:            data.StartYear,
+		EndYear:              data.EndYear,
+		Description:          data.Description,
+		CharacteristicsCraft: data.CharacteristicsCraft,
+		CharacteristicsArt:   data.CharacteristicsArt,
+		ImageURL:             data.ImageURL,
+		Takeaways:            data.Takeaways,
+		DisplayOrder:         data.DisplayOrder,
+	})
+	r.stories[s.ID] = s
+	s = copyStory(s)
+	return &s, nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, id int64, data models.UpdateCeramicStoryData) (*models.CeramicStory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.stories[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	setIfNotNil(&s.DynastyName, data.DynastyName)
+	setIfNotNil(&s.Slug, data.Slug)
+	setIfNotNil(&s.Period, data.Period)
+	setIfNotNil(&s.Description, data.Description)
+	setIfNotNil(&s.CharacteristicsCraft, data.CharacteristicsCraft)
+	setIfNotNil(&s.CharacteristicsArt, data.CharacteristicsArt)
+	setIfNotNil(&s.ImageURL, data.ImageURL)
+	setIfNotNil(&s.Takeaways, data.Takeaways)
+	setIfNotNil(&s.DisplayOrder, data.DisplayOrder)
+	if data.StartYear != nil {
+		s.StartYear = data.StartYear
+	}
+	if data.EndYear != nil {
+		s.EndYear = data.EndYear
+	}
+	if r.taken(id, s.Slug, s.DisplayOrder) {
+		return nil, models.ErrConflict
+	}
+
+	r.stories[id] = copyStory(s)
+	s = copyStory(s)
+	return &s, nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.stories[id]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.stories, id)
+	return nil
+}
+
+func setIfNotNil[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
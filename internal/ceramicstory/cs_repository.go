@@ -2,23 +2,22 @@ package ceramicstory
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
 	"strconv"
-	"strings"
-
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // RepositoryInterface defines the methods for interacting with ceramic story storage.
 type RepositoryInterface interface {
 	FindAll(ctx context.Context) ([]models.CeramicStory, error)
 	FindByIDOrSlug(ctx context.Context, idOrSlug string) (*models.CeramicStory, error)
-	// Admin methods (example, not directly used by current public routes)
-	// Create(ctx context.Context, data models.CreateCeramicStoryData) (*models.CeramicStory, error)
-	// Update(ctx context.Context, id int64, data models.UpdateCeramicStoryData) (*models.CeramicStory, error)
-	// Delete(ctx context.Context, id int64) error
+	// Admin methods
+	Create(ctx context.Context, data models.CreateCeramicStoryData) (*models.CeramicStory, error)
+	Update(ctx context.Context, id int64, data models.UpdateCeramicStoryData) (*models.CeramicStory, error)
+	Delete(ctx context.Context, id int64) error
 }
 
 // Repository provides access to the ceramic story storage.
@@ -31,17 +30,32 @@ func NewRepository(db *pgxpool.Pool) RepositoryInterface {
 	return &Repository{db: db}
 }
 
+// storyColumns is shared by every query returning models.CeramicStory so the Scan order stays in one place.
+// Optional text columns are COALESCEd since the struct uses plain strings.
+const storyColumns = `
+	id, dynasty_name, slug, COALESCE(period, ''), start_year, end_year,
+	description, COALESCE(characteristics_craft, ''), COALESCE(characteristics_art, ''),
+	COALESCE(image_url, ''), COALESCE(takeaways, ''), COALESCE(display_order, 0)
+`
+
+func scanStory(row pgx.Row) (*models.CeramicStory, error) {
+	var story models.CeramicStory
+	err := row.Scan(
+		&story.ID, &story.DynastyName, &story.Slug, &story.Period, &story.StartYear, &story.EndYear,
+		&story.Description, &story.CharacteristicsCraft, &story.CharacteristicsArt,
+		&story.ImageURL, &story.Takeaways, &story.DisplayOrder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
+
 // FindAll retrieves all ceramic stories, ordered by display_order.
 // For the timeline view, you might want to select fewer fields if it's just a summary.
 func (r *Repository) FindAll(ctx context.Context) ([]models.CeramicStory, error) {
 	stories := []models.CeramicStory{}
-	query := `
-		SELECT id, dynasty_name, slug, period, start_year, end_year, 
-		       description, characteristics_craft, characteristics_art, 
-		       image_url, takeaways, display_order
-		FROM ceramic_stories
-		ORDER BY display_order ASC, start_year ASC
-	`
+	query := `SELECT ` + storyColumns + ` FROM ceramic_stories ORDER BY display_order ASC, start_year ASC`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("repository.FindAll.Query: %w", err)
@@ -49,16 +63,11 @@ func (r *Repository) FindAll(ctx context.Context) ([]models.CeramicStory, error)
 	defer rows.Close()
 
 	for rows.Next() {
-		var story models.CeramicStory
-		err := rows.Scan(
-			&story.ID, &story.DynastyName, &story.Slug, &story.Period, &story.StartYear, &story.EndYear,
-			&story.Description, &story.CharacteristicsCraft, &story.CharacteristicsArt,
-			&story.ImageURL, &story.Takeaways, &story.DisplayOrder,
-		)
+		story, err := scanStory(rows)
 		if err != nil {
 			return nil, fmt.Errorf("repository.FindAll.Scan: %w", err)
 		}
-		stories = append(stories, story)
+		stories = append(stories, *story)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -70,75 +79,54 @@ func (r *Repository) FindAll(ctx context.Context) ([]models.CeramicStory, error)
 
 // FindByIDOrSlug retrieves a single ceramic story by its ID or slug.
 func (r *Repository) FindByIDOrSlug(ctx context.Context, idOrSlug string) (*models.CeramicStory, error) {
-	var story models.CeramicStory
-	query := `
-		SELECT id, dynasty_name, slug, period, start_year, end_year, 
-		       description, characteristics_craft, characteristics_art, 
-		       image_url, takeaways, display_order
-		FROM ceramic_stories
-	`
-	var err error
+	query := `SELECT ` + storyColumns + ` FROM ceramic_stories`
+	var row pgx.Row
 	// Try to parse idOrSlug as an integer (ID) first
 	id, convErr := strconv.ParseInt(idOrSlug, 10, 64)
 	if convErr == nil {
 		// It's a numeric ID
-		query += " WHERE id = $1"
-		err = r.db.QueryRow(ctx, query, id).Scan(
-			&story.ID, &story.DynastyName, &story.Slug, &story.Period, &story.StartYear, &story.EndYear,
-			&story.Description, &story.CharacteristicsCraft, &story.CharacteristicsArt,
-			&story.ImageURL, &story.Takeaways, &story.DisplayOrder,
-		)
+		row = r.db.QueryRow(ctx, query+" WHERE id = $1", id)
 	} else {
 		// Assume it's a slug (string)
-		query += " WHERE slug = $1"
-		err = r.db.QueryRow(ctx, query, idOrSlug).Scan(
-			&story.ID, &story.DynastyName, &story.Slug, &story.Period, &story.StartYear, &story.EndYear,
-			&story.Description, &story.CharacteristicsCraft, &story.CharacteristicsArt,
-			&story.ImageURL, &story.Takeaways, &story.DisplayOrder,
-		)
+		row = r.db.QueryRow(ctx, query+" WHERE slug = $1", idOrSlug)
 	}
 
+	story, err := scanStory(row)
 	if err != nil {
-		if err == sql.ErrNoRows || strings.Contains(err.Error(), "no rows in result set") { // pgx might use different error
-			return nil, models.ErrNotFound // Use a common error type from your models package
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
 		}
 		return nil, fmt.Errorf("repository.FindByIDOrSlug: %w", err)
 	}
-	return &story, nil
+	return story, nil
 }
 
-// --- Admin methods (Example Implementations - uncomment and complete if needed for admin panel) ---
-/*
+// --- Admin methods ---
+
+// Create inserts a new story. Returns models.ErrConflict if the slug or display order is taken.
 func (r *Repository) Create(ctx context.Context, data models.CreateCeramicStoryData) (*models.CeramicStory, error) {
-	story := models.CeramicStory{}
 	query := `
 		INSERT INTO ceramic_stories (
 			dynasty_name, slug, period, start_year, end_year, description,
 			characteristics_craft, characteristics_art, image_url, takeaways, display_order
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id, dynasty_name, slug, period, start_year, end_year, description,
-		          characteristics_craft, characteristics_art, image_url, takeaways, display_order
-	`
-	err := r.db.QueryRow(ctx, query,
+		) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11)
+		RETURNING ` + storyColumns
+	story, err := scanStory(r.db.QueryRow(ctx, query,
 		data.DynastyName, data.Slug, data.Period, data.StartYear, data.EndYear, data.Description,
 		data.CharacteristicsCraft, data.CharacteristicsArt, data.ImageURL, data.Takeaways, data.DisplayOrder,
-	).Scan(
-		&story.ID, &story.DynastyName, &story.Slug, &story.Period, &story.StartYear, &story.EndYear,
-		&story.Description, &story.CharacteristicsCraft, &story.CharacteristicsArt,
-		&story.ImageURL, &story.Takeaways, &story.DisplayOrder,
-	)
+	))
 	if err != nil {
-		// Handle potential duplicate slug error (unique constraint)
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
 		return nil, fmt.Errorf("repository.Create: %w", err)
 	}
-	return &story, nil
+	return story, nil
 }
 
+// Update changes the non-nil fields of data. Returns models.ErrConflict if the new slug or
+// display order is taken by another story.
 func (r *Repository) Update(ctx context.Context, id int64, data models.UpdateCeramicStoryData) (*models.CeramicStory, error) {
-	// Build query dynamically based on which fields in `data` are not nil
-	// For brevity, this is a simplified example assuming all fields might be updated
-	// A more robust implementation would use squirrel or build SET clauses carefully.
-	story := models.CeramicStory{}
 	query := `
 		UPDATE ceramic_stories SET
 			dynasty_name = COALESCE($2, dynasty_name),
@@ -153,24 +141,21 @@ func (r *Repository) Update(ctx context.Context, id int64, data models.UpdateCer
 			takeaways = COALESCE($11, takeaways),
 			display_order = COALESCE($12, display_order)
 		WHERE id = $1
-		RETURNING id, dynasty_name, slug, period, start_year, end_year, description,
-		          characteristics_craft, characteristics_art, image_url, takeaways, display_order
-	`
-	err := r.db.QueryRow(ctx, query,
+		RETURNING ` + storyColumns
+	story, err := scanStory(r.db.QueryRow(ctx, query,
 		id, data.DynastyName, data.Slug, data.Period, data.StartYear, data.EndYear, data.Description,
 		data.CharacteristicsCraft, data.CharacteristicsArt, data.ImageURL, data.Takeaways, data.DisplayOrder,
-	).Scan(
-		&story.ID, &story.DynastyName, &story.Slug, &story.Period, &story.StartYear, &story.EndYear,
-		&story.Description, &story.CharacteristicsCraft, &story.CharacteristicsArt,
-		&story.ImageURL, &story.Takeaways, &story.DisplayOrder,
-	)
+	))
 	if err != nil {
-		if err == sql.ErrNoRows || strings.Contains(err.Error(), "no rows") {
+		if utils.IsNoRows(err) {
 			return nil, models.ErrNotFound
 		}
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
 		return nil, fmt.Errorf("repository.Update: %w", err)
 	}
-	return &story, nil
+	return story, nil
 }
 
 func (r *Repository) Delete(ctx context.Context, id int64) error {
@@ -184,4 +169,3 @@ func (r *Repository) Delete(ctx context.Context, id int64) error {
 	}
 	return nil
 }
-*/
@@ -0,0 +1,154 @@
+package ceramicstory
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty repository.
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewRepository(pgtest.NewDB(t)) })
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewMemoryRepository() })
+}
+
+func testRepositoryContract(t *testing.T, newRepo func(t *testing.T) RepositoryInterface) {
+	ctx := context.Background()
+	create := func(t *testing.T, repo RepositoryInterface, slug string, order int, startYear *int) *models.CeramicStory {
+		t.Helper()
+		story, err := repo.Create(ctx, models.CreateCeramicStoryData{
+			DynastyName: "Dynasty " + slug, Slug: slug, StartYear: startYear, Description: "Porcelain.", DisplayOrder: order,
+		})
+		if err != nil {
+			t.Fatalf("Create(%s): %v", slug, err)
+		}
+		return story
+	}
+
+	t.Run("FindAllEmpty", func(t *testing.T) {
+		stories, err := newRepo(t).FindAll(ctx)
+		if err != nil || stories == nil || len(stories) != 0 {
+			t.Errorf("FindAll = (%v, %v), want an empty non-nil slice", stories, err)
+		}
+	})
+
+	t.Run("FindAllOrder", func(t *testing.T) {
+		repo := newRepo(t)
+		third := create(t, repo, "qing", 3, ptr(1644))
+		first := create(t, repo, "song", 1, ptr(960))
+		second := create(t, repo, "yuan", 2, nil)
+
+		stories, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll: %v", err)
+		}
+		var ids []int64
+		for _, s := range stories {
+			ids = append(ids, s.ID)
+		}
+		if len(ids) != 3 || ids[0] != first.ID || ids[1] != second.ID || ids[2] != third.ID {
+			t.Errorf("FindAll ids = %v, want [%d %d %d]", ids, first.ID, second.ID, third.ID)
+		}
+	})
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		created, err := repo.Create(ctx, models.CreateCeramicStoryData{
+			DynastyName: "Ming", Slug: "ming", Period: "Early Ming", StartYear: ptr(1368), EndYear: ptr(1644),
+			Description: "Blue and white.", ImageURL: "https://example.com/ming.jpg", DisplayOrder: 5,
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.ID == 0 {
+			t.Fatal("Create returned no ID")
+		}
+		for _, key := range []string{strconv.FormatInt(created.ID, 10), "ming"} {
+			got, err := repo.FindByIDOrSlug(ctx, key)
+			if err != nil {
+				t.Fatalf("FindByIDOrSlug(%q): %v", key, err)
+			}
+			if got.Slug != "ming" || got.Period != "Early Ming" || *got.StartYear != 1368 || *got.EndYear != 1644 ||
+				got.ImageURL != created.ImageURL || got.CharacteristicsArt != "" {
+				t.Errorf("FindByIDOrSlug(%q) = %+v", key, got)
+			}
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, key := range []string{"42", "no-such-dynasty"} {
+			if _, err := repo.FindByIDOrSlug(ctx, key); !errors.Is(err, models.ErrNotFound) {
+				t.Errorf("FindByIDOrSlug(%q): error = %v, want ErrNotFound", key, err)
+			}
+		}
+		if _, err := repo.Update(ctx, 42, models.UpdateCeramicStoryData{Takeaways: ptr("x")}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Update: error = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, 42); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Delete: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		repo := newRepo(t)
+		song := create(t, repo, "song", 1, nil)
+		tang := create(t, repo, "tang", 2, nil)
+
+		if _, err := repo.Create(ctx, models.CreateCeramicStoryData{DynastyName: "Song", Slug: "song", Description: "x", DisplayOrder: 3}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Create with taken slug: error = %v, want ErrConflict", err)
+		}
+		if _, err := repo.Create(ctx, models.CreateCeramicStoryData{DynastyName: "Han", Slug: "han", Description: "x", DisplayOrder: 1}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Create with taken display order: error = %v, want ErrConflict", err)
+		}
+		if _, err := repo.Update(ctx, tang.ID, models.UpdateCeramicStoryData{Slug: &song.Slug}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Update to a taken slug: error = %v, want ErrConflict", err)
+		}
+		if _, err := repo.Update(ctx, tang.ID, models.UpdateCeramicStoryData{DisplayOrder: &song.DisplayOrder}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Update to a taken display order: error = %v, want ErrConflict", err)
+		}
+		// Keeping its own slug is not a conflict
+		if _, err := repo.Update(ctx, tang.ID, models.UpdateCeramicStoryData{Slug: &tang.Slug}); err != nil {
+			t.Errorf("Update to its own slug: %v", err)
+		}
+		got, err := repo.FindByIDOrSlug(ctx, "tang")
+		if err != nil || got.DisplayOrder != 2 {
+			t.Errorf("story after failed updates = (%+v, %v), want display order 2", got, err)
+		}
+	})
+
+	t.Run("PartialUpdate", func(t *testing.T) {
+		repo := newRepo(t)
+		story := create(t, repo, "ming", 1, ptr(1368))
+
+		got, err := repo.Update(ctx, story.ID, models.UpdateCeramicStoryData{Period: ptr("Late Ming"), EndYear: ptr(1644)})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if got.Period != "Late Ming" || *got.StartYear != 1368 || *got.EndYear != 1644 || got.Slug != "ming" || got.Description != story.Description {
+			t.Errorf("Update = %+v", got)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		story := create(t, repo, "ming", 1, nil)
+		if err := repo.Delete(ctx, story.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.FindByIDOrSlug(ctx, "ming"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindByIDOrSlug after Delete: error = %v, want ErrNotFound", err)
+		}
+		// The slug and display order are free again
+		create(t, repo, "ming", 1, nil)
+	})
+}
@@ -0,0 +1,130 @@
+package ceramicstory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func ptr[T any](v T) *T { return &v }
+
+func TestRepositoryFindAllOrdersByDisplayOrder(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	late := factory.Story(t, db, func(s *models.CeramicStory) { s.DisplayOrder = 20 })
+	early := factory.Story(t, db, func(s *models.CeramicStory) { s.DisplayOrder = 10 })
+
+	stories, err := repo.FindAll(context.Background())
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(stories) != 2 || stories[0].ID != early.ID || stories[1].ID != late.ID {
+		t.Fatalf("FindAll = %+v, want ids [%d %d]", stories, early.ID, late.ID)
+	}
+}
+
+func TestRepositoryFindByIDOrSlug(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	story := factory.Story(t, db)
+	ctx := context.Background()
+
+	for _, key := range []string{strconv.FormatInt(story.ID, 10), story.Slug} {
+		got, err := repo.FindByIDOrSlug(ctx, key)
+		if err != nil {
+			t.Fatalf("FindByIDOrSlug(%q): %v", key, err)
+		}
+		if got.ID != story.ID || got.DynastyName != story.DynastyName || got.Period != story.Period {
+			t.Errorf("FindByIDOrSlug(%q) = %+v, want %+v", key, got, story)
+		}
+	}
+
+	for _, key := range []string{"999999", "no-such-dynasty"} {
+		if _, err := repo.FindByIDOrSlug(ctx, key); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindByIDOrSlug(%q) error = %v, want ErrNotFound", key, err)
+		}
+	}
+}
+
+func TestRepositoryCreate(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	existing := factory.Story(t, db)
+	ctx := context.Background()
+
+	data := models.CreateCeramicStoryData{
+		DynastyName:  "Ming",
+		Slug:         "ming",
+		StartYear:    ptr(1368),
+		EndYear:      ptr(1644),
+		Description:  "Blue and white porcelain reaches its height.",
+		DisplayOrder: existing.DisplayOrder + 1,
+	}
+	story, err := repo.Create(ctx, data)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if story.ID == 0 || story.Slug != "ming" || story.Period != "" || *story.StartYear != 1368 {
+		t.Errorf("Create = %+v", story)
+	}
+
+	dupSlug := data
+	dupSlug.DisplayOrder++
+	if _, err := repo.Create(ctx, dupSlug); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("Create with taken slug: error = %v, want ErrConflict", err)
+	}
+	dupOrder := data
+	dupOrder.Slug = "ming-2"
+	dupOrder.DisplayOrder = existing.DisplayOrder
+	if _, err := repo.Create(ctx, dupOrder); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("Create with taken display order: error = %v, want ErrConflict", err)
+	}
+}
+
+func TestRepositoryUpdate(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	story := factory.Story(t, db)
+	other := factory.Story(t, db)
+	ctx := context.Background()
+
+	got, err := repo.Update(ctx, story.ID, models.UpdateCeramicStoryData{Takeaways: ptr("Cobalt from Persia.")})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got.Takeaways != "Cobalt from Persia." || got.DynastyName != story.DynastyName || got.Slug != story.Slug {
+		t.Errorf("Update = %+v: want only takeaways changed", got)
+	}
+
+	if _, err := repo.Update(ctx, story.ID, models.UpdateCeramicStoryData{Slug: &other.Slug}); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("Update to a taken slug: error = %v, want ErrConflict", err)
+	}
+	if _, err := repo.Update(ctx, 999999, models.UpdateCeramicStoryData{Takeaways: ptr("x")}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Update of a missing story: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRepositoryDelete(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	story := factory.Story(t, db)
+	ctx := context.Background()
+
+	if err := repo.Delete(ctx, story.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.FindByIDOrSlug(ctx, story.Slug); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("FindByIDOrSlug after Delete: error = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, story.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("second Delete: error = %v, want ErrNotFound", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"jingdezhen-ceramics-backend/internal/models"
+	"strconv"
 )
 
 // ServiceInterface defines the methods for ceramic story business logic.
@@ -11,9 +12,9 @@ type ServiceInterface interface {
 	GetAllCeramicStories(ctx context.Context) ([]models.CeramicStory, error)
 	GetCeramicStoryDetail(ctx context.Context, idOrSlug string) (*models.CeramicStory, error)
 	// Admin methods
-	// CreateCeramicStory(ctx context.Context, data models.CreateCeramicStoryData) (*models.CeramicStory, error)
-	// UpdateCeramicStory(ctx context.Context, id int64, data models.UpdateCeramicStoryData) (*models.CeramicStory, error)
-	// DeleteCeramicStory(ctx context.Context, id int64) error
+	CreateCeramicStory(ctx context.Context, data models.CreateCeramicStoryData) (*models.CeramicStory, error)
+	UpdateCeramicStory(ctx context.Context, id int64, data models.UpdateCeramicStoryData) (*models.CeramicStory, error)
+	DeleteCeramicStory(ctx context.Context, id int64) error
 }
 
 // Service provides business logic for ceramic stories.
@@ -51,29 +52,58 @@ func (s *Service) GetCeramicStoryDetail(ctx context.Context, idOrSlug string) (*
 	return story, nil
 }
 
-// --- Admin Service Methods (Example Implementations - uncomment and complete if needed for admin panel) ---
-/*
+// --- Admin Service Methods ---
+
+// CreateCeramicStory creates a story. Slug uniqueness is enforced by the database and surfaces as models.ErrConflict.
 func (s *Service) CreateCeramicStory(ctx context.Context, data models.CreateCeramicStoryData) (*models.CeramicStory, error) {
-	// Add validation logic here if not fully covered by handler/validator (e.g., check for slug uniqueness)
-	// existingBySlug, _ := s.repo.FindByIDOrSlug(ctx, data.Slug)
-	// if existingBySlug != nil {
-	// 	 return nil, models.ErrConflict // Or a more specific "slug taken" error
-	// }
-	return s.repo.Create(ctx, data)
+	if err := checkYearRange(data.StartYear, data.EndYear); err != nil {
+		return nil, err
+	}
+	story, err := s.repo.Create(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateCeramicStory: %w", err)
+	}
+	return story, nil
 }
 
+// UpdateCeramicStory applies a partial update. When only one of the years is sent it is
+// checked against the year already stored.
 func (s *Service) UpdateCeramicStory(ctx context.Context, id int64, data models.UpdateCeramicStoryData) (*models.CeramicStory, error) {
-	// Add validation logic (e.g., if slug is being changed, check for uniqueness)
-	// if data.Slug != nil {
-	// 	 existingBySlug, _ := s.repo.FindByIDOrSlug(ctx, *data.Slug)
-	// 	 if existingBySlug != nil && existingBySlug.ID != id {
-	// 	 	return nil, models.ErrConflict // Slug taken by another story
-	// 	 }
-	// }
-	return s.repo.Update(ctx, id, data)
+	if data.StartYear != nil || data.EndYear != nil {
+		existing, err := s.repo.FindByIDOrSlug(ctx, strconv.FormatInt(id, 10))
+		if err != nil {
+			return nil, fmt.Errorf("service.UpdateCeramicStory: %w", err)
+		}
+		startYear, endYear := existing.StartYear, existing.EndYear
+		if data.StartYear != nil {
+			startYear = data.StartYear
+		}
+		if data.EndYear != nil {
+			endYear = data.EndYear
+		}
+		if err := checkYearRange(startYear, endYear); err != nil {
+			return nil, err
+		}
+	}
+
+	story, err := s.repo.Update(ctx, id, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateCeramicStory: %w", err)
+	}
+	return story, nil
 }
 
 func (s *Service) DeleteCeramicStory(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("service.DeleteCeramicStory: %w", err)
+	}
+	return nil
+}
+
+// checkYearRange allows either year to be unknown (BC dates and open-ended periods are fine).
+func checkYearRange(startYear, endYear *int) error {
+	if startYear != nil && endYear != nil && *startYear > *endYear {
+		return models.ErrInvalidYearRange
+	}
+	return nil
 }
-*/
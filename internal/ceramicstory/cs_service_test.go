@@ -0,0 +1,45 @@
+package ceramicstory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+)
+
+func TestServiceCreateRejectsInvertedYears(t *testing.T) {
+	repo := NewMemoryRepository()
+	svc := NewService(repo)
+
+	_, err := svc.CreateCeramicStory(context.Background(), models.CreateCeramicStoryData{
+		DynastyName: "Ming", Slug: "ming", StartYear: ptr(1644), EndYear: ptr(1368), Description: "x",
+	})
+	if !errors.Is(err, models.ErrInvalidYearRange) {
+		t.Fatalf("error = %v, want ErrInvalidYearRange", err)
+	}
+	if stories, _ := repo.FindAll(context.Background()); len(stories) != 0 {
+		t.Errorf("stored %d stories, want none", len(stories))
+	}
+}
+
+func TestServiceUpdateChecksYearsAgainstStoredStory(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(NewMemoryRepository())
+	story, err := svc.CreateCeramicStory(ctx, models.CreateCeramicStoryData{
+		DynastyName: "Ming", Slug: "ming", StartYear: ptr(1368), EndYear: ptr(1644), Description: "x",
+	})
+	if err != nil {
+		t.Fatalf("CreateCeramicStory: %v", err)
+	}
+
+	if _, err := svc.UpdateCeramicStory(ctx, story.ID, models.UpdateCeramicStoryData{StartYear: ptr(1700)}); !errors.Is(err, models.ErrInvalidYearRange) {
+		t.Errorf("start year after the stored end year: error = %v, want ErrInvalidYearRange", err)
+	}
+	if _, err := svc.UpdateCeramicStory(ctx, story.ID, models.UpdateCeramicStoryData{EndYear: ptr(1400)}); err != nil {
+		t.Errorf("valid end year: %v", err)
+	}
+	if _, err := svc.UpdateCeramicStory(ctx, story.ID+1, models.UpdateCeramicStoryData{EndYear: ptr(1400)}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("missing story: error = %v, want ErrNotFound", err)
+	}
+}
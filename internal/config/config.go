@@ -1,16 +1,41 @@
 package config
 
 import (
-	"github.com/spf13/viper"
 	"log"
+	"time"
+
+	"github.com/spf13/viper"
 )
 
 type Config struct {
-	ServerPort   string `mapstructure:"SERVER_PORT"`
-	DatabaseURL  string `mapstructure:"DATABASE_URL"`
-	JWTSecret    string `mapstructure:"JWT_SECRET"`
-	ClientOrigin string `mapstructure:"CLIENT_ORIGIN"`
-	AdminEmail   string `mapstructure:"ADMIN_EMAIL"`
+	ServerPort  string `mapstructure:"SERVER_PORT"`
+	DatabaseURL string `mapstructure:"DATABASE_URL"`
+	JWTSecret   string `mapstructure:"JWT_SECRET"`
+	// JWTExpiry is how long issued access tokens stay valid, e.g. "15m" or "24h"
+	JWTExpiry time.Duration `mapstructure:"JWT_EXPIRY"`
+	// RefreshTokenExpiry is how long a login session can be kept alive through /auth/refresh, e.g. "720h"
+	RefreshTokenExpiry time.Duration `mapstructure:"REFRESH_TOKEN_EXPIRY"`
+	ClientOrigin       string        `mapstructure:"CLIENT_ORIGIN"`
+	AdminEmail         string        `mapstructure:"ADMIN_EMAIL"`
+
+	// Outgoing mail
+	SMTPServer       string `mapstructure:"SMTP_SERVER"`
+	SMTPPort         string `mapstructure:"SMTP_PORT"`
+	SMTPUser         string `mapstructure:"SMTP_USER"`
+	SMTPPassword     string `mapstructure:"SMTP_PASSWORD"`
+	SMTPTLSMode      string `mapstructure:"SMTP_TLS_MODE"` // "starttls" (default), "tls" or "none"
+	EmailFromAddress string `mapstructure:"EMAIL_FROM_ADDRESS"`
+	EmailWorkers     int    `mapstructure:"EMAIL_WORKERS"` // Outbox delivery goroutines, defaults to 2
+
+	// RateLimitStore is "memory" (default, per instance) or "postgres" (shared by all instances)
+	RateLimitStore string `mapstructure:"RATE_LIMIT_STORE"`
+	// TrustedProxies are comma-separated CIDRs of our reverse proxies, e.g. "10.0.0.0/8". Only hops from
+	// them are skipped in X-Forwarded-For and only their X-Request-ID is kept; when empty the client IP is the
+	// TCP peer, the header is ignored and every request ID is generated here.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// AutoMigrate applies pending schema migrations on start; otherwise run cmd/migrate before deploying
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
 	// Add other configurations as needed
 }
 
@@ -0,0 +1,132 @@
+package contact
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler serves the public contact form and the admin inbox. The inbox routes require contact.manage.
+type Handler struct {
+	service  ServiceInterface
+	validate *validator.Validate
+}
+
+// NewHandler creates a new contact handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// GetFormToken issues the token the contact form has to submit; fetch it when the form is shown.
+func (h *Handler) GetFormToken(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"form_token": h.service.NewFormToken()})
+}
+
+// SubmitContactForm answers the same for accepted and silently discarded (spam) submissions.
+// The per-IP limit relies on c.RealIP, which only believes X-Forwarded-For from trusted proxies.
+func (h *Handler) SubmitContactForm(c echo.Context) error {
+	var req models.ContactFormData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	if _, err := h.service.Submit(c.Request().Context(), req, c.RealIP()); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Contact form submitted successfully"})
+}
+
+// --- Admin Inbox ---
+
+// GetMessages lists contact messages, newest first. Spam is only listed with status=spam.
+// Params: ?page=1&limit=20&status=new|replied|closed|spam&q=keyword
+func (h *Handler) GetMessages(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	filter := models.ContactMessageFilter{
+		Page:   page,
+		Limit:  limit,
+		Status: c.QueryParam("status"),
+		Query:  c.QueryParam("q"),
+	}
+	switch filter.Status {
+	case "", models.ContactStatusNew, models.ContactStatusReplied, models.ContactStatusClosed, models.ContactStatusSpam:
+	default:
+		return models.BadRequestError("status must be new, replied, closed or spam")
+	}
+
+	messages, total, err := h.service.ListMessages(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(messages, page, limit, total))
+}
+
+// GetMessage returns a contact message with the replies sent so far.
+func (h *Handler) GetMessage(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid message ID")
+	}
+
+	message, err := h.service.GetMessage(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, message)
+}
+
+func (h *Handler) UpdateMessageStatus(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid message ID")
+	}
+	var req models.UpdateContactStatusData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	updated, err := h.service.UpdateStatus(c.Request().Context(), id, req.Status)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// ReplyToMessage emails the sender. The reply is queued, so 201 means it will be delivered (or
+// show up under /admin/emails/failed), not that it already was.
+func (h *Handler) ReplyToMessage(c echo.Context) error {
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	id, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid message ID")
+	}
+	var req models.ContactReplyData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	reply, err := h.service.Reply(c.Request().Context(), id, adminID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, reply)
+}
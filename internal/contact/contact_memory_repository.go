@@ -0,0 +1,209 @@
+package contact
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository; reply emails that would be queued in the outbox are kept for Emails, and admin nicknames,
+// which come from the users table, are registered with AddUser.
+type MemoryRepository struct {
+	mu        sync.Mutex
+	messages  map[int64]*memoryMessage
+	replies   map[int64][]models.ContactReply
+	nicknames map[string]string
+	emails    []email.Message
+	lastID    int64
+}
+
+type memoryMessage struct {
+	models.ContactMessage
+	network, formNonce string
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory contact inbox.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		messages:  map[int64]*memoryMessage{},
+		replies:   map[int64][]models.ContactReply{},
+		nicknames: map[string]string{},
+	}
+}
+
+// AddUser registers the nickname ListReplies shows for replies by userID.
+func (r *MemoryRepository) AddUser(userID, nickname string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nicknames[userID] = nickname
+}
+
+// Emails returns the messages queued by CreateReply, oldest first.
+func (r *MemoryRepository) Emails() []email.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]email.Message(nil), r.emails...)
+}
+
+// view returns m as the repository returns it, without the replies and with detached pointers.
+func view(m *memoryMessage) *models.ContactMessage {
+	out := m.ContactMessage
+	if out.LastRepliedAt != nil {
+		at := *out.LastRepliedAt
+		out.LastRepliedAt = &at
+	}
+	out.Replies = nil
+	return &out
+}
+
+func validStatus(status string) bool {
+	switch status {
+	case models.ContactStatusNew, models.ContactStatusReplied, models.ContactStatusClosed, models.ContactStatusSpam:
+		return true
+	}
+	return false
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, data models.ContactFormData, sender models.ContactSender, status, spamReason string) (*models.ContactMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !validStatus(status) {
+		return nil, fmt.Errorf("repository.Create: invalid status %q", status) // The CHECK constraint
+	}
+	if sender.FormNonce != "" {
+		for _, m := range r.messages {
+			if m.formNonce == sender.FormNonce {
+				return nil, models.ErrConflict
+			}
+		}
+	}
+	r.lastID++
+	now := time.Now()
+	m := &memoryMessage{
+		ContactMessage: models.ContactMessage{
+			ID: r.lastID, Name: data.Name, Email: data.Email, Subject: data.Subject, Message: data.Message,
+			Status: status, SpamReason: spamReason, IPAddress: sender.IPAddress, CreatedAt: now, UpdatedAt: now,
+		},
+		network:   sender.Network,
+		formNonce: sender.FormNonce,
+	}
+	r.messages[m.ID] = m
+	return view(m), nil
+}
+
+func (r *MemoryRepository) CountRecent(ctx context.Context, network, email string, since time.Time) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var byNetwork, byEmail int
+	for _, m := range r.messages {
+		if m.CreatedAt.Before(since) {
+			continue
+		}
+		if m.network == network {
+			byNetwork++
+		}
+		if strings.EqualFold(m.Email, email) {
+			byEmail++
+		}
+	}
+	return byNetwork, byEmail, nil
+}
+
+func (r *MemoryRepository) FindByID(ctx context.Context, id int64) (*models.ContactMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.messages[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return view(m), nil
+}
+
+func (r *MemoryRepository) List(ctx context.Context, filter models.ContactMessageFilter) ([]models.ContactMessage, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := strings.ToLower(filter.Query)
+	var matched []models.ContactMessage
+	for _, m := range r.messages {
+		if filter.Status == "" && m.Status == models.ContactStatusSpam || filter.Status != "" && m.Status != filter.Status {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(m.Name), query) &&
+			!strings.Contains(strings.ToLower(m.Email), query) && !strings.Contains(strings.ToLower(m.Subject), query) {
+			continue
+		}
+		matched = append(matched, *view(m))
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	offset := (filter.Page - 1) * filter.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	page := []models.ContactMessage{}
+	for i := offset; i < len(matched) && i < offset+filter.Limit; i++ {
+		page = append(page, matched[i])
+	}
+	return page, len(matched), nil
+}
+
+func (r *MemoryRepository) ListReplies(ctx context.Context, id int64) ([]models.ContactReply, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	replies := []models.ContactReply{}
+	for _, reply := range r.replies[id] {
+		reply.AdminNickname = r.nicknames[reply.AdminID]
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+func (r *MemoryRepository) UpdateStatus(ctx context.Context, id int64, status string) (*models.ContactMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.messages[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if !validStatus(status) {
+		return nil, fmt.Errorf("repository.UpdateStatus: invalid status %q", status)
+	}
+	m.Status = status
+	m.UpdatedAt = time.Now()
+	return view(m), nil
+}
+
+func (r *MemoryRepository) CreateReply(ctx context.Context, id int64, adminID, subject, body string, msg *email.Message) (*models.ContactReply, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.messages[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, fmt.Errorf("repository.CreateReply: outbox.Enqueue: %w", err)
+	}
+
+	now := time.Now()
+	r.lastID++
+	reply := models.ContactReply{ID: r.lastID, AdminID: adminID, Subject: subject, Body: body, CreatedAt: now}
+	r.replies[id] = append(r.replies[id], reply)
+	r.emails = append(r.emails, *msg)
+	repliedAt := now
+	m.Status, m.LastRepliedAt, m.UpdatedAt = models.ContactStatusReplied, &repliedAt, now
+	return &reply, nil
+}
@@ -0,0 +1,207 @@
+package contact
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/outbox"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines methods for the contact_messages inbox.
+type RepositoryInterface interface {
+	// Create stores a submission with status new, or spam with a spamReason. It returns models.ErrConflict
+	// when sender.FormNonce was already used by another submission.
+	Create(ctx context.Context, data models.ContactFormData, sender models.ContactSender, status, spamReason string) (*models.ContactMessage, error)
+	// CountRecent counts submissions since since from network (see models.ContactSender) and from email, spam included.
+	CountRecent(ctx context.Context, network, email string, since time.Time) (byNetwork int, byEmail int, err error)
+	FindByID(ctx context.Context, id int64) (*models.ContactMessage, error)
+	List(ctx context.Context, filter models.ContactMessageFilter) ([]models.ContactMessage, int, error)
+	ListReplies(ctx context.Context, id int64) ([]models.ContactReply, error)
+	UpdateStatus(ctx context.Context, id int64, status string) (*models.ContactMessage, error)
+	// CreateReply records the reply, marks the message replied and queues msg in one transaction,
+	// so a reply shown in the inbox has always been handed to the outbox.
+	CreateReply(ctx context.Context, id int64, adminID, subject, body string, msg *email.Message) (*models.ContactReply, error)
+}
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+const messageColumns = `id, name, email, subject, message, status, COALESCE(spam_reason, ''), COALESCE(ip_address, ''),
+	last_replied_at, created_at, updated_at`
+
+const messageSelect = `SELECT ` + messageColumns + ` FROM contact_messages`
+
+func scanMessage(row pgx.Row) (*models.ContactMessage, error) {
+	m := &models.ContactMessage{}
+	err := row.Scan(&m.ID, &m.Name, &m.Email, &m.Subject, &m.Message, &m.Status, &m.SpamReason, &m.IPAddress,
+		&m.LastRepliedAt, &m.CreatedAt, &m.UpdatedAt)
+	return m, err
+}
+
+func (r *Repository) Create(ctx context.Context, data models.ContactFormData, sender models.ContactSender, status, spamReason string) (*models.ContactMessage, error) {
+	query := `INSERT INTO contact_messages (name, email, subject, message, status, spam_reason, ip_address, ip_network, form_nonce)
+	          VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''))
+	          ON CONFLICT (form_nonce) DO NOTHING
+	          RETURNING ` + messageColumns
+	created, err := scanMessage(r.db.QueryRow(ctx, query,
+		data.Name, data.Email, data.Subject, data.Message, status, spamReason, sender.IPAddress, sender.Network, sender.FormNonce))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.Create: %w", err)
+	}
+	return created, nil
+}
+
+func (r *Repository) CountRecent(ctx context.Context, network, email string, since time.Time) (int, int, error) {
+	query := `SELECT COUNT(*) FILTER (WHERE ip_network = $1), COUNT(*) FILTER (WHERE LOWER(email) = LOWER($2))
+	          FROM contact_messages
+	          WHERE created_at >= $3 AND (ip_network = $1 OR LOWER(email) = LOWER($2))`
+	var byNetwork, byEmail int
+	if err := r.db.QueryRow(ctx, query, network, email, since).Scan(&byNetwork, &byEmail); err != nil {
+		return 0, 0, fmt.Errorf("repository.CountRecent: %w", err)
+	}
+	return byNetwork, byEmail, nil
+}
+
+func (r *Repository) FindByID(ctx context.Context, id int64) (*models.ContactMessage, error) {
+	m, err := scanMessage(r.db.QueryRow(ctx, messageSelect+` WHERE id = $1`, id))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindByID: %w", err)
+	}
+	return m, nil
+}
+
+func (r *Repository) List(ctx context.Context, filter models.ContactMessageFilter) ([]models.ContactMessage, int, error) {
+	where := []string{"status <> 'spam'"}
+	var args []interface{}
+	argIdx := 1
+
+	if filter.Status != "" {
+		where[0] = fmt.Sprintf("status = $%d", argIdx)
+		args = append(args, filter.Status)
+		argIdx++
+	}
+	if filter.Query != "" {
+		where = append(where, fmt.Sprintf(`(name ILIKE $%[1]d ESCAPE '\' OR email ILIKE $%[1]d ESCAPE '\' OR subject ILIKE $%[1]d ESCAPE '\')`, argIdx))
+		args = append(args, utils.ContainsPattern(filter.Query))
+		argIdx++
+	}
+	whereClause := " WHERE " + strings.Join(where, " AND ")
+
+	offset := (filter.Page - 1) * filter.Limit
+	query := messageSelect + whereClause + fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	rows, err := r.db.Query(ctx, query, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.List: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.ContactMessage{}
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("repository.List.Scan: %w", err)
+		}
+		messages = append(messages, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.List.Rows: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM contact_messages"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.List.Count: %w", err)
+	}
+	return messages, total, nil
+}
+
+func (r *Repository) ListReplies(ctx context.Context, id int64) ([]models.ContactReply, error) {
+	query := `SELECT cr.id, COALESCE(cr.admin_id::text, ''), COALESCE(u.nickname, ''), cr.subject, cr.body, cr.created_at
+	          FROM contact_message_replies cr
+	          LEFT JOIN users u ON u.id = cr.admin_id
+	          WHERE cr.contact_message_id = $1
+	          ORDER BY cr.created_at`
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListReplies: %w", err)
+	}
+	defer rows.Close()
+
+	replies := []models.ContactReply{}
+	for rows.Next() {
+		var reply models.ContactReply
+		if err := rows.Scan(&reply.ID, &reply.AdminID, &reply.AdminNickname, &reply.Subject, &reply.Body, &reply.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository.ListReplies.Scan: %w", err)
+		}
+		replies = append(replies, reply)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListReplies.Rows: %w", err)
+	}
+	return replies, nil
+}
+
+func (r *Repository) UpdateStatus(ctx context.Context, id int64, status string) (*models.ContactMessage, error) {
+	query := `UPDATE contact_messages SET status = $2, updated_at = NOW()
+	          WHERE id = $1
+	          RETURNING ` + messageColumns
+	updated, err := scanMessage(r.db.QueryRow(ctx, query, id, status))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.UpdateStatus: %w", err)
+	}
+	return updated, nil
+}
+
+func (r *Repository) CreateReply(ctx context.Context, id int64, adminID, subject, body string, msg *email.Message) (*models.ContactReply, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateReply.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Answering a closed message reopens the conversation as replied.
+	tag, err := tx.Exec(ctx,
+		`UPDATE contact_messages SET status = 'replied', last_replied_at = NOW(), updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateReply.Status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	reply := &models.ContactReply{AdminID: adminID, Subject: subject, Body: body}
+	query := `INSERT INTO contact_message_replies (contact_message_id, admin_id, subject, body)
+	          VALUES ($1, $2, $3, $4)
+	          RETURNING id, created_at`
+	if err := tx.QueryRow(ctx, query, id, adminID, subject, body).Scan(&reply.ID, &reply.CreatedAt); err != nil {
+		return nil, fmt.Errorf("repository.CreateReply: %w", err)
+	}
+
+	if err := outbox.Enqueue(ctx, tx, msg); err != nil {
+		return nil, fmt.Errorf("repository.CreateReply: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.CreateReply.Commit: %w", err)
+	}
+	return reply, nil
+}
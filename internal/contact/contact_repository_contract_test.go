@@ -0,0 +1,216 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+	"jingdezhen-ceramics-backend/pkg/email"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty repository and a function creating an
+// admin account, returning its ID and nickname.
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) (RepositoryInterface, func() (string, string)) {
+		db := pgtest.NewDB(t)
+		return NewRepository(db), func() (string, string) {
+			u := factory.User(t, db, func(u *models.User) { u.Role = models.RoleAdmin })
+			return u.ID, u.Nickname
+		}
+	})
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) (RepositoryInterface, func() (string, string)) {
+		repo := NewMemoryRepository()
+		lastUserID := 0
+		return repo, func() (string, string) {
+			lastUserID++
+			id, nickname := strconv.Itoa(lastUserID), "admin"+strconv.Itoa(lastUserID)
+			repo.AddUser(id, nickname)
+			return id, nickname
+		}
+	})
+}
+
+func testRepositoryContract(t *testing.T, newRepo func(t *testing.T) (RepositoryInterface, func() (string, string))) {
+	ctx := context.Background()
+	sender := models.ContactSender{IPAddress: "2001:db8::1", Network: "2001:db8::/64"}
+	create := func(t *testing.T, repo RepositoryInterface, name, subject, status string) *models.ContactMessage {
+		t.Helper()
+		data := models.ContactFormData{Name: name, Email: name + "@example.com", Subject: subject, Message: "When is the kiln open?"}
+		reason := ""
+		if status == models.ContactStatusSpam {
+			reason = "honeypot field filled in"
+		}
+		m, err := repo.Create(ctx, data, sender, status, reason)
+		if err != nil {
+			t.Fatalf("Create(%s): %v", name, err)
+		}
+		return m
+	}
+	ids := func(messages []models.ContactMessage) []int64 {
+		ids := []int64{}
+		for _, m := range messages {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+	reply := &email.Message{To: []string{"li@example.com"}, Subject: "Re: Visit", TextBody: "Open daily."}
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		created := create(t, repo, "li", "Visit", models.ContactStatusNew)
+		if created.ID == 0 || created.CreatedAt.IsZero() {
+			t.Fatalf("Create = %+v, want ID and timestamps", created)
+		}
+		got, err := repo.FindByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if got.Name != "li" || got.Email != "li@example.com" || got.Subject != "Visit" || got.Status != models.ContactStatusNew ||
+			got.SpamReason != "" || got.IPAddress != "2001:db8::1" || got.LastRepliedAt != nil {
+			t.Errorf("FindByID = %+v", got)
+		}
+		if _, err := repo.FindByID(ctx, created.ID+100); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindByID(missing): error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("FormNonceIsSingleUse", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		data := models.ContactFormData{Name: "li", Email: "li@example.com", Subject: "Visit", Message: "When is the kiln open?"}
+		withNonce := sender
+		withNonce.FormNonce = "nonce-1"
+		if _, err := repo.Create(ctx, data, withNonce, models.ContactStatusNew, ""); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := repo.Create(ctx, data, withNonce, models.ContactStatusNew, ""); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Create with a used nonce: error = %v, want ErrConflict", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := repo.Create(ctx, data, sender, models.ContactStatusSpam, "missing or forged form token"); err != nil {
+				t.Errorf("Create without nonce %d: %v", i, err)
+			}
+		}
+	})
+
+	t.Run("CountRecent", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		create(t, repo, "li", "Visit", models.ContactStatusNew)
+		create(t, repo, "li", "Visit again", models.ContactStatusSpam)
+		other := models.ContactFormData{Name: "wang", Email: "wang@example.com", Subject: "Hi", Message: "When is the kiln open?"}
+		if _, err := repo.Create(ctx, other, models.ContactSender{IPAddress: "203.0.113.7", Network: "203.0.113.7"}, models.ContactStatusNew, ""); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		byNetwork, byEmail, err := repo.CountRecent(ctx, sender.Network, "LI@example.com", time.Now().Add(-time.Hour))
+		if err != nil || byNetwork != 2 || byEmail != 2 {
+			t.Errorf("CountRecent = (%d, %d, %v), want 2 by network and 2 by email, spam included", byNetwork, byEmail, err)
+		}
+		byNetwork, byEmail, _ = repo.CountRecent(ctx, "203.0.113.7", "nobody@example.com", time.Now().Add(-time.Hour))
+		if byNetwork != 1 || byEmail != 0 {
+			t.Errorf("CountRecent(other sender) = (%d, %d), want (1, 0)", byNetwork, byEmail)
+		}
+		byNetwork, byEmail, _ = repo.CountRecent(ctx, sender.Network, "li@example.com", time.Now().Add(time.Hour))
+		if byNetwork != 0 || byEmail != 0 {
+			t.Errorf("CountRecent(since the future) = (%d, %d), want none", byNetwork, byEmail)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		visit := create(t, repo, "li", "Visit", models.ContactStatusNew)
+		spam := create(t, repo, "bot", "Casino", models.ContactStatusSpam)
+		percent := create(t, repo, "wang", "100% porcelain?", models.ContactStatusNew)
+		closed, err := repo.UpdateStatus(ctx, create(t, repo, "zhao", "Workshop", models.ContactStatusNew).ID, models.ContactStatusClosed)
+		if err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		if closed.Status != models.ContactStatusClosed {
+			t.Errorf("UpdateStatus = %+v, want closed", closed)
+		}
+
+		tests := []struct {
+			name   string
+			filter models.ContactMessageFilter
+			want   []int64
+		}{
+			{"inbox hides spam", models.ContactMessageFilter{}, []int64{closed.ID, percent.ID, visit.ID}},
+			{"spam", models.ContactMessageFilter{Status: models.ContactStatusSpam}, []int64{spam.ID}},
+			{"closed", models.ContactMessageFilter{Status: models.ContactStatusClosed}, []int64{closed.ID}},
+			{"name ignores case", models.ContactMessageFilter{Query: "LI"}, []int64{visit.ID}},
+			{"email", models.ContactMessageFilter{Query: "wang@"}, []int64{percent.ID}},
+			{"subject", models.ContactMessageFilter{Query: "shop"}, []int64{closed.ID}},
+			{"wildcards are literal", models.ContactMessageFilter{Query: "%"}, []int64{percent.ID}},
+		}
+		for _, tt := range tests {
+			tt.filter.Page, tt.filter.Limit = 1, 10
+			messages, total, err := repo.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List(%s): %v", tt.name, err)
+			}
+			if total != len(tt.want) || !reflect.DeepEqual(ids(messages), tt.want) {
+				t.Errorf("List(%s) = %v (total %d), want %v", tt.name, ids(messages), total, tt.want)
+			}
+		}
+
+		messages, total, err := repo.List(ctx, models.ContactMessageFilter{Page: 2, Limit: 2})
+		if err != nil || total != 3 || !reflect.DeepEqual(ids(messages), []int64{visit.ID}) {
+			t.Errorf("List(page 2) = %v (total %d, %v), want [%d] of 3", ids(messages), total, err, visit.ID)
+		}
+		if _, err := repo.UpdateStatus(ctx, spam.ID+100, models.ContactStatusClosed); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateStatus(missing): error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Reply", func(t *testing.T) {
+		repo, newAdmin := newRepo(t)
+		adminID, nickname := newAdmin()
+		m := create(t, repo, "li", "Visit", models.ContactStatusNew)
+		if _, err := repo.UpdateStatus(ctx, m.ID, models.ContactStatusClosed); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+
+		created, err := repo.CreateReply(ctx, m.ID, adminID, "Re: Visit", "Open daily.", reply)
+		if err != nil {
+			t.Fatalf("CreateReply: %v", err)
+		}
+		if created.ID == 0 || created.AdminID != adminID || created.Subject != "Re: Visit" || created.CreatedAt.IsZero() {
+			t.Errorf("CreateReply = %+v", created)
+		}
+		got, err := repo.FindByID(ctx, m.ID)
+		if err != nil || got.Status != models.ContactStatusReplied || got.LastRepliedAt == nil {
+			t.Errorf("message after reply = (%+v, %v), want it reopened as replied", got, err)
+		}
+		replies, err := repo.ListReplies(ctx, m.ID)
+		if err != nil || len(replies) != 1 || replies[0].ID != created.ID || replies[0].AdminNickname != nickname || replies[0].Body != "Open daily." {
+			t.Errorf("ListReplies = (%+v, %v), want the reply by %s", replies, err, nickname)
+		}
+
+		if _, err := repo.CreateReply(ctx, m.ID+100, adminID, "Re: x", "x", reply); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("CreateReply(missing): error = %v, want ErrNotFound", err)
+		}
+		other := create(t, repo, "wang", "Hi", models.ContactStatusNew)
+		if _, err := repo.CreateReply(ctx, other.ID, adminID, "Re: Hi", "x", &email.Message{Subject: "Re: Hi"}); err == nil {
+			t.Error("CreateReply accepted an email without recipients")
+		}
+		if got, _ := repo.FindByID(ctx, other.ID); got == nil || got.Status != models.ContactStatusNew {
+			t.Errorf("message after a failed reply = %+v, want it still new", got)
+		}
+		if replies, _ := repo.ListReplies(ctx, other.ID); len(replies) != 0 {
+			t.Errorf("ListReplies after a failed reply = %+v, want none", replies)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"log"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	// At most contactRateLimitPerIP messages from one IPv4 address or IPv6 /64 and contactRateLimitPerEmail from one address
+	// are accepted per contactRateWindow, spam included.
+	contactRateLimitPerIP    = 5
+	contactRateLimitPerEmail = 3
+	contactRateWindow        = time.Hour
+)
+
+// ServiceInterface defines the contact form and the admin inbox.
+type ServiceInterface interface {
+	// NewFormToken is handed out with the contact form and must come back with the submission.
+	NewFormToken() string
+	// Submit stores the message and notifies the admin address. The message is kept even if the
+	// notification cannot be queued; only a failure to store it is returned. Submissions flagged as
+	// spam are stored with status spam and succeed like any other, so bots cannot tell what gave them away.
+	// It returns models.ErrContactRateLimited when clientIP (its /64 for IPv6) or the sender sent too many
+	// messages recently. A form token is accepted once; resubmissions with it are flagged as spam.
+	Submit(ctx context.Context, data models.ContactFormData, clientIP string) (*models.ContactMessage, error)
+
+	// Admin inbox
+	ListMessages(ctx context.Context, filter models.ContactMessageFilter) ([]models.ContactMessage, int, error)
+	GetMessage(ctx context.Context, id int64) (*models.ContactMessage, error)
+	UpdateStatus(ctx context.Context, id int64, status string) (*models.ContactMessage, error)
+	// Reply emails the sender through the outbox and marks the message replied.
+	Reply(ctx context.Context, id int64, adminID string, data models.ContactReplyData) (*models.ContactReply, error)
+}
+
+type Service struct {
+	repo       RepositoryInterface
+	emailSvc   email.ServiceInterface // Queues admin notifications through the outbox
+	templates  *email.Registry
+	scorer     Scorer
+	formSecret []byte // Signs form tokens
+	adminEmail string // Notifications are skipped when empty
+}
+
+func NewService(
+	repo RepositoryInterface,
+	emailSvc email.ServiceInterface,
+	templates *email.Registry,
+	scorer Scorer,
+	formSecret string,
+	adminEmailFromConfig string,
+) ServiceInterface {
+	return &Service{
+		repo:       repo,
+		emailSvc:   emailSvc,
+		templates:  templates,
+		scorer:     scorer,
+		formSecret: []byte(formSecret),
+		adminEmail: strings.TrimSpace(adminEmailFromConfig),
+	}
+}
+
+func (s *Service) NewFormToken() string {
+	return s.newFormToken(time.Now())
+}
+
+func (s *Service) Submit(ctx context.Context, data models.ContactFormData, clientIP string) (*models.ContactMessage, error) {
+	data.Name = strings.TrimSpace(data.Name)
+	data.Email = strings.TrimSpace(data.Email)
+	data.Subject = strings.TrimSpace(data.Subject)
+	sender := models.ContactSender{IPAddress: canonicalIP(clientIP)}
+	sender.Network = ipNetwork(sender.IPAddress)
+
+	byNetwork, byEmail, err := s.repo.CountRecent(ctx, sender.Network, data.Email, time.Now().Add(-contactRateWindow))
+	if err != nil {
+		return nil, fmt.Errorf("service.Submit: %w", err)
+	}
+	if byNetwork >= contactRateLimitPerIP || byEmail >= contactRateLimitPerEmail {
+		return nil, models.ErrContactRateLimited
+	}
+
+	var spamReason string
+	sender.FormNonce, spamReason = s.checkFormToken(data.FormToken, time.Now())
+	if spamReason == "" {
+		spamReason = s.spamReason(ctx, data)
+	}
+	status := models.ContactStatusNew
+	if spamReason != "" {
+		status = models.ContactStatusSpam
+	}
+	created, err := s.repo.Create(ctx, data, sender, status, spamReason)
+	if errors.Is(err, models.ErrConflict) {
+		// The form token was replayed; keep the message like any other spam
+		sender.FormNonce, status, spamReason = "", models.ContactStatusSpam, "form token already used"
+		created, err = s.repo.Create(ctx, data, sender, status, spamReason)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service.Submit: %w", err)
+	}
+	if status == models.ContactStatusSpam {
+		log.Printf("INFO: service.Submit: contact message %d flagged as spam: %s", created.ID, spamReason)
+		return created, nil
+	}
+	if err := s.notifyAdmin(ctx, created); err != nil {
+		log.Printf("ERROR: service.Submit: notification for contact message %d not queued: %v", created.ID, err)
+	}
+	return created, nil
+}
+
+// canonicalIP gives every spelling of an address one rate limit bucket, e.g. "::ffff:203.0.113.7" is
+// "203.0.113.7". Anything that is not an IP is kept as is.
+func canonicalIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	return addr.Unmap().WithZone("").String()
+}
+
+// ipNetwork is the rate limit key for a canonical IP: IPv4 addresses as they are, IPv6 addresses as their
+// /64, since a single host is usually handed a whole /64 and could otherwise rotate through it.
+func ipNetwork(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Is6() {
+		return ip
+	}
+	return netip.PrefixFrom(addr, 64).Masked().String()
+}
+
+// spamReason runs the honeypot and content checks and returns why the submission looks like spam,
+// or "" if it does not. The form token is checked separately by checkFormToken.
+func (s *Service) spamReason(ctx context.Context, data models.ContactFormData) string {
+	if strings.TrimSpace(data.Website) != "" {
+		return "honeypot field filled in"
+	}
+	if s.scorer == nil {
+		return ""
+	}
+	score, err := s.scorer.Score(ctx, data)
+	if err != nil {
+		// A scorer outage must not lose real messages; the other checks still apply.
+		log.Printf("ERROR: service.Submit: spam scoring failed: %v", err)
+		return ""
+	}
+	if score.Score >= SpamThreshold {
+		return fmt.Sprintf("score %.1f: %s", score.Score, strings.Join(score.Reasons, ", "))
+	}
+	return ""
+}
+
+// notifyAdmin queues the contact_form email to Config.AdminEmail.
+func (s *Service) notifyAdmin(ctx context.Context, m *models.ContactMessage) error {
+	if s.adminEmail == "" {
+		log.Printf("WARN: service.Submit: ADMIN_EMAIL is not set, contact message %d is only in the inbox", m.ID)
+		return nil
+	}
+	msg, err := s.templates.Message(email.TemplateContactForm, email.DefaultLocale, email.TemplateData{
+		"Name": m.Name, "Email": m.Email, "Subject": m.Subject, "Message": m.Message,
+	}, s.adminEmail)
+	if err != nil {
+		return err
+	}
+	msg.ReplyTo = m.Email // Admins can also answer straight from their mail client
+	return s.emailSvc.Send(ctx, msg)
+}
+
+func (s *Service) ListMessages(ctx context.Context, filter models.ContactMessageFilter) ([]models.ContactMessage, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	messages, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListMessages: %w", err)
+	}
+	return messages, total, nil
+}
+
+func (s *Service) GetMessage(ctx context.Context, id int64) (*models.ContactMessage, error) {
+	m, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetMessage: %w", err)
+	}
+	if m.Replies, err = s.repo.ListReplies(ctx, id); err != nil {
+		return nil, fmt.Errorf("service.GetMessage: %w", err)
+	}
+	return m, nil
+}
+
+func (s *Service) UpdateStatus(ctx context.Context, id int64, status string) (*models.ContactMessage, error) {
+	updated, err := s.repo.UpdateStatus(ctx, id, status)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateStatus: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) Reply(ctx context.Context, id int64, adminID string, data models.ContactReplyData) (*models.ContactReply, error) {
+	original, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.Reply: %w", err)
+	}
+
+	subject := strings.TrimSpace(data.Subject)
+	if subject == "" {
+		subject = "Re: " + original.Subject
+	}
+	msg, err := s.templates.Message(email.TemplateContactReply, data.Locale, email.TemplateData{
+		"Name": original.Name, "Subject": subject, "Reply": data.Body, "OriginalMessage": original.Message,
+	}, original.Email)
+	if err != nil {
+		return nil, fmt.Errorf("service.Reply: %w", err)
+	}
+	if s.adminEmail != "" {
+		msg.ReplyTo = s.adminEmail // Follow-ups reach the inbox owner rather than the no-reply sender
+	}
+
+	reply, err := s.repo.CreateReply(ctx, id, adminID, subject, data.Body, msg)
+	if err != nil {
+		return nil, fmt.Errorf("service.Reply: %w", err)
+	}
+	return reply, nil
+}
@@ -0,0 +1,78 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/internal/models"
+)
+
+// limitedRepository reports every sender as over the limit and records the network it was asked about.
+type limitedRepository struct {
+	RepositoryInterface
+	countedNetworks []string
+}
+
+func (r *limitedRepository) CountRecent(ctx context.Context, network, email string, since time.Time) (int, int, error) {
+	r.countedNetworks = append(r.countedNetworks, network)
+	return contactRateLimitPerIP, 0, nil
+}
+
+func TestServiceSubmitCountsCanonicalNetwork(t *testing.T) {
+	repo := &limitedRepository{}
+	svc := NewService(repo, nil, nil, nil, "secret", "")
+	data := models.ContactFormData{Name: "Li", Email: "li@example.com", Subject: "Visit", Message: "When is the kiln open?"}
+
+	ips := []string{"203.0.113.7", "::ffff:203.0.113.7", "2001:db8::1", "2001:0db8:0:0::1", "2001:db8::ffff:2"}
+	for _, ip := range ips {
+		if _, err := svc.Submit(context.Background(), data, ip); !errors.Is(err, models.ErrContactRateLimited) {
+			t.Fatalf("Submit from %s: error = %v, want ErrContactRateLimited", ip, err)
+		}
+	}
+	want := []string{"203.0.113.7", "203.0.113.7", "2001:db8::/64", "2001:db8::/64", "2001:db8::/64"}
+	for i, network := range repo.countedNetworks {
+		if network != want[i] {
+			t.Errorf("counted network %d = %q, want %q", i, network, want[i])
+		}
+	}
+}
+
+func TestServiceSubmitFormToken(t *testing.T) {
+	repo := NewMemoryRepository()
+	svc := NewService(repo, nil, nil, nil, "secret", "").(*Service)
+	other := NewService(repo, nil, nil, nil, "other secret", "").(*Service)
+	now := time.Now()
+	token := svc.newFormToken(now.Add(-time.Minute))
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus string
+		wantReason string
+	}{
+		{"valid", token, models.ContactStatusNew, ""},
+		{"replayed", token, models.ContactStatusSpam, "form token already used"},
+		{"missing", "", models.ContactStatusSpam, "missing or forged form token"},
+		{"other secret", other.newFormToken(now.Add(-time.Minute)), models.ContactStatusSpam, "missing or forged form token"},
+		{"expired", svc.newFormToken(now.Add(-maxFormAge - time.Minute)), models.ContactStatusSpam, "form token expired"},
+		{"fresh valid", svc.newFormToken(now.Add(-time.Minute)), models.ContactStatusNew, ""},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A sender of its own per case keeps the rate limits out of the way
+			data := models.ContactFormData{
+				Name: "Li", Email: fmt.Sprintf("li%d@example.com", i), Subject: "Visit", Message: "When is the kiln open?", FormToken: tt.token,
+			}
+			created, err := svc.Submit(context.Background(), data, fmt.Sprintf("203.0.113.%d", i+1))
+			if err != nil {
+				t.Fatalf("Submit: %v", err)
+			}
+			if created.Status != tt.wantStatus || created.SpamReason != tt.wantReason {
+				t.Errorf("Submit = status %q, reason %q; want %q, %q", created.Status, created.SpamReason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package contact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SpamThreshold is the score from which a submission is flagged as spam.
+const SpamThreshold = 1.0
+
+const (
+	// People need at least minFillTime between loading the form and submitting it; bots post immediately.
+	minFillTime = 3 * time.Second
+	maxFormAge  = 24 * time.Hour
+)
+
+// SpamScore is a Scorer's verdict. Reasons are stored with flagged messages for admins, never shown to the sender.
+type SpamScore struct {
+	Score   float64
+	Reasons []string
+}
+
+// Scorer rates how likely a submission is spam; SpamThreshold and above is flagged.
+// Implementations may call out to an external service; errors let the submission through.
+type Scorer interface {
+	Score(ctx context.Context, data models.ContactFormData) (SpamScore, error)
+}
+
+// DefaultSpamKeywords are matched case-insensitively by the KeywordScorer.
+var DefaultSpamKeywords = []string{
+	"casino", "viagra", "cialis", "crypto", "bitcoin", "forex", "seo service", "backlinks",
+	"web traffic", "whatsapp", "telegram", "博彩", "贷款", "代开发票", "刷单",
+}
+
+// DefaultMaxLinks is how many links a genuine message may contain before the KeywordScorer counts them.
+const DefaultMaxLinks = 2
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// KeywordScorer is the local Scorer: each keyword found adds 0.5, each link beyond maxLinks adds 0.5,
+// and any link in the name or subject counts as 1 since people do not put URLs there.
+type KeywordScorer struct {
+	keywords []string
+	maxLinks int
+}
+
+// NewKeywordScorer creates a KeywordScorer. Keywords are matched case-insensitively.
+func NewKeywordScorer(keywords []string, maxLinks int) *KeywordScorer {
+	lowered := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			lowered = append(lowered, keyword)
+		}
+	}
+	return &KeywordScorer{keywords: lowered, maxLinks: maxLinks}
+}
+
+func (k *KeywordScorer) Score(_ context.Context, data models.ContactFormData) (SpamScore, error) {
+	var result SpamScore
+	text := strings.ToLower(data.Name + "\n" + data.Subject + "\n" + data.Message)
+	for _, keyword := range k.keywords {
+		if strings.Contains(text, keyword) {
+			result.Score += 0.5
+			result.Reasons = append(result.Reasons, fmt.Sprintf("keyword %q", keyword))
+		}
+	}
+	if links := len(linkPattern.FindAllString(data.Message, -1)); links > k.maxLinks {
+		result.Score += 0.5 * float64(links-k.maxLinks)
+		result.Reasons = append(result.Reasons, fmt.Sprintf("%d links", links))
+	}
+	if linkPattern.MatchString(data.Name) || linkPattern.MatchString(data.Subject) {
+		result.Score += 1
+		result.Reasons = append(result.Reasons, "link in name or subject")
+	}
+	return result, nil
+}
+
+// formNonceBytes is the random part of a form token; its base64 form fits contact_messages.form_nonce.
+const formNonceBytes = 16
+
+// newFormToken returns "<unix seconds>.<nonce>.<signature>" so the submission can prove when the form was
+// loaded, and the nonce lets each token be accepted only once.
+func (s *Service) newFormToken(now time.Time) string {
+	nonce := make([]byte, formNonceBytes)
+	_, _ = rand.Read(nonce) // crypto/rand.Read never returns an error on supported platforms
+	payload := strconv.FormatInt(now.Unix(), 10) + "." + base64.RawURLEncoding.EncodeToString(nonce)
+	return payload + "." + s.signFormToken(payload)
+}
+
+// checkFormToken returns the token's nonce, or why the token is unacceptable. Whether the nonce was
+// already used is up to Repository.Create.
+func (s *Service) checkFormToken(token string, now time.Time) (nonce, reason string) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[2]), []byte(s.signFormToken(parts[0]+"."+parts[1]))) {
+		return "", "missing or forged form token"
+	}
+	unix, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", "missing or forged form token"
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age < minFillTime {
+		return "", fmt.Sprintf("submitted %s after the form was loaded", age.Round(time.Millisecond))
+	}
+	if age > maxFormAge {
+		return "", "form token expired"
+	}
+	return parts[1], ""
+}
+
+func (s *Service) signFormToken(payload string) string {
+	mac := hmac.New(sha256.New, s.formSecret)
+	mac.Write([]byte("contact_form:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,401 @@
+package course
+
+import (
+	"errors"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler handles HTTP requests for courses.
+type Handler struct {
+	service  ServiceInterface
+	validate *validator.Validate
+}
+
+// NewHandler creates a new course handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// parseChapterRoute reads the :course_id and :chapter_id path params.
+func parseChapterRoute(c echo.Context) (courseID, chapterID int64, err error) {
+	courseID, err = strconv.ParseInt(c.Param("course_id"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("Invalid course ID")
+	}
+	chapterID, err = strconv.ParseInt(c.Param("chapter_id"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("Invalid chapter ID")
+	}
+	return courseID, chapterID, nil
+}
+
+// --- Public Routes ---
+
+func (h *Handler) GetAllCourses(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	courses, total, err := h.service.ListCourses(c.Request().Context(), page, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(courses, page, limit, total))
+}
+
+// GetCourseDetails returns the course and its chapter list. Enrollment and progress are
+// included when a valid JWT was sent.
+func (h *Handler) GetCourseDetails(c echo.Context) error {
+	courseID, err := strconv.ParseInt(c.Param("course_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid course ID")
+	}
+	viewerID, _ := utils.GetUserIDFromContext(c) // Empty for guests
+
+	course, err := h.service.GetCourseDetails(c.Request().Context(), courseID, viewerID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, course)
+}
+
+// GetChapterContent is public for the first models.FreePreviewChapterCount chapters.
+// Later chapters require an enrolled, logged-in viewer.
+func (h *Handler) GetChapterContent(c echo.Context) error {
+	courseID, chapterID, err := parseChapterRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+	viewerID, _ := utils.GetUserIDFromContext(c)
+
+	chapter, err := h.service.GetChapterContent(c.Request().Context(), courseID, chapterID, viewerID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, chapter)
+}
+
+// --- Protected Routes ---
+
+func (h *Handler) EnrollCourse(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	courseID, err := strconv.ParseInt(c.Param("course_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid course ID")
+	}
+
+	enrollment, err := h.service.EnrollCourse(c.Request().Context(), userID, courseID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, enrollment)
+}
+
+func (h *Handler) GetFullChapterContentForEnrolled(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	courseID, chapterID, err := parseChapterRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+
+	chapter, err := h.service.GetFullChapterContent(c.Request().Context(), userID, courseID, chapterID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, chapter)
+}
+
+func (h *Handler) UpdateProgress(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	courseID, chapterID, err := parseChapterRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+
+	var req models.UpdateChapterProgressData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	progress, err := h.service.UpdateProgress(c.Request().Context(), userID, courseID, chapterID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, progress)
+}
+
+func (h *Handler) AddNoteToChapter(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	courseID, chapterID, err := parseChapterRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+
+	var req models.AddEntityNoteData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	note, err := h.service.AddNoteToChapter(c.Request().Context(), userID, courseID, chapterID, req.Title, req.Content)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, note)
+}
+
+// parseQuizRoute reads the :quiz_id path param on top of parseChapterRoute.
+func parseQuizRoute(c echo.Context) (courseID, chapterID, quizID int64, err error) {
+	courseID, chapterID, err = parseChapterRoute(c)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	quizID, err = strconv.ParseInt(c.Param("quiz_id"), 10, 64)
+	if err != nil {
+		return 0, 0, 0, errors.New("Invalid quiz ID")
+	}
+	return courseID, chapterID, quizID, nil
+}
+
+// GetQuiz returns the quiz questions without the correct answers.
+func (h *Handler) GetQuiz(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	courseID, chapterID, quizID, err := parseQuizRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+
+	quiz, err := h.service.GetQuiz(c.Request().Context(), userID, courseID, chapterID, quizID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, quiz)
+}
+
+// SubmitQuiz grades the submitted answers and records the attempt.
+func (h *Handler) SubmitQuiz(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	courseID, chapterID, quizID, err := parseQuizRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+
+	var req models.SubmitQuizData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	attempt, err := h.service.SubmitQuiz(c.Request().Context(), userID, courseID, chapterID, quizID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, attempt)
+}
+
+// GetQuizAttempts lists the caller's previous attempts, newest first.
+func (h *Handler) GetQuizAttempts(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	courseID, chapterID, quizID, err := parseQuizRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+	page, limit := utils.GetPageLimit(c)
+
+	attempts, total, err := h.service.ListQuizAttempts(c.Request().Context(), userID, courseID, chapterID, quizID, page, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(attempts, page, limit, total))
+}
+
+// --- Admin Handlers ---
+// Routed under /admin/courses, behind JWTMAuth and RequirePermission(course.author).
+
+func (h *Handler) CreateCourse(c echo.Context) error {
+	authorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	var req models.CreateCourseData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	course, err := h.service.CreateCourse(c.Request().Context(), authorID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, course)
+}
+
+func (h *Handler) UpdateCourse(c echo.Context) error {
+	courseID, err := strconv.ParseInt(c.Param("course_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid course ID")
+	}
+	var req models.UpdateCourseData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	course, err := h.service.UpdateCourse(c.Request().Context(), courseID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, course)
+}
+
+func (h *Handler) DeleteCourse(c echo.Context) error {
+	courseID, err := strconv.ParseInt(c.Param("course_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid course ID")
+	}
+	if err := h.service.DeleteCourse(c.Request().Context(), courseID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) CreateChapter(c echo.Context) error {
+	courseID, err := strconv.ParseInt(c.Param("course_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid course ID")
+	}
+	var req models.CreateChapterData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	chapter, err := h.service.CreateChapter(c.Request().Context(), courseID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, chapter)
+}
+
+func (h *Handler) UpdateChapter(c echo.Context) error {
+	courseID, chapterID, err := parseChapterRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+	var req models.UpdateChapterData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	chapter, err := h.service.UpdateChapter(c.Request().Context(), courseID, chapterID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, chapter)
+}
+
+func (h *Handler) DeleteChapter(c echo.Context) error {
+	courseID, chapterID, err := parseChapterRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+	if err := h.service.DeleteChapter(c.Request().Context(), courseID, chapterID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateQuiz adds a quiz to a chapter. The response includes the answers.
+func (h *Handler) CreateQuiz(c echo.Context) error {
+	courseID, chapterID, err := parseChapterRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+	var req models.CreateQuizData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	quiz, err := h.service.CreateQuiz(c.Request().Context(), courseID, chapterID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, quiz)
+}
+
+func (h *Handler) UpdateQuiz(c echo.Context) error {
+	courseID, chapterID, quizID, err := parseQuizRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+	var req models.UpdateQuizData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	quiz, err := h.service.UpdateQuiz(c.Request().Context(), courseID, chapterID, quizID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, quiz)
+}
+
+func (h *Handler) DeleteQuiz(c echo.Context) error {
+	courseID, chapterID, quizID, err := parseQuizRoute(c)
+	if err != nil {
+		return models.BadRequestError(err.Error())
+	}
+	if err := h.service.DeleteQuiz(c.Request().Context(), courseID, chapterID, quizID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
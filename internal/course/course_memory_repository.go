@@ -0,0 +1,710 @@
+package course
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository. Instructors and students, which the foreign keys require, are registered with AddUser.
+type MemoryRepository struct {
+	mu          sync.Mutex
+	courses     map[int64]*models.Course
+	chapters    map[int64]*models.CourseChapter
+	quizzes     map[int64]*models.ChapterQuiz
+	enrollments map[enrollmentKey]*models.CourseEnrollment
+	progress    map[progressKey]*models.ChapterProgress
+	attempts    []models.QuizAttempt
+	nicknames   map[string]string
+	lastCourse  int64
+	lastChapter int64
+	lastQuiz    int64
+	lastAttempt int64
+}
+
+type enrollmentKey struct {
+	userID   string
+	courseID int64
+}
+
+type progressKey struct {
+	userID    string
+	chapterID int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory course repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		courses:     map[int64]*models.Course{},
+		chapters:    map[int64]*models.CourseChapter{},
+		quizzes:     map[int64]*models.ChapterQuiz{},
+		enrollments: map[enrollmentKey]*models.CourseEnrollment{},
+		progress:    map[progressKey]*models.ChapterProgress{},
+		nicknames:   map[string]string{},
+	}
+}
+
+// AddUser registers userID with the nickname shown for instructors and in the student progress report.
+func (r *MemoryRepository) AddUser(userID, nickname string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nicknames[userID] = nickname
+}
+
+func (r *MemoryRepository) userExists(userID string) bool {
+	_, ok := r.nicknames[userID]
+	return ok
+}
+
+// roundTrip copies in to out through JSON, like a value stored in a JSONB column and read back.
+func roundTrip(in, out any) {
+	data, _ := json.Marshal(in) // The quiz and attempt types always marshal
+	_ = json.Unmarshal(data, out)
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func (r *MemoryRepository) viewCourse(c *models.Course) *models.Course {
+	out := *c
+	out.InstructorNickname = ""
+	if c.InstructorID != nil {
+		id := *c.InstructorID
+		out.InstructorID = &id
+		out.InstructorNickname = r.nicknames[id]
+	}
+	out.ChapterCount = len(r.courseChapters(c.ID))
+	out.EnrollmentCount = 0
+	for key := range r.enrollments {
+		if key.courseID == c.ID {
+			out.EnrollmentCount++
+		}
+	}
+	return &out
+}
+
+// courseChapters returns the chapters of the course numbered as chapterSelect does.
+func (r *MemoryRepository) courseChapters(courseID int64) []models.CourseChapter {
+	chapters := []models.CourseChapter{}
+	for _, ch := range r.chapters {
+		if ch.CourseID == courseID {
+			chapters = append(chapters, *ch)
+		}
+	}
+	sort.Slice(chapters, func(i, j int) bool {
+		if chapters[i].DisplayOrder != chapters[j].DisplayOrder {
+			return chapters[i].DisplayOrder < chapters[j].DisplayOrder
+		}
+		return chapters[i].ID < chapters[j].ID
+	})
+	for i := range chapters {
+		chapters[i].Position = i + 1
+		chapters[i].IsFreePreview = chapters[i].Position <= models.FreePreviewChapterCount
+		if d := chapters[i].VideoDuration; d != nil {
+			duration := *d
+			chapters[i].VideoDuration = &duration
+		}
+	}
+	return chapters
+}
+
+func (r *MemoryRepository) displayOrderTaken(courseID int64, displayOrder int, exceptID int64) bool {
+	for _, ch := range r.chapters {
+		if ch.CourseID == courseID && ch.DisplayOrder == displayOrder && ch.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Courses and Chapters ---
+
+func (r *MemoryRepository) ListCourses(ctx context.Context, page, limit int) ([]models.Course, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sorted := make([]*models.Course, 0, len(r.courses))
+	for _, c := range r.courses {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+
+	courses := []models.Course{}
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	for i := offset; i < len(sorted) && i < offset+limit; i++ {
+		courses = append(courses, *r.viewCourse(sorted[i]))
+	}
+	return courses, len(sorted), nil
+}
+
+func (r *MemoryRepository) FindCourseByID(ctx context.Context, courseID int64) (*models.Course, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.courses[courseID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.viewCourse(c), nil
+}
+
+func (r *MemoryRepository) ListChapters(ctx context.Context, courseID int64) ([]models.CourseChapter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chapters := r.courseChapters(courseID)
+	for i := range chapters {
+		chapters[i].Content = ""
+		chapters[i].VideoURL = ""
+	}
+	return chapters, nil
+}
+
+func (r *MemoryRepository) FindChapter(ctx context.Context, courseID, chapterID int64) (*models.CourseChapter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.findChapter(courseID, chapterID)
+}
+
+func (r *MemoryRepository) findChapter(courseID, chapterID int64) (*models.CourseChapter, error) {
+	for _, ch := range r.courseChapters(courseID) {
+		if ch.ID == chapterID {
+			return &ch, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+// --- Authoring ---
+
+func (r *MemoryRepository) CreateCourse(ctx context.Context, instructorID string, data models.CreateCourseData) (*models.Course, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.userExists(instructorID) {
+		return nil, fmt.Errorf("repository.CreateCourse: user %q does not exist", instructorID) // The users foreign key
+	}
+	r.lastCourse++
+	now := time.Now()
+	c := &models.Course{
+		ID: r.lastCourse, Title: data.Title, Description: data.Description, InstructorID: &instructorID,
+		ThumbnailURL: data.ThumbnailURL, CreatedAt: now, UpdatedAt: now,
+	}
+	r.courses[c.ID] = c
+	return r.viewCourse(c), nil
+}
+
+func (r *MemoryRepository) UpdateCourse(ctx context.Context, courseID int64, data models.UpdateCourseData) (*models.Course, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.courses[courseID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if data.Title != nil {
+		c.Title = *data.Title
+	}
+	if data.Description != nil {
+		c.Description = *data.Description
+	}
+	if data.ThumbnailURL != nil {
+		c.ThumbnailURL = *data.ThumbnailURL
+	}
+	c.UpdatedAt = time.Now()
+	return r.viewCourse(c), nil
+}
+
+func (r *MemoryRepository) DeleteCourse(ctx context.Context, courseID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.courses[courseID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.courses, courseID)
+	for id, ch := range r.chapters {
+		if ch.CourseID == courseID {
+			r.deleteChapter(id)
+		}
+	}
+	for key := range r.enrollments {
+		if key.courseID == courseID {
+			delete(r.enrollments, key)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) CreateChapter(ctx context.Context, courseID int64, data models.CreateChapterData) (*models.CourseChapter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.courses[courseID]; !ok {
+		return nil, fmt.Errorf("repository.CreateChapter: course %d does not exist", courseID) // The courses foreign key
+	}
+	if r.displayOrderTaken(courseID, data.DisplayOrder, 0) {
+		return nil, models.ErrConflict
+	}
+	r.lastChapter++
+	now := time.Now()
+	ch := &models.CourseChapter{
+		ID: r.lastChapter, CourseID: courseID, Title: data.Title, DisplayOrder: data.DisplayOrder,
+		BackgroundColor: data.BackgroundColor, VideoURL: data.VideoURL, Content: data.Content,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if data.VideoDuration != nil {
+		duration := *data.VideoDuration
+		ch.VideoDuration = &duration
+	}
+	r.chapters[ch.ID] = ch
+	return r.findChapter(courseID, ch.ID)
+}
+
+func (r *MemoryRepository) UpdateChapter(ctx context.Context, courseID, chapterID int64, data models.UpdateChapterData) (*models.CourseChapter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch, ok := r.chapters[chapterID]
+	if !ok || ch.CourseID != courseID {
+		return nil, models.ErrNotFound
+	}
+	if data.DisplayOrder != nil && r.displayOrderTaken(courseID, *data.DisplayOrder, chapterID) {
+		return nil, models.ErrConflict
+	}
+	if data.Title != nil {
+		ch.Title = *data.Title
+	}
+	if data.DisplayOrder != nil {
+		ch.DisplayOrder = *data.DisplayOrder
+	}
+	if data.BackgroundColor != nil {
+		ch.BackgroundColor = *data.BackgroundColor
+	}
+	if data.VideoURL != nil {
+		ch.VideoURL = *data.VideoURL
+	}
+	if data.VideoDuration != nil {
+		duration := *data.VideoDuration
+		ch.VideoDuration = &duration
+	}
+	if data.Content != nil {
+		ch.Content = *data.Content
+	}
+	ch.UpdatedAt = time.Now()
+	return r.findChapter(courseID, chapterID)
+}
+
+func (r *MemoryRepository) DeleteChapter(ctx context.Context, courseID, chapterID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch, ok := r.chapters[chapterID]
+	if !ok || ch.CourseID != courseID {
+		return models.ErrNotFound
+	}
+	r.deleteChapter(chapterID)
+	return nil
+}
+
+// deleteChapter removes the chapter with its quizzes and progress. Attempts are kept, as in Postgres.
+func (r *MemoryRepository) deleteChapter(chapterID int64) {
+	delete(r.chapters, chapterID)
+	for id, q := range r.quizzes {
+		if q.ChapterID == chapterID {
+			delete(r.quizzes, id)
+		}
+	}
+	for key := range r.progress {
+		if key.chapterID == chapterID {
+			delete(r.progress, key)
+		}
+	}
+}
+
+// --- Enrollment ---
+
+func (r *MemoryRepository) IsEnrolled(ctx context.Context, userID string, courseID int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.enrollments[enrollmentKey{userID, courseID}]
+	return ok, nil
+}
+
+func (r *MemoryRepository) CreateEnrollment(ctx context.Context, userID string, courseID int64) (*models.CourseEnrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := enrollmentKey{userID, courseID}
+	if _, ok := r.enrollments[key]; ok { // Already enrolled
+		return nil, models.ErrConflict
+	}
+	if _, ok := r.courses[courseID]; !ok || !r.userExists(userID) {
+		return nil, fmt.Errorf("repository.CreateEnrollment: user %q or course %d does not exist", userID, courseID)
+	}
+	enrollment := &models.CourseEnrollment{UserID: userID, CourseID: courseID, EnrolledAt: time.Now()}
+	r.enrollments[key] = enrollment
+	out := *enrollment
+	return &out, nil
+}
+
+func (r *MemoryRepository) MarkEnrollmentCompletedIfDone(ctx context.Context, userID string, courseID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	enrollment, ok := r.enrollments[enrollmentKey{userID, courseID}]
+	if !ok || enrollment.CompletedAt != nil {
+		return nil
+	}
+	for _, ch := range r.chapters {
+		if p := r.progress[progressKey{userID, ch.ID}]; ch.CourseID == courseID && (p == nil || p.CompletedAt == nil) {
+			return nil
+		}
+	}
+	now := time.Now()
+	enrollment.CompletedAt = &now
+	return nil
+}
+
+// --- Quizzes ---
+
+func (r *MemoryRepository) viewQuiz(q *models.ChapterQuiz) *models.ChapterQuiz {
+	out := *q
+	out.Questions = nil
+	roundTrip(q.Questions, &out.Questions)
+	if out.Questions == nil {
+		out.Questions = []models.QuizQuestion{}
+	}
+	return &out
+}
+
+// validThreshold mirrors the NOT NULL and CHECK constraints on pass_threshold.
+func validThreshold(threshold *int) bool {
+	return threshold != nil && *threshold >= 0 && *threshold <= 100
+}
+
+func (r *MemoryRepository) ListChapterQuizzes(ctx context.Context, chapterID int64) ([]models.ChapterQuiz, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []*models.ChapterQuiz{}
+	for _, q := range r.quizzes {
+		if q.ChapterID == chapterID {
+			matched = append(matched, q)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].DisplayOrder != matched[j].DisplayOrder {
+			return matched[i].DisplayOrder < matched[j].DisplayOrder
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	quizzes := []models.ChapterQuiz{}
+	for _, q := range matched {
+		quizzes = append(quizzes, *r.viewQuiz(q))
+	}
+	return quizzes, nil
+}
+
+func (r *MemoryRepository) FindQuiz(ctx context.Context, chapterID, quizID int64) (*models.ChapterQuiz, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q, ok := r.quizzes[quizID]
+	if !ok || q.ChapterID != chapterID {
+		return nil, models.ErrNotFound
+	}
+	return r.viewQuiz(q), nil
+}
+
+func (r *MemoryRepository) CreateQuiz(ctx context.Context, chapterID int64, data models.CreateQuizData) (*models.ChapterQuiz, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.chapters[chapterID]; !ok {
+		return nil, fmt.Errorf("repository.CreateQuiz: chapter %d does not exist", chapterID) // The chapters foreign key
+	}
+	if !validThreshold(data.PassThreshold) {
+		return nil, fmt.Errorf("repository.CreateQuiz: invalid pass threshold %v", data.PassThreshold)
+	}
+	r.lastQuiz++
+	q := &models.ChapterQuiz{
+		ID: r.lastQuiz, ChapterID: chapterID, Title: data.Title, PassThreshold: *data.PassThreshold,
+		MarksChapterComplete: data.MarksChapterComplete, DisplayOrder: data.DisplayOrder,
+	}
+	roundTrip(data.Questions, &q.Questions)
+	r.quizzes[q.ID] = q
+	return r.viewQuiz(q), nil
+}
+
+func (r *MemoryRepository) UpdateQuiz(ctx context.Context, chapterID, quizID int64, data models.UpdateQuizData) (*models.ChapterQuiz, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q, ok := r.quizzes[quizID]
+	if !ok || q.ChapterID != chapterID {
+		return nil, models.ErrNotFound
+	}
+	if data.PassThreshold != nil && !validThreshold(data.PassThreshold) {
+		return nil, fmt.Errorf("repository.UpdateQuiz: invalid pass threshold %d", *data.PassThreshold)
+	}
+	if data.Title != nil {
+		q.Title = *data.Title
+	}
+	if data.Questions != nil {
+		q.Questions = nil
+		roundTrip(data.Questions, &q.Questions)
+	}
+	if data.PassThreshold != nil {
+		q.PassThreshold = *data.PassThreshold
+	}
+	if data.MarksChapterComplete != nil {
+		q.MarksChapterComplete = *data.MarksChapterComplete
+	}
+	if data.DisplayOrder != nil {
+		q.DisplayOrder = *data.DisplayOrder
+	}
+	return r.viewQuiz(q), nil
+}
+
+func (r *MemoryRepository) DeleteQuiz(ctx context.Context, chapterID, quizID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q, ok := r.quizzes[quizID]
+	if !ok || q.ChapterID != chapterID {
+		return models.ErrNotFound
+	}
+	delete(r.quizzes, quizID)
+	return nil
+}
+
+// copyAttempt returns the attempt as ListQuizAttempts reads it back from attempt_data.
+func copyAttempt(a models.QuizAttempt) models.QuizAttempt {
+	out := a
+	out.Answers, out.Results = nil, nil
+	roundTrip(a.Answers, &out.Answers)
+	roundTrip(a.Results, &out.Results)
+	out.ChapterCompleted = false
+	return out
+}
+
+func (r *MemoryRepository) CreateQuizAttempt(ctx context.Context, attempt *models.QuizAttempt) (*models.QuizAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.userExists(attempt.UserID) {
+		return nil, fmt.Errorf("repository.CreateQuizAttempt: user %q does not exist", attempt.UserID)
+	}
+	r.lastAttempt++
+	attempt.ID = r.lastAttempt
+	attempt.AttemptedAt = time.Now()
+	r.attempts = append(r.attempts, copyAttempt(*attempt))
+	return attempt, nil
+}
+
+func (r *MemoryRepository) ListQuizAttempts(ctx context.Context, userID string, quizID int64, page, limit int) ([]models.QuizAttempt, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []models.QuizAttempt{}
+	for _, a := range r.attempts {
+		if a.UserID == userID && a.QuizID == quizID {
+			matched = append(matched, a)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].AttemptedAt.Equal(matched[j].AttemptedAt) {
+			return matched[i].AttemptedAt.After(matched[j].AttemptedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	attempts := []models.QuizAttempt{}
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		attempts = append(attempts, copyAttempt(matched[i]))
+	}
+	return attempts, len(matched), nil
+}
+
+// --- Progress ---
+
+func copyProgress(p *models.ChapterProgress) *models.ChapterProgress {
+	out := *p
+	out.CompletedAt = copyTime(p.CompletedAt)
+	return &out
+}
+
+func (r *MemoryRepository) ListProgressForCourse(ctx context.Context, userID string, courseID int64) ([]models.ChapterProgress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	progressList := []models.ChapterProgress{}
+	for key, p := range r.progress {
+		if key.userID == userID && r.chapters[key.chapterID].CourseID == courseID {
+			progressList = append(progressList, *copyProgress(p))
+		}
+	}
+	sort.Slice(progressList, func(i, j int) bool { return progressList[i].ChapterID < progressList[j].ChapterID })
+	return progressList, nil
+}
+
+func (r *MemoryRepository) GetChapterProgress(ctx context.Context, userID string, chapterID int64) (*models.ChapterProgress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.progress[progressKey{userID, chapterID}]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return copyProgress(p), nil
+}
+
+// upsertProgress returns the stored progress of the user in the chapter, creating it if the foreign keys allow.
+func (r *MemoryRepository) upsertProgress(userID string, chapterID int64) (*models.ChapterProgress, error) {
+	key := progressKey{userID, chapterID}
+	if p, ok := r.progress[key]; ok {
+		return p, nil
+	}
+	if _, ok := r.chapters[chapterID]; !ok || !r.userExists(userID) {
+		return nil, fmt.Errorf("user %q or chapter %d does not exist", userID, chapterID)
+	}
+	p := &models.ChapterProgress{UserID: userID, ChapterID: chapterID}
+	r.progress[key] = p
+	return p, nil
+}
+
+func (r *MemoryRepository) UpsertChapterProgress(ctx context.Context, userID string, chapterID int64, data models.UpdateChapterProgressData) (*models.ChapterProgress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if data.ProgressPercentage < 0 || data.ProgressPercentage > 100 {
+		return nil, fmt.Errorf("repository.UpsertChapterProgress: invalid progress %d", data.ProgressPercentage) // The CHECK constraint
+	}
+	p, err := r.upsertProgress(userID, chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpsertChapterProgress: %w", err)
+	}
+	now := time.Now()
+	p.ProgressPercentage = max(p.ProgressPercentage, data.ProgressPercentage)
+	p.VideoLastStoppedAt = data.VideoLastStoppedAt
+	if p.CompletedAt == nil && data.ProgressPercentage >= 100 {
+		p.CompletedAt = &now
+	}
+	p.UpdatedAt = now
+	return copyProgress(p), nil
+}
+
+func (r *MemoryRepository) MarkChapterComplete(ctx context.Context, userID string, chapterID int64) (*models.ChapterProgress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, err := r.upsertProgress(userID, chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.MarkChapterComplete: %w", err)
+	}
+	now := time.Now()
+	p.ProgressPercentage = 100
+	if p.CompletedAt == nil {
+		p.CompletedAt = &now
+	}
+	p.UpdatedAt = now
+	return copyProgress(p), nil
+}
+
+// --- Admin Reporting ---
+
+func (r *MemoryRepository) ListCourseProgressSummaries(ctx context.Context) ([]models.CourseProgressSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summaries := []models.CourseProgressSummary{}
+	for _, c := range r.courses {
+		chapters := r.courseChapters(c.ID)
+		summary := models.CourseProgressSummary{CourseID: c.ID, Title: c.Title, ChapterCount: len(chapters)}
+		total := 0.0
+		for key, e := range r.enrollments {
+			if key.courseID != c.ID {
+				continue
+			}
+			summary.EnrolledCount++
+			if e.CompletedAt != nil {
+				summary.CompletedCount++
+			}
+			if len(chapters) > 0 {
+				total += float64(r.sumProgress(key.userID, chapters)) / float64(len(chapters))
+			}
+		}
+		// Without chapters every student's progress is NULL, which AVG skips
+		if summary.EnrolledCount > 0 && len(chapters) > 0 {
+			summary.AverageProgress = math.Round(total/float64(summary.EnrolledCount)*10) / 10
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].CourseID < summaries[j].CourseID })
+	return summaries, nil
+}
+
+func (r *MemoryRepository) sumProgress(userID string, chapters []models.CourseChapter) int {
+	sum := 0
+	for _, ch := range chapters {
+		if p, ok := r.progress[progressKey{userID, ch.ID}]; ok {
+			sum += p.ProgressPercentage
+		}
+	}
+	return sum
+}
+
+func (r *MemoryRepository) ListStudentProgress(ctx context.Context, courseID int64, page, limit int) ([]models.StudentCourseProgress, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chapters := r.courseChapters(courseID)
+	students := []models.StudentCourseProgress{}
+	for key, e := range r.enrollments {
+		if key.courseID != courseID {
+			continue
+		}
+		student := models.StudentCourseProgress{
+			UserID: key.userID, Nickname: r.nicknames[key.userID], EnrolledAt: e.EnrolledAt, CompletedAt: copyTime(e.CompletedAt),
+		}
+		for _, ch := range chapters {
+			p, ok := r.progress[progressKey{key.userID, ch.ID}]
+			if !ok {
+				continue
+			}
+			if p.CompletedAt != nil {
+				student.CompletedChapters++
+			}
+			if student.LastActivityAt == nil || p.UpdatedAt.After(*student.LastActivityAt) {
+				student.LastActivityAt = copyTime(&p.UpdatedAt)
+			}
+		}
+		if len(chapters) > 0 {
+			student.ProgressPercentage = r.sumProgress(key.userID, chapters) / len(chapters) // Integer division, as in SQL
+		}
+		students = append(students, student)
+	}
+	sort.Slice(students, func(i, j int) bool {
+		a, b := students[i].LastActivityAt, students[j].LastActivityAt
+		switch {
+		case a != nil && b != nil && !a.Equal(*b):
+			return a.After(*b)
+		case (a == nil) != (b == nil):
+			return b == nil // NULLS LAST
+		}
+		return students[i].EnrolledAt.After(students[j].EnrolledAt)
+	})
+
+	total := len(students)
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	paged := []models.StudentCourseProgress{}
+	for i := offset; i < total && i < offset+limit; i++ {
+		paged = append(paged, students[i])
+	}
+	return paged, total, nil
+}
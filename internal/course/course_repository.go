@@ -0,0 +1,675 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines the methods for interacting with course storage.
+type RepositoryInterface interface {
+	// Courses and chapters
+	ListCourses(ctx context.Context, page, limit int) ([]models.Course, int, error)
+	FindCourseByID(ctx context.Context, courseID int64) (*models.Course, error)
+	ListChapters(ctx context.Context, courseID int64) ([]models.CourseChapter, error)
+	FindChapter(ctx context.Context, courseID, chapterID int64) (*models.CourseChapter, error)
+
+	// Enrollment
+	IsEnrolled(ctx context.Context, userID string, courseID int64) (bool, error)
+	CreateEnrollment(ctx context.Context, userID string, courseID int64) (*models.CourseEnrollment, error)
+	MarkEnrollmentCompletedIfDone(ctx context.Context, userID string, courseID int64) error
+
+	// Quizzes
+	ListChapterQuizzes(ctx context.Context, chapterID int64) ([]models.ChapterQuiz, error)
+	FindQuiz(ctx context.Context, chapterID, quizID int64) (*models.ChapterQuiz, error)
+	CreateQuizAttempt(ctx context.Context, attempt *models.QuizAttempt) (*models.QuizAttempt, error)
+	ListQuizAttempts(ctx context.Context, userID string, quizID int64, page, limit int) ([]models.QuizAttempt, int, error)
+
+	// Progress
+	ListProgressForCourse(ctx context.Context, userID string, courseID int64) ([]models.ChapterProgress, error)
+	GetChapterProgress(ctx context.Context, userID string, chapterID int64) (*models.ChapterProgress, error)
+	UpsertChapterProgress(ctx context.Context, userID string, chapterID int64, data models.UpdateChapterProgressData) (*models.ChapterProgress, error)
+	MarkChapterComplete(ctx context.Context, userID string, chapterID int64) (*models.ChapterProgress, error)
+
+	// Authoring, see models.PermCourseAuthor. Chapters are scoped to their course and quizzes to
+	// their chapter; one that belongs elsewhere is models.ErrNotFound.
+	CreateCourse(ctx context.Context, instructorID string, data models.CreateCourseData) (*models.Course, error)
+	UpdateCourse(ctx context.Context, courseID int64, data models.UpdateCourseData) (*models.Course, error)
+	DeleteCourse(ctx context.Context, courseID int64) error
+	// CreateChapter and UpdateChapter return models.ErrConflict if display_order is taken in the course.
+	CreateChapter(ctx context.Context, courseID int64, data models.CreateChapterData) (*models.CourseChapter, error)
+	UpdateChapter(ctx context.Context, courseID, chapterID int64, data models.UpdateChapterData) (*models.CourseChapter, error)
+	DeleteChapter(ctx context.Context, courseID, chapterID int64) error
+	CreateQuiz(ctx context.Context, chapterID int64, data models.CreateQuizData) (*models.ChapterQuiz, error)
+	UpdateQuiz(ctx context.Context, chapterID, quizID int64, data models.UpdateQuizData) (*models.ChapterQuiz, error)
+	DeleteQuiz(ctx context.Context, chapterID, quizID int64) error
+
+	// Admin reporting
+	ListCourseProgressSummaries(ctx context.Context) ([]models.CourseProgressSummary, error)
+	ListStudentProgress(ctx context.Context, courseID int64, page, limit int) ([]models.StudentCourseProgress, int, error)
+}
+
+// Repository provides access to the course storage.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new course repository.
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+// --- Courses and Chapters ---
+
+const courseSelect = `
+	SELECT c.id, c.title, COALESCE(c.description, ''), c.instructor_id, COALESCE(u.nickname, ''),
+	       COALESCE(c.thumbnail_url, ''),
+	       (SELECT COUNT(*) FROM course_chapters ch WHERE ch.course_id = c.id) AS chapter_count,
+	       (SELECT COUNT(*) FROM course_enrollments e WHERE e.course_id = c.id) AS enrollment_count,
+	       c.created_at, c.updated_at
+	FROM courses c
+	LEFT JOIN users u ON u.id = c.instructor_id
+`
+
+func scanCourse(row pgx.Row) (*models.Course, error) {
+	var course models.Course
+	err := row.Scan(
+		&course.ID, &course.Title, &course.Description, &course.InstructorID, &course.InstructorNickname,
+		&course.ThumbnailURL, &course.ChapterCount, &course.EnrollmentCount,
+		&course.CreatedAt, &course.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+func (r *Repository) ListCourses(ctx context.Context, page, limit int) ([]models.Course, int, error) {
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(ctx, courseSelect+" ORDER BY c.created_at DESC, c.id DESC LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListCourses: %w", err)
+	}
+	defer rows.Close()
+
+	courses := []models.Course{}
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("repository.ListCourses.Scan: %w", err)
+		}
+		courses = append(courses, *course)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListCourses.RowsErr: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM courses").Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListCourses.Count: %w", err)
+	}
+	return courses, total, nil
+}
+
+func (r *Repository) FindCourseByID(ctx context.Context, courseID int64) (*models.Course, error) {
+	course, err := scanCourse(r.db.QueryRow(ctx, courseSelect+" WHERE c.id = $1", courseID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindCourseByID: %w", err)
+	}
+	return course, nil
+}
+
+// chapterSelect numbers chapters by display_order so the free-preview rule does not depend on
+// display_order values being contiguous.
+const chapterSelect = `
+	SELECT id, course_id, title, display_order, position, background_color, video_url, video_duration, content,
+	       created_at, updated_at
+	FROM (
+		SELECT ch.id, ch.course_id, ch.title, ch.display_order,
+		       ROW_NUMBER() OVER (PARTITION BY ch.course_id ORDER BY ch.display_order, ch.id) AS position,
+		       COALESCE(ch.background_color, '') AS background_color, COALESCE(ch.video_url, '') AS video_url,
+		       ch.video_duration, COALESCE(ch.content, '') AS content, ch.created_at, ch.updated_at
+		FROM course_chapters ch
+		WHERE ch.course_id = $1
+	) numbered
+`
+
+func scanChapter(row pgx.Row) (*models.CourseChapter, error) {
+	var chapter models.CourseChapter
+	err := row.Scan(
+		&chapter.ID, &chapter.CourseID, &chapter.Title, &chapter.DisplayOrder, &chapter.Position,
+		&chapter.BackgroundColor, &chapter.VideoURL, &chapter.VideoDuration, &chapter.Content,
+		&chapter.CreatedAt, &chapter.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	chapter.IsFreePreview = chapter.Position <= models.FreePreviewChapterCount
+	return &chapter, nil
+}
+
+// ListChapters returns the chapter outline of a course. Content and video URLs are omitted.
+func (r *Repository) ListChapters(ctx context.Context, courseID int64) ([]models.CourseChapter, error) {
+	rows, err := r.db.Query(ctx, chapterSelect+" ORDER BY position ASC", courseID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListChapters: %w", err)
+	}
+	defer rows.Close()
+
+	chapters := []models.CourseChapter{}
+	for rows.Next() {
+		chapter, err := scanChapter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListChapters.Scan: %w", err)
+		}
+		chapter.Content = ""
+		chapter.VideoURL = ""
+		chapters = append(chapters, *chapter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListChapters.RowsErr: %w", err)
+	}
+	return chapters, nil
+}
+
+// FindChapter returns a chapter with its full content. A chapter that exists but belongs to
+// another course is reported as not found.
+func (r *Repository) FindChapter(ctx context.Context, courseID, chapterID int64) (*models.CourseChapter, error) {
+	chapter, err := scanChapter(r.db.QueryRow(ctx, chapterSelect+" WHERE id = $2", courseID, chapterID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindChapter: %w", err)
+	}
+	return chapter, nil
+}
+
+// --- Authoring ---
+
+func (r *Repository) CreateCourse(ctx context.Context, instructorID string, data models.CreateCourseData) (*models.Course, error) {
+	var courseID int64
+	query := `INSERT INTO courses (title, description, instructor_id, thumbnail_url)
+	          VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, '')) RETURNING id`
+	err := r.db.QueryRow(ctx, query, data.Title, data.Description, instructorID, data.ThumbnailURL).Scan(&courseID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateCourse: %w", err)
+	}
+	return r.FindCourseByID(ctx, courseID)
+}
+
+func (r *Repository) UpdateCourse(ctx context.Context, courseID int64, data models.UpdateCourseData) (*models.Course, error) {
+	query := `
+		UPDATE courses SET
+			title = COALESCE($2, title),
+			description = COALESCE($3, description),
+			thumbnail_url = COALESCE($4, thumbnail_url),
+			updated_at = NOW()
+		WHERE id = $1`
+	cmdTag, err := r.db.Exec(ctx, query, courseID, data.Title, data.Description, data.ThumbnailURL)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateCourse: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+	return r.FindCourseByID(ctx, courseID)
+}
+
+// DeleteCourse removes the course with its chapters, quizzes, enrollments and progress.
+func (r *Repository) DeleteCourse(ctx context.Context, courseID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM courses WHERE id = $1`, courseID)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteCourse: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) CreateChapter(ctx context.Context, courseID int64, data models.CreateChapterData) (*models.CourseChapter, error) {
+	var chapterID int64
+	query := `INSERT INTO course_chapters (course_id, title, display_order, background_color, video_url, video_duration, content)
+	          VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, NULLIF($7, '')) RETURNING id`
+	err := r.db.QueryRow(ctx, query,
+		courseID, data.Title, data.DisplayOrder, data.BackgroundColor, data.VideoURL, data.VideoDuration, data.Content,
+	).Scan(&chapterID)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.CreateChapter: %w", err)
+	}
+	return r.FindChapter(ctx, courseID, chapterID)
+}
+
+func (r *Repository) UpdateChapter(ctx context.Context, courseID, chapterID int64, data models.UpdateChapterData) (*models.CourseChapter, error) {
+	query := `
+		UPDATE course_chapters SET
+			title = COALESCE($3, title),
+			display_order = COALESCE($4, display_order),
+			background_color = COALESCE($5, background_color),
+			video_url = COALESCE($6, video_url),
+			video_duration = COALESCE($7, video_duration),
+			content = COALESCE($8, content),
+			updated_at = NOW()
+		WHERE id = $2 AND course_id = $1`
+	cmdTag, err := r.db.Exec(ctx, query,
+		courseID, chapterID, data.Title, data.DisplayOrder, data.BackgroundColor, data.VideoURL, data.VideoDuration, data.Content,
+	)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.UpdateChapter: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+	return r.FindChapter(ctx, courseID, chapterID)
+}
+
+func (r *Repository) DeleteChapter(ctx context.Context, courseID, chapterID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM course_chapters WHERE id = $2 AND course_id = $1`, courseID, chapterID)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteChapter: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// --- Enrollment ---
+
+func (r *Repository) IsEnrolled(ctx context.Context, userID string, courseID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM course_enrollments WHERE user_id = $1 AND course_id = $2)`
+	if err := r.db.QueryRow(ctx, query, userID, courseID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("repository.IsEnrolled: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *Repository) CreateEnrollment(ctx context.Context, userID string, courseID int64) (*models.CourseEnrollment, error) {
+	enrollment := models.CourseEnrollment{UserID: userID, CourseID: courseID}
+	query := `INSERT INTO course_enrollments (user_id, course_id, enrolled_at) VALUES ($1, $2, $3) RETURNING enrolled_at`
+	err := r.db.QueryRow(ctx, query, userID, courseID, time.Now()).Scan(&enrollment.EnrolledAt)
+	if err != nil {
+		if utils.IsUniqueViolation(err) { // Already enrolled
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.CreateEnrollment: %w", err)
+	}
+	return &enrollment, nil
+}
+
+// MarkEnrollmentCompletedIfDone stamps completed_at once every chapter of the course is completed.
+func (r *Repository) MarkEnrollmentCompletedIfDone(ctx context.Context, userID string, courseID int64) error {
+	query := `
+		UPDATE course_enrollments e SET completed_at = $3
+		WHERE e.user_id = $1 AND e.course_id = $2 AND e.completed_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM course_chapters ch
+			LEFT JOIN user_chapter_progress p ON p.chapter_id = ch.id AND p.user_id = $1
+			WHERE ch.course_id = $2 AND p.completed_at IS NULL
+		  )`
+	if _, err := r.db.Exec(ctx, query, userID, courseID, time.Now()); err != nil {
+		return fmt.Errorf("repository.MarkEnrollmentCompletedIfDone: %w", err)
+	}
+	return nil
+}
+
+// --- Quizzes ---
+
+const quizColumns = `id, chapter_id, COALESCE(title, ''), quiz_data, pass_threshold, marks_chapter_complete, COALESCE(display_order, 0)`
+
+const quizSelect = `SELECT ` + quizColumns + ` FROM chapter_quizzes `
+
+func scanQuiz(row pgx.Row) (*models.ChapterQuiz, error) {
+	var quiz models.ChapterQuiz
+	var quizData models.QuizData
+	err := row.Scan(&quiz.ID, &quiz.ChapterID, &quiz.Title, &quizData, &quiz.PassThreshold, &quiz.MarksChapterComplete, &quiz.DisplayOrder)
+	if err != nil {
+		return nil, err
+	}
+	quiz.Questions = quizData.Questions
+	if quiz.Questions == nil {
+		quiz.Questions = []models.QuizQuestion{}
+	}
+	return &quiz, nil
+}
+
+func (r *Repository) ListChapterQuizzes(ctx context.Context, chapterID int64) ([]models.ChapterQuiz, error) {
+	rows, err := r.db.Query(ctx, quizSelect+" WHERE chapter_id = $1 ORDER BY display_order ASC NULLS LAST, id ASC", chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListChapterQuizzes: %w", err)
+	}
+	defer rows.Close()
+
+	quizzes := []models.ChapterQuiz{}
+	for rows.Next() {
+		quiz, err := scanQuiz(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListChapterQuizzes.Scan: %w", err)
+		}
+		quizzes = append(quizzes, *quiz)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListChapterQuizzes.RowsErr: %w", err)
+	}
+	return quizzes, nil
+}
+
+func (r *Repository) FindQuiz(ctx context.Context, chapterID, quizID int64) (*models.ChapterQuiz, error) {
+	quiz, err := scanQuiz(r.db.QueryRow(ctx, quizSelect+" WHERE id = $1 AND chapter_id = $2", quizID, chapterID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindQuiz: %w", err)
+	}
+	return quiz, nil
+}
+
+func (r *Repository) CreateQuiz(ctx context.Context, chapterID int64, data models.CreateQuizData) (*models.ChapterQuiz, error) {
+	query := `INSERT INTO chapter_quizzes (chapter_id, title, quiz_data, pass_threshold, marks_chapter_complete, display_order)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + quizColumns
+	quiz, err := scanQuiz(r.db.QueryRow(ctx, query,
+		chapterID, data.Title, models.QuizData{Questions: data.Questions}, data.PassThreshold,
+		data.MarksChapterComplete, data.DisplayOrder,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateQuiz: %w", err)
+	}
+	return quiz, nil
+}
+
+func (r *Repository) UpdateQuiz(ctx context.Context, chapterID, quizID int64, data models.UpdateQuizData) (*models.ChapterQuiz, error) {
+	var quizData *models.QuizData // NULL keeps the stored questions
+	if data.Questions != nil {
+		quizData = &models.QuizData{Questions: data.Questions}
+	}
+	query := `
+		UPDATE chapter_quizzes SET
+			title = COALESCE($3, title),
+			quiz_data = COALESCE($4, quiz_data),
+			pass_threshold = COALESCE($5, pass_threshold),
+			marks_chapter_complete = COALESCE($6, marks_chapter_complete),
+			display_order = COALESCE($7, display_order)
+		WHERE id = $2 AND chapter_id = $1
+		RETURNING ` + quizColumns
+	quiz, err := scanQuiz(r.db.QueryRow(ctx, query,
+		chapterID, quizID, data.Title, quizData, data.PassThreshold, data.MarksChapterComplete, data.DisplayOrder,
+	))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.UpdateQuiz: %w", err)
+	}
+	return quiz, nil
+}
+
+// DeleteQuiz removes the quiz. Attempts are kept: user_quiz_attempts.quiz_id has no foreign key.
+func (r *Repository) DeleteQuiz(ctx context.Context, chapterID, quizID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM chapter_quizzes WHERE id = $2 AND chapter_id = $1`, chapterID, quizID)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteQuiz: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// attemptData is the JSON stored in user_quiz_attempts.attempt_data
+type attemptData struct {
+	Answers        []models.QuizAnswer         `json:"answers"`
+	Results        []models.QuizQuestionResult `json:"results"`
+	PointsAwarded  int                         `json:"points_awarded"`
+	PointsPossible int                         `json:"points_possible"`
+}
+
+func (r *Repository) CreateQuizAttempt(ctx context.Context, attempt *models.QuizAttempt) (*models.QuizAttempt, error) {
+	data := attemptData{
+		Answers:        attempt.Answers,
+		Results:        attempt.Results,
+		PointsAwarded:  attempt.PointsAwarded,
+		PointsPossible: attempt.PointsPossible,
+	}
+	query := `INSERT INTO user_quiz_attempts (user_id, quiz_id, quiz_type, attempt_data, score, passed, attempted_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, attempted_at`
+	err := r.db.QueryRow(ctx, query,
+		attempt.UserID, attempt.QuizID, models.QuizTypeChapter, data, attempt.Score, attempt.Passed, time.Now(),
+	).Scan(&attempt.ID, &attempt.AttemptedAt)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateQuizAttempt: %w", err)
+	}
+	return attempt, nil
+}
+
+func (r *Repository) ListQuizAttempts(ctx context.Context, userID string, quizID int64, page, limit int) ([]models.QuizAttempt, int, error) {
+	offset := (page - 1) * limit
+	query := `SELECT id, user_id, quiz_id, attempt_data, COALESCE(score, 0), passed, attempted_at
+	          FROM user_quiz_attempts
+	          WHERE user_id = $1 AND quiz_id = $2 AND quiz_type = $3
+	          ORDER BY attempted_at DESC LIMIT $4 OFFSET $5`
+	rows, err := r.db.Query(ctx, query, userID, quizID, models.QuizTypeChapter, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListQuizAttempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []models.QuizAttempt{}
+	for rows.Next() {
+		var attempt models.QuizAttempt
+		var data attemptData
+		if err := rows.Scan(&attempt.ID, &attempt.UserID, &attempt.QuizID, &data, &attempt.Score, &attempt.Passed, &attempt.AttemptedAt); err != nil {
+			return nil, 0, fmt.Errorf("repository.ListQuizAttempts.Scan: %w", err)
+		}
+		attempt.Answers = data.Answers
+		attempt.Results = data.Results
+		attempt.PointsAwarded = data.PointsAwarded
+		attempt.PointsPossible = data.PointsPossible
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListQuizAttempts.RowsErr: %w", err)
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM user_quiz_attempts WHERE user_id = $1 AND quiz_id = $2 AND quiz_type = $3`
+	if err := r.db.QueryRow(ctx, countQuery, userID, quizID, models.QuizTypeChapter).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListQuizAttempts.Count: %w", err)
+	}
+	return attempts, total, nil
+}
+
+// --- Progress ---
+
+const progressColumns = `user_id, chapter_id, progress_percentage, COALESCE(video_last_stopped_at, 0), completed_at, updated_at`
+
+func scanProgress(row pgx.Row) (*models.ChapterProgress, error) {
+	var progress models.ChapterProgress
+	err := row.Scan(
+		&progress.UserID, &progress.ChapterID, &progress.ProgressPercentage,
+		&progress.VideoLastStoppedAt, &progress.CompletedAt, &progress.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (r *Repository) ListProgressForCourse(ctx context.Context, userID string, courseID int64) ([]models.ChapterProgress, error) {
+	query := `SELECT p.user_id, p.chapter_id, p.progress_percentage, COALESCE(p.video_last_stopped_at, 0), p.completed_at, p.updated_at
+	          FROM user_chapter_progress p
+	          JOIN course_chapters ch ON ch.id = p.chapter_id
+	          WHERE p.user_id = $1 AND ch.course_id = $2`
+	rows, err := r.db.Query(ctx, query, userID, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListProgressForCourse: %w", err)
+	}
+	defer rows.Close()
+
+	progressList := []models.ChapterProgress{}
+	for rows.Next() {
+		progress, err := scanProgress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListProgressForCourse.Scan: %w", err)
+		}
+		progressList = append(progressList, *progress)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListProgressForCourse.RowsErr: %w", err)
+	}
+	return progressList, nil
+}
+
+func (r *Repository) GetChapterProgress(ctx context.Context, userID string, chapterID int64) (*models.ChapterProgress, error) {
+	query := `SELECT ` + progressColumns + ` FROM user_chapter_progress WHERE user_id = $1 AND chapter_id = $2`
+	progress, err := scanProgress(r.db.QueryRow(ctx, query, userID, chapterID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.GetChapterProgress: %w", err)
+	}
+	return progress, nil
+}
+
+// UpsertChapterProgress records progress without ever lowering the stored percentage,
+// and sets completed_at the first time the chapter reaches 100%.
+func (r *Repository) UpsertChapterProgress(ctx context.Context, userID string, chapterID int64, data models.UpdateChapterProgressData) (*models.ChapterProgress, error) {
+	query := `
+		INSERT INTO user_chapter_progress (user_id, chapter_id, progress_percentage, video_last_stopped_at, completed_at, updated_at)
+		VALUES ($1, $2, $3, $4, CASE WHEN $3 >= 100 THEN $5::timestamptz END, $5)
+		ON CONFLICT (user_id, chapter_id) DO UPDATE SET
+			progress_percentage = GREATEST(user_chapter_progress.progress_percentage, EXCLUDED.progress_percentage),
+			video_last_stopped_at = EXCLUDED.video_last_stopped_at,
+			completed_at = COALESCE(user_chapter_progress.completed_at, EXCLUDED.completed_at),
+			updated_at = EXCLUDED.updated_at
+		RETURNING ` + progressColumns
+	progress, err := scanProgress(r.db.QueryRow(ctx, query, userID, chapterID, data.ProgressPercentage, data.VideoLastStoppedAt, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpsertChapterProgress: %w", err)
+	}
+	return progress, nil
+}
+
+// MarkChapterComplete sets the chapter to 100% without touching the saved video position.
+func (r *Repository) MarkChapterComplete(ctx context.Context, userID string, chapterID int64) (*models.ChapterProgress, error) {
+	query := `
+		INSERT INTO user_chapter_progress (user_id, chapter_id, progress_percentage, completed_at, updated_at)
+		VALUES ($1, $2, 100, $3, $3)
+		ON CONFLICT (user_id, chapter_id) DO UPDATE SET
+			progress_percentage = 100,
+			completed_at = COALESCE(user_chapter_progress.completed_at, EXCLUDED.completed_at),
+			updated_at = EXCLUDED.updated_at
+		RETURNING ` + progressColumns
+	progress, err := scanProgress(r.db.QueryRow(ctx, query, userID, chapterID, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("repository.MarkChapterComplete: %w", err)
+	}
+	return progress, nil
+}
+
+// --- Admin Reporting ---
+
+// ListCourseProgressSummaries returns enrollment and progress figures for every course. A student's
+// course progress is the sum of their chapter percentages divided by the number of chapters.
+func (r *Repository) ListCourseProgressSummaries(ctx context.Context) ([]models.CourseProgressSummary, error) {
+	query := `
+		WITH chapter_counts AS (
+			SELECT course_id, COUNT(*) AS chapter_count FROM course_chapters GROUP BY course_id
+		), student_progress AS (
+			SELECT ce.course_id, ce.user_id, ce.completed_at,
+			       COALESCE(SUM(ucp.progress_percentage), 0)::float8 / NULLIF(cc.chapter_count, 0) AS progress
+			FROM course_enrollments ce
+			LEFT JOIN chapter_counts cc ON cc.course_id = ce.course_id
+			LEFT JOIN course_chapters ch ON ch.course_id = ce.course_id
+			LEFT JOIN user_chapter_progress ucp ON ucp.chapter_id = ch.id AND ucp.user_id = ce.user_id
+			GROUP BY ce.course_id, ce.user_id, ce.completed_at, cc.chapter_count
+		)
+		SELECT c.id, c.title, COALESCE(cc.chapter_count, 0),
+		       COUNT(sp.user_id), COUNT(sp.completed_at),
+		       COALESCE(ROUND(AVG(sp.progress)::numeric, 1), 0)::float8
+		FROM courses c
+		LEFT JOIN chapter_counts cc ON cc.course_id = c.id
+		LEFT JOIN student_progress sp ON sp.course_id = c.id
+		GROUP BY c.id, c.title, cc.chapter_count
+		ORDER BY c.id ASC`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListCourseProgressSummaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []models.CourseProgressSummary{}
+	for rows.Next() {
+		var summary models.CourseProgressSummary
+		err := rows.Scan(&summary.CourseID, &summary.Title, &summary.ChapterCount,
+			&summary.EnrolledCount, &summary.CompletedCount, &summary.AverageProgress)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListCourseProgressSummaries.Scan: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListCourseProgressSummaries.Rows: %w", err)
+	}
+	return summaries, nil
+}
+
+// ListStudentProgress lists the students enrolled in a course, most recently active first.
+func (r *Repository) ListStudentProgress(ctx context.Context, courseID int64, page, limit int) ([]models.StudentCourseProgress, int, error) {
+	offset := (page - 1) * limit
+	query := `
+		SELECT ce.user_id, COALESCE(u.nickname, ''), ce.enrolled_at, ce.completed_at,
+		       COUNT(ucp.completed_at),
+		       COALESCE(SUM(ucp.progress_percentage) / NULLIF(COUNT(DISTINCT ch.id), 0), 0),
+		       MAX(ucp.updated_at)
+		FROM course_enrollments ce
+		JOIN users u ON u.id = ce.user_id
+		LEFT JOIN course_chapters ch ON ch.course_id = ce.course_id
+		LEFT JOIN user_chapter_progress ucp ON ucp.chapter_id = ch.id AND ucp.user_id = ce.user_id
+		WHERE ce.course_id = $1
+		GROUP BY ce.user_id, u.nickname, ce.enrolled_at, ce.completed_at
+		ORDER BY MAX(ucp.updated_at) DESC NULLS LAST, ce.enrolled_at DESC
+		LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, courseID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListStudentProgress: %w", err)
+	}
+	defer rows.Close()
+
+	students := []models.StudentCourseProgress{}
+	for rows.Next() {
+		var student models.StudentCourseProgress
+		err := rows.Scan(&student.UserID, &student.Nickname, &student.EnrolledAt, &student.CompletedAt,
+			&student.CompletedChapters, &student.ProgressPercentage, &student.LastActivityAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("repository.ListStudentProgress.Scan: %w", err)
+		}
+		students = append(students, student)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListStudentProgress.Rows: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM course_enrollments WHERE course_id = $1`, courseID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListStudentProgress.Count: %w", err)
+	}
+	return students, total, nil
+}
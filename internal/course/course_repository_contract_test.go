@@ -0,0 +1,468 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty catalogue and a function creating a user
+// (returning the ID and nickname).
+type fixture struct {
+	repo    RepositoryInterface
+	addUser func() (string, string)
+}
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		db := pgtest.NewDB(t)
+		return fixture{
+			repo: NewRepository(db),
+			addUser: func() (string, string) {
+				u := factory.User(t, db)
+				return u.ID, u.Nickname
+			},
+		}
+	})
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		repo := NewMemoryRepository()
+		lastUserID := 0
+		return fixture{
+			repo: repo,
+			addUser: func() (string, string) {
+				lastUserID++
+				id, nickname := strconv.Itoa(lastUserID), "user"+strconv.Itoa(lastUserID)
+				repo.AddUser(id, nickname)
+				return id, nickname
+			},
+		}
+	})
+}
+
+func testRepositoryContract(t *testing.T, newFixture func(t *testing.T) fixture) {
+	ctx := context.Background()
+	createCourse := func(t *testing.T, f fixture, instructorID, title string) *models.Course {
+		t.Helper()
+		course, err := f.repo.CreateCourse(ctx, instructorID, models.CreateCourseData{Title: title})
+		if err != nil {
+			t.Fatalf("CreateCourse: %v", err)
+		}
+		return course
+	}
+	createChapter := func(t *testing.T, f fixture, courseID int64, title string, displayOrder int) *models.CourseChapter {
+		t.Helper()
+		chapter, err := f.repo.CreateChapter(ctx, courseID, models.CreateChapterData{
+			Title: title, DisplayOrder: displayOrder, VideoURL: "https://example.com/" + title + ".mp4", Content: "About " + title,
+		})
+		if err != nil {
+			t.Fatalf("CreateChapter: %v", err)
+		}
+		return chapter
+	}
+	threshold := func(n int) *int { return &n }
+
+	t.Run("Courses", func(t *testing.T) {
+		f := newFixture(t)
+		teacher, nickname := f.addUser()
+		if _, err := f.repo.CreateCourse(ctx, "missing", models.CreateCourseData{Title: "Orphan"}); err == nil {
+			t.Error("CreateCourse by a missing user: error = nil, want the foreign key error")
+		}
+
+		course, err := f.repo.CreateCourse(ctx, teacher, models.CreateCourseData{Title: "Glazing", Description: "Celadon"})
+		if err != nil {
+			t.Fatalf("CreateCourse: %v", err)
+		}
+		if course.InstructorID == nil || *course.InstructorID != teacher || course.InstructorNickname != nickname ||
+			course.Description != "Celadon" || course.ChapterCount != 0 || course.EnrollmentCount != 0 {
+			t.Errorf("CreateCourse = %+v, want an empty course by the teacher", course)
+		}
+		newer := createCourse(t, f, teacher, "Firing")
+
+		title := "Glazing basics"
+		updated, err := f.repo.UpdateCourse(ctx, course.ID, models.UpdateCourseData{Title: &title})
+		if err != nil {
+			t.Fatalf("UpdateCourse: %v", err)
+		}
+		if updated.Title != title || updated.Description != "Celadon" || updated.UpdatedAt.Before(course.UpdatedAt) {
+			t.Errorf("UpdateCourse = %+v, want only the title changed", updated)
+		}
+		if _, err := f.repo.UpdateCourse(ctx, newer.ID+100, models.UpdateCourseData{Title: &title}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateCourse of a missing course: error = %v, want ErrNotFound", err)
+		}
+
+		courses, total, err := f.repo.ListCourses(ctx, 1, 1)
+		if err != nil {
+			t.Fatalf("ListCourses: %v", err)
+		}
+		if total != 2 || len(courses) != 1 || courses[0].ID != newer.ID {
+			t.Errorf("ListCourses page 1 = %+v (total %d), want the newest course of 2", courses, total)
+		}
+		if courses, _, _ := f.repo.ListCourses(ctx, 2, 1); len(courses) != 1 || courses[0].ID != course.ID {
+			t.Errorf("ListCourses page 2 = %+v, want the older course", courses)
+		}
+
+		createChapter(t, f, course.ID, "intro", 1)
+		student, _ := f.addUser()
+		if _, err := f.repo.CreateEnrollment(ctx, student, course.ID); err != nil {
+			t.Fatalf("CreateEnrollment: %v", err)
+		}
+		if found, err := f.repo.FindCourseByID(ctx, course.ID); err != nil || found.ChapterCount != 1 || found.EnrollmentCount != 1 {
+			t.Errorf("FindCourseByID = %+v, %v; want 1 chapter and 1 enrollment", found, err)
+		}
+
+		if err := f.repo.DeleteCourse(ctx, course.ID); err != nil {
+			t.Fatalf("DeleteCourse: %v", err)
+		}
+		if err := f.repo.DeleteCourse(ctx, course.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteCourse twice: error = %v, want ErrNotFound", err)
+		}
+		if _, err := f.repo.FindCourseByID(ctx, course.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindCourseByID after delete: error = %v, want ErrNotFound", err)
+		}
+		if enrolled, _ := f.repo.IsEnrolled(ctx, student, course.ID); enrolled {
+			t.Error("IsEnrolled after deleting the course = true, want the enrollment gone")
+		}
+	})
+
+	t.Run("Chapters", func(t *testing.T) {
+		f := newFixture(t)
+		teacher, _ := f.addUser()
+		course := createCourse(t, f, teacher, "Throwing")
+		other := createCourse(t, f, teacher, "Trimming")
+		if _, err := f.repo.CreateChapter(ctx, other.ID+100, models.CreateChapterData{Title: "lost"}); err == nil {
+			t.Error("CreateChapter in a missing course: error = nil, want the foreign key error")
+		}
+
+		third := createChapter(t, f, course.ID, "pulling", 30)
+		first := createChapter(t, f, course.ID, "centering", 10)
+		second := createChapter(t, f, course.ID, "opening", 20)
+		if first.Position != 1 || !first.IsFreePreview || first.Content != "About centering" {
+			t.Errorf("CreateChapter = %+v, want the first, free chapter with its content", first)
+		}
+		if _, err := f.repo.CreateChapter(ctx, course.ID, models.CreateChapterData{Title: "again", DisplayOrder: 10}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("CreateChapter with a taken display order: error = %v, want ErrConflict", err)
+		}
+		if _, err := f.repo.CreateChapter(ctx, other.ID, models.CreateChapterData{Title: "elsewhere", DisplayOrder: 10}); err != nil {
+			t.Errorf("CreateChapter with a display order taken in another course: %v", err)
+		}
+
+		chapters, err := f.repo.ListChapters(ctx, course.ID)
+		if err != nil {
+			t.Fatalf("ListChapters: %v", err)
+		}
+		var ids []int64
+		for i, ch := range chapters {
+			ids = append(ids, ch.ID)
+			if ch.Position != i+1 || ch.IsFreePreview != (i < models.FreePreviewChapterCount) || ch.Content != "" || ch.VideoURL != "" {
+				t.Errorf("ListChapters[%d] = %+v, want position %d without content", i, ch, i+1)
+			}
+		}
+		if want := []int64{first.ID, second.ID, third.ID}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("ListChapters IDs = %v, want %v", ids, want)
+		}
+
+		if _, err := f.repo.UpdateChapter(ctx, course.ID, third.ID, models.UpdateChapterData{DisplayOrder: &first.DisplayOrder}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("UpdateChapter to a taken display order: error = %v, want ErrConflict", err)
+		}
+		order := 5
+		moved, err := f.repo.UpdateChapter(ctx, course.ID, third.ID, models.UpdateChapterData{DisplayOrder: &order})
+		if err != nil {
+			t.Fatalf("UpdateChapter: %v", err)
+		}
+		if moved.Position != 1 || moved.Title != "pulling" || moved.VideoURL == "" {
+			t.Errorf("UpdateChapter = %+v, want position 1 with the old title and video", moved)
+		}
+		if found, err := f.repo.FindChapter(ctx, course.ID, first.ID); err != nil || found.Position != 2 || found.Content != "About centering" {
+			t.Errorf("FindChapter = %+v, %v; want position 2 with content", found, err)
+		}
+		if _, err := f.repo.FindChapter(ctx, other.ID, first.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindChapter through another course: error = %v, want ErrNotFound", err)
+		}
+		if _, err := f.repo.UpdateChapter(ctx, other.ID, first.ID, models.UpdateChapterData{DisplayOrder: &order}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateChapter through another course: error = %v, want ErrNotFound", err)
+		}
+		if err := f.repo.DeleteChapter(ctx, other.ID, first.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteChapter through another course: error = %v, want ErrNotFound", err)
+		}
+		if err := f.repo.DeleteChapter(ctx, course.ID, first.ID); err != nil {
+			t.Fatalf("DeleteChapter: %v", err)
+		}
+		if chapters, _ := f.repo.ListChapters(ctx, course.ID); len(chapters) != 2 {
+			t.Errorf("ListChapters after delete = %+v, want 2 chapters", chapters)
+		}
+	})
+
+	t.Run("Quizzes", func(t *testing.T) {
+		f := newFixture(t)
+		teacher, _ := f.addUser()
+		course := createCourse(t, f, teacher, "Throwing")
+		chapter := createChapter(t, f, course.ID, "centering", 1)
+		yes := true
+		questions := []models.QuizQuestion{{ID: "q1", Type: models.QuestionTrueFalse, Text: "Wet hands?", CorrectBool: &yes}}
+
+		if _, err := f.repo.CreateQuiz(ctx, chapter.ID, models.CreateQuizData{Title: "no threshold", Questions: questions}); err == nil {
+			t.Error("CreateQuiz without a pass threshold: error = nil, want the NOT NULL error")
+		}
+		if _, err := f.repo.CreateQuiz(ctx, chapter.ID+100, models.CreateQuizData{Title: "lost", Questions: questions, PassThreshold: threshold(50)}); err == nil {
+			t.Error("CreateQuiz in a missing chapter: error = nil, want the foreign key error")
+		}
+		later, err := f.repo.CreateQuiz(ctx, chapter.ID, models.CreateQuizData{
+			Title: "Final", Questions: questions, PassThreshold: threshold(80), MarksChapterComplete: true, DisplayOrder: 2,
+		})
+		if err != nil {
+			t.Fatalf("CreateQuiz: %v", err)
+		}
+		if later.PassThreshold != 80 || !later.MarksChapterComplete || !reflect.DeepEqual(later.Questions, questions) {
+			t.Errorf("CreateQuiz = %+v, want the sent quiz", later)
+		}
+		earlier, err := f.repo.CreateQuiz(ctx, chapter.ID, models.CreateQuizData{Title: "Warm-up", Questions: questions, PassThreshold: threshold(50), DisplayOrder: 1})
+		if err != nil {
+			t.Fatalf("CreateQuiz: %v", err)
+		}
+
+		quizzes, err := f.repo.ListChapterQuizzes(ctx, chapter.ID)
+		if err != nil {
+			t.Fatalf("ListChapterQuizzes: %v", err)
+		}
+		if len(quizzes) != 2 || quizzes[0].ID != earlier.ID || quizzes[1].ID != later.ID {
+			t.Errorf("ListChapterQuizzes = %+v, want the warm-up first", quizzes)
+		}
+
+		title := "Final exam"
+		updated, err := f.repo.UpdateQuiz(ctx, chapter.ID, later.ID, models.UpdateQuizData{Title: &title})
+		if err != nil {
+			t.Fatalf("UpdateQuiz: %v", err)
+		}
+		if updated.Title != title || updated.PassThreshold != 80 || !reflect.DeepEqual(updated.Questions, questions) {
+			t.Errorf("UpdateQuiz = %+v, want only the title changed", updated)
+		}
+		replaced := []models.QuizQuestion{{ID: "q2", Type: models.QuestionTrueFalse, Text: "Dry clay?", CorrectBool: &yes}}
+		if updated, err := f.repo.UpdateQuiz(ctx, chapter.ID, later.ID, models.UpdateQuizData{Questions: replaced}); err != nil || !reflect.DeepEqual(updated.Questions, replaced) {
+			t.Errorf("UpdateQuiz questions = %+v, %v; want them replaced", updated, err)
+		}
+		if _, err := f.repo.UpdateQuiz(ctx, chapter.ID+100, later.ID, models.UpdateQuizData{Title: &title}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateQuiz through another chapter: error = %v, want ErrNotFound", err)
+		}
+		if _, err := f.repo.FindQuiz(ctx, chapter.ID+100, later.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindQuiz through another chapter: error = %v, want ErrNotFound", err)
+		}
+
+		if err := f.repo.DeleteQuiz(ctx, chapter.ID, earlier.ID); err != nil {
+			t.Fatalf("DeleteQuiz: %v", err)
+		}
+		if err := f.repo.DeleteQuiz(ctx, chapter.ID, earlier.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteQuiz twice: error = %v, want ErrNotFound", err)
+		}
+		if err := f.repo.DeleteChapter(ctx, course.ID, chapter.ID); err != nil {
+			t.Fatalf("DeleteChapter: %v", err)
+		}
+		if _, err := f.repo.FindQuiz(ctx, chapter.ID, later.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindQuiz after deleting the chapter: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Attempts", func(t *testing.T) {
+		f := newFixture(t)
+		student, _ := f.addUser()
+		other, _ := f.addUser()
+		yes := true
+		var created []int64
+		for i := range 3 {
+			attempt := &models.QuizAttempt{
+				UserID: student, QuizID: 7, Score: 50 * i, Passed: i == 2, PointsAwarded: i, PointsPossible: 2,
+				Answers: []models.QuizAnswer{{QuestionID: "q1", Bool: &yes}},
+				Results: []models.QuizQuestionResult{{QuestionID: "q1", Correct: true, PointsAwarded: 1, PointsPossible: 1}},
+			}
+			got, err := f.repo.CreateQuizAttempt(ctx, attempt)
+			if err != nil {
+				t.Fatalf("CreateQuizAttempt: %v", err)
+			}
+			if got != attempt || attempt.ID == 0 || attempt.AttemptedAt.IsZero() {
+				t.Fatalf("CreateQuizAttempt = %+v, want the attempt with its ID and time set", got)
+			}
+			created = append(created, attempt.ID)
+		}
+		if _, err := f.repo.CreateQuizAttempt(ctx, &models.QuizAttempt{UserID: other, QuizID: 8}); err != nil {
+			t.Fatalf("CreateQuizAttempt: %v", err)
+		}
+
+		attempts, total, err := f.repo.ListQuizAttempts(ctx, student, 7, 1, 2)
+		if err != nil {
+			t.Fatalf("ListQuizAttempts: %v", err)
+		}
+		if total != 3 || len(attempts) != 2 {
+			t.Fatalf("ListQuizAttempts = %+v (total %d), want 2 of 3 attempts", attempts, total)
+		}
+		latest := attempts[0]
+		if latest.ID != created[2] || !latest.Passed || latest.Score != 100 || latest.PointsAwarded != 2 || latest.PointsPossible != 2 ||
+			len(latest.Answers) != 1 || latest.Answers[0].Bool == nil || !*latest.Answers[0].Bool || len(latest.Results) != 1 || !latest.Results[0].Correct {
+			t.Errorf("ListQuizAttempts[0] = %+v, want the latest attempt with its answers and results", latest)
+		}
+		if attempts, _, _ := f.repo.ListQuizAttempts(ctx, student, 7, 2, 2); len(attempts) != 1 || attempts[0].ID != created[0] {
+			t.Errorf("ListQuizAttempts page 2 = %+v, want the first attempt", attempts)
+		}
+		if attempts, total, _ := f.repo.ListQuizAttempts(ctx, other, 7, 1, 10); len(attempts) != 0 || total != 0 {
+			t.Errorf("ListQuizAttempts of another user = %+v (total %d), want none", attempts, total)
+		}
+	})
+
+	t.Run("EnrollmentAndProgress", func(t *testing.T) {
+		f := newFixture(t)
+		teacher, _ := f.addUser()
+		student, _ := f.addUser()
+		course := createCourse(t, f, teacher, "Throwing")
+		first := createChapter(t, f, course.ID, "centering", 1)
+		second := createChapter(t, f, course.ID, "opening", 2)
+
+		if _, err := f.repo.CreateEnrollment(ctx, "missing", course.ID); err == nil || errors.Is(err, models.ErrConflict) {
+			t.Errorf("CreateEnrollment of a missing user: error = %v, want the foreign key error", err)
+		}
+		enrollment, err := f.repo.CreateEnrollment(ctx, student, course.ID)
+		if err != nil {
+			t.Fatalf("CreateEnrollment: %v", err)
+		}
+		if enrollment.UserID != student || enrollment.CourseID != course.ID || enrollment.EnrolledAt.IsZero() || enrollment.CompletedAt != nil {
+			t.Errorf("CreateEnrollment = %+v, want an open enrollment", enrollment)
+		}
+		if _, err := f.repo.CreateEnrollment(ctx, student, course.ID); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("CreateEnrollment twice: error = %v, want ErrConflict", err)
+		}
+		if enrolled, err := f.repo.IsEnrolled(ctx, student, course.ID); err != nil || !enrolled {
+			t.Errorf("IsEnrolled = %v, %v; want true", enrolled, err)
+		}
+
+		if _, err := f.repo.GetChapterProgress(ctx, student, first.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetChapterProgress before watching: error = %v, want ErrNotFound", err)
+		}
+		if _, err := f.repo.UpsertChapterProgress(ctx, student, first.ID, models.UpdateChapterProgressData{ProgressPercentage: 101}); err == nil {
+			t.Error("UpsertChapterProgress above 100%: error = nil, want the CHECK error")
+		}
+		if _, err := f.repo.UpsertChapterProgress(ctx, student, second.ID+100, models.UpdateChapterProgressData{ProgressPercentage: 10}); err == nil {
+			t.Error("UpsertChapterProgress of a missing chapter: error = nil, want the foreign key error")
+		}
+		progress, err := f.repo.UpsertChapterProgress(ctx, student, first.ID, models.UpdateChapterProgressData{ProgressPercentage: 60, VideoLastStoppedAt: 90})
+		if err != nil {
+			t.Fatalf("UpsertChapterProgress: %v", err)
+		}
+		if progress.ProgressPercentage != 60 || progress.VideoLastStoppedAt != 90 || progress.CompletedAt != nil {
+			t.Errorf("UpsertChapterProgress = %+v, want 60%% at 90s", progress)
+		}
+		// Progress never goes back, but the video position follows the player
+		progress, err = f.repo.UpsertChapterProgress(ctx, student, first.ID, models.UpdateChapterProgressData{ProgressPercentage: 30, VideoLastStoppedAt: 40})
+		if err != nil {
+			t.Fatalf("UpsertChapterProgress: %v", err)
+		}
+		if progress.ProgressPercentage != 60 || progress.VideoLastStoppedAt != 40 || progress.CompletedAt != nil {
+			t.Errorf("UpsertChapterProgress backwards = %+v, want 60%% at 40s", progress)
+		}
+		progress, err = f.repo.UpsertChapterProgress(ctx, student, first.ID, models.UpdateChapterProgressData{ProgressPercentage: 100, VideoLastStoppedAt: 300})
+		if err != nil {
+			t.Fatalf("UpsertChapterProgress: %v", err)
+		}
+		if progress.ProgressPercentage != 100 || progress.CompletedAt == nil {
+			t.Fatalf("UpsertChapterProgress to 100%% = %+v, want it completed", progress)
+		}
+		completedAt := *progress.CompletedAt
+		if again, _ := f.repo.UpsertChapterProgress(ctx, student, first.ID, models.UpdateChapterProgressData{ProgressPercentage: 100}); again == nil || again.CompletedAt == nil || !again.CompletedAt.Equal(completedAt) {
+			t.Errorf("UpsertChapterProgress after completion = %+v, want completed_at kept", again)
+		}
+
+		if err := f.repo.MarkEnrollmentCompletedIfDone(ctx, student, course.ID); err != nil {
+			t.Fatalf("MarkEnrollmentCompletedIfDone: %v", err)
+		}
+		if students, _, _ := f.repo.ListStudentProgress(ctx, course.ID, 1, 10); len(students) != 1 || students[0].CompletedAt != nil {
+			t.Errorf("ListStudentProgress with a chapter left = %+v, want the course open", students)
+		}
+
+		if _, err := f.repo.UpsertChapterProgress(ctx, student, second.ID, models.UpdateChapterProgressData{ProgressPercentage: 20, VideoLastStoppedAt: 15}); err != nil {
+			t.Fatalf("UpsertChapterProgress: %v", err)
+		}
+		marked, err := f.repo.MarkChapterComplete(ctx, student, second.ID)
+		if err != nil {
+			t.Fatalf("MarkChapterComplete: %v", err)
+		}
+		if marked.ProgressPercentage != 100 || marked.VideoLastStoppedAt != 15 || marked.CompletedAt == nil {
+			t.Errorf("MarkChapterComplete = %+v, want 100%% with the video position kept", marked)
+		}
+		if got, err := f.repo.GetChapterProgress(ctx, student, second.ID); err != nil || got.CompletedAt == nil {
+			t.Errorf("GetChapterProgress = %+v, %v; want the completed chapter", got, err)
+		}
+		if list, err := f.repo.ListProgressForCourse(ctx, student, course.ID); err != nil || len(list) != 2 {
+			t.Errorf("ListProgressForCourse = %+v, %v; want both chapters", list, err)
+		}
+
+		if err := f.repo.MarkEnrollmentCompletedIfDone(ctx, student, course.ID); err != nil {
+			t.Fatalf("MarkEnrollmentCompletedIfDone: %v", err)
+		}
+		students, total, err := f.repo.ListStudentProgress(ctx, course.ID, 1, 10)
+		if err != nil {
+			t.Fatalf("ListStudentProgress: %v", err)
+		}
+		if total != 1 || len(students) != 1 || students[0].CompletedAt == nil {
+			t.Errorf("ListStudentProgress after every chapter = %+v, want the course completed", students)
+		}
+	})
+
+	t.Run("Reporting", func(t *testing.T) {
+		f := newFixture(t)
+		teacher, _ := f.addUser()
+		active, activeNickname := f.addUser()
+		idle, _ := f.addUser()
+		course := createCourse(t, f, teacher, "Throwing")
+		empty := createCourse(t, f, teacher, "Coming soon")
+		first := createChapter(t, f, course.ID, "centering", 1)
+		second := createChapter(t, f, course.ID, "opening", 2)
+		createChapter(t, f, course.ID, "pulling", 3)
+		for _, userID := range []string{idle, active} {
+			if _, err := f.repo.CreateEnrollment(ctx, userID, course.ID); err != nil {
+				t.Fatalf("CreateEnrollment: %v", err)
+			}
+		}
+		if _, err := f.repo.CreateEnrollment(ctx, active, empty.ID); err != nil {
+			t.Fatalf("CreateEnrollment: %v", err)
+		}
+		if _, err := f.repo.MarkChapterComplete(ctx, active, first.ID); err != nil {
+			t.Fatalf("MarkChapterComplete: %v", err)
+		}
+		if _, err := f.repo.UpsertChapterProgress(ctx, active, second.ID, models.UpdateChapterProgressData{ProgressPercentage: 50}); err != nil {
+			t.Fatalf("UpsertChapterProgress: %v", err)
+		}
+
+		summaries, err := f.repo.ListCourseProgressSummaries(ctx)
+		if err != nil {
+			t.Fatalf("ListCourseProgressSummaries: %v", err)
+		}
+		want := []models.CourseProgressSummary{
+			// (150/3 + 0/3) / 2 students
+			{CourseID: course.ID, Title: "Throwing", ChapterCount: 3, EnrolledCount: 2, AverageProgress: 25},
+			{CourseID: empty.ID, Title: "Coming soon", EnrolledCount: 1},
+		}
+		if !reflect.DeepEqual(summaries, want) {
+			t.Errorf("ListCourseProgressSummaries = %+v, want %+v", summaries, want)
+		}
+
+		students, total, err := f.repo.ListStudentProgress(ctx, course.ID, 1, 1)
+		if err != nil {
+			t.Fatalf("ListStudentProgress: %v", err)
+		}
+		if total != 2 || len(students) != 1 {
+			t.Fatalf("ListStudentProgress = %+v (total %d), want 1 of 2 students", students, total)
+		}
+		if s := students[0]; s.UserID != active || s.Nickname != activeNickname || s.CompletedChapters != 1 || s.ProgressPercentage != 50 || s.LastActivityAt == nil {
+			t.Errorf("ListStudentProgress[0] = %+v, want the active student at 50%% with 1 chapter done", s)
+		}
+		if students, _, _ := f.repo.ListStudentProgress(ctx, course.ID, 2, 1); len(students) != 1 || students[0].UserID != idle || students[0].LastActivityAt != nil || students[0].ProgressPercentage != 0 {
+			t.Errorf("ListStudentProgress page 2 = %+v, want the idle student last", students)
+		}
+	})
+}
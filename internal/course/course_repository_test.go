@@ -0,0 +1,85 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func TestRepositoryAuthoring(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+	teacher := factory.User(t, db, func(u *models.User) { u.Role = models.RoleTeacher })
+
+	course, err := repo.CreateCourse(ctx, teacher.ID, models.CreateCourseData{Title: "Throwing on the wheel"})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	if course.InstructorID == nil || *course.InstructorID != teacher.ID || course.InstructorNickname != teacher.Nickname {
+		t.Errorf("CreateCourse = %+v, want the author as instructor", course)
+	}
+
+	first, err := repo.CreateChapter(ctx, course.ID, models.CreateChapterData{Title: "Centering", DisplayOrder: 10})
+	if err != nil {
+		t.Fatalf("CreateChapter: %v", err)
+	}
+	if _, err := repo.CreateChapter(ctx, course.ID, models.CreateChapterData{Title: "Again", DisplayOrder: 10}); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("CreateChapter with a taken display order: error = %v, want ErrConflict", err)
+	}
+	second, err := repo.CreateChapter(ctx, course.ID, models.CreateChapterData{Title: "Pulling", DisplayOrder: 20})
+	if err != nil {
+		t.Fatalf("CreateChapter: %v", err)
+	}
+	// Moving the second chapter first renumbers the positions
+	order := 5
+	moved, err := repo.UpdateChapter(ctx, course.ID, second.ID, models.UpdateChapterData{DisplayOrder: &order})
+	if err != nil {
+		t.Fatalf("UpdateChapter: %v", err)
+	}
+	if moved.Position != 1 || moved.Title != "Pulling" {
+		t.Errorf("UpdateChapter = %+v, want position 1 with the old title", moved)
+	}
+	if _, err := repo.UpdateChapter(ctx, course.ID+1, first.ID, models.UpdateChapterData{DisplayOrder: &order}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateChapter through another course: error = %v, want ErrNotFound", err)
+	}
+
+	yes := true
+	threshold := 80
+	quiz, err := repo.CreateQuiz(ctx, first.ID, models.CreateQuizData{
+		Title:         "Centering check",
+		Questions:     []models.QuizQuestion{{ID: "q1", Type: models.QuestionTrueFalse, Text: "Wet hands?", CorrectBool: &yes}},
+		PassThreshold: &threshold,
+	})
+	if err != nil {
+		t.Fatalf("CreateQuiz: %v", err)
+	}
+	if len(quiz.Questions) != 1 || quiz.Questions[0].CorrectBool == nil || quiz.PassThreshold != 80 {
+		t.Errorf("CreateQuiz = %+v, want the question with its answer", quiz)
+	}
+	title := "Centering quiz"
+	updated, err := repo.UpdateQuiz(ctx, first.ID, quiz.ID, models.UpdateQuizData{Title: &title})
+	if err != nil {
+		t.Fatalf("UpdateQuiz: %v", err)
+	}
+	if updated.Title != title || len(updated.Questions) != 1 {
+		t.Errorf("UpdateQuiz = %+v, want the new title and the old questions", updated)
+	}
+	if err := repo.DeleteQuiz(ctx, second.ID, quiz.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteQuiz through another chapter: error = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.DeleteCourse(ctx, course.ID); err != nil {
+		t.Fatalf("DeleteCourse: %v", err)
+	}
+	if _, err := repo.FindChapter(ctx, course.ID, first.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("chapter after DeleteCourse: error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,420 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/user"
+	"log"
+)
+
+const noteEntityTypeChapter = "course_chapter"
+
+// ServiceInterface defines the methods for course business logic.
+type ServiceInterface interface {
+	ListCourses(ctx context.Context, page, limit int) ([]models.Course, int, error)
+	// GetCourseDetails returns the course with its chapter outline.
+	// viewerID is empty for guests; otherwise enrollment and per-chapter progress are filled in.
+	GetCourseDetails(ctx context.Context, courseID int64, viewerID string) (*models.Course, error)
+	// GetChapterContent serves free-preview chapters to anyone and the rest only to enrolled viewers.
+	GetChapterContent(ctx context.Context, courseID, chapterID int64, viewerID string) (*models.CourseChapter, error)
+	// GetFullChapterContent serves any chapter, but only to enrolled users.
+	GetFullChapterContent(ctx context.Context, userID string, courseID, chapterID int64) (*models.CourseChapter, error)
+
+	EnrollCourse(ctx context.Context, userID string, courseID int64) (*models.CourseEnrollment, error)
+	UpdateProgress(ctx context.Context, userID string, courseID, chapterID int64, data models.UpdateChapterProgressData) (*models.ChapterProgress, error)
+	AddNoteToChapter(ctx context.Context, userID string, courseID, chapterID int64, title, content string) (*models.UserNote, error)
+
+	// Quizzes follow the same access rule as the chapter they belong to.
+	GetQuiz(ctx context.Context, userID string, courseID, chapterID, quizID int64) (*models.ChapterQuiz, error)
+	SubmitQuiz(ctx context.Context, userID string, courseID, chapterID, quizID int64, data models.SubmitQuizData) (*models.QuizAttempt, error)
+	ListQuizAttempts(ctx context.Context, userID string, courseID, chapterID, quizID int64, page, limit int) ([]models.QuizAttempt, int, error)
+
+	// Authoring, routed behind models.PermCourseAuthor. Any author may edit any course. Quizzes are
+	// returned with their answers; invalid questions give models.ErrInvalidQuiz.
+	CreateCourse(ctx context.Context, authorID string, data models.CreateCourseData) (*models.Course, error)
+	UpdateCourse(ctx context.Context, courseID int64, data models.UpdateCourseData) (*models.Course, error)
+	DeleteCourse(ctx context.Context, courseID int64) error
+	CreateChapter(ctx context.Context, courseID int64, data models.CreateChapterData) (*models.CourseChapter, error)
+	UpdateChapter(ctx context.Context, courseID, chapterID int64, data models.UpdateChapterData) (*models.CourseChapter, error)
+	DeleteChapter(ctx context.Context, courseID, chapterID int64) error
+	CreateQuiz(ctx context.Context, courseID, chapterID int64, data models.CreateQuizData) (*models.ChapterQuiz, error)
+	UpdateQuiz(ctx context.Context, courseID, chapterID, quizID int64, data models.UpdateQuizData) (*models.ChapterQuiz, error)
+	DeleteQuiz(ctx context.Context, courseID, chapterID, quizID int64) error
+
+	// Admin reporting, callers must check for admin rights.
+	GetProgressDashboard(ctx context.Context) (*models.StudentProgressDashboard, error)
+	ListStudentProgress(ctx context.Context, courseID int64, page, limit int) ([]models.StudentCourseProgress, int, error)
+}
+
+// Service provides business logic for courses.
+type Service struct {
+	repo    RepositoryInterface
+	userSvc user.ServiceInterface // Injected for creating chapter notes
+}
+
+// NewService creates a new course service.
+func NewService(repo RepositoryInterface, userSvc user.ServiceInterface) ServiceInterface {
+	return &Service{repo: repo, userSvc: userSvc}
+}
+
+func (s *Service) ListCourses(ctx context.Context, page, limit int) ([]models.Course, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	} // Default/max limit
+	courses, total, err := s.repo.ListCourses(ctx, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListCourses: %w", err)
+	}
+	return courses, total, nil
+}
+
+func (s *Service) GetCourseDetails(ctx context.Context, courseID int64, viewerID string) (*models.Course, error) {
+	course, err := s.repo.FindCourseByID(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetCourseDetails: %w", err)
+	}
+	chapters, err := s.repo.ListChapters(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetCourseDetails.Chapters: %w", err)
+	}
+	course.Chapters = chapters
+
+	if viewerID == "" {
+		return course, nil
+	}
+
+	course.IsEnrolled, err = s.repo.IsEnrolled(ctx, viewerID, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetCourseDetails.IsEnrolled: %w", err)
+	}
+	progressList, err := s.repo.ListProgressForCourse(ctx, viewerID, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetCourseDetails.Progress: %w", err)
+	}
+	progressByChapter := make(map[int64]models.ChapterProgress, len(progressList))
+	for _, progress := range progressList {
+		progressByChapter[progress.ChapterID] = progress
+	}
+	for i := range course.Chapters {
+		if progress, ok := progressByChapter[course.Chapters[i].ID]; ok {
+			course.Chapters[i].Progress = &progress
+		}
+	}
+	return course, nil
+}
+
+func (s *Service) GetChapterContent(ctx context.Context, courseID, chapterID int64, viewerID string) (*models.CourseChapter, error) {
+	chapter, err := s.repo.FindChapter(ctx, courseID, chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetChapterContent: %w", err)
+	}
+	if chapter.IsFreePreview {
+		if err := s.attachQuizzes(ctx, chapter); err != nil {
+			return nil, fmt.Errorf("service.GetChapterContent: %w", err)
+		}
+		return chapter, nil
+	}
+	if viewerID == "" {
+		return nil, models.ErrForbidden
+	}
+	return s.GetFullChapterContent(ctx, viewerID, courseID, chapterID)
+}
+
+func (s *Service) GetFullChapterContent(ctx context.Context, userID string, courseID, chapterID int64) (*models.CourseChapter, error) {
+	if err := s.requireEnrollment(ctx, userID, courseID); err != nil {
+		return nil, fmt.Errorf("service.GetFullChapterContent: %w", err)
+	}
+	chapter, err := s.repo.FindChapter(ctx, courseID, chapterID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetFullChapterContent: %w", err)
+	}
+
+	progress, err := s.repo.GetChapterProgress(ctx, userID, chapterID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("service.GetFullChapterContent.Progress: %w", err)
+	}
+	chapter.Progress = progress
+
+	if err := s.attachQuizzes(ctx, chapter); err != nil {
+		return nil, fmt.Errorf("service.GetFullChapterContent: %w", err)
+	}
+	return chapter, nil
+}
+
+// attachQuizzes adds the chapter's quizzes with the answers stripped.
+func (s *Service) attachQuizzes(ctx context.Context, chapter *models.CourseChapter) error {
+	quizzes, err := s.repo.ListChapterQuizzes(ctx, chapter.ID)
+	if err != nil {
+		return err
+	}
+	chapter.Quizzes = make([]models.ChapterQuiz, len(quizzes))
+	for i, quiz := range quizzes {
+		chapter.Quizzes[i] = quiz.WithoutAnswers()
+	}
+	return nil
+}
+
+func (s *Service) EnrollCourse(ctx context.Context, userID string, courseID int64) (*models.CourseEnrollment, error) {
+	if _, err := s.repo.FindCourseByID(ctx, courseID); err != nil {
+		return nil, fmt.Errorf("service.EnrollCourse: %w", err)
+	}
+	enrollment, err := s.repo.CreateEnrollment(ctx, userID, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("service.EnrollCourse: %w", err)
+	}
+	return enrollment, nil
+}
+
+func (s *Service) UpdateProgress(ctx context.Context, userID string, courseID, chapterID int64, data models.UpdateChapterProgressData) (*models.ChapterProgress, error) {
+	if _, err := s.repo.FindChapter(ctx, courseID, chapterID); err != nil {
+		return nil, fmt.Errorf("service.UpdateProgress: %w", err)
+	}
+	if err := s.requireEnrollment(ctx, userID, courseID); err != nil {
+		return nil, fmt.Errorf("service.UpdateProgress: %w", err)
+	}
+
+	progress, err := s.repo.UpsertChapterProgress(ctx, userID, chapterID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateProgress: %w", err)
+	}
+	if progress.CompletedAt != nil {
+		// Course completion is a derived flag, progress is already saved if this fails.
+		if err := s.repo.MarkEnrollmentCompletedIfDone(ctx, userID, courseID); err != nil {
+			log.Printf("ERROR: service.UpdateProgress.MarkEnrollmentCompletedIfDone for user %s, course %d: %v", userID, courseID, err)
+		}
+	}
+	return progress, nil
+}
+
+func (s *Service) AddNoteToChapter(ctx context.Context, userID string, courseID, chapterID int64, title, content string) (*models.UserNote, error) {
+	// Notes are allowed on any chapter the user can read.
+	chapter, err := s.GetChapterContent(ctx, courseID, chapterID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.AddNoteToChapter: %w", err)
+	}
+	if title == "" {
+		title = chapter.Title
+	}
+
+	entityType := noteEntityTypeChapter
+	entityID := int(chapterID)
+	note, err := s.userSvc.CreateUserNote(ctx, userID, models.CreateUserNoteData{
+		Title:      title,
+		Content:    content,
+		EntityType: &entityType,
+		EntityID:   &entityID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.AddNoteToChapter: %w", err)
+	}
+	return note, nil
+}
+
+// --- Quizzes ---
+
+func (s *Service) GetQuiz(ctx context.Context, userID string, courseID, chapterID, quizID int64) (*models.ChapterQuiz, error) {
+	if _, err := s.GetChapterContent(ctx, courseID, chapterID, userID); err != nil {
+		return nil, fmt.Errorf("service.GetQuiz: %w", err)
+	}
+	quiz, err := s.repo.FindQuiz(ctx, chapterID, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetQuiz: %w", err)
+	}
+	withoutAnswers := quiz.WithoutAnswers()
+	return &withoutAnswers, nil
+}
+
+// SubmitQuiz grades the answers, stores the attempt and, when the quiz is configured to,
+// marks the chapter complete on a passing attempt.
+func (s *Service) SubmitQuiz(ctx context.Context, userID string, courseID, chapterID, quizID int64, data models.SubmitQuizData) (*models.QuizAttempt, error) {
+	if _, err := s.GetChapterContent(ctx, courseID, chapterID, userID); err != nil {
+		return nil, fmt.Errorf("service.SubmitQuiz: %w", err)
+	}
+	quiz, err := s.repo.FindQuiz(ctx, chapterID, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SubmitQuiz: %w", err)
+	}
+
+	attempt := gradeQuiz(quiz, data.Answers)
+	attempt.UserID = userID
+	attempt, err = s.repo.CreateQuizAttempt(ctx, attempt)
+	if err != nil {
+		return nil, fmt.Errorf("service.SubmitQuiz: %w", err)
+	}
+
+	if attempt.Passed && quiz.MarksChapterComplete {
+		// The attempt is already recorded, so a failure here is logged rather than returned.
+		if _, err := s.repo.MarkChapterComplete(ctx, userID, chapterID); err != nil {
+			log.Printf("ERROR: service.SubmitQuiz.MarkChapterComplete for user %s, chapter %d: %v", userID, chapterID, err)
+			return attempt, nil
+		}
+		attempt.ChapterCompleted = true
+		if err := s.repo.MarkEnrollmentCompletedIfDone(ctx, userID, courseID); err != nil {
+			log.Printf("ERROR: service.SubmitQuiz.MarkEnrollmentCompletedIfDone for user %s, course %d: %v", userID, courseID, err)
+		}
+	}
+	return attempt, nil
+}
+
+func (s *Service) ListQuizAttempts(ctx context.Context, userID string, courseID, chapterID, quizID int64, page, limit int) ([]models.QuizAttempt, int, error) {
+	if _, err := s.repo.FindChapter(ctx, courseID, chapterID); err != nil {
+		return nil, 0, fmt.Errorf("service.ListQuizAttempts: %w", err)
+	}
+	if _, err := s.repo.FindQuiz(ctx, chapterID, quizID); err != nil {
+		return nil, 0, fmt.Errorf("service.ListQuizAttempts: %w", err)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	attempts, total, err := s.repo.ListQuizAttempts(ctx, userID, quizID, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListQuizAttempts: %w", err)
+	}
+	return attempts, total, nil
+}
+
+// --- Authoring ---
+
+func (s *Service) CreateCourse(ctx context.Context, authorID string, data models.CreateCourseData) (*models.Course, error) {
+	course, err := s.repo.CreateCourse(ctx, authorID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateCourse: %w", err)
+	}
+	return course, nil
+}
+
+func (s *Service) UpdateCourse(ctx context.Context, courseID int64, data models.UpdateCourseData) (*models.Course, error) {
+	course, err := s.repo.UpdateCourse(ctx, courseID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateCourse: %w", err)
+	}
+	return course, nil
+}
+
+func (s *Service) DeleteCourse(ctx context.Context, courseID int64) error {
+	if err := s.repo.DeleteCourse(ctx, courseID); err != nil {
+		return fmt.Errorf("service.DeleteCourse: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) CreateChapter(ctx context.Context, courseID int64, data models.CreateChapterData) (*models.CourseChapter, error) {
+	if _, err := s.repo.FindCourseByID(ctx, courseID); err != nil {
+		return nil, fmt.Errorf("service.CreateChapter: %w", err)
+	}
+	chapter, err := s.repo.CreateChapter(ctx, courseID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateChapter: %w", err)
+	}
+	return chapter, nil
+}
+
+func (s *Service) UpdateChapter(ctx context.Context, courseID, chapterID int64, data models.UpdateChapterData) (*models.CourseChapter, error) {
+	chapter, err := s.repo.UpdateChapter(ctx, courseID, chapterID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateChapter: %w", err)
+	}
+	return chapter, nil
+}
+
+func (s *Service) DeleteChapter(ctx context.Context, courseID, chapterID int64) error {
+	if err := s.repo.DeleteChapter(ctx, courseID, chapterID); err != nil {
+		return fmt.Errorf("service.DeleteChapter: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) CreateQuiz(ctx context.Context, courseID, chapterID int64, data models.CreateQuizData) (*models.ChapterQuiz, error) {
+	if _, err := s.repo.FindChapter(ctx, courseID, chapterID); err != nil {
+		return nil, fmt.Errorf("service.CreateQuiz: %w", err)
+	}
+	if err := validateQuestions(data.Questions); err != nil {
+		return nil, fmt.Errorf("service.CreateQuiz: %w", err)
+	}
+	if data.PassThreshold == nil {
+		threshold := models.DefaultPassThreshold
+		data.PassThreshold = &threshold
+	}
+	quiz, err := s.repo.CreateQuiz(ctx, chapterID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateQuiz: %w", err)
+	}
+	return quiz, nil
+}
+
+func (s *Service) UpdateQuiz(ctx context.Context, courseID, chapterID, quizID int64, data models.UpdateQuizData) (*models.ChapterQuiz, error) {
+	if _, err := s.repo.FindChapter(ctx, courseID, chapterID); err != nil {
+		return nil, fmt.Errorf("service.UpdateQuiz: %w", err)
+	}
+	if data.Questions != nil {
+		if err := validateQuestions(data.Questions); err != nil {
+			return nil, fmt.Errorf("service.UpdateQuiz: %w", err)
+		}
+	}
+	quiz, err := s.repo.UpdateQuiz(ctx, chapterID, quizID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateQuiz: %w", err)
+	}
+	return quiz, nil
+}
+
+func (s *Service) DeleteQuiz(ctx context.Context, courseID, chapterID, quizID int64) error {
+	if _, err := s.repo.FindChapter(ctx, courseID, chapterID); err != nil {
+		return fmt.Errorf("service.DeleteQuiz: %w", err)
+	}
+	if err := s.repo.DeleteQuiz(ctx, chapterID, quizID); err != nil {
+		return fmt.Errorf("service.DeleteQuiz: %w", err)
+	}
+	return nil
+}
+
+// --- Admin Reporting ---
+
+func (s *Service) GetProgressDashboard(ctx context.Context) (*models.StudentProgressDashboard, error) {
+	summaries, err := s.repo.ListCourseProgressSummaries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetProgressDashboard: %w", err)
+	}
+	dashboard := &models.StudentProgressDashboard{Courses: summaries}
+	for _, summary := range summaries {
+		dashboard.TotalEnrollments += summary.EnrolledCount
+		dashboard.CompletedEnrollments += summary.CompletedCount
+	}
+	return dashboard, nil
+}
+
+func (s *Service) ListStudentProgress(ctx context.Context, courseID int64, page, limit int) ([]models.StudentCourseProgress, int, error) {
+	if _, err := s.repo.FindCourseByID(ctx, courseID); err != nil {
+		return nil, 0, fmt.Errorf("service.ListStudentProgress: %w", err)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	students, total, err := s.repo.ListStudentProgress(ctx, courseID, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListStudentProgress: %w", err)
+	}
+	return students, total, nil
+}
+
+func (s *Service) requireEnrollment(ctx context.Context, userID string, courseID int64) error {
+	enrolled, err := s.repo.IsEnrolled(ctx, userID, courseID)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return models.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package course
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"strings"
+)
+
+// gradeQuiz scores answers against the quiz server-side. Every question counts towards the
+// possible points, so unanswered questions simply score zero. Multiple-choice questions are
+// all-or-nothing.
+func gradeQuiz(quiz *models.ChapterQuiz, answers []models.QuizAnswer) *models.QuizAttempt {
+	answersByQuestion := make(map[string]models.QuizAnswer, len(answers))
+	for _, answer := range answers {
+		answersByQuestion[answer.QuestionID] = answer
+	}
+
+	attempt := &models.QuizAttempt{
+		QuizID:  quiz.ID,
+		Answers: answers,
+		Results: make([]models.QuizQuestionResult, 0, len(quiz.Questions)),
+	}
+	for _, question := range quiz.Questions {
+		points := question.Points
+		if points <= 0 {
+			points = 1
+		}
+		answer, answered := answersByQuestion[question.ID]
+		correct := answered && isCorrect(question, answer)
+
+		result := models.QuizQuestionResult{
+			QuestionID:     question.ID,
+			Correct:        correct,
+			PointsPossible: points,
+			Explanation:    question.Explanation,
+		}
+		if correct {
+			result.PointsAwarded = points
+		}
+		attempt.PointsAwarded += result.PointsAwarded
+		attempt.PointsPossible += points
+		attempt.Results = append(attempt.Results, result)
+	}
+
+	if attempt.PointsPossible > 0 {
+		// Round half up so e.g. 2/3 shows as 67 rather than 66.
+		attempt.Score = (attempt.PointsAwarded*100*2 + attempt.PointsPossible) / (attempt.PointsPossible * 2)
+	}
+	attempt.Passed = attempt.PointsPossible > 0 && attempt.Score >= quiz.PassThreshold
+	return attempt
+}
+
+func isCorrect(question models.QuizQuestion, answer models.QuizAnswer) bool {
+	switch question.Type {
+	case models.QuestionSingleChoice:
+		return len(answer.OptionIDs) == 1 && len(question.CorrectOptionIDs) == 1 &&
+			answer.OptionIDs[0] == question.CorrectOptionIDs[0]
+	case models.QuestionMultipleChoice:
+		return sameSet(answer.OptionIDs, question.CorrectOptionIDs)
+	case models.QuestionTrueFalse:
+		return answer.Bool != nil && question.CorrectBool != nil && *answer.Bool == *question.CorrectBool
+	case models.QuestionShortAnswer:
+		given := normalizeShortAnswer(answer.Text)
+		if given == "" {
+			return false
+		}
+		for _, accepted := range question.AcceptedAnswers {
+			if given == normalizeShortAnswer(accepted) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false // Unknown question types can never be answered correctly
+	}
+}
+
+func sameSet(a, b []string) bool {
+	a = dedupeSorted(a)
+	b = dedupeSorted(b)
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func dedupeSorted(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	deduped := sorted[:0]
+	for i, value := range sorted {
+		if i == 0 || value != sorted[i-1] {
+			deduped = append(deduped, value)
+		}
+	}
+	return deduped
+}
+
+// normalizeShortAnswer lowercases and collapses whitespace so "Blue  and White" matches "blue and white".
+func normalizeShortAnswer(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
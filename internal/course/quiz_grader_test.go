@@ -0,0 +1,104 @@
+package course
+
+import (
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+)
+
+func TestGradeQuiz(t *testing.T) {
+	yes := true
+	multiple := models.QuizQuestion{ID: "pigments", Type: models.QuestionMultipleChoice, CorrectOptionIDs: []string{"cobalt", "iron"}}
+	single := models.QuizQuestion{ID: "kiln", Type: models.QuestionSingleChoice, CorrectOptionIDs: []string{"dragon"}}
+	truth := models.QuizQuestion{ID: "twice", Type: models.QuestionTrueFalse, CorrectBool: &yes}
+	city := models.QuizQuestion{ID: "city", Type: models.QuestionShortAnswer, AcceptedAnswers: []string{"Jingdezhen", "Blue and White"}}
+
+	tests := []struct {
+		name      string
+		questions []models.QuizQuestion
+		threshold int
+		answers   []models.QuizAnswer
+		awarded   int
+		possible  int
+		score     int
+		passed    bool
+	}{
+		{
+			"multiple choice needs every correct option",
+			[]models.QuizQuestion{multiple}, 60,
+			[]models.QuizAnswer{{QuestionID: "pigments", OptionIDs: []string{"cobalt"}}},
+			0, 1, 0, false,
+		},
+		{
+			"multiple choice rejects an extra option",
+			[]models.QuizQuestion{multiple}, 60,
+			[]models.QuizAnswer{{QuestionID: "pigments", OptionIDs: []string{"cobalt", "iron", "copper"}}},
+			0, 1, 0, false,
+		},
+		{
+			"multiple choice ignores order and repeats",
+			[]models.QuizQuestion{multiple}, 60,
+			[]models.QuizAnswer{{QuestionID: "pigments", OptionIDs: []string{"iron", "cobalt", "iron"}}},
+			1, 1, 100, true,
+		},
+		{
+			"two of three rounds half up",
+			[]models.QuizQuestion{single, truth, city}, 60,
+			[]models.QuizAnswer{
+				{QuestionID: "kiln", OptionIDs: []string{"dragon"}},
+				{QuestionID: "twice", Bool: &yes},
+			},
+			2, 3, 67, true,
+		},
+		{
+			"score equal to the threshold passes",
+			[]models.QuizQuestion{single, truth}, 50,
+			[]models.QuizAnswer{{QuestionID: "kiln", OptionIDs: []string{"dragon"}}},
+			1, 2, 50, true,
+		},
+		{
+			"score one below the threshold fails",
+			[]models.QuizQuestion{single, truth}, 51,
+			[]models.QuizAnswer{{QuestionID: "kiln", OptionIDs: []string{"dragon"}}},
+			1, 2, 50, false,
+		},
+		{
+			"zero points count as one",
+			[]models.QuizQuestion{single, {ID: "twice", Type: models.QuestionTrueFalse, CorrectBool: &yes, Points: 3}}, 60,
+			[]models.QuizAnswer{{QuestionID: "kiln", OptionIDs: []string{"dragon"}}},
+			1, 4, 25, false,
+		},
+		{
+			"short answer ignores case and whitespace",
+			[]models.QuizQuestion{city}, 60,
+			[]models.QuizAnswer{{QuestionID: "city", Text: "  blue   AND\twhite "}},
+			1, 1, 100, true,
+		},
+		{
+			"blank short answer is wrong",
+			[]models.QuizQuestion{city}, 60,
+			[]models.QuizAnswer{{QuestionID: "city", Text: "   "}},
+			0, 1, 0, false,
+		},
+		{
+			"no questions never passes",
+			nil, 0, nil,
+			0, 0, 0, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quiz := &models.ChapterQuiz{ID: 7, Questions: tt.questions, PassThreshold: tt.threshold}
+			attempt := gradeQuiz(quiz, tt.answers)
+			if attempt.PointsAwarded != tt.awarded || attempt.PointsPossible != tt.possible ||
+				attempt.Score != tt.score || attempt.Passed != tt.passed {
+				t.Errorf("gradeQuiz = %d/%d points, score %d, passed %v; want %d/%d, score %d, passed %v",
+					attempt.PointsAwarded, attempt.PointsPossible, attempt.Score, attempt.Passed,
+					tt.awarded, tt.possible, tt.score, tt.passed)
+			}
+			if attempt.QuizID != quiz.ID || len(attempt.Results) != len(tt.questions) {
+				t.Errorf("attempt has quiz %d and %d results, want quiz %d and %d", attempt.QuizID, len(attempt.Results), quiz.ID, len(tt.questions))
+			}
+		})
+	}
+}
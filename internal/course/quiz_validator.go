@@ -0,0 +1,64 @@
+package course
+
+import (
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"slices"
+	"strings"
+)
+
+// validateQuestions checks that every question can be graded by gradeQuiz: unique IDs, a known type
+// and an answer key that fits the type. Errors wrap models.ErrInvalidQuiz.
+func validateQuestions(questions []models.QuizQuestion) error {
+	seen := make(map[string]bool, len(questions))
+	for i, question := range questions {
+		if question.ID == "" || seen[question.ID] {
+			return fmt.Errorf("%w: question %d needs a unique id", models.ErrInvalidQuiz, i+1)
+		}
+		seen[question.ID] = true
+		if strings.TrimSpace(question.Text) == "" || question.Points < 0 {
+			return fmt.Errorf("%w: question %q needs text and non-negative points", models.ErrInvalidQuiz, question.ID)
+		}
+		if err := validateAnswerKey(question); err != nil {
+			return fmt.Errorf("%w: question %q %v", models.ErrInvalidQuiz, question.ID, err)
+		}
+	}
+	return nil
+}
+
+func validateAnswerKey(question models.QuizQuestion) error {
+	switch question.Type {
+	case models.QuestionSingleChoice, models.QuestionMultipleChoice:
+		optionIDs := make([]string, 0, len(question.Options))
+		for _, option := range question.Options {
+			if option.ID == "" || slices.Contains(optionIDs, option.ID) {
+				return errors.New("needs unique option ids")
+			}
+			optionIDs = append(optionIDs, option.ID)
+		}
+		if len(optionIDs) < 2 {
+			return errors.New("needs at least two options")
+		}
+		correct := dedupeSorted(question.CorrectOptionIDs)
+		if len(correct) == 0 || (question.Type == models.QuestionSingleChoice && len(correct) != 1) {
+			return errors.New("has the wrong number of correct options")
+		}
+		for _, id := range correct {
+			if !slices.Contains(optionIDs, id) {
+				return fmt.Errorf("marks unknown option %q as correct", id)
+			}
+		}
+	case models.QuestionTrueFalse:
+		if question.CorrectBool == nil {
+			return errors.New("needs correct_bool")
+		}
+	case models.QuestionShortAnswer:
+		if !slices.ContainsFunc(question.AcceptedAnswers, func(a string) bool { return normalizeShortAnswer(a) != "" }) {
+			return errors.New("needs an accepted answer")
+		}
+	default:
+		return fmt.Errorf("has unknown type %q", question.Type)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package course
+
+import (
+	"errors"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+)
+
+func TestValidateQuestions(t *testing.T) {
+	yes := true
+	options := []models.QuizOption{{ID: "a", Text: "Cobalt"}, {ID: "b", Text: "Copper"}}
+	valid := []models.QuizQuestion{
+		{ID: "q1", Type: models.QuestionSingleChoice, Text: "Which pigment?", Options: options, CorrectOptionIDs: []string{"a"}},
+		{ID: "q2", Type: models.QuestionMultipleChoice, Text: "Which ones?", Options: options, CorrectOptionIDs: []string{"a", "b"}},
+		{ID: "q3", Type: models.QuestionTrueFalse, Text: "Fired twice?", CorrectBool: &yes},
+		{ID: "q4", Type: models.QuestionShortAnswer, Text: "Name the city", AcceptedAnswers: []string{"Jingdezhen"}},
+	}
+	if err := validateQuestions(valid); err != nil {
+		t.Fatalf("validateQuestions(valid) = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		question models.QuizQuestion
+	}{
+		{"missing id", models.QuizQuestion{Type: models.QuestionTrueFalse, Text: "?", CorrectBool: &yes}},
+		{"duplicate id", models.QuizQuestion{ID: "q1", Type: models.QuestionTrueFalse, Text: "?", CorrectBool: &yes}},
+		{"no text", models.QuizQuestion{ID: "x", Type: models.QuestionTrueFalse, Text: " ", CorrectBool: &yes}},
+		{"negative points", models.QuizQuestion{ID: "x", Type: models.QuestionTrueFalse, Text: "?", Points: -1, CorrectBool: &yes}},
+		{"unknown type", models.QuizQuestion{ID: "x", Type: "essay", Text: "?"}},
+		{"single choice with two answers", models.QuizQuestion{ID: "x", Type: models.QuestionSingleChoice, Text: "?", Options: options, CorrectOptionIDs: []string{"a", "b"}}},
+		{"unknown correct option", models.QuizQuestion{ID: "x", Type: models.QuestionMultipleChoice, Text: "?", Options: options, CorrectOptionIDs: []string{"c"}}},
+		{"one option", models.QuizQuestion{ID: "x", Type: models.QuestionSingleChoice, Text: "?", Options: options[:1], CorrectOptionIDs: []string{"a"}}},
+		{"true/false without answer", models.QuizQuestion{ID: "x", Type: models.QuestionTrueFalse, Text: "?"}},
+		{"short answer without answers", models.QuizQuestion{ID: "x", Type: models.QuestionShortAnswer, Text: "?", AcceptedAnswers: []string{"  "}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questions := append(valid[:1:1], tt.question)
+			if err := validateQuestions(questions); !errors.Is(err, models.ErrInvalidQuiz) {
+				t.Errorf("validateQuestions = %v, want ErrInvalidQuiz", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package engage
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler handles HTTP requests for activities and their articles.
+type Handler struct {
+	service ServiceInterface
+}
+
+// NewHandler creates a new engage handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{service: service}
+}
+
+// GetActivities lists activities. Params: ?page=1&limit=20&type=...&when=upcoming|past
+func (h *Handler) GetActivities(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	filter := models.ActivityFilter{
+		Page:  page,
+		Limit: limit,
+		Type:  c.QueryParam("type"),
+		When:  c.QueryParam("when"),
+	}
+
+	activities, total, err := h.service.ListActivities(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(activities, page, limit, total))
+}
+
+// GetActivityArticle returns an activity, looked up by ID or article slug, with its article.
+// Corresponds to: engageGroup.GET("/:activity_id_or_slug", engageHandler.GetActivityArticle)
+func (h *Handler) GetActivityArticle(c echo.Context) error {
+	idOrSlug := c.Param("activity_id_or_slug")
+	if idOrSlug == "" {
+		return models.BadRequestError("Activity ID or slug parameter is required")
+	}
+
+	activity, err := h.service.GetActivityArticle(c.Request().Context(), idOrSlug)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, activity)
+}
@@ -0,0 +1,168 @@
+package engage
+
+import (
+	"context"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. The API only reads activities
+// and articles, so tests add them with AddActivity and AddArticle.
+type MemoryRepository struct {
+	mu         sync.Mutex
+	activities []models.Activity
+	articles   []models.Article
+	lastID     int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory engage repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+// AddActivity stores a, filling in the ID and timestamps, and returns the stored activity.
+func (r *MemoryRepository) AddActivity(a models.Activity) models.Activity {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	a.ID = r.lastID
+	a.CreatedAt, a.UpdatedAt = time.Now(), time.Now()
+	a.Article = nil
+	r.activities = append(r.activities, copyActivity(a))
+	return a
+}
+
+// AddArticle stores a, filling in the ID and timestamps. AuthorNickname is returned as given.
+func (r *MemoryRepository) AddArticle(a models.Article) models.Article {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	a.ID = r.lastID
+	a.CreatedAt, a.UpdatedAt = time.Now(), time.Now()
+	r.articles = append(r.articles, a)
+	return a
+}
+
+// finish is COALESCE(end_date, start_date).
+func finish(a models.Activity) *time.Time {
+	if a.EndDate != nil {
+		return a.EndDate
+	}
+	return a.StartDate
+}
+
+// copyActivity detaches the pointer fields so callers cannot change stored activities.
+func copyActivity(a models.Activity) models.Activity {
+	if a.StartDate != nil {
+		start := *a.StartDate
+		a.StartDate = &start
+	}
+	if a.EndDate != nil {
+		end := *a.EndDate
+		a.EndDate = &end
+	}
+	if a.Capacity != nil {
+		capacity := *a.Capacity
+		a.Capacity = &capacity
+	}
+	return a
+}
+
+// earlierFirst orders by t ascending with nil last, then by id ascending.
+func earlierFirst(ta, tb *time.Time, idA, idB int64) bool {
+	if (ta == nil) != (tb == nil) {
+		return ta != nil
+	}
+	if ta != nil && !ta.Equal(*tb) {
+		return ta.Before(*tb)
+	}
+	return idA < idB
+}
+
+// laterFirst orders by t descending with nil last, then by id descending.
+func laterFirst(ta, tb *time.Time, idA, idB int64) bool {
+	if (ta == nil) != (tb == nil) {
+		return ta != nil
+	}
+	if ta != nil && !ta.Equal(*tb) {
+		return ta.After(*tb)
+	}
+	return idA > idB
+}
+
+func (r *MemoryRepository) ListActivities(ctx context.Context, filter models.ActivityFilter) ([]models.Activity, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var matched []models.Activity
+	for _, a := range r.activities {
+		if filter.Type != "" && !strings.EqualFold(a.Type, filter.Type) {
+			continue
+		}
+		end := finish(a)
+		if filter.When == models.ActivityWhenUpcoming && (end == nil || end.Before(now)) {
+			continue
+		}
+		if filter.When == models.ActivityWhenPast && (end == nil || !end.Before(now)) {
+			continue
+		}
+		matched = append(matched, copyActivity(a))
+	}
+
+	switch filter.When {
+	case models.ActivityWhenUpcoming: // start_date ASC (NULLs last), id ASC
+		sort.Slice(matched, func(i, j int) bool {
+			return earlierFirst(matched[i].StartDate, matched[j].StartDate, matched[i].ID, matched[j].ID)
+		})
+	case models.ActivityWhenPast:
+		sort.Slice(matched, func(i, j int) bool {
+			return laterFirst(finish(matched[i]), finish(matched[j]), matched[i].ID, matched[j].ID)
+		})
+	default:
+		sort.Slice(matched, func(i, j int) bool {
+			return laterFirst(matched[i].StartDate, matched[j].StartDate, matched[i].ID, matched[j].ID)
+		})
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	page := []models.Activity{}
+	for i := offset; i < len(matched) && i < offset+filter.Limit; i++ {
+		page = append(page, matched[i])
+	}
+	return page, len(matched), nil
+}
+
+func (r *MemoryRepository) FindActivityByIDOrSlug(ctx context.Context, idOrSlug string) (*models.Activity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, convErr := strconv.ParseInt(idOrSlug, 10, 64)
+	for _, a := range r.activities {
+		if (convErr == nil && a.ID == id) || (convErr != nil && a.ArticleSlug == idOrSlug) {
+			a = copyActivity(a)
+			return &a, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *MemoryRepository) FindPublishedArticleBySlug(ctx context.Context, slug string) (*models.Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, a := range r.articles {
+		if a.Slug == slug && a.PublishedAt != nil && !a.PublishedAt.After(now) {
+			return &a, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
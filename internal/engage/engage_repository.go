@@ -0,0 +1,150 @@
+package engage
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines the methods for interacting with activity and article storage.
+type RepositoryInterface interface {
+	ListActivities(ctx context.Context, filter models.ActivityFilter) ([]models.Activity, int, error)
+	FindActivityByIDOrSlug(ctx context.Context, idOrSlug string) (*models.Activity, error)
+	FindPublishedArticleBySlug(ctx context.Context, slug string) (*models.Article, error)
+}
+
+// Repository provides access to the activity and article storage.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new engage repository.
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+// activitySelect is shared by every query returning models.Activity so the Scan order stays in one place.
+const activitySelect = `
+	SELECT e.id, e.title, e.type, COALESCE(e.brief_introduction, ''), COALESCE(e.photograph_url, ''),
+	       e.article_slug, e.start_date, e.end_date, COALESCE(e.location, ''), e.capacity,
+	       e.created_at, e.updated_at
+	FROM events e
+`
+
+func scanActivity(row pgx.Row) (*models.Activity, error) {
+	var activity models.Activity
+	err := row.Scan(
+		&activity.ID, &activity.Title, &activity.Type, &activity.BriefIntroduction, &activity.PhotographURL,
+		&activity.ArticleSlug, &activity.StartDate, &activity.EndDate, &activity.Location, &activity.Capacity,
+		&activity.CreatedAt, &activity.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// ListActivities lists activities. An activity counts as upcoming until its end date (or start
+// date when it has no end date) has passed; activities without dates only show up unfiltered.
+func (r *Repository) ListActivities(ctx context.Context, filter models.ActivityFilter) ([]models.Activity, int, error) {
+	var where []string
+	var args []interface{}
+	argIdx := 1
+
+	if filter.Type != "" {
+		where = append(where, fmt.Sprintf("LOWER(e.type) = LOWER($%d)", argIdx))
+		args = append(args, filter.Type)
+		argIdx++
+	}
+
+	orderBy := " ORDER BY e.start_date DESC NULLS LAST, e.id DESC"
+	switch filter.When {
+	case models.ActivityWhenUpcoming:
+		where = append(where, "COALESCE(e.end_date, e.start_date) >= NOW()")
+		orderBy = " ORDER BY e.start_date ASC, e.id ASC"
+	case models.ActivityWhenPast:
+		where = append(where, "COALESCE(e.end_date, e.start_date) < NOW()")
+		orderBy = " ORDER BY COALESCE(e.end_date, e.start_date) DESC, e.id DESC"
+	}
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+	query := activitySelect + whereClause + orderBy + fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	rows, err := r.db.Query(ctx, query, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListActivities: %w", err)
+	}
+	defer rows.Close()
+
+	activities := []models.Activity{}
+	for rows.Next() {
+		activity, err := scanActivity(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("repository.ListActivities.Scan: %w", err)
+		}
+		activities = append(activities, *activity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListActivities.Rows: %w", err)
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM events e" + whereClause
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListActivities.Count: %w", err)
+	}
+	return activities, total, nil
+}
+
+// FindActivityByIDOrSlug retrieves a single activity by its ID or its article slug.
+func (r *Repository) FindActivityByIDOrSlug(ctx context.Context, idOrSlug string) (*models.Activity, error) {
+	var row pgx.Row
+	// Try to parse idOrSlug as an integer (ID) first
+	if id, convErr := strconv.ParseInt(idOrSlug, 10, 64); convErr == nil {
+		row = r.db.QueryRow(ctx, activitySelect+" WHERE e.id = $1", id)
+	} else {
+		row = r.db.QueryRow(ctx, activitySelect+" WHERE e.article_slug = $1", idOrSlug)
+	}
+
+	activity, err := scanActivity(row)
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindActivityByIDOrSlug: %w", err)
+	}
+	return activity, nil
+}
+
+// FindPublishedArticleBySlug returns models.ErrNotFound for drafts and articles scheduled in the future.
+func (r *Repository) FindPublishedArticleBySlug(ctx context.Context, slug string) (*models.Article, error) {
+	var article models.Article
+	query := `
+		SELECT a.id, a.slug, a.title, a.content, a.author_id, COALESCE(u.nickname, ''),
+		       a.published_at, a.created_at, a.updated_at
+		FROM articles a
+		LEFT JOIN users u ON u.id = a.author_id
+		WHERE a.slug = $1 AND a.published_at IS NOT NULL AND a.published_at <= NOW()
+	`
+	err := r.db.QueryRow(ctx, query, slug).Scan(
+		&article.ID, &article.Slug, &article.Title, &article.Content, &article.AuthorID, &article.AuthorNickname,
+		&article.PublishedAt, &article.CreatedAt, &article.UpdatedAt,
+	)
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindPublishedArticleBySlug: %w", err)
+	}
+	return &article, nil
+}
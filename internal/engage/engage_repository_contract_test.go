@@ -0,0 +1,165 @@
+package engage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. The repository only reads, so each subtest gets an empty store and
+// functions adding an activity (returning its ID) and an article.
+type fixture struct {
+	repo        RepositoryInterface
+	addActivity func(a models.Activity) int64
+	addArticle  func(a models.Article)
+}
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		db := pgtest.NewDB(t)
+		ctx := context.Background()
+		return fixture{
+			repo: NewRepository(db),
+			addActivity: func(a models.Activity) int64 {
+				err := db.QueryRow(ctx,
+					`INSERT INTO events (title, type, brief_introduction, photograph_url, article_slug, start_date, end_date, location, capacity)
+					 VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''), $9) RETURNING id`,
+					a.Title, a.Type, a.BriefIntroduction, a.PhotographURL, a.ArticleSlug, a.StartDate, a.EndDate, a.Location, a.Capacity,
+				).Scan(&a.ID)
+				if err != nil {
+					t.Fatalf("insert event: %v", err)
+				}
+				return a.ID
+			},
+			addArticle: func(a models.Article) {
+				if _, err := db.Exec(ctx, `INSERT INTO articles (slug, title, content, published_at) VALUES ($1, $2, $3, $4)`,
+					a.Slug, a.Title, a.Content, a.PublishedAt); err != nil {
+					t.Fatalf("insert article: %v", err)
+				}
+			},
+		}
+	})
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		repo := NewMemoryRepository()
+		return fixture{
+			repo:        repo,
+			addActivity: func(a models.Activity) int64 { return repo.AddActivity(a).ID },
+			addArticle:  func(a models.Article) { repo.AddArticle(a) },
+		}
+	})
+}
+
+func testRepositoryContract(t *testing.T, newFixture func(t *testing.T) fixture) {
+	ctx := context.Background()
+	at := func(d time.Duration) *time.Time {
+		ts := time.Now().Add(d).Truncate(time.Second)
+		return &ts
+	}
+	day := 24 * time.Hour
+	list := func(t *testing.T, f fixture, filter models.ActivityFilter) ([]int64, int) {
+		t.Helper()
+		if filter.Page == 0 {
+			filter.Page, filter.Limit = 1, 10
+		}
+		activities, total, err := f.repo.ListActivities(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListActivities(%+v): %v", filter, err)
+		}
+		ids := []int64{}
+		for _, a := range activities {
+			ids = append(ids, a.ID)
+		}
+		return ids, total
+	}
+
+	t.Run("ListActivities", func(t *testing.T) {
+		f := newFixture(t)
+		add := func(slug, kind string, start, end *time.Time) int64 {
+			return f.addActivity(models.Activity{Title: slug, Type: kind, ArticleSlug: slug, StartDate: start, EndDate: end})
+		}
+		finished := add("finished", "Workshop", at(-10*day), at(-9*day))
+		running := add("running", "Exhibition", at(-2*day), at(3*day))
+		soon := add("soon", "workshop", at(day), nil)
+		later := add("later", "Festival", at(5*day), at(6*day))
+		undated := add("undated", "Workshop", nil, nil)
+		yesterday := add("yesterday", "Fair", at(-day), nil)
+
+		tests := []struct {
+			name   string
+			filter models.ActivityFilter
+			want   []int64
+		}{
+			{"all", models.ActivityFilter{}, []int64{later, soon, yesterday, running, finished, undated}},
+			{"upcoming", models.ActivityFilter{When: models.ActivityWhenUpcoming}, []int64{running, soon, later}},
+			{"past", models.ActivityFilter{When: models.ActivityWhenPast}, []int64{yesterday, finished}},
+			{"type ignores case", models.ActivityFilter{Type: "WORKSHOP"}, []int64{soon, finished, undated}},
+			{"type and when", models.ActivityFilter{Type: "workshop", When: models.ActivityWhenUpcoming}, []int64{soon}},
+			{"unknown type", models.ActivityFilter{Type: "Lecture"}, []int64{}},
+		}
+		for _, tt := range tests {
+			ids, total := list(t, f, tt.filter)
+			if total != len(tt.want) || !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("ListActivities(%s) = %v (total %d), want %v", tt.name, ids, total, tt.want)
+			}
+		}
+
+		ids, total := list(t, f, models.ActivityFilter{Page: 2, Limit: 4})
+		if total != 6 || !reflect.DeepEqual(ids, []int64{finished, undated}) {
+			t.Errorf("ListActivities(page 2) = %v (total %d), want [%d %d] of 6", ids, total, finished, undated)
+		}
+	})
+
+	t.Run("FindActivity", func(t *testing.T) {
+		f := newFixture(t)
+		capacity := 20
+		id := f.addActivity(models.Activity{
+			Title: "Kiln opening", Type: "Kiln Visit", BriefIntroduction: "Watch the wood kiln open.",
+			ArticleSlug: "kiln-opening", StartDate: at(day), Location: "Sanbao", Capacity: &capacity,
+		})
+		for _, key := range []string{strconv.FormatInt(id, 10), "kiln-opening"} {
+			got, err := f.repo.FindActivityByIDOrSlug(ctx, key)
+			if err != nil {
+				t.Fatalf("FindActivityByIDOrSlug(%q): %v", key, err)
+			}
+			if got.ID != id || got.Title != "Kiln opening" || got.Location != "Sanbao" || got.PhotographURL != "" ||
+				got.Capacity == nil || *got.Capacity != 20 || got.StartDate == nil || got.EndDate != nil {
+				t.Errorf("FindActivityByIDOrSlug(%q) = %+v", key, got)
+			}
+		}
+		for _, key := range []string{"999", "no-such-activity"} {
+			if _, err := f.repo.FindActivityByIDOrSlug(ctx, key); !errors.Is(err, models.ErrNotFound) {
+				t.Errorf("FindActivityByIDOrSlug(%q): error = %v, want ErrNotFound", key, err)
+			}
+		}
+	})
+
+	t.Run("FindPublishedArticle", func(t *testing.T) {
+		f := newFixture(t)
+		f.addArticle(models.Article{Slug: "published", Title: "Published", Content: "Kiln notes.", PublishedAt: at(-time.Hour)})
+		f.addArticle(models.Article{Slug: "draft", Title: "Draft", Content: "Kiln notes."})
+		f.addArticle(models.Article{Slug: "scheduled", Title: "Scheduled", Content: "Kiln notes.", PublishedAt: at(day)})
+
+		got, err := f.repo.FindPublishedArticleBySlug(ctx, "published")
+		if err != nil || got.Title != "Published" || got.Content != "Kiln notes." || got.AuthorID != nil || got.AuthorNickname != "" {
+			t.Errorf("FindPublishedArticleBySlug(published) = (%+v, %v)", got, err)
+		}
+		for _, slug := range []string{"draft", "scheduled", "missing"} {
+			if _, err := f.repo.FindPublishedArticleBySlug(ctx, slug); !errors.Is(err, models.ErrNotFound) {
+				t.Errorf("FindPublishedArticleBySlug(%s): error = %v, want ErrNotFound", slug, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package engage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"strings"
+)
+
+// ServiceInterface defines the methods for engage (activities and articles) business logic.
+type ServiceInterface interface {
+	ListActivities(ctx context.Context, filter models.ActivityFilter) ([]models.Activity, int, error)
+	// GetActivityArticle returns the activity with its published article attached.
+	// Article is nil while the article is still a draft.
+	GetActivityArticle(ctx context.Context, idOrSlug string) (*models.Activity, error)
+}
+
+// Service provides business logic for activities and articles.
+type Service struct {
+	repo RepositoryInterface
+}
+
+// NewService creates a new engage service.
+func NewService(repo RepositoryInterface) ServiceInterface {
+	return &Service{repo: repo}
+}
+
+func (s *Service) ListActivities(ctx context.Context, filter models.ActivityFilter) ([]models.Activity, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	} // Default/max limit
+	if filter.When != models.ActivityWhenUpcoming && filter.When != models.ActivityWhenPast {
+		filter.When = ""
+	}
+	filter.Type = strings.TrimSpace(filter.Type)
+
+	activities, total, err := s.repo.ListActivities(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListActivities: %w", err)
+	}
+	return activities, total, nil
+}
+
+func (s *Service) GetActivityArticle(ctx context.Context, idOrSlug string) (*models.Activity, error) {
+	activity, err := s.repo.FindActivityByIDOrSlug(ctx, idOrSlug)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetActivityArticle: %w", err)
+	}
+
+	article, err := s.repo.FindPublishedArticleBySlug(ctx, activity.ArticleSlug)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("service.GetActivityArticle.Article: %w", err)
+	}
+	activity.Article = article
+	return activity, nil
+}
@@ -0,0 +1,276 @@
+package forum
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler handles HTTP requests for the forum.
+type Handler struct {
+	service  ServiceInterface
+	validate *validator.Validate
+}
+
+// NewHandler creates a new forum handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// --- Public Routes ---
+
+// GetPosts lists posts. Params: ?page=1&limit=10&sort=latest|hottest&tag=...&category=...
+func (h *Handler) GetPosts(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	filter := models.ForumPostFilter{
+		Page:  page,
+		Limit: limit,
+		Sort:  c.QueryParam("sort"),
+		Tag:   c.QueryParam("tag"),
+	}
+	if categoryStr := c.QueryParam("category"); categoryStr != "" {
+		categoryID, err := strconv.Atoi(categoryStr)
+		if err != nil {
+			return models.BadRequestError("Invalid category ID")
+		}
+		filter.CategoryID = categoryID
+	}
+
+	posts, total, err := h.service.ListPosts(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(posts, page, limit, total))
+}
+
+// SearchPosts searches post titles and contents. Param: ?q=keyword
+func (h *Handler) SearchPosts(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	posts, total, err := h.service.SearchPosts(c.Request().Context(), c.QueryParam("q"), page, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(posts, page, limit, total))
+}
+
+func (h *Handler) GetPostByID(c echo.Context) error {
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+
+	post, err := h.service.GetPostDetail(c.Request().Context(), postID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, post)
+}
+
+func (h *Handler) GetTopicsTagCloud(c echo.Context) error {
+	topics, err := h.service.GetTopicsTagCloud(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, topics)
+}
+
+func (h *Handler) GetCategories(c echo.Context) error {
+	categories, err := h.service.GetCategories(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, categories)
+}
+
+// --- Protected Routes ---
+
+func (h *Handler) CreatePost(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+
+	var req models.CreateForumPostData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	post, err := h.service.CreatePost(c.Request().Context(), userID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, post)
+}
+
+func (h *Handler) UpdatePost(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+
+	var req models.UpdateForumPostData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	post, err := h.service.UpdatePost(c.Request().Context(), userID, postID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, post)
+}
+
+func (h *Handler) DeletePost(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+
+	err = h.service.DeletePost(c.Request().Context(), userID, utils.GetUserRoleFromContext(c), postID)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) CreateComment(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+
+	var req models.CreateForumCommentData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	comment, err := h.service.CreateComment(c.Request().Context(), userID, postID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, comment)
+}
+
+func (h *Handler) UpdateComment(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid comment ID")
+	}
+
+	var req models.UpdateForumCommentData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	comment, err := h.service.UpdateComment(c.Request().Context(), userID, commentID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, comment)
+}
+
+func (h *Handler) DeleteComment(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid comment ID")
+	}
+
+	err = h.service.DeleteComment(c.Request().Context(), userID, utils.GetUserRoleFromContext(c), commentID)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LikePost toggles the current user's like on a post.
+func (h *Handler) LikePost(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+
+	result, err := h.service.LikePost(c.Request().Context(), userID, postID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// SavePost toggles whether the post is in the current user's saved list.
+func (h *Handler) SavePost(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	postID, err := strconv.ParseInt(c.Param("post_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid post ID")
+	}
+
+	result, err := h.service.SavePost(c.Request().Context(), userID, postID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// LikeComment toggles the current user's like on a comment.
+func (h *Handler) LikeComment(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid comment ID")
+	}
+
+	result, err := h.service.LikeComment(c.Request().Context(), userID, commentID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
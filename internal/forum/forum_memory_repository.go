@@ -0,0 +1,502 @@
+package forum
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository. Authors and categories, which the API does not create, are added with AddUser and AddCategory.
+type MemoryRepository struct {
+	mu           sync.Mutex
+	posts        map[int64]*models.ForumPost
+	comments     map[int64]*models.ForumComment
+	categories   map[int]*models.ForumCategory
+	nicknames    map[string]string
+	postLikes    map[interaction]bool
+	postSaves    map[interaction]bool
+	commentLikes map[interaction]bool
+	lastPost     int64
+	lastComment  int64
+	lastCategory int
+}
+
+// interaction is a row of one of the (user, post or comment) junction tables.
+type interaction struct {
+	userID   string
+	entityID int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory forum.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		posts:        map[int64]*models.ForumPost{},
+		comments:     map[int64]*models.ForumComment{},
+		categories:   map[int]*models.ForumCategory{},
+		nicknames:    map[string]string{},
+		postLikes:    map[interaction]bool{},
+		postSaves:    map[interaction]bool{},
+		commentLikes: map[interaction]bool{},
+	}
+}
+
+// AddUser registers userID with the nickname shown on their posts and comments.
+func (r *MemoryRepository) AddUser(userID, nickname string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nicknames[userID] = nickname
+}
+
+// AddCategory stores a category, filling in its ID, and returns it.
+func (r *MemoryRepository) AddCategory(c models.ForumCategory) models.ForumCategory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastCategory++
+	c.ID = r.lastCategory
+	r.categories[c.ID] = &c
+	return c
+}
+
+// viewPost returns the post as postSelect does, with the joined names and counts filled in.
+func (r *MemoryRepository) viewPost(p *models.ForumPost) *models.ForumPost {
+	out := *p
+	out.AuthorNickname = r.nicknames[p.UserID]
+	out.CategoryName = ""
+	if c, ok := r.categories[p.CategoryID]; ok {
+		out.CategoryName = c.Name
+	}
+	out.Tags = append([]string{}, p.Tags...)
+	out.CommentCount = 0
+	for _, c := range r.comments {
+		if c.PostID == p.ID {
+			out.CommentCount++
+		}
+	}
+	out.LikeCount = countInteractions(r.postLikes, p.ID)
+	return &out
+}
+
+func (r *MemoryRepository) viewComment(c *models.ForumComment) *models.ForumComment {
+	out := *c
+	out.AuthorNickname = r.nicknames[c.UserID]
+	if c.ParentCommentID != nil {
+		parent := *c.ParentCommentID
+		out.ParentCommentID = &parent
+	}
+	out.LikeCount = countInteractions(r.commentLikes, c.ID)
+	return &out
+}
+
+func countInteractions(rows map[interaction]bool, entityID int64) int {
+	count := 0
+	for row := range rows {
+		if row.entityID == entityID {
+			count++
+		}
+	}
+	return count
+}
+
+// postTags trims the names, drops empty ones and duplicates, and sorts them like the tags ARRAY.
+func postTags(tags []string) []string {
+	linked := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !containsString(linked, tag) {
+			linked = append(linked, tag)
+		}
+	}
+	sort.Strings(linked)
+	return linked
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// pagePosts sorts the posts with less and returns the requested page of their views.
+func (r *MemoryRepository) pagePosts(matched []*models.ForumPost, less func(a, b *models.ForumPost) bool, page, limit int) []models.ForumPost {
+	sort.Slice(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+	posts := []models.ForumPost{}
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		posts = append(posts, *r.viewPost(matched[i]))
+	}
+	return posts
+}
+
+func newerFirst(a, b *models.ForumPost) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+
+// --- Posts ---
+
+func (r *MemoryRepository) ListPosts(ctx context.Context, filter models.ForumPostFilter) ([]models.ForumPost, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []*models.ForumPost{}
+	for _, p := range r.posts {
+		if p.IsArchived || (filter.CategoryID > 0 && p.CategoryID != filter.CategoryID) ||
+			(filter.Tag != "" && !containsString(p.Tags, filter.Tag)) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+
+	// Pinned posts always float to the top, the chosen sort applies below them.
+	less := func(a, b *models.ForumPost) bool {
+		if a.IsPinned != b.IsPinned {
+			return a.IsPinned
+		}
+		if filter.Sort == models.ForumSortHottest {
+			va, vb := r.viewPost(a), r.viewPost(b)
+			if scoreA, scoreB := va.LikeCount+va.CommentCount, vb.LikeCount+vb.CommentCount; scoreA != scoreB {
+				return scoreA > scoreB
+			}
+			if !a.LastActivityAt.Equal(b.LastActivityAt) {
+				return a.LastActivityAt.After(b.LastActivityAt)
+			}
+			return a.ID > b.ID
+		}
+		return newerFirst(a, b)
+	}
+	return r.pagePosts(matched, less, filter.Page, filter.Limit), len(matched), nil
+}
+
+func (r *MemoryRepository) SearchPosts(ctx context.Context, keyword string, page, limit int) ([]models.ForumPost, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keyword = strings.ToLower(keyword)
+	matched := []*models.ForumPost{}
+	for _, p := range r.posts {
+		if !p.IsArchived && (strings.Contains(strings.ToLower(p.Title), keyword) || strings.Contains(strings.ToLower(p.Content), keyword)) {
+			matched = append(matched, p)
+		}
+	}
+	return r.pagePosts(matched, newerFirst, page, limit), len(matched), nil
+}
+
+func (r *MemoryRepository) FindPostByID(ctx context.Context, postID int64) (*models.ForumPost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.posts[postID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.viewPost(p), nil
+}
+
+func (r *MemoryRepository) IncrementViewCount(ctx context.Context, postID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.posts[postID]; ok {
+		p.ViewCount++
+	}
+	return nil
+}
+
+func (r *MemoryRepository) CreatePost(ctx context.Context, userID string, data models.CreateForumPostData) (*models.ForumPost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.nicknames[userID]; !ok {
+		return nil, fmt.Errorf("repository.CreatePost: user %q does not exist", userID) // The users foreign key
+	}
+	if _, ok := r.categories[data.CategoryID]; !ok {
+		return nil, fmt.Errorf("repository.CreatePost: category %d does not exist", data.CategoryID)
+	}
+	r.lastPost++
+	now := time.Now()
+	p := &models.ForumPost{
+		ID: r.lastPost, UserID: userID, Title: data.Title, Content: data.Content, CategoryID: data.CategoryID,
+		Tags: postTags(data.Tags), CreatedAt: now, UpdatedAt: now, LastActivityAt: now,
+	}
+	r.posts[p.ID] = p
+	return r.viewPost(p), nil
+}
+
+func (r *MemoryRepository) UpdatePost(ctx context.Context, postID int64, data models.UpdateForumPostData) (*models.ForumPost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.posts[postID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if data.CategoryID != nil {
+		if _, ok := r.categories[*data.CategoryID]; !ok {
+			return nil, fmt.Errorf("repository.UpdatePost: category %d does not exist", *data.CategoryID)
+		}
+		p.CategoryID = *data.CategoryID
+	}
+	if data.Title != nil {
+		p.Title = *data.Title
+	}
+	if data.Content != nil {
+		p.Content = *data.Content
+	}
+	p.UpdatedAt = time.Now()
+	if data.Tags != nil {
+		p.Tags = postTags(data.Tags)
+	}
+	return r.viewPost(p), nil
+}
+
+func (r *MemoryRepository) DeletePost(ctx context.Context, postID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.posts[postID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.posts, postID)
+	for id, c := range r.comments {
+		if c.PostID == postID {
+			r.deleteComment(id)
+		}
+	}
+	deleteInteractions(r.postLikes, postID)
+	deleteInteractions(r.postSaves, postID)
+	return nil
+}
+
+func deleteInteractions(rows map[interaction]bool, entityID int64) {
+	for row := range rows {
+		if row.entityID == entityID {
+			delete(rows, row)
+		}
+	}
+}
+
+func (r *MemoryRepository) SetPinned(ctx context.Context, postID int64, pinned bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.posts[postID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	p.IsPinned = pinned
+	return nil
+}
+
+func (r *MemoryRepository) SetArchived(ctx context.Context, postID int64, archived bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.posts[postID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	p.IsArchived = archived
+	return nil
+}
+
+// --- Categories and Tags ---
+
+func (r *MemoryRepository) ListCategories(ctx context.Context) ([]models.ForumCategory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	categories := []models.ForumCategory{}
+	for _, c := range r.categories {
+		categories = append(categories, *c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].DisplayOrder != categories[j].DisplayOrder {
+			return categories[i].DisplayOrder < categories[j].DisplayOrder
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+func (r *MemoryRepository) CategoryExists(ctx context.Context, categoryID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.categories[categoryID]
+	return ok, nil
+}
+
+func (r *MemoryRepository) GetTopicsTagCloud(ctx context.Context, limit int) ([]models.ForumTopic, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := map[string]int{}
+	for _, p := range r.posts {
+		if p.IsArchived {
+			continue
+		}
+		for _, tag := range p.Tags {
+			counts[tag]++
+		}
+	}
+	topics := []models.ForumTopic{}
+	for tag, count := range counts {
+		topics = append(topics, models.ForumTopic{Tag: tag, PostCount: count})
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i].PostCount != topics[j].PostCount {
+			return topics[i].PostCount > topics[j].PostCount
+		}
+		return topics[i].Tag < topics[j].Tag
+	})
+	if len(topics) > limit {
+		topics = topics[:limit]
+	}
+	return topics, nil
+}
+
+// --- Comments ---
+
+func (r *MemoryRepository) ListComments(ctx context.Context, postID int64) ([]models.ForumComment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []*models.ForumComment{}
+	for _, c := range r.comments {
+		if c.PostID == postID {
+			matched = append(matched, c)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	comments := []models.ForumComment{}
+	for _, c := range matched {
+		comments = append(comments, *r.viewComment(c))
+	}
+	return comments, nil
+}
+
+func (r *MemoryRepository) FindCommentByID(ctx context.Context, commentID int64) (*models.ForumComment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.comments[commentID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.viewComment(c), nil
+}
+
+func (r *MemoryRepository) CreateComment(ctx context.Context, postID int64, userID string, data models.CreateForumCommentData) (*models.ForumComment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, ok := r.posts[postID]
+	if _, known := r.nicknames[userID]; !ok || !known {
+		return nil, fmt.Errorf("repository.CreateComment: user %q or post %d does not exist", userID, postID) // The foreign keys
+	}
+	if data.ParentCommentID != nil && r.comments[*data.ParentCommentID] == nil {
+		return nil, fmt.Errorf("repository.CreateComment: parent comment %d does not exist", *data.ParentCommentID)
+	}
+	r.lastComment++
+	now := time.Now()
+	c := &models.ForumComment{ID: r.lastComment, PostID: postID, UserID: userID, Content: data.Content, CreatedAt: now, UpdatedAt: now}
+	if data.ParentCommentID != nil {
+		parent := *data.ParentCommentID
+		c.ParentCommentID = &parent
+	}
+	r.comments[c.ID] = c
+	// A new comment bumps the post for the "latest activity" ordering.
+	post.LastActivityAt = now
+	return r.viewComment(c), nil
+}
+
+func (r *MemoryRepository) UpdateComment(ctx context.Context, commentID int64, data models.UpdateForumCommentData) (*models.ForumComment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.comments[commentID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	c.Content = data.Content
+	c.UpdatedAt = time.Now()
+	return r.viewComment(c), nil
+}
+
+func (r *MemoryRepository) DeleteComment(ctx context.Context, commentID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.comments[commentID]; !ok {
+		return models.ErrNotFound
+	}
+	r.deleteComment(commentID)
+	return nil
+}
+
+// deleteComment removes the comment, its likes and, like parent_comment_id's ON DELETE CASCADE, its replies.
+func (r *MemoryRepository) deleteComment(commentID int64) {
+	delete(r.comments, commentID)
+	deleteInteractions(r.commentLikes, commentID)
+	for id, c := range r.comments {
+		if c.ParentCommentID != nil && *c.ParentCommentID == commentID {
+			r.deleteComment(id)
+		}
+	}
+}
+
+// --- Interactions ---
+
+func (r *MemoryRepository) TogglePostLike(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.posts[postID]
+	result, err := r.toggle(r.postLikes, userID, postID, ok)
+	if err != nil {
+		return nil, fmt.Errorf("repository.TogglePostLike: %w", err)
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) TogglePostSave(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.posts[postID]
+	result, err := r.toggle(r.postSaves, userID, postID, ok)
+	if err != nil {
+		return nil, fmt.Errorf("repository.TogglePostSave: %w", err)
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) ToggleCommentLike(ctx context.Context, userID string, commentID int64) (*models.ToggleResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.comments[commentID]
+	result, err := r.toggle(r.commentLikes, userID, commentID, ok)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ToggleCommentLike: %w", err)
+	}
+	return result, nil
+}
+
+// toggle removes the (user, entity) row if present, otherwise inserts it when both the user and the
+// entity exist, as the foreign keys require.
+func (r *MemoryRepository) toggle(rows map[interaction]bool, userID string, entityID int64, entityExists bool) (*models.ToggleResult, error) {
+	row := interaction{userID, entityID}
+	result := &models.ToggleResult{}
+	if rows[row] {
+		delete(rows, row)
+	} else {
+		if _, known := r.nicknames[userID]; !known || !entityExists {
+			return nil, fmt.Errorf("user %q or entity %d does not exist", userID, entityID)
+		}
+		rows[row] = true
+		result.Active = true
+	}
+	result.Count = countInteractions(rows, entityID)
+	return result, nil
+}
@@ -0,0 +1,561 @@
+package forum
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines the methods for interacting with forum storage.
+type RepositoryInterface interface {
+	// Posts
+	ListPosts(ctx context.Context, filter models.ForumPostFilter) ([]models.ForumPost, int, error)
+	SearchPosts(ctx context.Context, keyword string, page, limit int) ([]models.ForumPost, int, error)
+	FindPostByID(ctx context.Context, postID int64) (*models.ForumPost, error)
+	IncrementViewCount(ctx context.Context, postID int64) error
+	CreatePost(ctx context.Context, userID string, data models.CreateForumPostData) (*models.ForumPost, error)
+	UpdatePost(ctx context.Context, postID int64, data models.UpdateForumPostData) (*models.ForumPost, error)
+	DeletePost(ctx context.Context, postID int64) error
+	SetPinned(ctx context.Context, postID int64, pinned bool) error
+	SetArchived(ctx context.Context, postID int64, archived bool) error
+
+	// Categories and tags
+	ListCategories(ctx context.Context) ([]models.ForumCategory, error)
+	CategoryExists(ctx context.Context, categoryID int) (bool, error)
+	GetTopicsTagCloud(ctx context.Context, limit int) ([]models.ForumTopic, error)
+
+	// Comments
+	ListComments(ctx context.Context, postID int64) ([]models.ForumComment, error)
+	FindCommentByID(ctx context.Context, commentID int64) (*models.ForumComment, error)
+	CreateComment(ctx context.Context, postID int64, userID string, data models.CreateForumCommentData) (*models.ForumComment, error)
+	UpdateComment(ctx context.Context, commentID int64, data models.UpdateForumCommentData) (*models.ForumComment, error)
+	DeleteComment(ctx context.Context, commentID int64) error
+
+	// Interactions
+	TogglePostLike(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error)
+	TogglePostSave(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error)
+	ToggleCommentLike(ctx context.Context, userID string, commentID int64) (*models.ToggleResult, error)
+}
+
+// Repository provides access to the forum storage.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new forum repository.
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+// Comment and like counts of the post fp. ORDER BY cannot use the output aliases inside an expression,
+// so the hottest sort repeats them.
+const (
+	commentCount = `(SELECT COUNT(*) FROM forum_comments c WHERE c.post_id = fp.id)`
+	likeCount    = `(SELECT COUNT(*) FROM forum_post_likes l WHERE l.post_id = fp.id)`
+)
+
+// postSelect is shared by every query returning models.ForumPost so the Scan order stays in one place.
+const postSelect = `
+	SELECT fp.id, fp.user_id, COALESCE(u.nickname, ''), fp.title, fp.content,
+	       COALESCE(fp.category_id, 0), COALESCE(fc.name, ''),
+	       ARRAY(SELECT t.name FROM forum_post_tags fpt JOIN tags t ON t.id = fpt.tag_id
+	             WHERE fpt.post_id = fp.id ORDER BY t.name) AS tags,
+	       COALESCE(fp.is_pinned, FALSE), COALESCE(fp.is_archived, FALSE), COALESCE(fp.view_count, 0),
+	       ` + commentCount + ` AS comment_count,
+	       ` + likeCount + ` AS like_count,
+	       fp.created_at, fp.updated_at, COALESCE(fp.last_activity_at, fp.created_at)
+	FROM forum_posts fp
+	JOIN users u ON u.id = fp.user_id
+	LEFT JOIN forum_categories fc ON fc.id = fp.category_id
+`
+
+func scanPost(row pgx.Row) (*models.ForumPost, error) {
+	var post models.ForumPost
+	err := row.Scan(
+		&post.ID, &post.UserID, &post.AuthorNickname, &post.Title, &post.Content,
+		&post.CategoryID, &post.CategoryName, &post.Tags,
+		&post.IsPinned, &post.IsArchived, &post.ViewCount, &post.CommentCount, &post.LikeCount,
+		&post.CreatedAt, &post.UpdatedAt, &post.LastActivityAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if post.Tags == nil {
+		post.Tags = []string{}
+	}
+	return &post, nil
+}
+
+// --- Posts ---
+
+func (r *Repository) ListPosts(ctx context.Context, filter models.ForumPostFilter) ([]models.ForumPost, int, error) {
+	where := []string{"COALESCE(fp.is_archived, FALSE) = FALSE"}
+	var args []interface{}
+	argIdx := 1
+
+	if filter.CategoryID > 0 {
+		where = append(where, fmt.Sprintf("fp.category_id = $%d", argIdx))
+		args = append(args, filter.CategoryID)
+		argIdx++
+	}
+	if filter.Tag != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (SELECT 1 FROM forum_post_tags fpt JOIN tags t ON t.id = fpt.tag_id
+		                                    WHERE fpt.post_id = fp.id AND t.name = $%d)`, argIdx))
+		args = append(args, filter.Tag)
+		argIdx++
+	}
+	whereClause := " WHERE " + strings.Join(where, " AND ")
+
+	// Pinned posts always float to the top, the chosen sort applies below them.
+	orderBy := " ORDER BY COALESCE(fp.is_pinned, FALSE) DESC, fp.created_at DESC"
+	if filter.Sort == models.ForumSortHottest {
+		orderBy = " ORDER BY COALESCE(fp.is_pinned, FALSE) DESC, " +
+			likeCount + " + " + commentCount + " DESC, COALESCE(fp.last_activity_at, fp.created_at) DESC"
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+	query := postSelect + whereClause + orderBy + fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	posts, err := r.queryPosts(ctx, query, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListPosts: %w", err)
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM forum_posts fp" + whereClause
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListPosts.Count: %w", err)
+	}
+	return posts, total, nil
+}
+
+func (r *Repository) SearchPosts(ctx context.Context, keyword string, page, limit int) ([]models.ForumPost, int, error) {
+	offset := (page - 1) * limit
+	pattern := utils.ContainsPattern(keyword)
+	whereClause := ` WHERE COALESCE(fp.is_archived, FALSE) = FALSE AND (fp.title ILIKE $1 ESCAPE '\' OR fp.content ILIKE $1 ESCAPE '\')`
+
+	query := postSelect + whereClause + ` ORDER BY fp.created_at DESC LIMIT $2 OFFSET $3`
+	posts, err := r.queryPosts(ctx, query, pattern, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.SearchPosts: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM forum_posts fp"+whereClause, pattern).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.SearchPosts.Count: %w", err)
+	}
+	return posts, total, nil
+}
+
+func (r *Repository) queryPosts(ctx context.Context, query string, args ...interface{}) ([]models.ForumPost, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []models.ForumPost{}
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		posts = append(posts, *post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	return posts, nil
+}
+
+func (r *Repository) FindPostByID(ctx context.Context, postID int64) (*models.ForumPost, error) {
+	post, err := scanPost(r.db.QueryRow(ctx, postSelect+" WHERE fp.id = $1", postID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindPostByID: %w", err)
+	}
+	return post, nil
+}
+
+func (r *Repository) IncrementViewCount(ctx context.Context, postID int64) error {
+	_, err := r.db.Exec(ctx, `UPDATE forum_posts SET view_count = COALESCE(view_count, 0) + 1 WHERE id = $1`, postID)
+	if err != nil {
+		return fmt.Errorf("repository.IncrementViewCount: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CreatePost(ctx context.Context, userID string, data models.CreateForumPostData) (*models.ForumPost, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreatePost.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	var postID int64
+	now := time.Now()
+	query := `INSERT INTO forum_posts (user_id, category_id, title, content, last_activity_at, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $5, $5) RETURNING id`
+	err = tx.QueryRow(ctx, query, userID, data.CategoryID, data.Title, data.Content, now).Scan(&postID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreatePost: %w", err)
+	}
+
+	if err := replacePostTags(ctx, tx, postID, data.Tags); err != nil {
+		return nil, fmt.Errorf("repository.CreatePost.Tags: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.CreatePost.Commit: %w", err)
+	}
+	return r.FindPostByID(ctx, postID)
+}
+
+func (r *Repository) UpdatePost(ctx context.Context, postID int64, data models.UpdateForumPostData) (*models.ForumPost, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdatePost.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var setClauses []string
+	var args []interface{}
+	argIdx := 1
+
+	if data.Title != nil {
+		setClauses = append(setClauses, fmt.Sprintf("title = $%d", argIdx))
+		args = append(args, *data.Title)
+		argIdx++
+	}
+	if data.Content != nil {
+		setClauses = append(setClauses, fmt.Sprintf("content = $%d", argIdx))
+		args = append(args, *data.Content)
+		argIdx++
+	}
+	if data.CategoryID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("category_id = $%d", argIdx))
+		args = append(args, *data.CategoryID)
+		argIdx++
+	}
+
+	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", argIdx))
+	args = append(args, time.Now())
+	argIdx++
+
+	args = append(args, postID)
+	query := fmt.Sprintf(`UPDATE forum_posts SET %s WHERE id = $%d`, strings.Join(setClauses, ", "), argIdx)
+	cmdTag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdatePost: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	if data.Tags != nil {
+		if err := replacePostTags(ctx, tx, postID, data.Tags); err != nil {
+			return nil, fmt.Errorf("repository.UpdatePost.Tags: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.UpdatePost.Commit: %w", err)
+	}
+	return r.FindPostByID(ctx, postID)
+}
+
+// replacePostTags upserts the tag names into tags and links exactly those tags to the post.
+func replacePostTags(ctx context.Context, tx pgx.Tx, postID int64, tags []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM forum_post_tags WHERE post_id = $1`, postID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		var tagID int
+		err := tx.QueryRow(ctx,
+			`INSERT INTO tags (name) VALUES ($1)
+			 ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			 RETURNING id`, tag).Scan(&tagID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO forum_post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, postID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) DeletePost(ctx context.Context, postID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM forum_posts WHERE id = $1`, postID)
+	if err != nil {
+		return fmt.Errorf("repository.DeletePost: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// SetPinned and SetArchived are moderation flags, so updated_at is left alone.
+func (r *Repository) SetPinned(ctx context.Context, postID int64, pinned bool) error {
+	cmdTag, err := r.db.Exec(ctx, `UPDATE forum_posts SET is_pinned = $1 WHERE id = $2`, pinned, postID)
+	if err != nil {
+		return fmt.Errorf("repository.SetPinned: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) SetArchived(ctx context.Context, postID int64, archived bool) error {
+	cmdTag, err := r.db.Exec(ctx, `UPDATE forum_posts SET is_archived = $1 WHERE id = $2`, archived, postID)
+	if err != nil {
+		return fmt.Errorf("repository.SetArchived: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// --- Categories and Tags ---
+
+func (r *Repository) ListCategories(ctx context.Context) ([]models.ForumCategory, error) {
+	query := `SELECT id, name, COALESCE(description, ''), COALESCE(display_order, 0)
+	          FROM forum_categories ORDER BY display_order ASC, id ASC`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListCategories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []models.ForumCategory{}
+	for rows.Next() {
+		var category models.ForumCategory
+		if err := rows.Scan(&category.ID, &category.Name, &category.Description, &category.DisplayOrder); err != nil {
+			return nil, fmt.Errorf("repository.ListCategories.Scan: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListCategories.RowsErr: %w", err)
+	}
+	return categories, nil
+}
+
+func (r *Repository) CategoryExists(ctx context.Context, categoryID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM forum_categories WHERE id = $1)`, categoryID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("repository.CategoryExists: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *Repository) GetTopicsTagCloud(ctx context.Context, limit int) ([]models.ForumTopic, error) {
+	query := `
+		SELECT t.name, COUNT(*) AS post_count
+		FROM forum_post_tags fpt
+		JOIN tags t ON t.id = fpt.tag_id
+		JOIN forum_posts fp ON fp.id = fpt.post_id
+		WHERE COALESCE(fp.is_archived, FALSE) = FALSE
+		GROUP BY t.name
+		ORDER BY post_count DESC, t.name ASC
+		LIMIT $1`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository.GetTopicsTagCloud: %w", err)
+	}
+	defer rows.Close()
+
+	topics := []models.ForumTopic{}
+	for rows.Next() {
+		var topic models.ForumTopic
+		if err := rows.Scan(&topic.Tag, &topic.PostCount); err != nil {
+			return nil, fmt.Errorf("repository.GetTopicsTagCloud.Scan: %w", err)
+		}
+		topics = append(topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.GetTopicsTagCloud.RowsErr: %w", err)
+	}
+	return topics, nil
+}
+
+// --- Comments ---
+
+const commentSelect = `
+	SELECT c.id, c.post_id, c.user_id, COALESCE(u.nickname, ''), c.parent_comment_id, c.content,
+	       (SELECT COUNT(*) FROM forum_comment_likes cl WHERE cl.comment_id = c.id) AS like_count,
+	       c.created_at, c.updated_at
+	FROM forum_comments c
+	JOIN users u ON u.id = c.user_id
+`
+
+func scanComment(row pgx.Row) (*models.ForumComment, error) {
+	var comment models.ForumComment
+	err := row.Scan(
+		&comment.ID, &comment.PostID, &comment.UserID, &comment.AuthorNickname, &comment.ParentCommentID,
+		&comment.Content, &comment.LikeCount, &comment.CreatedAt, &comment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *Repository) ListComments(ctx context.Context, postID int64) ([]models.ForumComment, error) {
+	rows, err := r.db.Query(ctx, commentSelect+" WHERE c.post_id = $1 ORDER BY c.created_at ASC", postID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListComments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []models.ForumComment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListComments.Scan: %w", err)
+		}
+		comments = append(comments, *comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListComments.RowsErr: %w", err)
+	}
+	return comments, nil
+}
+
+func (r *Repository) FindCommentByID(ctx context.Context, commentID int64) (*models.ForumComment, error) {
+	comment, err := scanComment(r.db.QueryRow(ctx, commentSelect+" WHERE c.id = $1", commentID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindCommentByID: %w", err)
+	}
+	return comment, nil
+}
+
+func (r *Repository) CreateComment(ctx context.Context, postID int64, userID string, data models.CreateForumCommentData) (*models.ForumComment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateComment.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var commentID int64
+	now := time.Now()
+	query := `INSERT INTO forum_comments (post_id, user_id, parent_comment_id, content, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $5) RETURNING id`
+	err = tx.QueryRow(ctx, query, postID, userID, data.ParentCommentID, data.Content, now).Scan(&commentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateComment: %w", err)
+	}
+
+	// A new comment bumps the post for the "latest activity" ordering.
+	if _, err := tx.Exec(ctx, `UPDATE forum_posts SET last_activity_at = $1 WHERE id = $2`, now, postID); err != nil {
+		return nil, fmt.Errorf("repository.CreateComment.TouchPost: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.CreateComment.Commit: %w", err)
+	}
+	return r.FindCommentByID(ctx, commentID)
+}
+
+func (r *Repository) UpdateComment(ctx context.Context, commentID int64, data models.UpdateForumCommentData) (*models.ForumComment, error) {
+	cmdTag, err := r.db.Exec(ctx, `UPDATE forum_comments SET content = $1, updated_at = $2 WHERE id = $3`,
+		data.Content, time.Now(), commentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateComment: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+	return r.FindCommentByID(ctx, commentID)
+}
+
+func (r *Repository) DeleteComment(ctx context.Context, commentID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM forum_comments WHERE id = $1`, commentID)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteComment: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// --- Interactions ---
+
+func (r *Repository) TogglePostLike(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error) {
+	result, err := r.toggle(ctx, "forum_post_likes", "post_id", userID, postID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.TogglePostLike: %w", err)
+	}
+	return result, nil
+}
+
+func (r *Repository) TogglePostSave(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error) {
+	result, err := r.toggle(ctx, "user_saved_forum_posts", "post_id", userID, postID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.TogglePostSave: %w", err)
+	}
+	return result, nil
+}
+
+func (r *Repository) ToggleCommentLike(ctx context.Context, userID string, commentID int64) (*models.ToggleResult, error) {
+	result, err := r.toggle(ctx, "forum_comment_likes", "comment_id", userID, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ToggleCommentLike: %w", err)
+	}
+	return result, nil
+}
+
+// toggle removes the (user, entity) row from a junction table if present, otherwise inserts it.
+// table and column are always package constants, never user input.
+func (r *Repository) toggle(ctx context.Context, table, column, userID string, entityID int64) (*models.ToggleResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result := &models.ToggleResult{}
+	deleteQuery := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1 AND %s = $2`, table, column)
+	cmdTag, err := tx.Exec(ctx, deleteQuery, userID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		insertQuery := fmt.Sprintf(`INSERT INTO %s (user_id, %s) VALUES ($1, $2)`, table, column)
+		if _, err := tx.Exec(ctx, insertQuery, userID, entityID); err != nil {
+			return nil, err
+		}
+		result.Active = true
+	}
+
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = $1`, table, column)
+	if err := tx.QueryRow(ctx, countQuery, entityID).Scan(&result.Count); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,367 @@
+package forum
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty forum, a function creating a user (returning
+// the ID and nickname) and one adding a category.
+type fixture struct {
+	repo        RepositoryInterface
+	addUser     func() (string, string)
+	addCategory func(name string, displayOrder int) models.ForumCategory
+}
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		db := pgtest.NewDB(t)
+		return fixture{
+			repo: NewRepository(db),
+			addUser: func() (string, string) {
+				u := factory.User(t, db)
+				return u.ID, u.Nickname
+			},
+			addCategory: func(name string, displayOrder int) models.ForumCategory {
+				c := models.ForumCategory{Name: name, Description: "About " + name, DisplayOrder: displayOrder}
+				err := db.QueryRow(context.Background(),
+					`INSERT INTO forum_categories (slug, name, description, display_order) VALUES ($1, $2, $3, $4) RETURNING id`,
+					"category-"+name, c.Name, c.Description, c.DisplayOrder).Scan(&c.ID)
+				if err != nil {
+					t.Fatalf("insert category: %v", err)
+				}
+				return c
+			},
+		}
+	})
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		repo := NewMemoryRepository()
+		lastUserID := 0
+		return fixture{
+			repo: repo,
+			addUser: func() (string, string) {
+				lastUserID++
+				id, nickname := strconv.Itoa(lastUserID), "user"+strconv.Itoa(lastUserID)
+				repo.AddUser(id, nickname)
+				return id, nickname
+			},
+			addCategory: func(name string, displayOrder int) models.ForumCategory {
+				return repo.AddCategory(models.ForumCategory{Name: name, Description: "About " + name, DisplayOrder: displayOrder})
+			},
+		}
+	})
+}
+
+func testRepositoryContract(t *testing.T, newFixture func(t *testing.T) fixture) {
+	ctx := context.Background()
+	createPost := func(t *testing.T, f fixture, userID string, categoryID int, title string, tags ...string) *models.ForumPost {
+		t.Helper()
+		post, err := f.repo.CreatePost(ctx, userID, models.CreateForumPostData{
+			Title: title, Content: "How do I keep celadon from crazing?", CategoryID: categoryID, Tags: tags,
+		})
+		if err != nil {
+			t.Fatalf("CreatePost(%s): %v", title, err)
+		}
+		return post
+	}
+	createComment := func(t *testing.T, f fixture, postID int64, userID string, parentID *int64) *models.ForumComment {
+		t.Helper()
+		comment, err := f.repo.CreateComment(ctx, postID, userID, models.CreateForumCommentData{Content: "Try a slower cooling.", ParentCommentID: parentID})
+		if err != nil {
+			t.Fatalf("CreateComment: %v", err)
+		}
+		return comment
+	}
+	titles := func(posts []models.ForumPost) []string {
+		titles := []string{}
+		for _, p := range posts {
+			titles = append(titles, p.Title)
+		}
+		return titles
+	}
+
+	t.Run("Posts", func(t *testing.T) {
+		f := newFixture(t)
+		userID, nickname := f.addUser()
+		glaze := f.addCategory("glaze", 1)
+		firing := f.addCategory("firing", 2)
+
+		created := createPost(t, f, userID, glaze.ID, "Crazing", " celadon", "crackle", "celadon", "")
+		if created.ID == 0 || created.UserID != userID || created.AuthorNickname != nickname || created.CategoryID != glaze.ID ||
+			created.CategoryName != "glaze" || !reflect.DeepEqual(created.Tags, []string{"celadon", "crackle"}) ||
+			created.IsPinned || created.IsArchived || created.ViewCount != 0 || created.CreatedAt.IsZero() ||
+			!created.LastActivityAt.Equal(created.CreatedAt) {
+			t.Errorf("CreatePost = %+v", created)
+		}
+		if found, err := f.repo.FindPostByID(ctx, created.ID); err != nil || !reflect.DeepEqual(found, created) {
+			t.Errorf("FindPostByID = %+v, %v; want %+v", found, err, created)
+		}
+		if _, err := f.repo.FindPostByID(ctx, created.ID+100); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindPostByID(missing): error = %v, want ErrNotFound", err)
+		}
+		if _, err := f.repo.CreatePost(ctx, userID, models.CreateForumPostData{Title: "x", Content: "x", CategoryID: firing.ID + 100}); err == nil {
+			t.Error("CreatePost accepted a category that does not exist")
+		}
+		if untagged := createPost(t, f, userID, glaze.ID, "Untagged"); untagged.Tags == nil || len(untagged.Tags) != 0 {
+			t.Errorf("Tags of an untagged post = %#v, want an empty non-nil slice", untagged.Tags)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := f.repo.IncrementViewCount(ctx, created.ID); err != nil {
+				t.Fatalf("IncrementViewCount: %v", err)
+			}
+		}
+		if err := f.repo.IncrementViewCount(ctx, created.ID+100); err != nil {
+			t.Errorf("IncrementViewCount(missing) = %v, want it ignored", err)
+		}
+
+		title := "Crazing on celadon"
+		updated, err := f.repo.UpdatePost(ctx, created.ID, models.UpdateForumPostData{Title: &title, CategoryID: &firing.ID, Tags: []string{}})
+		if err != nil {
+			t.Fatalf("UpdatePost: %v", err)
+		}
+		if updated.Title != title || updated.Content != created.Content || updated.CategoryName != "firing" || len(updated.Tags) != 0 ||
+			updated.ViewCount != 2 || updated.UpdatedAt.Before(created.UpdatedAt) {
+			t.Errorf("UpdatePost = %+v", updated)
+		}
+		// Even without changes updated_at moves
+		updated, err = f.repo.UpdatePost(ctx, created.ID, models.UpdateForumPostData{})
+		if err != nil || len(updated.Tags) != 0 || updated.Title != title {
+			t.Errorf("UpdatePost without changes = %+v, %v", updated, err)
+		}
+		if _, err := f.repo.UpdatePost(ctx, created.ID+100, models.UpdateForumPostData{Title: &title}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdatePost(missing): error = %v, want ErrNotFound", err)
+		}
+
+		if err := f.repo.SetPinned(ctx, created.ID, true); err != nil {
+			t.Fatalf("SetPinned: %v", err)
+		}
+		if err := f.repo.SetArchived(ctx, created.ID, true); err != nil {
+			t.Fatalf("SetArchived: %v", err)
+		}
+		if found, _ := f.repo.FindPostByID(ctx, created.ID); !found.IsPinned || !found.IsArchived || !found.UpdatedAt.Equal(updated.UpdatedAt) {
+			t.Errorf("post after SetPinned and SetArchived = %+v", found)
+		}
+		if err := f.repo.SetPinned(ctx, created.ID+100, true); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("SetPinned(missing): error = %v, want ErrNotFound", err)
+		}
+		if err := f.repo.SetArchived(ctx, created.ID+100, true); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("SetArchived(missing): error = %v, want ErrNotFound", err)
+		}
+
+		if err := f.repo.DeletePost(ctx, created.ID); err != nil {
+			t.Fatalf("DeletePost: %v", err)
+		}
+		if err := f.repo.DeletePost(ctx, created.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeletePost twice: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("ListAndSearch", func(t *testing.T) {
+		f := newFixture(t)
+		author, _ := f.addUser()
+		reader, _ := f.addUser()
+		glaze := f.addCategory("glaze", 1)
+		firing := f.addCategory("firing", 2)
+
+		pinned := createPost(t, f, author, glaze.ID, "Pinned rules", "rules")
+		createPost(t, f, author, glaze.ID, "Cold 100% kaolin", "kaolin")
+		hot := createPost(t, f, author, firing.ID, "Hot kiln", "kiln", "kaolin")
+		warm := createPost(t, f, author, glaze.ID, "Warm glaze", "kaolin")
+		archived := createPost(t, f, author, glaze.ID, "Archived kaolin", "kaolin")
+		for _, err := range []error{
+			f.repo.SetPinned(ctx, pinned.ID, true),
+			f.repo.SetArchived(ctx, archived.ID, true),
+		} {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, like := range []struct {
+			userID string
+			postID int64
+		}{{author, hot.ID}, {reader, hot.ID}, {reader, warm.ID}} {
+			if _, err := f.repo.TogglePostLike(ctx, like.userID, like.postID); err != nil {
+				t.Fatalf("TogglePostLike: %v", err)
+			}
+		}
+		createComment(t, f, hot.ID, reader, nil)
+
+		for _, tc := range []struct {
+			filter models.ForumPostFilter
+			want   []string
+		}{
+			{models.ForumPostFilter{}, []string{"Pinned rules", "Warm glaze", "Hot kiln", "Cold 100% kaolin"}},
+			{models.ForumPostFilter{Sort: models.ForumSortHottest}, []string{"Pinned rules", "Hot kiln", "Warm glaze", "Cold 100% kaolin"}},
+			{models.ForumPostFilter{CategoryID: glaze.ID}, []string{"Pinned rules", "Warm glaze", "Cold 100% kaolin"}},
+			{models.ForumPostFilter{Tag: "kaolin"}, []string{"Warm glaze", "Hot kiln", "Cold 100% kaolin"}},
+			{models.ForumPostFilter{Tag: "kaol"}, []string{}},
+		} {
+			tc.filter.Page, tc.filter.Limit = 1, 10
+			posts, total, err := f.repo.ListPosts(ctx, tc.filter)
+			if err != nil || total != len(tc.want) || !reflect.DeepEqual(titles(posts), tc.want) {
+				t.Errorf("ListPosts(%+v) = %v (total %d), %v; want %v", tc.filter, titles(posts), total, err, tc.want)
+			}
+		}
+		posts, total, err := f.repo.ListPosts(ctx, models.ForumPostFilter{Page: 2, Limit: 3})
+		if err != nil || total != 4 || !reflect.DeepEqual(titles(posts), []string{"Cold 100% kaolin"}) {
+			t.Errorf("ListPosts(page 2) = %v (total %d), %v", titles(posts), total, err)
+		}
+		if posts, _, _ := f.repo.ListPosts(ctx, models.ForumPostFilter{Page: 1, Limit: 10, Tag: "kiln"}); len(posts) != 1 ||
+			posts[0].LikeCount != 2 || posts[0].CommentCount != 1 || !posts[0].LastActivityAt.After(posts[0].CreatedAt) {
+			t.Errorf("hot post = %+v, want 2 likes, 1 comment and a later activity", posts)
+		}
+
+		for keyword, want := range map[string][]string{
+			"KAOLIN":  {"Cold 100% kaolin"},
+			"%":       {"Cold 100% kaolin"},
+			"celadon": {"Warm glaze", "Hot kiln", "Cold 100% kaolin", "Pinned rules"},
+			"_":       {},
+		} {
+			posts, total, err := f.repo.SearchPosts(ctx, keyword, 1, 10)
+			if err != nil || total != len(want) || !reflect.DeepEqual(titles(posts), want) {
+				t.Errorf("SearchPosts(%q) = %v (total %d), %v; want %v", keyword, titles(posts), total, err, want)
+			}
+		}
+
+		topics, err := f.repo.GetTopicsTagCloud(ctx, 2)
+		wantTopics := []models.ForumTopic{{Tag: "kaolin", PostCount: 3}, {Tag: "kiln", PostCount: 1}}
+		if err != nil || !reflect.DeepEqual(topics, wantTopics) {
+			t.Errorf("GetTopicsTagCloud = %+v, %v; want %+v", topics, err, wantTopics)
+		}
+	})
+
+	t.Run("Categories", func(t *testing.T) {
+		f := newFixture(t)
+		if categories, err := f.repo.ListCategories(ctx); err != nil || categories == nil || len(categories) != 0 {
+			t.Errorf("ListCategories(empty) = %v, %v; want an empty non-nil slice", categories, err)
+		}
+		later := f.addCategory("later", 2)
+		first := f.addCategory("first", 1)
+		tie := f.addCategory("tie", 2)
+		categories, err := f.repo.ListCategories(ctx)
+		if want := []models.ForumCategory{first, later, tie}; err != nil || !reflect.DeepEqual(categories, want) {
+			t.Errorf("ListCategories = %+v, %v; want %+v", categories, err, want)
+		}
+		if ok, err := f.repo.CategoryExists(ctx, first.ID); err != nil || !ok {
+			t.Errorf("CategoryExists = %v, %v", ok, err)
+		}
+		if ok, _ := f.repo.CategoryExists(ctx, tie.ID+100); ok {
+			t.Error("CategoryExists(missing) = true")
+		}
+	})
+
+	t.Run("Comments", func(t *testing.T) {
+		f := newFixture(t)
+		author, _ := f.addUser()
+		reader, nickname := f.addUser()
+		category := f.addCategory("glaze", 1)
+		post := createPost(t, f, author, category.ID, "Crazing")
+
+		root := createComment(t, f, post.ID, reader, nil)
+		if root.ID == 0 || root.PostID != post.ID || root.UserID != reader || root.AuthorNickname != nickname ||
+			root.ParentCommentID != nil || root.Content != "Try a slower cooling." || root.LikeCount != 0 {
+			t.Errorf("CreateComment = %+v", root)
+		}
+		reply := createComment(t, f, post.ID, author, &root.ID)
+		if reply.ParentCommentID == nil || *reply.ParentCommentID != root.ID {
+			t.Errorf("reply parent = %v, want %d", reply.ParentCommentID, root.ID)
+		}
+		other := createComment(t, f, post.ID, author, nil)
+		if _, err := f.repo.CreateComment(ctx, post.ID+100, reader, models.CreateForumCommentData{Content: "x"}); err == nil {
+			t.Error("CreateComment accepted a post that does not exist")
+		}
+		missing := other.ID + 100
+		if _, err := f.repo.CreateComment(ctx, post.ID, reader, models.CreateForumCommentData{Content: "x", ParentCommentID: &missing}); err == nil {
+			t.Error("CreateComment accepted a parent that does not exist")
+		}
+
+		comments, err := f.repo.ListComments(ctx, post.ID)
+		if err != nil || len(comments) != 3 || comments[0].ID != root.ID || comments[1].ID != reply.ID || comments[2].ID != other.ID {
+			t.Errorf("ListComments = %+v, %v; want oldest first", comments, err)
+		}
+		if comments, err := f.repo.ListComments(ctx, post.ID+100); err != nil || comments == nil || len(comments) != 0 {
+			t.Errorf("ListComments(missing) = %v, %v; want an empty non-nil slice", comments, err)
+		}
+
+		updated, err := f.repo.UpdateComment(ctx, root.ID, models.UpdateForumCommentData{Content: "Cool slower."})
+		if err != nil || updated.Content != "Cool slower." || updated.UpdatedAt.Before(root.UpdatedAt) || !updated.CreatedAt.Equal(root.CreatedAt) {
+			t.Errorf("UpdateComment = %+v, %v", updated, err)
+		}
+		if _, err := f.repo.UpdateComment(ctx, missing, models.UpdateForumCommentData{Content: "x"}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateComment(missing): error = %v, want ErrNotFound", err)
+		}
+		if _, err := f.repo.FindCommentByID(ctx, missing); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindCommentByID(missing): error = %v, want ErrNotFound", err)
+		}
+
+		// Deleting a comment takes its replies with it
+		if err := f.repo.DeleteComment(ctx, root.ID); err != nil {
+			t.Fatalf("DeleteComment: %v", err)
+		}
+		if _, err := f.repo.FindCommentByID(ctx, reply.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindCommentByID(reply) after deleting the parent: error = %v, want ErrNotFound", err)
+		}
+		if err := f.repo.DeleteComment(ctx, root.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteComment twice: error = %v, want ErrNotFound", err)
+		}
+		if found, _ := f.repo.FindPostByID(ctx, post.ID); found.CommentCount != 1 {
+			t.Errorf("CommentCount = %d, want 1", found.CommentCount)
+		}
+
+		if err := f.repo.DeletePost(ctx, post.ID); err != nil {
+			t.Fatalf("DeletePost: %v", err)
+		}
+		if _, err := f.repo.FindCommentByID(ctx, other.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindCommentByID after deleting the post: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Toggles", func(t *testing.T) {
+		f := newFixture(t)
+		author, _ := f.addUser()
+		reader, _ := f.addUser()
+		category := f.addCategory("glaze", 1)
+		post := createPost(t, f, author, category.ID, "Crazing")
+		comment := createComment(t, f, post.ID, author, nil)
+
+		toggles := map[string]func(userID string) (*models.ToggleResult, error){
+			"TogglePostLike": func(userID string) (*models.ToggleResult, error) { return f.repo.TogglePostLike(ctx, userID, post.ID) },
+			"TogglePostSave": func(userID string) (*models.ToggleResult, error) { return f.repo.TogglePostSave(ctx, userID, post.ID) },
+			"ToggleCommentLike": func(userID string) (*models.ToggleResult, error) {
+				return f.repo.ToggleCommentLike(ctx, userID, comment.ID)
+			},
+		}
+		for name, toggle := range toggles {
+			for i, want := range []models.ToggleResult{{Active: true, Count: 1}, {Active: true, Count: 2}, {Active: false, Count: 1}} {
+				userID := reader
+				if i == 1 {
+					userID = author
+				}
+				if got, err := toggle(userID); err != nil || *got != want {
+					t.Errorf("%s step %d = %+v, %v; want %+v", name, i, got, err, want)
+				}
+			}
+		}
+		if found, _ := f.repo.FindCommentByID(ctx, comment.ID); found.LikeCount != 1 {
+			t.Errorf("comment LikeCount = %d, want 1", found.LikeCount)
+		}
+		if _, err := f.repo.TogglePostLike(ctx, reader, post.ID+100); err == nil {
+			t.Error("TogglePostLike accepted a post that does not exist")
+		}
+		if _, err := f.repo.ToggleCommentLike(ctx, reader, comment.ID+100); err == nil {
+			t.Error("ToggleCommentLike accepted a comment that does not exist")
+		}
+	})
+}
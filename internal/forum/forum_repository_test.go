@@ -0,0 +1,87 @@
+package forum
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func like(t *testing.T, db *pgxpool.Pool, userID string, postID int64) {
+	t.Helper()
+	if _, err := db.Exec(context.Background(),
+		`INSERT INTO forum_post_likes (user_id, post_id) VALUES ($1, $2)`, userID, postID); err != nil {
+		t.Fatalf("like: %v", err)
+	}
+}
+
+func comment(t *testing.T, db *pgxpool.Pool, userID string, postID int64) {
+	t.Helper()
+	if _, err := db.Exec(context.Background(),
+		`INSERT INTO forum_comments (post_id, user_id, content) VALUES ($1, $2, 'Try a slower cooling.')`, postID, userID); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+}
+
+func TestRepositoryListPostsHottest(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	author, reader := factory.User(t, db), factory.User(t, db)
+	category := factory.ForumCategory(t, db)
+	inCategory := func(p *models.ForumPost) { p.CategoryID = category.ID }
+
+	pinned := factory.ForumPost(t, db, author.ID, inCategory, func(p *models.ForumPost) { p.IsPinned = true })
+	cold := factory.ForumPost(t, db, author.ID, inCategory)
+	hot := factory.ForumPost(t, db, author.ID, inCategory)
+	warm := factory.ForumPost(t, db, author.ID, inCategory)
+	factory.ForumPost(t, db, author.ID, inCategory, func(p *models.ForumPost) { p.IsArchived = true })
+	like(t, db, author.ID, hot.ID)
+	like(t, db, reader.ID, hot.ID)
+	comment(t, db, reader.ID, hot.ID)
+	like(t, db, reader.ID, warm.ID)
+
+	posts, total, err := repo.ListPosts(context.Background(), models.ForumPostFilter{
+		Page: 1, Limit: 10, Sort: models.ForumSortHottest, CategoryID: category.ID,
+	})
+	if err != nil {
+		t.Fatalf("ListPosts: %v", err)
+	}
+	want := []int64{pinned.ID, hot.ID, warm.ID, cold.ID}
+	if total != len(want) || len(posts) != len(want) {
+		t.Fatalf("ListPosts returned %d posts, total %d, want %d", len(posts), total, len(want))
+	}
+	for i, post := range posts {
+		if post.ID != want[i] {
+			t.Errorf("posts[%d] = %d, want %d", i, post.ID, want[i])
+		}
+	}
+	if posts[1].LikeCount != 2 || posts[1].CommentCount != 1 {
+		t.Errorf("hot post counts = %d likes, %d comments, want 2 and 1", posts[1].LikeCount, posts[1].CommentCount)
+	}
+}
+
+func TestRepositorySearchPostsMatchesWildcardsLiterally(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	author := factory.User(t, db)
+	percent := factory.ForumPost(t, db, author.ID, func(p *models.ForumPost) { p.Title = "Is 100% kaolin too much?" })
+	factory.ForumPost(t, db, author.ID, func(p *models.ForumPost) { p.Title = "Firing schedules" })
+	underscore := factory.ForumPost(t, db, author.ID, func(p *models.ForumPost) { p.Title = "Glaze recipe blue_white_02" })
+
+	for keyword, want := range map[string]int64{"%": percent.ID, "_": underscore.ID} {
+		posts, total, err := repo.SearchPosts(context.Background(), keyword, 1, 10)
+		if err != nil {
+			t.Fatalf("SearchPosts(%q): %v", keyword, err)
+		}
+		if total != 1 || len(posts) != 1 || posts[0].ID != want {
+			t.Errorf("SearchPosts(%q) = %d posts (total %d), want only post %d", keyword, len(posts), total, want)
+		}
+	}
+}
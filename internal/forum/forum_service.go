@@ -0,0 +1,354 @@
+package forum
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/audit"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/permission"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// ServiceInterface defines the methods for forum business logic.
+type ServiceInterface interface {
+	// Posts
+	ListPosts(ctx context.Context, filter models.ForumPostFilter) ([]models.ForumPost, int, error)
+	SearchPosts(ctx context.Context, keyword string, page, limit int) ([]models.ForumPost, int, error)
+	GetPostDetail(ctx context.Context, postID int64) (*models.ForumPost, error)
+	CreatePost(ctx context.Context, userID string, data models.CreateForumPostData) (*models.ForumPost, error)
+	UpdatePost(ctx context.Context, userID string, postID int64, data models.UpdateForumPostData) (*models.ForumPost, error)
+	DeletePost(ctx context.Context, userID, userRole string, postID int64) error
+	// SetPostPinned and SetPostArchived are moderation actions, callers must check for forum.moderate.
+	// actorID is recorded in the audit log.
+	SetPostPinned(ctx context.Context, actorID string, postID int64, pinned bool) (*models.ForumPost, error)
+	SetPostArchived(ctx context.Context, actorID string, postID int64, archived bool) (*models.ForumPost, error)
+
+	// Categories and tags
+	GetCategories(ctx context.Context) ([]models.ForumCategory, error)
+	IsValidCategory(ctx context.Context, categoryID int) (bool, error)
+	GetTopicsTagCloud(ctx context.Context) ([]models.ForumTopic, error)
+
+	// Comments
+	CreateComment(ctx context.Context, userID string, postID int64, data models.CreateForumCommentData) (*models.ForumComment, error)
+	UpdateComment(ctx context.Context, userID string, commentID int64, data models.UpdateForumCommentData) (*models.ForumComment, error)
+	DeleteComment(ctx context.Context, userID, userRole string, commentID int64) error
+
+	// Interactions
+	LikePost(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error)
+	SavePost(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error)
+	LikeComment(ctx context.Context, userID string, commentID int64) (*models.ToggleResult, error)
+}
+
+// Service provides business logic for the forum.
+type Service struct {
+	repo        RepositoryInterface
+	permissions permission.Checker // forum.moderate lets a role delete other users' posts and comments
+	auditLog    audit.Recorder     // Deletions and moderation actions
+}
+
+// NewService creates a new forum service.
+func NewService(repo RepositoryInterface, permissions permission.Checker, auditLog audit.Recorder) ServiceInterface {
+	return &Service{repo: repo, permissions: permissions, auditLog: auditLog}
+}
+
+const tagCloudSize = 50
+
+// --- Posts ---
+
+func (s *Service) ListPosts(ctx context.Context, filter models.ForumPostFilter) ([]models.ForumPost, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	} // Default/max limit
+	if filter.Sort != models.ForumSortHottest {
+		filter.Sort = models.ForumSortLatest
+	}
+	posts, total, err := s.repo.ListPosts(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListPosts: %w", err)
+	}
+	return posts, total, nil
+}
+
+func (s *Service) SearchPosts(ctx context.Context, keyword string, page, limit int) ([]models.ForumPost, int, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return []models.ForumPost{}, 0, nil
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	posts, total, err := s.repo.SearchPosts(ctx, keyword, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.SearchPosts: %w", err)
+	}
+	return posts, total, nil
+}
+
+func (s *Service) GetPostDetail(ctx context.Context, postID int64) (*models.ForumPost, error) {
+	post, err := s.repo.FindPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetPostDetail: %w", err)
+	}
+	comments, err := s.repo.ListComments(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetPostDetail.Comments: %w", err)
+	}
+	post.Comments = comments
+
+	// A failed view counter should never block reading the post.
+	if err := s.repo.IncrementViewCount(ctx, postID); err != nil {
+		log.Printf("WARN: service.GetPostDetail.IncrementViewCount for postID %d: %v", postID, err)
+	} else {
+		post.ViewCount++
+	}
+	return post, nil
+}
+
+func (s *Service) CreatePost(ctx context.Context, userID string, data models.CreateForumPostData) (*models.ForumPost, error) {
+	isValidCategory, err := s.IsValidCategory(ctx, data.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreatePost: %w", err)
+	}
+	if !isValidCategory {
+		return nil, models.ErrInvalidForumPostCategoryID
+	}
+	data.Tags = utils.NormalizeTags(data.Tags)
+
+	post, err := s.repo.CreatePost(ctx, userID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreatePost: %w", err)
+	}
+	return post, nil
+}
+
+func (s *Service) UpdatePost(ctx context.Context, userID string, postID int64, data models.UpdateForumPostData) (*models.ForumPost, error) {
+	post, err := s.repo.FindPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdatePost: %w", err)
+	}
+	if post.UserID != userID {
+		return nil, models.ErrForbidden
+	}
+
+	if data.CategoryID != nil {
+		isValidCategory, err := s.IsValidCategory(ctx, *data.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("service.UpdatePost: %w", err)
+		}
+		if !isValidCategory {
+			return nil, models.ErrInvalidForumPostCategoryID
+		}
+	}
+	if data.Tags != nil {
+		data.Tags = utils.NormalizeTags(data.Tags)
+	}
+
+	updated, err := s.repo.UpdatePost(ctx, postID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdatePost: %w", err)
+	}
+	return updated, nil
+}
+
+// DeletePost removes a post. Only the author or a role with forum.moderate may do so.
+func (s *Service) DeletePost(ctx context.Context, userID, userRole string, postID int64) error {
+	post, err := s.repo.FindPostByID(ctx, postID)
+	if err != nil {
+		return fmt.Errorf("service.DeletePost: %w", err)
+	}
+	if post.UserID != userID {
+		if err := s.requireModerator(ctx, userRole); err != nil {
+			return fmt.Errorf("service.DeletePost: %w", err)
+		}
+	}
+	if err := s.repo.DeletePost(ctx, postID); err != nil {
+		return fmt.Errorf("service.DeletePost: %w", err)
+	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID: userID, Action: audit.ActionForumPostDelete,
+		EntityType: audit.EntityForumPost, EntityID: strconv.FormatInt(postID, 10), Before: post,
+	})
+	return nil
+}
+
+func (s *Service) SetPostPinned(ctx context.Context, actorID string, postID int64, pinned bool) (*models.ForumPost, error) {
+	before, err := s.repo.FindPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetPostPinned: %w", err)
+	}
+	if err := s.repo.SetPinned(ctx, postID, pinned); err != nil {
+		return nil, fmt.Errorf("service.SetPostPinned: %w", err)
+	}
+	post, err := s.repo.FindPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetPostPinned: %w", err)
+	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID: actorID, Action: audit.ActionForumPostPin,
+		EntityType: audit.EntityForumPost, EntityID: strconv.FormatInt(postID, 10), Before: before, After: post,
+	})
+	return post, nil
+}
+
+func (s *Service) SetPostArchived(ctx context.Context, actorID string, postID int64, archived bool) (*models.ForumPost, error) {
+	before, err := s.repo.FindPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetPostArchived: %w", err)
+	}
+	if err := s.repo.SetArchived(ctx, postID, archived); err != nil {
+		return nil, fmt.Errorf("service.SetPostArchived: %w", err)
+	}
+	post, err := s.repo.FindPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetPostArchived: %w", err)
+	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID: actorID, Action: audit.ActionForumPostArchive,
+		EntityType: audit.EntityForumPost, EntityID: strconv.FormatInt(postID, 10), Before: before, After: post,
+	})
+	return post, nil
+}
+
+// --- Categories and Tags ---
+
+func (s *Service) GetCategories(ctx context.Context) ([]models.ForumCategory, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetCategories: %w", err)
+	}
+	return categories, nil
+}
+
+func (s *Service) IsValidCategory(ctx context.Context, categoryID int) (bool, error) {
+	if categoryID <= 0 {
+		return false, nil
+	}
+	exists, err := s.repo.CategoryExists(ctx, categoryID)
+	if err != nil {
+		return false, fmt.Errorf("service.IsValidCategory: %w", err)
+	}
+	return exists, nil
+}
+
+func (s *Service) GetTopicsTagCloud(ctx context.Context) ([]models.ForumTopic, error) {
+	topics, err := s.repo.GetTopicsTagCloud(ctx, tagCloudSize)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetTopicsTagCloud: %w", err)
+	}
+	return topics, nil
+}
+
+// --- Comments ---
+
+func (s *Service) CreateComment(ctx context.Context, userID string, postID int64, data models.CreateForumCommentData) (*models.ForumComment, error) {
+	if _, err := s.repo.FindPostByID(ctx, postID); err != nil {
+		return nil, fmt.Errorf("service.CreateComment: %w", err)
+	}
+	if data.ParentCommentID != nil {
+		parent, err := s.repo.FindCommentByID(ctx, *data.ParentCommentID)
+		if err != nil {
+			return nil, fmt.Errorf("service.CreateComment.Parent: %w", err)
+		}
+		if parent.PostID != postID { // Replies must stay within the same thread
+			return nil, models.ErrNotFound
+		}
+	}
+
+	comment, err := s.repo.CreateComment(ctx, postID, userID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateComment: %w", err)
+	}
+	return comment, nil
+}
+
+func (s *Service) UpdateComment(ctx context.Context, userID string, commentID int64, data models.UpdateForumCommentData) (*models.ForumComment, error) {
+	comment, err := s.repo.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateComment: %w", err)
+	}
+	if comment.UserID != userID {
+		return nil, models.ErrForbidden
+	}
+
+	updated, err := s.repo.UpdateComment(ctx, commentID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateComment: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) DeleteComment(ctx context.Context, userID, userRole string, commentID int64) error {
+	comment, err := s.repo.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteComment: %w", err)
+	}
+	if comment.UserID != userID {
+		if err := s.requireModerator(ctx, userRole); err != nil {
+			return fmt.Errorf("service.DeleteComment: %w", err)
+		}
+	}
+	if err := s.repo.DeleteComment(ctx, commentID); err != nil {
+		return fmt.Errorf("service.DeleteComment: %w", err)
+	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID: userID, Action: audit.ActionForumCommentDelete,
+		EntityType: audit.EntityForumComment, EntityID: strconv.FormatInt(commentID, 10), Before: comment,
+	})
+	return nil
+}
+
+// requireModerator returns models.ErrForbidden unless userRole has forum.moderate.
+func (s *Service) requireModerator(ctx context.Context, userRole string) error {
+	allowed, err := s.permissions.HasPermission(ctx, userRole, models.PermForumModerate)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+// --- Interactions ---
+
+func (s *Service) LikePost(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error) {
+	if _, err := s.repo.FindPostByID(ctx, postID); err != nil {
+		return nil, fmt.Errorf("service.LikePost: %w", err)
+	}
+	result, err := s.repo.TogglePostLike(ctx, userID, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.LikePost: %w", err)
+	}
+	return result, nil
+}
+
+func (s *Service) SavePost(ctx context.Context, userID string, postID int64) (*models.ToggleResult, error) {
+	if _, err := s.repo.FindPostByID(ctx, postID); err != nil {
+		return nil, fmt.Errorf("service.SavePost: %w", err)
+	}
+	result, err := s.repo.TogglePostSave(ctx, userID, postID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SavePost: %w", err)
+	}
+	return result, nil
+}
+
+func (s *Service) LikeComment(ctx context.Context, userID string, commentID int64) (*models.ToggleResult, error) {
+	if _, err := s.repo.FindCommentByID(ctx, commentID); err != nil {
+		return nil, fmt.Errorf("service.LikeComment: %w", err)
+	}
+	result, err := s.repo.ToggleCommentLike(ctx, userID, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("service.LikeComment: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package gallery
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler handles HTTP requests for the gallery.
+type Handler struct {
+	service  ServiceInterface
+	validate *validator.Validate
+}
+
+// NewHandler creates a new gallery handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{
+		service:  service,
+		validate: utils.NewValidator(), // Slugs use the alphanumdash tag
+	}
+}
+
+// --- Public Routes ---
+
+// GetArtworks lists artworks. Params: ?page=1&limit=20&category=...&artist=<id or name>
+func (h *Handler) GetArtworks(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	filter := models.ArtworkFilter{
+		Page:     page,
+		Limit:    limit,
+		Category: c.QueryParam("category"),
+		Artist:   c.QueryParam("artist"),
+	}
+
+	artworks, total, err := h.service.ListArtworks(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(artworks, page, limit, total))
+}
+
+// GetArtworkByID returns the artwork detail. is_favorite is only populated when a valid JWT was sent.
+func (h *Handler) GetArtworkByID(c echo.Context) error {
+	artworkID, err := strconv.ParseInt(c.Param("artwork_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid artwork ID")
+	}
+	viewerID, _ := utils.GetUserIDFromContext(c) // Empty for guests
+
+	artwork, err := h.service.GetArtworkDetail(c.Request().Context(), artworkID, viewerID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, artwork)
+}
+
+func (h *Handler) GetArtists(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	artists, total, err := h.service.ListArtists(c.Request().Context(), page, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(artists, page, limit, total))
+}
+
+func (h *Handler) GetArtistByID(c echo.Context) error {
+	artistID, err := strconv.Atoi(c.Param("artist_id"))
+	if err != nil {
+		return models.BadRequestError("Invalid artist ID")
+	}
+
+	artist, err := h.service.GetArtistDetail(c.Request().Context(), artistID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, artist)
+}
+
+func (h *Handler) GetGalleryCategories(c echo.Context) error {
+	categories, err := h.service.GetCategories(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, categories)
+}
+
+// --- Protected Routes ---
+
+func (h *Handler) MarkAsFavorite(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	artworkID, err := strconv.ParseInt(c.Param("artwork_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid artwork ID")
+	}
+
+	result, err := h.service.MarkAsFavorite(c.Request().Context(), userID, artworkID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) UnmarkAsFavorite(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	artworkID, err := strconv.ParseInt(c.Param("artwork_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid artwork ID")
+	}
+
+	result, err := h.service.UnmarkAsFavorite(c.Request().Context(), userID, artworkID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) AddNoteToArtwork(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	artworkID, err := strconv.ParseInt(c.Param("artwork_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid artwork ID")
+	}
+
+	var req models.AddEntityNoteData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	note, err := h.service.AddNoteToArtwork(c.Request().Context(), userID, artworkID, req.Title, req.Content)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, note)
+}
+
+// --- Admin Handlers ---
+// Routed under /admin/gallery, behind JWTMAuth and RequirePermission(gallery.edit).
+
+func (h *Handler) CreateArtwork(c echo.Context) error {
+	var req models.CreateArtworkData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	artwork, err := h.service.CreateArtwork(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, artwork)
+}
+
+func (h *Handler) UpdateArtwork(c echo.Context) error {
+	artworkID, err := strconv.ParseInt(c.Param("artwork_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid artwork ID")
+	}
+
+	var req models.UpdateArtworkData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	artwork, err := h.service.UpdateArtwork(c.Request().Context(), artworkID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, artwork)
+}
+
+func (h *Handler) DeleteArtwork(c echo.Context) error {
+	artworkID, err := strconv.ParseInt(c.Param("artwork_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid artwork ID")
+	}
+
+	if err := h.service.DeleteArtwork(c.Request().Context(), artworkID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) CreateArtist(c echo.Context) error {
+	var req models.CreateArtistData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	artist, err := h.service.CreateArtist(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, artist)
+}
+
+func (h *Handler) UpdateArtist(c echo.Context) error {
+	artistID, err := strconv.Atoi(c.Param("artist_id"))
+	if err != nil {
+		return models.BadRequestError("Invalid artist ID")
+	}
+
+	var req models.UpdateArtistData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	artist, err := h.service.UpdateArtist(c.Request().Context(), artistID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, artist)
+}
+
+func (h *Handler) DeleteArtist(c echo.Context) error {
+	artistID, err := strconv.Atoi(c.Param("artist_id"))
+	if err != nil {
+		return models.BadRequestError("Invalid artist ID")
+	}
+
+	if err := h.service.DeleteArtist(c.Request().Context(), artistID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,481 @@
+package gallery
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository. Notes live in the user package, so tests count them in with AddNote; favorites are not
+// checked against users, which this package does not know about.
+type MemoryRepository struct {
+	mu          sync.Mutex
+	artworks    map[int64]*models.Artwork
+	artists     map[int]*models.Artist
+	images      map[int64][]models.ArtworkImage
+	tags        map[int64][]string
+	favorites   map[favoriteKey]bool
+	noteCounts  map[int64]int
+	lastArtwork int64
+	lastArtist  int
+	lastImageID int
+}
+
+type favoriteKey struct {
+	userID    string
+	artworkID int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory gallery.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		artworks:   map[int64]*models.Artwork{},
+		artists:    map[int]*models.Artist{},
+		images:     map[int64][]models.ArtworkImage{},
+		tags:       map[int64][]string{},
+		favorites:  map[favoriteKey]bool{},
+		noteCounts: map[int64]int{},
+	}
+}
+
+// AddNote counts one more user note on the artwork in its NoteCount.
+func (r *MemoryRepository) AddNote(artworkID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.noteCounts[artworkID]++
+}
+
+// viewArtwork returns the artwork as artworkSelect does, with the artist name and counts filled in.
+func (r *MemoryRepository) viewArtwork(a *models.Artwork) *models.Artwork {
+	out := *a
+	out.ArtistName = a.ArtistNameOverride
+	if out.ArtistName == "" && a.ArtistID != nil {
+		out.ArtistName = r.artists[*a.ArtistID].Name
+	}
+	if a.ArtistID != nil {
+		id := *a.ArtistID
+		out.ArtistID = &id
+	}
+	if a.CreationYear != nil {
+		year := *a.CreationYear
+		out.CreationYear = &year
+	}
+	out.FavoriteCount = 0
+	for key := range r.favorites {
+		if key.artworkID == a.ID {
+			out.FavoriteCount++
+		}
+	}
+	out.NoteCount = r.noteCounts[a.ID]
+	return &out
+}
+
+func (r *MemoryRepository) viewArtist(a *models.Artist) *models.Artist {
+	out := *a
+	out.ArtworkCount = 0
+	for _, artwork := range r.artworks {
+		if artwork.ArtistID != nil && *artwork.ArtistID == a.ID {
+			out.ArtworkCount++
+		}
+	}
+	return &out
+}
+
+func (r *MemoryRepository) slugTaken(slug string, exceptID int64) bool {
+	for _, a := range r.artworks {
+		if a.Slug == slug && a.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryRepository) artistSlugTaken(slug string, exceptID int) bool {
+	for _, a := range r.artists {
+		if a.Slug == slug && a.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceArtworkTags links exactly the given tag names, once each, like the artwork_tags primary key.
+func (r *MemoryRepository) replaceArtworkTags(artworkID int64, tags []string) {
+	linked := []string{}
+	for _, tag := range tags {
+		if !containsString(linked, tag) {
+			linked = append(linked, tag)
+		}
+	}
+	sort.Strings(linked)
+	r.tags[artworkID] = linked
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Artworks ---
+
+func (r *MemoryRepository) ListArtworks(ctx context.Context, filter models.ArtworkFilter) ([]models.Artwork, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	artistID, byID := 0, false
+	if filter.Artist != "" {
+		if id, err := strconv.Atoi(filter.Artist); err == nil {
+			artistID, byID = id, true
+		}
+	}
+
+	matched := []*models.Artwork{}
+	for _, a := range r.artworks {
+		if filter.Category != "" && a.Category != filter.Category {
+			continue
+		}
+		if filter.Artist != "" {
+			if byID && (a.ArtistID == nil || *a.ArtistID != artistID) {
+				continue
+			}
+			if !byID {
+				// The names are matched literally, ignoring case
+				artistName := ""
+				if a.ArtistID != nil {
+					artistName = r.artists[*a.ArtistID].Name
+				}
+				if !strings.EqualFold(artistName, filter.Artist) && !strings.EqualFold(a.ArtistNameOverride, filter.Artist) {
+					continue
+				}
+			}
+		}
+		matched = append(matched, a)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	artworks := []models.Artwork{}
+	offset := (filter.Page - 1) * filter.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	for i := offset; i < len(matched) && i < offset+filter.Limit; i++ {
+		artworks = append(artworks, *r.viewArtwork(matched[i]))
+	}
+	return artworks, len(matched), nil
+}
+
+func (r *MemoryRepository) FindArtworkByID(ctx context.Context, artworkID int64) (*models.Artwork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.artworks[artworkID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.viewArtwork(a), nil
+}
+
+func (r *MemoryRepository) GetArtworkImages(ctx context.Context, artworkID int64) ([]models.ArtworkImage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	images := append([]models.ArtworkImage{}, r.images[artworkID]...)
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].IsPrimary != images[j].IsPrimary {
+			return images[i].IsPrimary
+		}
+		if images[i].DisplayOrder != images[j].DisplayOrder {
+			return images[i].DisplayOrder < images[j].DisplayOrder
+		}
+		return images[i].ID < images[j].ID
+	})
+	return images, nil
+}
+
+func (r *MemoryRepository) GetArtworkTags(ctx context.Context, artworkID int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.tags[artworkID]...), nil
+}
+
+func (r *MemoryRepository) ListCategories(ctx context.Context) ([]models.GalleryCategory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := map[string]int{}
+	for _, a := range r.artworks {
+		if a.Category != "" {
+			counts[a.Category]++
+		}
+	}
+	categories := []models.GalleryCategory{}
+	for name, count := range counts {
+		categories = append(categories, models.GalleryCategory{Name: name, ArtworkCount: count})
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return categories, nil
+}
+
+func (r *MemoryRepository) CreateArtwork(ctx context.Context, data models.CreateArtworkData) (*models.Artwork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.slugTaken(data.Slug, 0) {
+		return nil, models.ErrConflict
+	}
+	if data.ArtistID != nil && r.artists[*data.ArtistID] == nil {
+		return nil, fmt.Errorf("repository.CreateArtwork: artist %d does not exist", *data.ArtistID) // The artists foreign key
+	}
+
+	r.lastArtwork++
+	now := time.Now()
+	a := &models.Artwork{
+		ID: r.lastArtwork, Title: data.Title, Slug: data.Slug, ArtistNameOverride: data.ArtistNameOverride,
+		ThumbnailURL: data.ThumbnailURL, Description: data.Description, Dimensions: data.Dimensions,
+		Materials: data.Materials, Category: data.Category, Introduction: data.Introduction,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if data.ArtistID != nil {
+		id := *data.ArtistID
+		a.ArtistID = &id
+	}
+	if data.CreationYear != nil {
+		year := *data.CreationYear
+		a.CreationYear = &year
+	}
+	r.artworks[a.ID] = a
+
+	images := []models.ArtworkImage{}
+	for i, url := range data.ImageURLs {
+		r.lastImageID++
+		images = append(images, models.ArtworkImage{ID: r.lastImageID, ArtworkID: a.ID, ImageURL: url, IsPrimary: i == 0, DisplayOrder: i})
+	}
+	r.images[a.ID] = images
+	r.replaceArtworkTags(a.ID, data.Tags)
+	return r.viewArtwork(a), nil
+}
+
+func (r *MemoryRepository) UpdateArtwork(ctx context.Context, artworkID int64, data models.UpdateArtworkData) (*models.Artwork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.artworks[artworkID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if data.Slug != nil && r.slugTaken(*data.Slug, artworkID) {
+		return nil, models.ErrConflict
+	}
+	if data.ArtistID != nil && r.artists[*data.ArtistID] == nil {
+		return nil, fmt.Errorf("repository.UpdateArtwork: artist %d does not exist", *data.ArtistID)
+	}
+
+	setString := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	setString(&a.Title, data.Title)
+	setString(&a.Slug, data.Slug)
+	setString(&a.ArtistNameOverride, data.ArtistNameOverride)
+	setString(&a.ThumbnailURL, data.ThumbnailURL)
+	setString(&a.Description, data.Description)
+	setString(&a.Dimensions, data.Dimensions)
+	setString(&a.Materials, data.Materials)
+	setString(&a.Category, data.Category)
+	setString(&a.Introduction, data.Introduction)
+	if data.ArtistID != nil {
+		id := *data.ArtistID
+		a.ArtistID = &id
+	}
+	if data.CreationYear != nil {
+		year := *data.CreationYear
+		a.CreationYear = &year
+	}
+	a.UpdatedAt = time.Now()
+
+	if data.Tags != nil {
+		r.replaceArtworkTags(artworkID, data.Tags)
+	}
+	return r.viewArtwork(a), nil
+}
+
+func (r *MemoryRepository) DeleteArtwork(ctx context.Context, artworkID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.artworks[artworkID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.artworks, artworkID)
+	delete(r.images, artworkID)
+	delete(r.tags, artworkID)
+	for key := range r.favorites {
+		if key.artworkID == artworkID {
+			delete(r.favorites, key)
+		}
+	}
+	return nil
+}
+
+// --- Artists ---
+
+func (r *MemoryRepository) ListArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sorted := make([]*models.Artist, 0, len(r.artists))
+	for _, a := range r.artists {
+		sorted = append(sorted, a)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	artists := []models.Artist{}
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	for i := offset; i < len(sorted) && i < offset+limit; i++ {
+		artists = append(artists, *r.viewArtist(sorted[i]))
+	}
+	return artists, len(sorted), nil
+}
+
+func (r *MemoryRepository) FindArtistByID(ctx context.Context, artistID int) (*models.Artist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.artists[artistID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.viewArtist(a), nil
+}
+
+func (r *MemoryRepository) ListArtworksByArtist(ctx context.Context, artistID int) ([]models.Artwork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []*models.Artwork{}
+	for _, a := range r.artworks {
+		if a.ArtistID != nil && *a.ArtistID == artistID {
+			matched = append(matched, a)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		yi, yj := matched[i].CreationYear, matched[j].CreationYear
+		switch {
+		case yi != nil && yj != nil && *yi != *yj:
+			return *yi < *yj
+		case (yi == nil) != (yj == nil):
+			return yj == nil // NULLS LAST
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	artworks := []models.Artwork{}
+	for _, a := range matched {
+		artworks = append(artworks, *r.viewArtwork(a))
+	}
+	return artworks, nil
+}
+
+func (r *MemoryRepository) CreateArtist(ctx context.Context, data models.CreateArtistData) (*models.Artist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.artistSlugTaken(data.Slug, 0) {
+		return nil, models.ErrConflict
+	}
+	r.lastArtist++
+	now := time.Now()
+	a := &models.Artist{ID: r.lastArtist, Name: data.Name, Slug: data.Slug, Bio: data.Bio, CreatedAt: now, UpdatedAt: now}
+	r.artists[a.ID] = a
+	return r.viewArtist(a), nil
+}
+
+func (r *MemoryRepository) UpdateArtist(ctx context.Context, artistID int, data models.UpdateArtistData) (*models.Artist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.artists[artistID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if data.Slug != nil && r.artistSlugTaken(*data.Slug, artistID) {
+		return nil, models.ErrConflict
+	}
+	if data.Name != nil {
+		a.Name = *data.Name
+	}
+	if data.Slug != nil {
+		a.Slug = *data.Slug
+	}
+	if data.Bio != nil {
+		a.Bio = *data.Bio
+	}
+	a.UpdatedAt = time.Now()
+	return r.viewArtist(a), nil
+}
+
+func (r *MemoryRepository) DeleteArtist(ctx context.Context, artistID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.artists[artistID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.artists, artistID)
+	for _, a := range r.artworks {
+		if a.ArtistID != nil && *a.ArtistID == artistID {
+			a.ArtistID = nil
+		}
+	}
+	return nil
+}
+
+// --- Favorites ---
+
+func (r *MemoryRepository) IsFavorite(ctx context.Context, userID string, artworkID int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.favorites[favoriteKey{userID, artworkID}], nil
+}
+
+func (r *MemoryRepository) AddFavorite(ctx context.Context, userID string, artworkID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.artworks[artworkID]; !ok {
+		return fmt.Errorf("repository.AddFavorite: artwork %d does not exist", artworkID) // The artworks foreign key
+	}
+	r.favorites[favoriteKey{userID, artworkID}] = true
+	return nil
+}
+
+func (r *MemoryRepository) RemoveFavorite(ctx context.Context, userID string, artworkID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.favorites, favoriteKey{userID, artworkID})
+	return nil
+}
+
+func (r *MemoryRepository) CountFavorites(ctx context.Context, artworkID int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for key := range r.favorites {
+		if key.artworkID == artworkID {
+			count++
+		}
+	}
+	return count, nil
+}
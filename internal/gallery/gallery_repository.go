@@ -0,0 +1,505 @@
+package gallery
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines the methods for interacting with gallery storage.
+type RepositoryInterface interface {
+	// Artworks
+	ListArtworks(ctx context.Context, filter models.ArtworkFilter) ([]models.Artwork, int, error)
+	FindArtworkByID(ctx context.Context, artworkID int64) (*models.Artwork, error)
+	GetArtworkImages(ctx context.Context, artworkID int64) ([]models.ArtworkImage, error)
+	GetArtworkTags(ctx context.Context, artworkID int64) ([]string, error)
+	ListCategories(ctx context.Context) ([]models.GalleryCategory, error)
+
+	// Artists
+	ListArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error)
+	FindArtistByID(ctx context.Context, artistID int) (*models.Artist, error)
+	ListArtworksByArtist(ctx context.Context, artistID int) ([]models.Artwork, error)
+
+	// Editing, see models.PermGalleryEdit. Create and Update return models.ErrConflict for a taken slug.
+	CreateArtwork(ctx context.Context, data models.CreateArtworkData) (*models.Artwork, error)
+	UpdateArtwork(ctx context.Context, artworkID int64, data models.UpdateArtworkData) (*models.Artwork, error)
+	DeleteArtwork(ctx context.Context, artworkID int64) error
+	CreateArtist(ctx context.Context, data models.CreateArtistData) (*models.Artist, error)
+	UpdateArtist(ctx context.Context, artistID int, data models.UpdateArtistData) (*models.Artist, error)
+	// DeleteArtist keeps the artworks of the artist; their artist_id becomes NULL.
+	DeleteArtist(ctx context.Context, artistID int) error
+
+	// Favorites
+	IsFavorite(ctx context.Context, userID string, artworkID int64) (bool, error)
+	AddFavorite(ctx context.Context, userID string, artworkID int64) error
+	RemoveFavorite(ctx context.Context, userID string, artworkID int64) error
+	CountFavorites(ctx context.Context, artworkID int64) (int, error)
+}
+
+// Repository provides access to the gallery storage.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new gallery repository.
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+// artworkSelect is shared by every query returning models.Artwork so the Scan order stays in one place.
+// artist_name prefers the override, then the joined artist.
+const artworkSelect = `
+	SELECT a.id, a.title, a.slug, a.artist_id,
+	       COALESCE(NULLIF(a.artist_name_override, ''), ar.name, '') AS artist_name,
+	       COALESCE(a.artist_name_override, ''), a.thumbnail_url, COALESCE(a.description, ''),
+	       a.creation_year, COALESCE(a.dimensions, ''), COALESCE(a.materials, ''),
+	       COALESCE(a.category, ''), COALESCE(a.introduction, ''),
+	       (SELECT COUNT(*) FROM user_favorite_artworks f WHERE f.artwork_id = a.id) AS favorite_count,
+	       (SELECT COUNT(*) FROM user_notes n WHERE n.entity_type = 'artwork' AND n.entity_id = a.id) AS note_count,
+	       a.created_at, a.updated_at
+	FROM artworks a
+	LEFT JOIN artists ar ON ar.id = a.artist_id
+`
+
+func scanArtwork(row pgx.Row) (*models.Artwork, error) {
+	var artwork models.Artwork
+	err := row.Scan(
+		&artwork.ID, &artwork.Title, &artwork.Slug, &artwork.ArtistID, &artwork.ArtistName,
+		&artwork.ArtistNameOverride, &artwork.ThumbnailURL, &artwork.Description,
+		&artwork.CreationYear, &artwork.Dimensions, &artwork.Materials,
+		&artwork.Category, &artwork.Introduction,
+		&artwork.FavoriteCount, &artwork.NoteCount,
+		&artwork.CreatedAt, &artwork.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &artwork, nil
+}
+
+// --- Artworks ---
+
+func (r *Repository) ListArtworks(ctx context.Context, filter models.ArtworkFilter) ([]models.Artwork, int, error) {
+	var where []string
+	var args []interface{}
+	argIdx := 1
+
+	if filter.Category != "" {
+		where = append(where, fmt.Sprintf("a.category = $%d", argIdx))
+		args = append(args, filter.Category)
+		argIdx++
+	}
+	if filter.Artist != "" {
+		// Numeric values are artist IDs, anything else matches the displayed artist name.
+		if artistID, err := strconv.Atoi(filter.Artist); err == nil {
+			where = append(where, fmt.Sprintf("a.artist_id = $%d", argIdx))
+			args = append(args, artistID)
+		} else {
+			where = append(where, fmt.Sprintf(`(ar.name ILIKE $%[1]d ESCAPE '\' OR a.artist_name_override ILIKE $%[1]d ESCAPE '\')`, argIdx))
+			args = append(args, utils.EscapeLike(filter.Artist))
+		}
+		argIdx++
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+	query := artworkSelect + whereClause +
+		fmt.Sprintf(" ORDER BY a.created_at DESC, a.id DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	rows, err := r.db.Query(ctx, query, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListArtworks: %w", err)
+	}
+	artworks, err := collectArtworks(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListArtworks: %w", err)
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM artworks a LEFT JOIN artists ar ON ar.id = a.artist_id" + whereClause
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListArtworks.Count: %w", err)
+	}
+	return artworks, total, nil
+}
+
+func collectArtworks(rows pgx.Rows) ([]models.Artwork, error) {
+	defer rows.Close()
+	artworks := []models.Artwork{}
+	for rows.Next() {
+		artwork, err := scanArtwork(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		artworks = append(artworks, *artwork)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	return artworks, nil
+}
+
+func (r *Repository) FindArtworkByID(ctx context.Context, artworkID int64) (*models.Artwork, error) {
+	artwork, err := scanArtwork(r.db.QueryRow(ctx, artworkSelect+" WHERE a.id = $1", artworkID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindArtworkByID: %w", err)
+	}
+	return artwork, nil
+}
+
+func (r *Repository) GetArtworkImages(ctx context.Context, artworkID int64) ([]models.ArtworkImage, error) {
+	query := `SELECT id, artwork_id, image_url, COALESCE(is_primary, FALSE), COALESCE(caption, ''), COALESCE(display_order, 0)
+	          FROM artwork_images WHERE artwork_id = $1
+	          ORDER BY is_primary DESC, display_order ASC, id ASC`
+	rows, err := r.db.Query(ctx, query, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.GetArtworkImages: %w", err)
+	}
+	defer rows.Close()
+
+	images := []models.ArtworkImage{}
+	for rows.Next() {
+		var image models.ArtworkImage
+		if err := rows.Scan(&image.ID, &image.ArtworkID, &image.ImageURL, &image.IsPrimary, &image.Caption, &image.DisplayOrder); err != nil {
+			return nil, fmt.Errorf("repository.GetArtworkImages.Scan: %w", err)
+		}
+		images = append(images, image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.GetArtworkImages.RowsErr: %w", err)
+	}
+	return images, nil
+}
+
+func (r *Repository) GetArtworkTags(ctx context.Context, artworkID int64) ([]string, error) {
+	query := `SELECT t.name FROM artwork_tags at JOIN tags t ON t.id = at.tag_id
+	          WHERE at.artwork_id = $1 ORDER BY t.name`
+	rows, err := r.db.Query(ctx, query, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.GetArtworkTags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("repository.GetArtworkTags.Scan: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.GetArtworkTags.RowsErr: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *Repository) ListCategories(ctx context.Context) ([]models.GalleryCategory, error) {
+	query := `SELECT category, COUNT(*) AS artwork_count FROM artworks
+	          WHERE category IS NOT NULL AND category <> ''
+	          GROUP BY category ORDER BY category ASC`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListCategories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []models.GalleryCategory{}
+	for rows.Next() {
+		var category models.GalleryCategory
+		if err := rows.Scan(&category.Name, &category.ArtworkCount); err != nil {
+			return nil, fmt.Errorf("repository.ListCategories.Scan: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListCategories.RowsErr: %w", err)
+	}
+	return categories, nil
+}
+
+// CreateArtwork inserts the artwork with its images and tags in one transaction.
+func (r *Repository) CreateArtwork(ctx context.Context, data models.CreateArtworkData) (*models.Artwork, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateArtwork.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	var artworkID int64
+	query := `INSERT INTO artworks (slug, title, artist_id, artist_name_override, thumbnail_url, description,
+	                                creation_year, dimensions, materials, category, introduction)
+	          VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''), $10, NULLIF($11, ''))
+	          RETURNING id`
+	err = tx.QueryRow(ctx, query,
+		data.Slug, data.Title, data.ArtistID, data.ArtistNameOverride, data.ThumbnailURL, data.Description,
+		data.CreationYear, data.Dimensions, data.Materials, data.Category, data.Introduction,
+	).Scan(&artworkID)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.CreateArtwork: %w", err)
+	}
+
+	for i, url := range data.ImageURLs {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO artwork_images (artwork_id, image_url, is_primary, display_order) VALUES ($1, $2, $3, $4)`,
+			artworkID, url, i == 0, i)
+		if err != nil {
+			return nil, fmt.Errorf("repository.CreateArtwork.Images: %w", err)
+		}
+	}
+	if err := replaceArtworkTags(ctx, tx, artworkID, data.Tags); err != nil {
+		return nil, fmt.Errorf("repository.CreateArtwork.Tags: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.CreateArtwork.Commit: %w", err)
+	}
+	return r.FindArtworkByID(ctx, artworkID)
+}
+
+// UpdateArtwork changes the non-nil fields of data and, if data.Tags is not nil, replaces the tags.
+func (r *Repository) UpdateArtwork(ctx context.Context, artworkID int64, data models.UpdateArtworkData) (*models.Artwork, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateArtwork.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE artworks SET
+			title = COALESCE($2, title),
+			slug = COALESCE($3, slug),
+			artist_id = COALESCE($4, artist_id),
+			artist_name_override = COALESCE($5, artist_name_override),
+			thumbnail_url = COALESCE($6, thumbnail_url),
+			description = COALESCE($7, description),
+			creation_year = COALESCE($8, creation_year),
+			dimensions = COALESCE($9, dimensions),
+			materials = COALESCE($10, materials),
+			category = COALESCE($11, category),
+			introduction = COALESCE($12, introduction),
+			updated_at = NOW()
+		WHERE id = $1`
+	cmdTag, err := tx.Exec(ctx, query,
+		artworkID, data.Title, data.Slug, data.ArtistID, data.ArtistNameOverride, data.ThumbnailURL, data.Description,
+		data.CreationYear, data.Dimensions, data.Materials, data.Category, data.Introduction,
+	)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.UpdateArtwork: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	if data.Tags != nil {
+		if err := replaceArtworkTags(ctx, tx, artworkID, data.Tags); err != nil {
+			return nil, fmt.Errorf("repository.UpdateArtwork.Tags: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.UpdateArtwork.Commit: %w", err)
+	}
+	return r.FindArtworkByID(ctx, artworkID)
+}
+
+// DeleteArtwork removes the artwork; images, tags and favorites go with it.
+func (r *Repository) DeleteArtwork(ctx context.Context, artworkID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM artworks WHERE id = $1`, artworkID)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteArtwork: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// replaceArtworkTags upserts the tag names into tags and links exactly those tags to the artwork.
+func replaceArtworkTags(ctx context.Context, tx pgx.Tx, artworkID int64, tags []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM artwork_tags WHERE artwork_id = $1`, artworkID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		var tagID int
+		err := tx.QueryRow(ctx,
+			`INSERT INTO tags (name) VALUES ($1)
+			 ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			 RETURNING id`, tag).Scan(&tagID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO artwork_tags (artwork_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, artworkID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- Artists ---
+
+const artistSelect = `
+	SELECT ar.id, ar.name, ar.slug, COALESCE(ar.bio, ''), ar.user_id,
+	       (SELECT COUNT(*) FROM artworks a WHERE a.artist_id = ar.id) AS artwork_count,
+	       ar.created_at, ar.updated_at
+	FROM artists ar
+`
+
+func scanArtist(row pgx.Row) (*models.Artist, error) {
+	var artist models.Artist
+	err := row.Scan(&artist.ID, &artist.Name, &artist.Slug, &artist.Bio, &artist.UserID, &artist.ArtworkCount, &artist.CreatedAt, &artist.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+func (r *Repository) ListArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error) {
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(ctx, artistSelect+" ORDER BY ar.name ASC LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListArtists: %w", err)
+	}
+	defer rows.Close()
+
+	artists := []models.Artist{}
+	for rows.Next() {
+		artist, err := scanArtist(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("repository.ListArtists.Scan: %w", err)
+		}
+		artists = append(artists, *artist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListArtists.RowsErr: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM artists").Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListArtists.Count: %w", err)
+	}
+	return artists, total, nil
+}
+
+func (r *Repository) FindArtistByID(ctx context.Context, artistID int) (*models.Artist, error) {
+	artist, err := scanArtist(r.db.QueryRow(ctx, artistSelect+" WHERE ar.id = $1", artistID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindArtistByID: %w", err)
+	}
+	return artist, nil
+}
+
+func (r *Repository) ListArtworksByArtist(ctx context.Context, artistID int) ([]models.Artwork, error) {
+	rows, err := r.db.Query(ctx, artworkSelect+" WHERE a.artist_id = $1 ORDER BY a.creation_year ASC NULLS LAST, a.id ASC", artistID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListArtworksByArtist: %w", err)
+	}
+	artworks, err := collectArtworks(rows)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListArtworksByArtist: %w", err)
+	}
+	return artworks, nil
+}
+
+func (r *Repository) CreateArtist(ctx context.Context, data models.CreateArtistData) (*models.Artist, error) {
+	var artistID int
+	query := `INSERT INTO artists (slug, name, bio) VALUES ($1, $2, NULLIF($3, '')) RETURNING id`
+	if err := r.db.QueryRow(ctx, query, data.Slug, data.Name, data.Bio).Scan(&artistID); err != nil {
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.CreateArtist: %w", err)
+	}
+	return r.FindArtistByID(ctx, artistID)
+}
+
+func (r *Repository) UpdateArtist(ctx context.Context, artistID int, data models.UpdateArtistData) (*models.Artist, error) {
+	query := `
+		UPDATE artists SET
+			name = COALESCE($2, name),
+			slug = COALESCE($3, slug),
+			bio = COALESCE($4, bio),
+			updated_at = NOW()
+		WHERE id = $1`
+	cmdTag, err := r.db.Exec(ctx, query, artistID, data.Name, data.Slug, data.Bio)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, fmt.Errorf("repository.UpdateArtist: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+	return r.FindArtistByID(ctx, artistID)
+}
+
+func (r *Repository) DeleteArtist(ctx context.Context, artistID int) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM artists WHERE id = $1`, artistID)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteArtist: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// --- Favorites ---
+
+func (r *Repository) IsFavorite(ctx context.Context, userID string, artworkID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM user_favorite_artworks WHERE user_id = $1 AND artwork_id = $2)`
+	if err := r.db.QueryRow(ctx, query, userID, artworkID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("repository.IsFavorite: %w", err)
+	}
+	return exists, nil
+}
+
+// AddFavorite is idempotent: favoriting an already favorited artwork is not an error.
+func (r *Repository) AddFavorite(ctx context.Context, userID string, artworkID int64) error {
+	query := `INSERT INTO user_favorite_artworks (user_id, artwork_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(ctx, query, userID, artworkID); err != nil {
+		return fmt.Errorf("repository.AddFavorite: %w", err)
+	}
+	return nil
+}
+
+// RemoveFavorite is idempotent: removing a favorite that does not exist is not an error.
+func (r *Repository) RemoveFavorite(ctx context.Context, userID string, artworkID int64) error {
+	query := `DELETE FROM user_favorite_artworks WHERE user_id = $1 AND artwork_id = $2`
+	if _, err := r.db.Exec(ctx, query, userID, artworkID); err != nil {
+		return fmt.Errorf("repository.RemoveFavorite: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CountFavorites(ctx context.Context, artworkID int64) (int, error) {
+	var count int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM user_favorite_artworks WHERE artwork_id = $1", artworkID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("repository.CountFavorites: %w", err)
+	}
+	return count, nil
+}
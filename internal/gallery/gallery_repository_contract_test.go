@@ -0,0 +1,334 @@
+package gallery
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty gallery, a function creating a user and
+// one adding a user note on an artwork.
+type fixture struct {
+	repo    RepositoryInterface
+	addUser func() string
+	addNote func(artworkID int64)
+}
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		db := pgtest.NewDB(t)
+		return fixture{
+			repo:    NewRepository(db),
+			addUser: func() string { return factory.User(t, db).ID },
+			addNote: func(artworkID int64) {
+				factory.Note(t, db, factory.User(t, db).ID, func(n *models.UserNote) {
+					id := int(artworkID)
+					n.EntityID = &id
+				})
+			},
+		}
+	})
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		repo := NewMemoryRepository()
+		lastUserID := 0
+		return fixture{
+			repo: repo,
+			addUser: func() string {
+				lastUserID++
+				return strconv.Itoa(lastUserID)
+			},
+			addNote: repo.AddNote,
+		}
+	})
+}
+
+func testRepositoryContract(t *testing.T, newFixture func(t *testing.T) fixture) {
+	ctx := context.Background()
+	createArtist := func(t *testing.T, f fixture, name, slug string) *models.Artist {
+		t.Helper()
+		artist, err := f.repo.CreateArtist(ctx, models.CreateArtistData{Name: name, Slug: slug})
+		if err != nil {
+			t.Fatalf("CreateArtist(%s): %v", slug, err)
+		}
+		return artist
+	}
+	createArtwork := func(t *testing.T, f fixture, slug, category string, artistID *int, year *int) *models.Artwork {
+		t.Helper()
+		artwork, err := f.repo.CreateArtwork(ctx, models.CreateArtworkData{
+			Title: slug, Slug: slug, ArtistID: artistID, ThumbnailURL: "/" + slug + ".jpg", Category: category, CreationYear: year,
+		})
+		if err != nil {
+			t.Fatalf("CreateArtwork(%s): %v", slug, err)
+		}
+		return artwork
+	}
+	slugs := func(artworks []models.Artwork) []string {
+		slugs := []string{}
+		for _, a := range artworks {
+			slugs = append(slugs, a.Slug)
+		}
+		return slugs
+	}
+	year := func(y int) *int { return &y }
+
+	t.Run("CreateAndFindArtwork", func(t *testing.T) {
+		f := newFixture(t)
+		artist := createArtist(t, f, "Wang Bu", "wang-bu")
+		created, err := f.repo.CreateArtwork(ctx, models.CreateArtworkData{
+			Title: "Blue and white vase", Slug: "blue-and-white-vase", ArtistID: &artist.ID,
+			ThumbnailURL: "/vase.jpg", Description: "Cobalt on porcelain", CreationYear: year(1960),
+			Dimensions: "30cm", Materials: "Porcelain", Category: "blue and white", Introduction: "Prize winner",
+			ImageURLs: []string{"https://example.com/front.jpg", "https://example.com/back.jpg"},
+			Tags:      []string{"vase", "qing", "vase"},
+		})
+		if err != nil {
+			t.Fatalf("CreateArtwork: %v", err)
+		}
+		if created.ID == 0 || created.ArtistID == nil || *created.ArtistID != artist.ID || created.ArtistName != "Wang Bu" ||
+			created.ArtistNameOverride != "" || created.ThumbnailURL != "/vase.jpg" || created.Description != "Cobalt on porcelain" ||
+			created.CreationYear == nil || *created.CreationYear != 1960 || created.Dimensions != "30cm" || created.Materials != "Porcelain" ||
+			created.Category != "blue and white" || created.Introduction != "Prize winner" || created.FavoriteCount != 0 || created.CreatedAt.IsZero() {
+			t.Errorf("CreateArtwork = %+v", created)
+		}
+		if found, err := f.repo.FindArtworkByID(ctx, created.ID); err != nil || !reflect.DeepEqual(found, created) {
+			t.Errorf("FindArtworkByID = %+v, %v; want %+v", found, err, created)
+		}
+		if _, err := f.repo.FindArtworkByID(ctx, created.ID+100); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindArtworkByID(missing): error = %v, want ErrNotFound", err)
+		}
+
+		images, err := f.repo.GetArtworkImages(ctx, created.ID)
+		if err != nil || len(images) != 2 || !images[0].IsPrimary || images[0].ImageURL != "https://example.com/front.jpg" ||
+			images[1].IsPrimary || images[1].DisplayOrder != 1 || images[1].ArtworkID != created.ID {
+			t.Errorf("GetArtworkImages = %+v, %v; want the first one primary", images, err)
+		}
+		if tags, err := f.repo.GetArtworkTags(ctx, created.ID); err != nil || !reflect.DeepEqual(tags, []string{"qing", "vase"}) {
+			t.Errorf("GetArtworkTags = %v, %v; want [qing vase]", tags, err)
+		}
+		if tags, err := f.repo.GetArtworkTags(ctx, created.ID+100); err != nil || tags == nil || len(tags) != 0 {
+			t.Errorf("GetArtworkTags(missing) = %v, %v; want an empty non-nil slice", tags, err)
+		}
+
+		if _, err := f.repo.CreateArtwork(ctx, models.CreateArtworkData{Title: "Copy", Slug: "blue-and-white-vase", ThumbnailURL: "/c.jpg", Category: "x"}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("CreateArtwork with a taken slug: error = %v, want ErrConflict", err)
+		}
+		missingArtist := artist.ID + 100
+		if _, err := f.repo.CreateArtwork(ctx, models.CreateArtworkData{Title: "Orphan", Slug: "orphan", ArtistID: &missingArtist, ThumbnailURL: "/o.jpg", Category: "x"}); err == nil {
+			t.Error("CreateArtwork accepted an artist that does not exist")
+		}
+	})
+
+	t.Run("UpdateAndDeleteArtwork", func(t *testing.T) {
+		f := newFixture(t)
+		wang := createArtist(t, f, "Wang Bu", "wang-bu")
+		li := createArtist(t, f, "Li Ming", "li-ming")
+		artwork, err := f.repo.CreateArtwork(ctx, models.CreateArtworkData{
+			Title: "Vase", Slug: "vase", ArtistID: &wang.ID, ThumbnailURL: "/vase.jpg", Category: "blue and white", Tags: []string{"vase"},
+		})
+		if err != nil {
+			t.Fatalf("CreateArtwork: %v", err)
+		}
+		createArtwork(t, f, "bowl", "famille rose", nil, nil)
+
+		title, override := "Qing vase", "Workshop of Wang Bu"
+		updated, err := f.repo.UpdateArtwork(ctx, artwork.ID, models.UpdateArtworkData{Title: &title, ArtistNameOverride: &override, ArtistID: &li.ID, Tags: []string{}})
+		if err != nil {
+			t.Fatalf("UpdateArtwork: %v", err)
+		}
+		if updated.Title != title || updated.Slug != "vase" || updated.Category != "blue and white" || *updated.ArtistID != li.ID ||
+			updated.ArtistName != override || updated.UpdatedAt.Before(artwork.UpdatedAt) {
+			t.Errorf("UpdateArtwork = %+v", updated)
+		}
+		if tags, _ := f.repo.GetArtworkTags(ctx, artwork.ID); len(tags) != 0 {
+			t.Errorf("tags after clearing = %v, want none", tags)
+		}
+		empty := ""
+		if updated, _ := f.repo.UpdateArtwork(ctx, artwork.ID, models.UpdateArtworkData{ArtistNameOverride: &empty}); updated.ArtistName != "Li Ming" {
+			t.Errorf("ArtistName without override = %q, want the artist's name", updated.ArtistName)
+		}
+		taken := "bowl"
+		if _, err := f.repo.UpdateArtwork(ctx, artwork.ID, models.UpdateArtworkData{Slug: &taken}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("UpdateArtwork to a taken slug: error = %v, want ErrConflict", err)
+		}
+		own := "vase"
+		if _, err := f.repo.UpdateArtwork(ctx, artwork.ID, models.UpdateArtworkData{Slug: &own}); err != nil {
+			t.Errorf("UpdateArtwork keeping its slug: %v", err)
+		}
+		if _, err := f.repo.UpdateArtwork(ctx, artwork.ID+100, models.UpdateArtworkData{Title: &title}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateArtwork(missing): error = %v, want ErrNotFound", err)
+		}
+
+		userID := f.addUser()
+		if err := f.repo.AddFavorite(ctx, userID, artwork.ID); err != nil {
+			t.Fatalf("AddFavorite: %v", err)
+		}
+		if err := f.repo.DeleteArtwork(ctx, artwork.ID); err != nil {
+			t.Fatalf("DeleteArtwork: %v", err)
+		}
+		if images, _ := f.repo.GetArtworkImages(ctx, artwork.ID); len(images) != 0 {
+			t.Errorf("images after delete = %+v", images)
+		}
+		if n, _ := f.repo.CountFavorites(ctx, artwork.ID); n != 0 {
+			t.Errorf("CountFavorites after delete = %d, want 0", n)
+		}
+		if err := f.repo.DeleteArtwork(ctx, artwork.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteArtwork twice: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("ListArtworks", func(t *testing.T) {
+		f := newFixture(t)
+		wang := createArtist(t, f, "Wang_Bu", "wang-bu")
+		li := createArtist(t, f, "Li Ming", "li-ming")
+		createArtwork(t, f, "first", "blue and white", &wang.ID, nil)
+		createArtwork(t, f, "second", "famille rose", &li.ID, nil)
+		override, err := f.repo.CreateArtwork(ctx, models.CreateArtworkData{
+			Title: "third", Slug: "third", ArtistID: &li.ID, ArtistNameOverride: "Wang_Bu", ThumbnailURL: "/t.jpg", Category: "blue and white",
+		})
+		if err != nil {
+			t.Fatalf("CreateArtwork: %v", err)
+		}
+		createArtwork(t, f, "fourth", "", nil, nil)
+
+		for _, tc := range []struct {
+			filter models.ArtworkFilter
+			want   []string
+		}{
+			{models.ArtworkFilter{}, []string{"fourth", "third", "second", "first"}},
+			{models.ArtworkFilter{Category: "blue and white"}, []string{"third", "first"}},
+			{models.ArtworkFilter{Artist: strconv.Itoa(li.ID)}, []string{"third", "second"}},
+			{models.ArtworkFilter{Artist: "wang_bu"}, []string{"third", "first"}},
+			{models.ArtworkFilter{Artist: "wang%"}, []string{}},
+			{models.ArtworkFilter{Artist: "Li Ming", Category: "famille rose"}, []string{"second"}},
+		} {
+			tc.filter.Page, tc.filter.Limit = 1, 10
+			artworks, total, err := f.repo.ListArtworks(ctx, tc.filter)
+			if err != nil || total != len(tc.want) || !reflect.DeepEqual(slugs(artworks), tc.want) {
+				t.Errorf("ListArtworks(%+v) = %v (total %d), %v; want %v", tc.filter, slugs(artworks), total, err, tc.want)
+			}
+		}
+		artworks, total, err := f.repo.ListArtworks(ctx, models.ArtworkFilter{Page: 2, Limit: 3})
+		if err != nil || total != 4 || !reflect.DeepEqual(slugs(artworks), []string{"first"}) {
+			t.Errorf("ListArtworks(page 2) = %v (total %d), %v", slugs(artworks), total, err)
+		}
+		if artworks[0].ArtistName != "Wang_Bu" || override.ArtistName != "Wang_Bu" {
+			t.Errorf("ArtistName = %q and %q, want Wang_Bu", artworks[0].ArtistName, override.ArtistName)
+		}
+
+		categories, err := f.repo.ListCategories(ctx)
+		want := []models.GalleryCategory{{Name: "blue and white", ArtworkCount: 2}, {Name: "famille rose", ArtworkCount: 1}}
+		if err != nil || !reflect.DeepEqual(categories, want) {
+			t.Errorf("ListCategories = %+v, %v; want %+v", categories, err, want)
+		}
+	})
+
+	t.Run("Artists", func(t *testing.T) {
+		f := newFixture(t)
+		wang := createArtist(t, f, "Wang Bu", "wang-bu")
+		li, err := f.repo.CreateArtist(ctx, models.CreateArtistData{Name: "Li Ming", Slug: "li-ming", Bio: "Painter"})
+		if err != nil {
+			t.Fatalf("CreateArtist: %v", err)
+		}
+		if li.ID == 0 || li.Bio != "Painter" || li.UserID != nil || li.ArtworkCount != 0 || li.CreatedAt.IsZero() {
+			t.Errorf("CreateArtist = %+v", li)
+		}
+		if _, err := f.repo.CreateArtist(ctx, models.CreateArtistData{Name: "Another", Slug: "wang-bu"}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("CreateArtist with a taken slug: error = %v, want ErrConflict", err)
+		}
+		createArtwork(t, f, "undated", "", &wang.ID, nil)
+		createArtwork(t, f, "late", "", &wang.ID, year(1990))
+		createArtwork(t, f, "early", "", &wang.ID, year(1950))
+
+		artists, total, err := f.repo.ListArtists(ctx, 1, 10)
+		if err != nil || total != 2 || len(artists) != 2 || artists[0].Name != "Li Ming" || artists[1].ArtworkCount != 3 {
+			t.Errorf("ListArtists = %+v (total %d), %v", artists, total, err)
+		}
+		if artists, total, _ := f.repo.ListArtists(ctx, 2, 1); total != 2 || len(artists) != 1 || artists[0].ID != wang.ID {
+			t.Errorf("ListArtists(page 2) = %+v (total %d)", artists, total)
+		}
+		if byArtist, err := f.repo.ListArtworksByArtist(ctx, wang.ID); err != nil || !reflect.DeepEqual(slugs(byArtist), []string{"early", "late", "undated"}) {
+			t.Errorf("ListArtworksByArtist = %v, %v", slugs(byArtist), err)
+		}
+		if byArtist, err := f.repo.ListArtworksByArtist(ctx, li.ID); err != nil || byArtist == nil || len(byArtist) != 0 {
+			t.Errorf("ListArtworksByArtist(no artworks) = %v, %v; want an empty non-nil slice", byArtist, err)
+		}
+
+		name, bio := "Wang Bu (1950-2020)", ""
+		updated, err := f.repo.UpdateArtist(ctx, wang.ID, models.UpdateArtistData{Name: &name, Bio: &bio})
+		if err != nil || updated.Name != name || updated.Slug != "wang-bu" || updated.ArtworkCount != 3 {
+			t.Errorf("UpdateArtist = %+v, %v", updated, err)
+		}
+		taken := "li-ming"
+		if _, err := f.repo.UpdateArtist(ctx, wang.ID, models.UpdateArtistData{Slug: &taken}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("UpdateArtist to a taken slug: error = %v, want ErrConflict", err)
+		}
+		if _, err := f.repo.UpdateArtist(ctx, wang.ID+100, models.UpdateArtistData{Name: &name}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateArtist(missing): error = %v, want ErrNotFound", err)
+		}
+
+		// Deleting the artist keeps its artworks
+		if err := f.repo.DeleteArtist(ctx, wang.ID); err != nil {
+			t.Fatalf("DeleteArtist: %v", err)
+		}
+		if _, err := f.repo.FindArtistByID(ctx, wang.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindArtistByID after delete: error = %v, want ErrNotFound", err)
+		}
+		artworks, total, _ := f.repo.ListArtworks(ctx, models.ArtworkFilter{Page: 1, Limit: 10})
+		if total != 3 || artworks[0].ArtistID != nil || artworks[0].ArtistName != "" {
+			t.Errorf("artworks after DeleteArtist = %+v, want them kept without artist", artworks)
+		}
+		if err := f.repo.DeleteArtist(ctx, wang.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteArtist twice: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("FavoritesAndNotes", func(t *testing.T) {
+		f := newFixture(t)
+		artwork := createArtwork(t, f, "vase", "", nil, nil)
+		fan, other := f.addUser(), f.addUser()
+
+		for i := 0; i < 2; i++ {
+			if err := f.repo.AddFavorite(ctx, fan, artwork.ID); err != nil {
+				t.Fatalf("AddFavorite %d: %v", i, err)
+			}
+		}
+		if err := f.repo.AddFavorite(ctx, other, artwork.ID); err != nil {
+			t.Fatalf("AddFavorite: %v", err)
+		}
+		if err := f.repo.AddFavorite(ctx, fan, artwork.ID+100); err == nil {
+			t.Error("AddFavorite accepted an artwork that does not exist")
+		}
+		if ok, err := f.repo.IsFavorite(ctx, fan, artwork.ID); err != nil || !ok {
+			t.Errorf("IsFavorite = %v, %v", ok, err)
+		}
+		if n, err := f.repo.CountFavorites(ctx, artwork.ID); err != nil || n != 2 {
+			t.Errorf("CountFavorites = %d, %v; want 2", n, err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := f.repo.RemoveFavorite(ctx, fan, artwork.ID); err != nil {
+				t.Errorf("RemoveFavorite %d: %v", i, err)
+			}
+		}
+		if ok, _ := f.repo.IsFavorite(ctx, fan, artwork.ID); ok {
+			t.Error("IsFavorite after RemoveFavorite = true")
+		}
+
+		f.addNote(artwork.ID)
+		f.addNote(artwork.ID)
+		found, err := f.repo.FindArtworkByID(ctx, artwork.ID)
+		if err != nil || found.FavoriteCount != 1 || found.NoteCount != 2 {
+			t.Errorf("FindArtworkByID = %+v, %v; want 1 favorite and 2 notes", found, err)
+		}
+	})
+}
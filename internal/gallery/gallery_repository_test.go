@@ -0,0 +1,98 @@
+package gallery
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func TestRepositoryListArtworksArtistNameIsLiteral(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	wang := factory.Artist(t, db, func(a *models.Artist) { a.Name = "Wang_Bu" })
+	factory.Artist(t, db, func(a *models.Artist) { a.Name = "WangXBu" })
+	artwork := factory.Artwork(t, db, func(a *models.Artwork) { a.ArtistID = &wang.ID })
+	factory.Artwork(t, db) // By another artist
+
+	for _, name := range []string{"wang_bu", "%"} {
+		artworks, total, err := repo.ListArtworks(context.Background(), models.ArtworkFilter{Page: 1, Limit: 10, Artist: name})
+		if err != nil {
+			t.Fatalf("ListArtworks(artist=%q): %v", name, err)
+		}
+		want := 0
+		if name == "wang_bu" {
+			want = 1
+		}
+		if total != want || len(artworks) != want || (want == 1 && artworks[0].ID != artwork.ID) {
+			t.Errorf("ListArtworks(artist=%q) = %d artworks (total %d), want %d", name, len(artworks), total, want)
+		}
+	}
+}
+
+func TestRepositoryEditArtworks(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	artist, err := repo.CreateArtist(ctx, models.CreateArtistData{Name: "Wang Bu", Slug: "wang-bu"})
+	if err != nil {
+		t.Fatalf("CreateArtist: %v", err)
+	}
+	if _, err := repo.CreateArtist(ctx, models.CreateArtistData{Name: "Another Wang Bu", Slug: "wang-bu"}); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("CreateArtist with a taken slug: error = %v, want ErrConflict", err)
+	}
+
+	artwork, err := repo.CreateArtwork(ctx, models.CreateArtworkData{
+		Title: "Blue and white vase", Slug: "blue-and-white-vase", ArtistID: &artist.ID,
+		ThumbnailURL: "/vase.jpg", Category: "blue and white",
+		ImageURLs: []string{"https://example.com/front.jpg", "https://example.com/back.jpg"},
+		Tags:      []string{"vase", "qing"},
+	})
+	if err != nil {
+		t.Fatalf("CreateArtwork: %v", err)
+	}
+	if artwork.ArtistName != "Wang Bu" || artwork.Slug != "blue-and-white-vase" {
+		t.Errorf("CreateArtwork = %+v", artwork)
+	}
+	images, err := repo.GetArtworkImages(ctx, artwork.ID)
+	if err != nil || len(images) != 2 || !images[0].IsPrimary || images[0].ImageURL != "https://example.com/front.jpg" {
+		t.Errorf("images = %+v (%v), want the first one primary", images, err)
+	}
+
+	title := "Qing blue and white vase"
+	updated, err := repo.UpdateArtwork(ctx, artwork.ID, models.UpdateArtworkData{Title: &title, Tags: []string{"vase"}})
+	if err != nil {
+		t.Fatalf("UpdateArtwork: %v", err)
+	}
+	if updated.Title != title || updated.Category != "blue and white" {
+		t.Errorf("UpdateArtwork = %+v, want the new title and the old category", updated)
+	}
+	if tags, err := repo.GetArtworkTags(ctx, artwork.ID); err != nil || !reflect.DeepEqual(tags, []string{"vase"}) {
+		t.Errorf("tags = %v (%v), want [vase]", tags, err)
+	}
+	if _, err := repo.UpdateArtwork(ctx, 999999, models.UpdateArtworkData{Title: &title}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateArtwork of a missing artwork: error = %v, want ErrNotFound", err)
+	}
+
+	// Deleting the artist keeps its artworks
+	if err := repo.DeleteArtist(ctx, artist.ID); err != nil {
+		t.Fatalf("DeleteArtist: %v", err)
+	}
+	if kept, err := repo.FindArtworkByID(ctx, artwork.ID); err != nil || kept.ArtistID != nil {
+		t.Errorf("artwork after DeleteArtist = %+v (%v), want it kept without artist", kept, err)
+	}
+	if err := repo.DeleteArtwork(ctx, artwork.ID); err != nil {
+		t.Fatalf("DeleteArtwork: %v", err)
+	}
+	if err := repo.DeleteArtwork(ctx, artwork.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("deleting twice: error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,250 @@
+package gallery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/user"
+	"jingdezhen-ceramics-backend/pkg/utils"
+)
+
+const noteEntityTypeArtwork = "artwork"
+
+// ServiceInterface defines the methods for gallery business logic.
+type ServiceInterface interface {
+	ListArtworks(ctx context.Context, filter models.ArtworkFilter) ([]models.Artwork, int, error)
+	// GetArtworkDetail returns the artwork with images and tags.
+	// viewerID is empty for guests; otherwise IsFavorite reflects the viewer's state.
+	GetArtworkDetail(ctx context.Context, artworkID int64, viewerID string) (*models.Artwork, error)
+	GetCategories(ctx context.Context) ([]models.GalleryCategory, error)
+
+	ListArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error)
+	GetArtistDetail(ctx context.Context, artistID int) (*models.Artist, error)
+
+	MarkAsFavorite(ctx context.Context, userID string, artworkID int64) (*models.ToggleResult, error)
+	UnmarkAsFavorite(ctx context.Context, userID string, artworkID int64) (*models.ToggleResult, error)
+	AddNoteToArtwork(ctx context.Context, userID string, artworkID int64, title, content string) (*models.UserNote, error)
+
+	// Editing, routed behind models.PermGalleryEdit. A taken slug is models.ErrConflict and an
+	// artist_id that does not exist is models.ErrInvalidArtworkArtistID.
+	CreateArtwork(ctx context.Context, data models.CreateArtworkData) (*models.Artwork, error)
+	UpdateArtwork(ctx context.Context, artworkID int64, data models.UpdateArtworkData) (*models.Artwork, error)
+	DeleteArtwork(ctx context.Context, artworkID int64) error
+	CreateArtist(ctx context.Context, data models.CreateArtistData) (*models.Artist, error)
+	UpdateArtist(ctx context.Context, artistID int, data models.UpdateArtistData) (*models.Artist, error)
+	DeleteArtist(ctx context.Context, artistID int) error
+}
+
+// Service provides business logic for the gallery.
+type Service struct {
+	repo    RepositoryInterface
+	userSvc user.ServiceInterface // Injected for creating artwork notes
+}
+
+// NewService creates a new gallery service.
+func NewService(repo RepositoryInterface, userSvc user.ServiceInterface) ServiceInterface {
+	return &Service{repo: repo, userSvc: userSvc}
+}
+
+func (s *Service) ListArtworks(ctx context.Context, filter models.ArtworkFilter) ([]models.Artwork, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	} // Default/max limit
+	artworks, total, err := s.repo.ListArtworks(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListArtworks: %w", err)
+	}
+	return artworks, total, nil
+}
+
+func (s *Service) GetArtworkDetail(ctx context.Context, artworkID int64, viewerID string) (*models.Artwork, error) {
+	artwork, err := s.repo.FindArtworkByID(ctx, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetArtworkDetail: %w", err)
+	}
+
+	images, err := s.repo.GetArtworkImages(ctx, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetArtworkDetail.Images: %w", err)
+	}
+	artwork.Images = images
+
+	tags, err := s.repo.GetArtworkTags(ctx, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetArtworkDetail.Tags: %w", err)
+	}
+	artwork.Tags = tags
+
+	if viewerID != "" {
+		isFavorite, err := s.repo.IsFavorite(ctx, viewerID, artworkID)
+		if err != nil {
+			return nil, fmt.Errorf("service.GetArtworkDetail.IsFavorite: %w", err)
+		}
+		artwork.IsFavorite = isFavorite
+	}
+	return artwork, nil
+}
+
+func (s *Service) GetCategories(ctx context.Context) ([]models.GalleryCategory, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetCategories: %w", err)
+	}
+	return categories, nil
+}
+
+func (s *Service) ListArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	artists, total, err := s.repo.ListArtists(ctx, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListArtists: %w", err)
+	}
+	return artists, total, nil
+}
+
+func (s *Service) GetArtistDetail(ctx context.Context, artistID int) (*models.Artist, error) {
+	artist, err := s.repo.FindArtistByID(ctx, artistID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetArtistDetail: %w", err)
+	}
+	artworks, err := s.repo.ListArtworksByArtist(ctx, artistID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetArtistDetail.Artworks: %w", err)
+	}
+	artist.Artworks = artworks
+	return artist, nil
+}
+
+func (s *Service) MarkAsFavorite(ctx context.Context, userID string, artworkID int64) (*models.ToggleResult, error) {
+	if _, err := s.repo.FindArtworkByID(ctx, artworkID); err != nil {
+		return nil, fmt.Errorf("service.MarkAsFavorite: %w", err)
+	}
+	if err := s.repo.AddFavorite(ctx, userID, artworkID); err != nil {
+		return nil, fmt.Errorf("service.MarkAsFavorite: %w", err)
+	}
+	count, err := s.repo.CountFavorites(ctx, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("service.MarkAsFavorite: %w", err)
+	}
+	return &models.ToggleResult{Active: true, Count: count}, nil
+}
+
+func (s *Service) UnmarkAsFavorite(ctx context.Context, userID string, artworkID int64) (*models.ToggleResult, error) {
+	if _, err := s.repo.FindArtworkByID(ctx, artworkID); err != nil {
+		return nil, fmt.Errorf("service.UnmarkAsFavorite: %w", err)
+	}
+	if err := s.repo.RemoveFavorite(ctx, userID, artworkID); err != nil {
+		return nil, fmt.Errorf("service.UnmarkAsFavorite: %w", err)
+	}
+	count, err := s.repo.CountFavorites(ctx, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("service.UnmarkAsFavorite: %w", err)
+	}
+	return &models.ToggleResult{Active: false, Count: count}, nil
+}
+
+func (s *Service) AddNoteToArtwork(ctx context.Context, userID string, artworkID int64, title, content string) (*models.UserNote, error) {
+	artwork, err := s.repo.FindArtworkByID(ctx, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("service.AddNoteToArtwork: %w", err)
+	}
+	if title == "" {
+		title = artwork.Title
+	}
+
+	entityType := noteEntityTypeArtwork
+	entityID := int(artworkID)
+	note, err := s.userSvc.CreateUserNote(ctx, userID, models.CreateUserNoteData{
+		Title:      title,
+		Content:    content,
+		EntityType: &entityType,
+		EntityID:   &entityID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.AddNoteToArtwork: %w", err)
+	}
+	return note, nil
+}
+
+// --- Editing ---
+
+func (s *Service) CreateArtwork(ctx context.Context, data models.CreateArtworkData) (*models.Artwork, error) {
+	if err := s.checkArtist(ctx, data.ArtistID); err != nil {
+		return nil, fmt.Errorf("service.CreateArtwork: %w", err)
+	}
+	data.Tags = utils.NormalizeTags(data.Tags)
+
+	artwork, err := s.repo.CreateArtwork(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateArtwork: %w", err)
+	}
+	return artwork, nil
+}
+
+func (s *Service) UpdateArtwork(ctx context.Context, artworkID int64, data models.UpdateArtworkData) (*models.Artwork, error) {
+	if err := s.checkArtist(ctx, data.ArtistID); err != nil {
+		return nil, fmt.Errorf("service.UpdateArtwork: %w", err)
+	}
+	if data.Tags != nil {
+		data.Tags = utils.NormalizeTags(data.Tags)
+	}
+
+	artwork, err := s.repo.UpdateArtwork(ctx, artworkID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateArtwork: %w", err)
+	}
+	return artwork, nil
+}
+
+func (s *Service) DeleteArtwork(ctx context.Context, artworkID int64) error {
+	if err := s.repo.DeleteArtwork(ctx, artworkID); err != nil {
+		return fmt.Errorf("service.DeleteArtwork: %w", err)
+	}
+	return nil
+}
+
+// checkArtist returns models.ErrInvalidArtworkArtistID if artistID is set but no such artist exists.
+func (s *Service) checkArtist(ctx context.Context, artistID *int) error {
+	if artistID == nil {
+		return nil
+	}
+	if _, err := s.repo.FindArtistByID(ctx, *artistID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return models.ErrInvalidArtworkArtistID
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) CreateArtist(ctx context.Context, data models.CreateArtistData) (*models.Artist, error) {
+	artist, err := s.repo.CreateArtist(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateArtist: %w", err)
+	}
+	return artist, nil
+}
+
+func (s *Service) UpdateArtist(ctx context.Context, artistID int, data models.UpdateArtistData) (*models.Artist, error) {
+	artist, err := s.repo.UpdateArtist(ctx, artistID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateArtist: %w", err)
+	}
+	return artist, nil
+}
+
+func (s *Service) DeleteArtist(ctx context.Context, artistID int) error {
+	if err := s.repo.DeleteArtist(ctx, artistID); err != nil {
+		return fmt.Errorf("service.DeleteArtist: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+// Package migrate applies the versioned SQL files of internal/migrations and records them in the
+// schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey is the pg_advisory_lock key held while migrating, so API instances starting together with
+// AUTO_MIGRATE and a manual cmd/migrate run never apply the same migration twice.
+const lockKey = 7318240021
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one schema version with the SQL to apply and to revert it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and whether it has been applied.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // nil while pending
+}
+
+// Migrator runs migrations against one database.
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration // Sorted by version
+	logf       func(format string, args ...any)
+}
+
+// New reads the migrations in the root of fsys. Every version needs both an up and a down file.
+// logf receives one line per applied or reverted migration; nil discards them.
+func New(db *pgxpool.Pool, fsys fs.FS, logf func(format string, args ...any)) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	return &Migrator{db: db, migrations: migrations, logf: logf}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		m := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrate: %s: invalid version", entry.Name())
+		}
+		sql, err := fs.ReadFile(fsys, path.Join(".", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migrate: version %d is used by both %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(sql)
+		} else {
+			mig.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migrate: version %d (%s) needs both an up and a down file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest returns the highest known version, 0 if there are none.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration and returns how many it applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the newest steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps < 1 {
+		return 0, nil
+	}
+	var n int
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && n < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// To migrates up or down until exactly the migrations up to version are applied. Version 0 reverts
+// everything. It returns how many migrations it applied or reverted.
+func (m *Migrator) To(ctx context.Context, version int64) (int, error) {
+	if version < 0 {
+		return 0, fmt.Errorf("migrate: invalid version %d", version)
+	}
+	if version != 0 && !m.known(version) {
+		return 0, fmt.Errorf("migrate: unknown version %d", version)
+	}
+	var n int
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.revert(ctx, conn, mig); err != nil {
+					return err
+				}
+				n++
+			}
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(ctx, conn, mig); err != nil {
+					return err
+				}
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+// Status lists every known migration with the time it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(_ *pgxpool.Conn, applied map[int64]time.Time) error {
+		for _, mig := range m.migrations {
+			s := Status{Version: mig.Version, Name: mig.Name}
+			if at, ok := applied[mig.Version]; ok {
+				s.AppliedAt = &at
+			}
+			statuses = append(statuses, s)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// withLock holds the advisory lock on one connection for the whole run; a second migrator waits
+// until the first is done and then sees its results.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn, applied map[int64]time.Time) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("migrate: lock: %w", err)
+	}
+	defer func() {
+		// A fresh context: the caller's may be cancelled, and the lock must not outlive the run
+		// on a pooled connection.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			conn.Conn().Close(unlockCtx) // Dropping the session releases the lock
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return fmt.Errorf("migrate: create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+func appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("migrate: read schema_migrations: %w", err)
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("migrate: read schema_migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// apply runs one migration and records it in the same transaction, so a failing file leaves no trace.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, mig Migration) error {
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Up); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: apply %06d_%s: %w", mig.Version, mig.Name, err)
+	}
+	m.logf("migrate: applied %06d_%s", mig.Version, mig.Name)
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *pgxpool.Conn, mig Migration) error {
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Down); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+		if err == nil && tag.RowsAffected() == 0 {
+			err = errors.New("not recorded as applied")
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: revert %06d_%s: %w", mig.Version, mig.Name, err)
+	}
+	m.logf("migrate: reverted %06d_%s", mig.Version, mig.Name)
+	return nil
+}
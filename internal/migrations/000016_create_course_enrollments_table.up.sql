@@ -0,0 +1,8 @@
+CREATE TABLE course_enrollments (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    course_id INT NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    enrolled_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ, -- Set once every chapter is completed
+    PRIMARY KEY (user_id, course_id)
+);
+CREATE INDEX ON course_enrollments (course_id);
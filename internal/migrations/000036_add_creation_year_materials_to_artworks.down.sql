@@ -0,0 +1,3 @@
+ALTER TABLE artworks
+    DROP COLUMN IF EXISTS materials,
+    DROP COLUMN IF EXISTS creation_year;
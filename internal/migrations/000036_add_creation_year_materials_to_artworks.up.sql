@@ -0,0 +1,3 @@
+ALTER TABLE artworks
+    ADD COLUMN creation_year INT,
+    ADD COLUMN materials VARCHAR(255); -- e.g., "Porcelain, cobalt blue underglaze"
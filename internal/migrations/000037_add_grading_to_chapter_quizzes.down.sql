@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_user_quiz_attempts_user_quiz;
+
+ALTER TABLE user_quiz_attempts
+    DROP COLUMN IF EXISTS passed;
+
+ALTER TABLE chapter_quizzes
+    DROP COLUMN IF EXISTS marks_chapter_complete,
+    DROP COLUMN IF EXISTS pass_threshold;
@@ -0,0 +1,7 @@
+ALTER TABLE chapter_quizzes
+    ADD COLUMN pass_threshold INT NOT NULL DEFAULT 60 CHECK (pass_threshold >= 0 AND pass_threshold <= 100), -- Minimum score (%) to pass
+    ADD COLUMN marks_chapter_complete BOOLEAN NOT NULL DEFAULT FALSE; -- Passing completes the chapter
+
+ALTER TABLE user_quiz_attempts
+    ADD COLUMN passed BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX idx_user_quiz_attempts_user_quiz ON user_quiz_attempts (user_id, quiz_id, quiz_type);
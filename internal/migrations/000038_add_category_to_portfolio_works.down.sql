@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_portfolio_works_kudos_count;
+DROP INDEX IF EXISTS idx_portfolio_works_category;
+
+ALTER TABLE portfolio_works
+    DROP COLUMN IF EXISTS category;
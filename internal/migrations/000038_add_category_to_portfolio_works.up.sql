@@ -0,0 +1,5 @@
+ALTER TABLE portfolio_works
+    ADD COLUMN category VARCHAR(100); -- e.g., "Throwing", "Glazing", "Painting"
+
+CREATE INDEX idx_portfolio_works_category ON portfolio_works(category);
+CREATE INDEX idx_portfolio_works_kudos_count ON portfolio_works(kudos_count DESC);
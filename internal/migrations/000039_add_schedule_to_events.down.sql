@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_events_start_date;
+
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_end_after_start,
+    DROP COLUMN IF EXISTS capacity,
+    DROP COLUMN IF EXISTS location,
+    DROP COLUMN IF EXISTS end_date,
+    DROP COLUMN IF EXISTS start_date;
@@ -0,0 +1,8 @@
+ALTER TABLE events
+    ADD COLUMN start_date TIMESTAMPTZ,
+    ADD COLUMN end_date TIMESTAMPTZ,
+    ADD COLUMN location VARCHAR(255),
+    ADD COLUMN capacity INT CHECK (capacity IS NULL OR capacity > 0), -- NULL means unlimited
+    ADD CONSTRAINT events_end_after_start CHECK (end_date IS NULL OR start_date IS NULL OR end_date >= start_date);
+
+CREATE INDEX idx_events_start_date ON events(start_date);
@@ -0,0 +1,3 @@
+ALTER TABLE ceramic_stories
+    DROP CONSTRAINT IF EXISTS ceramic_stories_slug_key,
+    DROP COLUMN IF EXISTS slug;
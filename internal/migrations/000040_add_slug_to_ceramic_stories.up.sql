@@ -0,0 +1,14 @@
+ALTER TABLE ceramic_stories
+    ADD COLUMN slug VARCHAR(100); -- e.g., "ming-dynasty"
+
+-- Backfill existing rows from the dynasty name. Names without ASCII letters or digits (e.g. "明") and
+-- all-digit ones, which the detail route would read as an ID, fall back to 'story-<id>', as do names that
+-- collapse to the slug of an earlier row or could clash with a fallback.
+UPDATE ceramic_stories SET slug = trim(both '-' from regexp_replace(lower(dynasty_name), '[^a-z0-9]+', '-', 'g'));
+UPDATE ceramic_stories cs SET slug = 'story-' || cs.id
+WHERE cs.slug !~ '[a-z]' OR cs.slug ~ '^story-[0-9]+$'
+   OR EXISTS (SELECT 1 FROM ceramic_stories o WHERE o.slug = cs.slug AND o.id < cs.id);
+
+ALTER TABLE ceramic_stories
+    ALTER COLUMN slug SET NOT NULL,
+    ADD CONSTRAINT ceramic_stories_slug_key UNIQUE (slug);
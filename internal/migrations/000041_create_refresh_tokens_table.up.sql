@@ -0,0 +1,13 @@
+CREATE TABLE refresh_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL, -- One family per login; every rotation stays in it. Access tokens carry it as "sid"
+    token_hash CHAR(64) NOT NULL UNIQUE, -- SHA-256 hex of the token, the token itself is never stored
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ, -- Set when rotated; presenting a used token again revokes the family
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id) WHERE revoked_at IS NULL;
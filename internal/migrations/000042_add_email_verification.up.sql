@@ -0,0 +1,15 @@
+-- Accounts that exist before this migration are treated as verified; the default then switches to FALSE for new sign-ups.
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT FALSE;
+
+CREATE TABLE email_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    token_hash CHAR(64) NOT NULL UNIQUE, -- SHA-256 hex of the signed token sent by email
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_tokens_user_purpose ON email_tokens(user_id, purpose, created_at);
@@ -0,0 +1,22 @@
+CREATE TABLE email_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    to_addresses TEXT[] NOT NULL DEFAULT '{}',
+    cc_addresses TEXT[] NOT NULL DEFAULT '{}',
+    bcc_addresses TEXT[] NOT NULL DEFAULT '{}',
+    reply_to TEXT,
+    subject TEXT NOT NULL,
+    text_body TEXT,
+    html_body TEXT,
+    attachments JSONB, -- [{"Filename", "ContentType", "Data" (base64)}]
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sending', 'sent', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 8,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- For 'sending' rows this is when the worker's lease runs out
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_outbox_due ON email_outbox(next_attempt_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX idx_email_outbox_dead ON email_outbox(updated_at DESC) WHERE status = 'dead';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contact_message_replies;
+DROP TABLE IF EXISTS contact_messages;
@@ -0,0 +1,24 @@
+CREATE TABLE contact_messages (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'replied', 'closed')),
+    last_replied_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_contact_messages_status ON contact_messages(status, created_at DESC);
+
+CREATE TABLE contact_message_replies (
+    id BIGSERIAL PRIMARY KEY,
+    contact_message_id BIGINT NOT NULL REFERENCES contact_messages(id) ON DELETE CASCADE,
+    admin_id INT REFERENCES users(id) ON DELETE SET NULL, -- Kept when the admin account is deleted
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_contact_message_replies_message_id ON contact_message_replies(contact_message_id);
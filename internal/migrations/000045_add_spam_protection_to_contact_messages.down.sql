@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_contact_messages_email;
+DROP INDEX IF EXISTS idx_contact_messages_ip_address;
+
+DELETE FROM contact_messages WHERE status = 'spam';
+ALTER TABLE contact_messages DROP CONSTRAINT contact_messages_status_check;
+ALTER TABLE contact_messages ADD CONSTRAINT contact_messages_status_check CHECK (status IN ('new', 'replied', 'closed'));
+
+ALTER TABLE contact_messages
+    DROP COLUMN IF EXISTS spam_reason,
+    DROP COLUMN IF EXISTS ip_address;
@@ -0,0 +1,10 @@
+ALTER TABLE contact_messages
+    ADD COLUMN ip_address VARCHAR(45), -- Submitter IP for the per-IP rate limit
+    ADD COLUMN spam_reason TEXT; -- Why a submission was flagged; only set for status 'spam'
+
+-- Flagged submissions are kept as 'spam' so they still count towards the rate limits and false positives can be reviewed.
+ALTER TABLE contact_messages DROP CONSTRAINT contact_messages_status_check;
+ALTER TABLE contact_messages ADD CONSTRAINT contact_messages_status_check CHECK (status IN ('new', 'replied', 'closed', 'spam'));
+
+CREATE INDEX idx_contact_messages_ip_address ON contact_messages(ip_address, created_at);
+CREATE INDEX idx_contact_messages_email ON contact_messages(LOWER(email), created_at);
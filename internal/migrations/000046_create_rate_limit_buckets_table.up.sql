@@ -0,0 +1,9 @@
+-- Token buckets of ratelimit.PostgresStore, shared by every API instance.
+CREATE TABLE rate_limit_buckets (
+    key TEXT PRIMARY KEY, -- "<limit name>:user:<id>" or "<limit name>:ip:<address>"
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL -- The bucket is full again from here on and can be deleted
+);
+
+CREATE INDEX idx_rate_limit_buckets_expires_at ON rate_limit_buckets(expires_at);
@@ -0,0 +1,7 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+UPDATE users SET role = 'normal_user' WHERE role NOT IN ('guest', 'normal_user', 'admin');
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('guest', 'normal_user', 'admin'));
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,53 @@
+CREATE TABLE roles (
+    name VARCHAR(20) PRIMARY KEY, -- Matches users.role
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE permissions (
+    name VARCHAR(100) PRIMARY KEY, -- e.g. 'forum.moderate'
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role VARCHAR(20) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access; always has every permission'),
+    ('moderator', 'Moderates the forum, portfolio and contact inbox'),
+    ('teacher', 'Authors courses and follows student progress'),
+    ('curator', 'Edits gallery and ceramic story content'),
+    ('normal_user', 'Registered member'),
+    ('guest', 'Not logged in');
+
+INSERT INTO permissions (name, description) VALUES
+    ('users.manage', 'List users and change their roles'),
+    ('roles.manage', 'Change which permissions each role has'),
+    ('forum.moderate', 'Pin, archive and delete any forum post or comment'),
+    ('portfolio.moderate', 'Highlight and delete any portfolio work'),
+    ('gallery.edit', 'Create, edit and delete artworks and artists'),
+    ('ceramicstory.edit', 'Create, edit and delete ceramic story entries'),
+    ('course.author', 'Create, edit and delete courses, chapters and quizzes'),
+    ('course.view_progress', 'View the student progress dashboard'),
+    ('emails.manage', 'Inspect and replay outgoing email, preview templates'),
+    ('contact.manage', 'Read and answer contact form messages');
+
+-- admin is listed for completeness; the application grants it everything regardless.
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('moderator', 'forum.moderate'),
+    ('moderator', 'portfolio.moderate'),
+    ('moderator', 'contact.manage'),
+    ('teacher', 'course.author'),
+    ('teacher', 'course.view_progress'),
+    ('curator', 'gallery.edit'),
+    ('curator', 'ceramicstory.edit');
+
+-- users.role now references roles instead of a fixed CHECK list, so new roles need no schema change.
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_role_changes;
+DROP FUNCTION IF EXISTS user_role_changes_immutable();
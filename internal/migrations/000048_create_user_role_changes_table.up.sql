@@ -0,0 +1,27 @@
+-- Append-only audit trail of role changes. User ids are stored without foreign keys so entries
+-- survive account deletion unchanged.
+CREATE TABLE user_role_changes (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INT NOT NULL, -- Admin who made the change
+    target_user_id INT NOT NULL,
+    old_role VARCHAR(20) NOT NULL,
+    new_role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_role_changes_target ON user_role_changes(target_user_id, created_at DESC);
+CREATE INDEX idx_user_role_changes_actor ON user_role_changes(actor_id, created_at DESC);
+
+CREATE FUNCTION user_role_changes_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'user_role_changes is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_role_changes_no_update_delete
+    BEFORE UPDATE OR DELETE ON user_role_changes
+    FOR EACH ROW EXECUTE FUNCTION user_role_changes_immutable();
+
+CREATE TRIGGER user_role_changes_no_truncate
+    BEFORE TRUNCATE ON user_role_changes
+    FOR EACH STATEMENT EXECUTE FUNCTION user_role_changes_immutable();
@@ -0,0 +1,38 @@
+-- Append-only log of administrative and destructive actions. actor_id has no foreign key so
+-- entries survive account deletion unchanged.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INT, -- NULL for actions taken by the system
+    action VARCHAR(100) NOT NULL, -- e.g. 'forum.post.delete'
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    before_data JSONB, -- Only the fields that changed
+    after_data JSONB,
+    request_id TEXT NOT NULL DEFAULT '', -- TEXT so no request header can make the insert fail
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at DESC);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor_id, created_at DESC);
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id, created_at DESC);
+CREATE INDEX idx_audit_events_action ON audit_events(action, created_at DESC);
+
+CREATE FUNCTION audit_events_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_immutable();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_immutable();
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit.view', 'Search and export the audit log');
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'audit.view');
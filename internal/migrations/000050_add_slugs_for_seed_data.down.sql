@@ -0,0 +1,3 @@
+ALTER TABLE forum_categories DROP COLUMN IF EXISTS slug;
+ALTER TABLE artworks DROP COLUMN IF EXISTS slug;
+ALTER TABLE artists DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,28 @@
+-- Stable keys so cmd/seed can upsert fixture rows. Backfilled like ceramic_stories.slug: from the name,
+-- falling back to a fixed prefix and the id for names without ASCII letters, clashes and fallback lookalikes.
+ALTER TABLE artists ADD COLUMN slug VARCHAR(150);
+UPDATE artists SET slug = trim(both '-' from regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g'));
+UPDATE artists a SET slug = 'artist-' || a.id
+WHERE a.slug !~ '[a-z]' OR a.slug ~ '^artist-[0-9]+$'
+   OR EXISTS (SELECT 1 FROM artists o WHERE o.slug = a.slug AND o.id < a.id);
+ALTER TABLE artists
+    ALTER COLUMN slug SET NOT NULL,
+    ADD CONSTRAINT artists_slug_key UNIQUE (slug);
+
+ALTER TABLE artworks ADD COLUMN slug VARCHAR(150);
+UPDATE artworks SET slug = trim(both '-' from regexp_replace(lower(title), '[^a-z0-9]+', '-', 'g'));
+UPDATE artworks a SET slug = 'artwork-' || a.id
+WHERE a.slug !~ '[a-z]' OR a.slug ~ '^artwork-[0-9]+$'
+   OR EXISTS (SELECT 1 FROM artworks o WHERE o.slug = a.slug AND o.id < a.id);
+ALTER TABLE artworks
+    ALTER COLUMN slug SET NOT NULL,
+    ADD CONSTRAINT artworks_slug_key UNIQUE (slug);
+
+ALTER TABLE forum_categories ADD COLUMN slug VARCHAR(100);
+UPDATE forum_categories SET slug = trim(both '-' from regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g'));
+UPDATE forum_categories c SET slug = 'category-' || c.id
+WHERE c.slug !~ '[a-z]' OR c.slug ~ '^category-[0-9]+$'
+   OR EXISTS (SELECT 1 FROM forum_categories o WHERE o.slug = c.slug AND o.id < c.id);
+ALTER TABLE forum_categories
+    ALTER COLUMN slug SET NOT NULL,
+    ADD CONSTRAINT forum_categories_slug_key UNIQUE (slug);
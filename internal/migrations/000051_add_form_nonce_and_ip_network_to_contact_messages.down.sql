@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_contact_messages_ip_network;
+CREATE INDEX IF NOT EXISTS idx_contact_messages_ip_address ON contact_messages(ip_address, created_at);
+
+ALTER TABLE contact_messages
+    DROP CONSTRAINT IF EXISTS contact_messages_form_nonce_key,
+    DROP COLUMN IF EXISTS form_nonce,
+    DROP COLUMN IF EXISTS ip_network;
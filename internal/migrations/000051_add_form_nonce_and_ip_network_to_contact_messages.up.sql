@@ -0,0 +1,11 @@
+ALTER TABLE contact_messages
+    ADD COLUMN ip_network VARCHAR(49), -- Rate limit key: the IPv4 address, or the /64 for IPv6 since one host usually holds a whole /64
+    ADD COLUMN form_nonce VARCHAR(32), -- From the form token; unique so a token is accepted once
+    ADD CONSTRAINT contact_messages_form_nonce_key UNIQUE (form_nonce);
+
+UPDATE contact_messages
+SET ip_network = CASE WHEN ip_address LIKE '%:%' THEN network(set_masklen(ip_address::inet, 64))::text ELSE ip_address END
+WHERE ip_address ~ '^[0-9A-Fa-f:.]+$';
+
+DROP INDEX IF EXISTS idx_contact_messages_ip_address;
+CREATE INDEX idx_contact_messages_ip_network ON contact_messages(ip_network, created_at);
@@ -0,0 +1,12 @@
+// Package migrations embeds the versioned SQL schema applied by internal/migrate.
+//
+// Files are named <version>_<description>.up.sql and .down.sql with a zero-padded, unique, increasing
+// version. Add new changes as the next version; never edit a migration that has been released.
+package migrations
+
+import "embed"
+
+// FS holds every migration file.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Values for the ?when= filter on the activity list
+const (
+	ActivityWhenUpcoming = "upcoming" // Not finished yet, soonest first
+	ActivityWhenPast     = "past"     // Already finished, most recent first
+)
+
+// Activity is an event (workshop, exhibition, kiln visit, festival...) shown on the engage page
+type Activity struct {
+	ID                int64      `json:"id" db:"id"`
+	Title             string     `json:"title" db:"title"`
+	Type              string     `json:"type" db:"type"` // e.g. "Workshop", "Exhibition", "Kiln Visit", "Festival"
+	BriefIntroduction string     `json:"brief_introduction,omitempty" db:"brief_introduction"`
+	PhotographURL     string     `json:"photograph_url,omitempty" db:"photograph_url"`
+	ArticleSlug       string     `json:"article_slug" db:"article_slug"` // Links to articles.slug
+	StartDate         *time.Time `json:"start_date,omitempty" db:"start_date"`
+	EndDate           *time.Time `json:"end_date,omitempty" db:"end_date"`
+	Location          string     `json:"location,omitempty" db:"location"`
+	Capacity          *int       `json:"capacity,omitempty" db:"capacity"` // Nil means unlimited
+	Article           *Article   `json:"article,omitempty" db:"-"`         // Only loaded for the detail view
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Article is long-form content, currently used for the activity detail pages
+type Article struct {
+	ID             int64      `json:"id" db:"id"`
+	Slug           string     `json:"slug" db:"slug"`
+	Title          string     `json:"title" db:"title"`
+	Content        string     `json:"content" db:"content"` // Markdown or HTML
+	AuthorID       *string    `json:"author_id,omitempty" db:"author_id"`
+	AuthorNickname string     `json:"author_nickname,omitempty" db:"-"` // Populated by JOIN
+	PublishedAt    *time.Time `json:"published_at,omitempty" db:"published_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ActivityFilter holds the query options for listing activities.
+type ActivityFilter struct {
+	Page  int
+	Limit int
+	Type  string
+	When  string // ActivityWhenUpcoming, ActivityWhenPast or empty for all
+}
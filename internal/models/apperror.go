@@ -0,0 +1,158 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error codes sent in ErrorResponse.Code. Clients branch on them, so existing codes must never change.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidBody        = "invalid_body"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeConflict           = "conflict"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeRateLimited        = "rate_limited"
+	CodeInternal           = "internal_error"
+	CodeServiceUnavailable = "service_unavailable"
+
+	CodeNicknameTaken        = "nickname_taken"
+	CodeInvalidForumCategory = "invalid_forum_category"
+	CodeInvalidArtist        = "invalid_artist"
+	CodeSelfKudo             = "self_kudo"
+	CodeInvalidYearRange     = "invalid_year_range"
+	CodeInvalidQuiz          = "invalid_quiz"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeInvalidRefreshToken  = "invalid_refresh_token"
+	CodeInvalidEmailToken    = "invalid_email_token"
+	CodeEmailRateLimited     = "email_rate_limited"
+	CodeEmailNotVerified     = "email_not_verified"
+	CodeContactRateLimited   = "contact_rate_limited"
+	CodeUnknownPermission    = "unknown_permission"
+	CodeInvalidRole          = "invalid_role"
+	CodeLastAdmin            = "last_admin"
+)
+
+// AppError is an error together with how the API reports it: HTTP status, stable code, a message for
+// the user and optional details. Err is the cause; it is logged but never sent to the client.
+// Handlers return AppErrors (or plain domain errors, see ToAppError) and the HTTP error handler
+// writes the ErrorResponse.
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Details string
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails returns a copy of e with details, e.g. which field failed validation.
+func (e *AppError) WithDetails(details string) *AppError {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *AppError) Wrap(err error) *AppError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// Response is the JSON body sent for e.
+func (e *AppError) Response() ErrorResponse {
+	return ErrorResponse{Code: e.Code, Message: e.Message, Details: e.Details}
+}
+
+func NewAppError(status int, code, message string) *AppError {
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+// BadRequestError is for malformed parameters, such as a non-numeric ID in the path.
+func BadRequestError(message string) *AppError {
+	return NewAppError(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// InvalidBodyError is for a request body that c.Bind could not decode.
+func InvalidBodyError(err error) *AppError {
+	return NewAppError(http.StatusBadRequest, CodeInvalidBody, "Invalid request body").WithDetails(err.Error())
+}
+
+// ValidationError is for a decoded body that failed the validate tags.
+func ValidationError(err error) *AppError {
+	return NewAppError(http.StatusBadRequest, CodeValidationFailed, "Validation failed").WithDetails(err.Error())
+}
+
+func UnauthorizedError(message string) *AppError {
+	return NewAppError(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+func ForbiddenError(message string) *AppError {
+	return NewAppError(http.StatusForbidden, CodeForbidden, message)
+}
+
+func NotFoundError(message string) *AppError {
+	return NewAppError(http.StatusNotFound, CodeNotFound, message)
+}
+
+// InternalError reports err as a 500 with a generic message.
+func InternalError(err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, CodeInternal, "Internal server error").Wrap(err)
+}
+
+// domainErrors says how each sentinel error of errors.go is reported. The message is the sentinel's own text.
+var domainErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{ErrConflict, http.StatusConflict, CodeConflict},
+	{ErrNicknameTaken, http.StatusConflict, CodeNicknameTaken},
+	{ErrInvalidForumPostCategoryID, http.StatusBadRequest, CodeInvalidForumCategory},
+	{ErrInvalidArtworkArtistID, http.StatusBadRequest, CodeInvalidArtist},
+	{ErrSelfKudo, http.StatusBadRequest, CodeSelfKudo},
+	{ErrInvalidYearRange, http.StatusBadRequest, CodeInvalidYearRange},
+	{ErrInvalidQuiz, http.StatusBadRequest, CodeInvalidQuiz},
+	{ErrInvalidCredentials, http.StatusUnauthorized, CodeInvalidCredentials},
+	{ErrInvalidRefreshToken, http.StatusUnauthorized, CodeInvalidRefreshToken},
+	{ErrInvalidEmailToken, http.StatusBadRequest, CodeInvalidEmailToken},
+	{ErrEmailRateLimited, http.StatusTooManyRequests, CodeEmailRateLimited},
+	{ErrEmailNotVerified, http.StatusForbidden, CodeEmailNotVerified},
+	{ErrContactRateLimited, http.StatusTooManyRequests, CodeContactRateLimited},
+	{ErrUnknownPermission, http.StatusBadRequest, CodeUnknownPermission},
+	{ErrInvalidRole, http.StatusBadRequest, CodeInvalidRole},
+	{ErrLastAdmin, http.StatusConflict, CodeLastAdmin},
+}
+
+// ToAppError returns the first AppError in err's chain. Otherwise a domain error anywhere in the chain
+// (errors.Is, so "service.X: %w" wrapping is fine) is reported with its status and code, and anything
+// else becomes an InternalError.
+func ToAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			return NewAppError(d.status, d.code, d.err.Error()).Wrap(err)
+		}
+	}
+	return InternalError(err)
+}
@@ -4,12 +4,15 @@ import "time"
 
 // Artist represents an artist (can be a platform user or historical)
 type Artist struct {
-	ID        int       `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Bio       string    `json:"bio,omitempty" db:"bio"`
-	UserID    *string   `json:"user_id,omitempty" db:"user_id"` // Link to users.id (UUID string)
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID           int       `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Slug         string    `json:"slug" db:"slug"`
+	Bio          string    `json:"bio,omitempty" db:"bio"`
+	UserID       *string   `json:"user_id,omitempty" db:"user_id"` // Link to users.id (UUID string)
+	ArtworkCount int       `json:"artwork_count" db:"-"`           // Calculated
+	Artworks     []Artwork `json:"artworks,omitempty" db:"-"`      // Only loaded for the artist detail view
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // ArtworkImage represents an image associated with an artwork
@@ -26,6 +29,7 @@ type ArtworkImage struct {
 type Artwork struct {
 	ID                 int64          `json:"id" db:"id"` // Use int64 for BIGSERIAL
 	Title              string         `json:"title" db:"title"`
+	Slug               string         `json:"slug" db:"slug"`
 	ArtistID           *int           `json:"artist_id,omitempty" db:"artist_id"` // FK to artists.id
 	ArtistName         string         `json:"artist_name,omitempty" db:"-"`       // Populated by JOIN if ArtistID is present
 	ArtistNameOverride string         `json:"artist_name_override,omitempty" db:"artist_name_override"`
@@ -45,17 +49,66 @@ type Artwork struct {
 	UpdatedAt          time.Time      `json:"updated_at" db:"updated_at"`
 }
 
+// CreateArtistData is sent by gallery editors to add an artist.
+type CreateArtistData struct {
+	Name string `json:"name" validate:"required,max=255"`
+	Slug string `json:"slug" validate:"required,alphanumdash,max=150"`
+	Bio  string `json:"bio,omitempty"`
+}
+
+// UpdateArtistData changes the non-nil fields of an artist.
+type UpdateArtistData struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,max=255"`
+	Slug *string `json:"slug,omitempty" validate:"omitempty,alphanumdash,max=150"`
+	Bio  *string `json:"bio,omitempty"`
+}
+
 // CreateArtworkData is for creating new artworks
 type CreateArtworkData struct {
 	Title              string   `json:"title" validate:"required,max=255"`
-	ArtistID           *int     `json:"artist_id,omitempty"`
-	ArtistNameOverride string   `json:"artist_name_override,omitempty"`
+	Slug               string   `json:"slug" validate:"required,alphanumdash,max=150"`
+	ArtistID           *int     `json:"artist_id,omitempty"` // Must exist, checked in the service
+	ArtistNameOverride string   `json:"artist_name_override,omitempty" validate:"max=255"`
+	ThumbnailURL       string   `json:"thumbnail_url" validate:"required"`
 	Description        string   `json:"description,omitempty"`
 	CreationYear       *int     `json:"creation_year,omitempty"`
-	Category           string   `json:"category" validate:"required"`
+	Dimensions         string   `json:"dimensions,omitempty" validate:"max=100"`
+	Materials          string   `json:"materials,omitempty" validate:"max=255"`
+	Category           string   `json:"category" validate:"required,max=100"`
 	Introduction       string   `json:"introduction,omitempty"`
-	ImageURLs          []string `json:"image_urls,omitempty" validate:"omitempty,dive,url"` // For initial upload
-	Tags               []string `json:"tags,omitempty"`
+	ImageURLs          []string `json:"image_urls,omitempty" validate:"omitempty,dive,url"` // The first one is the primary image
+	Tags               []string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+}
+
+// UpdateArtworkData changes the non-nil fields of an artwork.
+// A nil Tags slice leaves tags untouched, an empty one clears them. Images are only set on creation.
+type UpdateArtworkData struct {
+	Title              *string  `json:"title,omitempty" validate:"omitempty,max=255"`
+	Slug               *string  `json:"slug,omitempty" validate:"omitempty,alphanumdash,max=150"`
+	ArtistID           *int     `json:"artist_id,omitempty"`
+	ArtistNameOverride *string  `json:"artist_name_override,omitempty" validate:"omitempty,max=255"`
+	ThumbnailURL       *string  `json:"thumbnail_url,omitempty" validate:"omitempty,min=1"`
+	Description        *string  `json:"description,omitempty"`
+	CreationYear       *int     `json:"creation_year,omitempty"`
+	Dimensions         *string  `json:"dimensions,omitempty" validate:"omitempty,max=100"`
+	Materials          *string  `json:"materials,omitempty" validate:"omitempty,max=255"`
+	Category           *string  `json:"category,omitempty" validate:"omitempty,max=100"`
+	Introduction       *string  `json:"introduction,omitempty"`
+	Tags               []string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+}
+
+// ArtworkFilter holds the query options for listing gallery artworks.
+type ArtworkFilter struct {
+	Page     int
+	Limit    int
+	Category string
+	Artist   string // Artist ID or name
+}
+
+// GalleryCategory is a distinct artwork category with the number of artworks in it.
+type GalleryCategory struct {
+	Name         string `json:"name" db:"category"`
+	ArtworkCount int    `json:"artwork_count" db:"artwork_count"`
 }
 
 type UserFavArtworkEntry struct {
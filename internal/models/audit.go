@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent is a stored entry of the audit log. Before and After hold only the fields that changed;
+// one of them is empty for creations and deletions.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	ActorID    string          `json:"actor_id,omitempty"` // Empty for system actions
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditEventFilter selects entries for GET /admin/audit-events and its CSV export. Empty fields match everything.
+type AuditEventFilter struct {
+	Page       int
+	Limit      int
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time // Exclusive
+}
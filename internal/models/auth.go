@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// RegisterData is the body of POST /auth/register
+type RegisterData struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"` // bcrypt ignores bytes past 72
+	Nickname string `json:"nickname" validate:"required,min=1,max=100"`
+}
+
+// LoginData is the body of POST /auth/login
+type LoginData struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// AuthResponse is returned after a successful login or refresh
+type AuthResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"` // Always "Bearer"
+	ExpiresIn    int    `json:"expires_in"` // Seconds until AccessToken expires
+	RefreshToken string `json:"refresh_token"`
+	User         *User  `json:"user"`
+}
+
+// RefreshTokenData is the body of POST /auth/refresh and POST /auth/logout
+type RefreshTokenData struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RefreshToken is a stored refresh token. The raw token is only ever returned to the client;
+// the table keeps its SHA-256 hash.
+type RefreshToken struct {
+	ID        int64
+	UserID    string
+	FamilyID  string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// Purposes of the single-use tokens sent by email (email_tokens.purpose)
+const (
+	EmailTokenPurposeVerifyEmail   = "verify_email"
+	EmailTokenPurposeResetPassword = "reset_password"
+)
+
+// VerifyEmailData is the body of POST /auth/verify-email
+type VerifyEmailData struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// PasswordResetRequestData is the body of POST /auth/password-reset/request
+type PasswordResetRequestData struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// PasswordResetConfirmData is the body of POST /auth/password-reset/confirm
+type PasswordResetConfirmData struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
@@ -7,5 +7,7 @@ type JwtCustomClaims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"` // e.g., RoleAdmin, RoleNormalUser
+	// SessionID is the refresh token family the access token was issued with. Empty for tokens not issued by /auth.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -24,10 +24,10 @@ type CeramicStory struct {
 // This would typically be used by an admin interface.
 type CreateCeramicStoryData struct {
 	DynastyName          string `json:"dynasty_name" validate:"required,max=100"`
-	Slug                 string `json:"slug" validate:"required,alphanumdash,max=100"` // Alphanumeric + dashes
-	Period               string `json:"period,omitempty" validate:"max=100"`
-	StartYear            *int   `json:"start_year,omitempty" validate:"omitempty,ltecsfield=EndYear"`
-	EndYear              *int   `json:"end_year,omitempty" validate:"omitempty,gtecsfield=StartYear"`
+	Slug                 string `json:"slug" validate:"required,alphanumdash,max=100"` // Lowercase alphanumeric + dashes
+	Period               string `json:"period,omitempty" validate:"max=50"`
+	StartYear            *int   `json:"start_year,omitempty"` // Must not be after EndYear, checked in the service
+	EndYear              *int   `json:"end_year,omitempty"`
 	Description          string `json:"description" validate:"required"`
 	CharacteristicsCraft string `json:"characteristics_craft,omitempty"`
 	CharacteristicsArt   string `json:"characteristics_art,omitempty"`
@@ -41,9 +41,9 @@ type CreateCeramicStoryData struct {
 type UpdateCeramicStoryData struct {
 	DynastyName          *string `json:"dynasty_name,omitempty" validate:"omitempty,max=100"`
 	Slug                 *string `json:"slug,omitempty" validate:"omitempty,alphanumdash,max=100"`
-	Period               *string `json:"period,omitempty" validate:"omitempty,max=100"`
-	StartYear            *int    `json:"start_year,omitempty" validate:"omitempty,ltecsfield=EndYear"`
-	EndYear              *int    `json:"end_year,omitempty" validate:"omitempty,gtecsfield=StartYear"`
+	Period               *string `json:"period,omitempty" validate:"omitempty,max=50"`
+	StartYear            *int    `json:"start_year,omitempty"` // Checked against the stored EndYear when omitted
+	EndYear              *int    `json:"end_year,omitempty"`
 	Description          *string `json:"description,omitempty"`
 	CharacteristicsCraft *string `json:"characteristics_craft,omitempty"`
 	CharacteristicsArt   *string `json:"characteristics_art,omitempty"`
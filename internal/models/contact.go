@@ -1,9 +1,76 @@
 package models
 
+import "time"
+
 // ContactFormData represents the data from the contact form
 type ContactFormData struct {
 	Name    string `json:"name" validate:"required,max=100"`
-	Email   string `json:"email" validate:"required,email"`
+	Email   string `json:"email" validate:"required,email,max=255"`
 	Subject string `json:"subject" validate:"required,max=255"`
 	Message string `json:"message" validate:"required,min=10"`
+	// Spam protection: Website is a honeypot the frontend hides from people, FormToken comes from
+	// GET /contact/form-token when the form is shown. Neither is validated here so bots get no hint.
+	Website   string `json:"website"`
+	FormToken string `json:"form_token"`
+}
+
+// ContactSender identifies where a contact form submission came from.
+type ContactSender struct {
+	IPAddress string // Canonical client IP, shown to admins
+	Network   string // Rate limit key: the IPv4 address, or the IPv6 /64
+	FormNonce string // From a valid form token; each is accepted once
+}
+
+// Inbox states of a contact message
+const (
+	ContactStatusNew     = "new"
+	ContactStatusReplied = "replied"
+	ContactStatusClosed  = "closed"
+	ContactStatusSpam    = "spam" // Flagged on submission; accepted silently but never emailed
+)
+
+// ContactMessage is a stored contact form submission as shown in the admin inbox.
+type ContactMessage struct {
+	ID            int64          `json:"id"`
+	Name          string         `json:"name"`
+	Email         string         `json:"email"`
+	Subject       string         `json:"subject"`
+	Message       string         `json:"message"`
+	Status        string         `json:"status"`
+	SpamReason    string         `json:"spam_reason,omitempty"`
+	IPAddress     string         `json:"ip_address,omitempty"`
+	LastRepliedAt *time.Time     `json:"last_replied_at,omitempty"`
+	Replies       []ContactReply `json:"replies,omitempty"` // Only filled for the detail view
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// ContactReply is an email an admin sent in answer to a contact message.
+type ContactReply struct {
+	ID            int64     `json:"id"`
+	AdminID       string    `json:"admin_id,omitempty"` // Empty once the admin account is deleted
+	AdminNickname string    `json:"admin_nickname,omitempty"`
+	Subject       string    `json:"subject"`
+	Body          string    `json:"body"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ContactMessageFilter holds the query options for the admin inbox.
+type ContactMessageFilter struct {
+	Page   int
+	Limit  int
+	Status string // Empty for every status except spam
+	Query  string // Matches name, email and subject
+}
+
+// ContactReplyData is the body of POST /admin/contact-messages/:message_id/reply
+type ContactReplyData struct {
+	Subject string `json:"subject" validate:"omitempty,max=255"` // Defaults to "Re: <original subject>"
+	Body    string `json:"body" validate:"required,max=20000"`
+	Locale  string `json:"locale" validate:"omitempty,max=35"` // Language of the email frame, defaults to en
+}
+
+// UpdateContactStatusData is the body of PUT /admin/contact-messages/:message_id/status
+type UpdateContactStatusData struct {
+	Status string `json:"status" validate:"required,oneof=new replied closed spam"`
 }
@@ -0,0 +1,128 @@
+package models
+
+import "time"
+
+// FreePreviewChapterCount is how many leading chapters of a course are readable without enrolling.
+const FreePreviewChapterCount = 2
+
+// Course represents a course in the learning section
+type Course struct {
+	ID                 int64           `json:"id" db:"id"`
+	Title              string          `json:"title" db:"title"`
+	Description        string          `json:"description,omitempty" db:"description"`
+	InstructorID       *string         `json:"instructor_id,omitempty" db:"instructor_id"`
+	InstructorNickname string          `json:"instructor_nickname,omitempty" db:"-"` // Populated by JOIN
+	ThumbnailURL       string          `json:"thumbnail_url,omitempty" db:"thumbnail_url"`
+	ChapterCount       int             `json:"chapter_count" db:"-"`    // Calculated
+	EnrollmentCount    int             `json:"enrollment_count" db:"-"` // Calculated
+	IsEnrolled         bool            `json:"is_enrolled" db:"-"`      // For current user, populated in service
+	Chapters           []CourseChapter `json:"chapters,omitempty" db:"-"`
+	CreatedAt          time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CourseChapter is a chapter of a course. Content and VideoURL are left empty
+// in listings and for chapters the viewer may not access.
+type CourseChapter struct {
+	ID              int64            `json:"id" db:"id"`
+	CourseID        int64            `json:"course_id" db:"course_id"`
+	Title           string           `json:"title" db:"title"`
+	DisplayOrder    int              `json:"display_order" db:"display_order"`
+	Position        int              `json:"position" db:"-"` // 1-based position within the course
+	BackgroundColor string           `json:"background_color,omitempty" db:"background_color"`
+	VideoURL        string           `json:"video_url,omitempty" db:"video_url"`
+	VideoDuration   *int             `json:"video_duration,omitempty" db:"video_duration"` // In seconds
+	Content         string           `json:"content,omitempty" db:"content"`
+	IsFreePreview   bool             `json:"is_free_preview" db:"-"`
+	Progress        *ChapterProgress `json:"progress,omitempty" db:"-"` // For current user, populated in service
+	Quizzes         []ChapterQuiz    `json:"quizzes,omitempty" db:"-"`  // Answers stripped, only with full content
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// CreateCourseData is sent by course authors to create a course. The author becomes its instructor.
+type CreateCourseData struct {
+	Title        string `json:"title" validate:"required,max=255"`
+	Description  string `json:"description,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" validate:"omitempty,url"`
+}
+
+// UpdateCourseData changes the non-nil fields of a course.
+type UpdateCourseData struct {
+	Title        *string `json:"title,omitempty" validate:"omitempty,max=255"`
+	Description  *string `json:"description,omitempty"`
+	ThumbnailURL *string `json:"thumbnail_url,omitempty" validate:"omitempty,url"`
+}
+
+// CreateChapterData is sent by course authors to add a chapter. DisplayOrder must be unique within the course.
+type CreateChapterData struct {
+	Title           string `json:"title" validate:"required,max=255"`
+	DisplayOrder    int    `json:"display_order" validate:"gte=0"`
+	BackgroundColor string `json:"background_color,omitempty" validate:"omitempty,hexcolor,max=7"` // "#RRGGBB"
+	VideoURL        string `json:"video_url,omitempty" validate:"omitempty,url"`
+	VideoDuration   *int   `json:"video_duration,omitempty" validate:"omitempty,gte=0"` // In seconds
+	Content         string `json:"content,omitempty"`
+}
+
+// UpdateChapterData changes the non-nil fields of a chapter.
+type UpdateChapterData struct {
+	Title           *string `json:"title,omitempty" validate:"omitempty,max=255"`
+	DisplayOrder    *int    `json:"display_order,omitempty" validate:"omitempty,gte=0"`
+	BackgroundColor *string `json:"background_color,omitempty" validate:"omitempty,hexcolor,max=7"`
+	VideoURL        *string `json:"video_url,omitempty" validate:"omitempty,url"`
+	VideoDuration   *int    `json:"video_duration,omitempty" validate:"omitempty,gte=0"`
+	Content         *string `json:"content,omitempty"`
+}
+
+// ChapterProgress is a user's progress within a single chapter
+type ChapterProgress struct {
+	UserID             string     `json:"user_id" db:"user_id"`
+	ChapterID          int64      `json:"chapter_id" db:"chapter_id"`
+	ProgressPercentage int        `json:"progress_percentage" db:"progress_percentage"`
+	VideoLastStoppedAt int        `json:"video_last_stopped_at" db:"video_last_stopped_at"` // In seconds
+	CompletedAt        *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CourseEnrollment records that a user has enrolled in a course
+type CourseEnrollment struct {
+	UserID      string     `json:"user_id" db:"user_id"`
+	CourseID    int64      `json:"course_id" db:"course_id"`
+	EnrolledAt  time.Time  `json:"enrolled_at" db:"enrolled_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// UpdateChapterProgressData is sent by the player as the user works through a chapter.
+// Progress never goes backwards; reaching 100 marks the chapter complete.
+type UpdateChapterProgressData struct {
+	ProgressPercentage int `json:"progress_percentage" validate:"gte=0,lte=100"`
+	VideoLastStoppedAt int `json:"video_last_stopped_at" validate:"gte=0"`
+}
+
+// CourseProgressSummary aggregates the progress of all students enrolled in a course
+type CourseProgressSummary struct {
+	CourseID        int64   `json:"course_id"`
+	Title           string  `json:"title"`
+	ChapterCount    int     `json:"chapter_count"`
+	EnrolledCount   int     `json:"enrolled_count"`
+	CompletedCount  int     `json:"completed_count"`
+	AverageProgress float64 `json:"average_progress"` // Mean course progress of enrolled students, 0-100
+}
+
+// StudentCourseProgress is one enrolled student's progress through a course
+type StudentCourseProgress struct {
+	UserID             string     `json:"user_id"`
+	Nickname           string     `json:"nickname"`
+	EnrolledAt         time.Time  `json:"enrolled_at"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	CompletedChapters  int        `json:"completed_chapters"`
+	ProgressPercentage int        `json:"progress_percentage"` // Across all chapters of the course
+	LastActivityAt     *time.Time `json:"last_activity_at,omitempty"`
+}
+
+// StudentProgressDashboard is the admin overview of learning progress across all courses
+type StudentProgressDashboard struct {
+	TotalEnrollments     int                     `json:"total_enrollments"`
+	CompletedEnrollments int                     `json:"completed_enrollments"`
+	Courses              []CourseProgressSummary `json:"courses"`
+}
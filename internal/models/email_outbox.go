@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Delivery states of an email_outbox row
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending" // Claimed by a worker
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead" // Gave up after max_attempts; can be replayed by an admin
+)
+
+// OutboxEmail is a queued outgoing email as shown to admins.
+type OutboxEmail struct {
+	ID              int64      `json:"id"`
+	To              []string   `json:"to"`
+	Cc              []string   `json:"cc,omitempty"`
+	Bcc             []string   `json:"bcc,omitempty"`
+	ReplyTo         string     `json:"reply_to,omitempty"`
+	Subject         string     `json:"subject"`
+	TextBody        string     `json:"text_body,omitempty"`
+	HTMLBody        string     `json:"html_body,omitempty"`
+	AttachmentCount int        `json:"attachment_count"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	MaxAttempts     int        `json:"max_attempts"`
+	NextAttemptAt   time.Time  `json:"next_attempt_at"`
+	LastError       string     `json:"last_error,omitempty"`
+	SentAt          *time.Time `json:"sent_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
@@ -7,5 +7,18 @@ var ErrForbidden = errors.New("user does not have permission to access this reso
 var ErrConflict = errors.New("resource conflict, item already exists")
 var ErrNicknameTaken = errors.New("nickname already taken")
 var ErrInvalidForumPostCategoryID = errors.New("invalid category of forum post")
+var ErrInvalidArtworkArtistID = errors.New("invalid artist of artwork")
+var ErrSelfKudo = errors.New("cannot give kudos to your own work")
+var ErrInvalidYearRange = errors.New("start year must not be after end year")
+var ErrInvalidQuiz = errors.New("invalid quiz questions")
+var ErrInvalidCredentials = errors.New("invalid email or password")
+var ErrInvalidRefreshToken = errors.New("refresh token is invalid, expired or revoked")
+var ErrInvalidEmailToken = errors.New("email token is invalid, expired or already used")
+var ErrEmailRateLimited = errors.New("too many emails requested for this address, try again later")
+var ErrEmailNotVerified = errors.New("email address has not been verified")
+var ErrContactRateLimited = errors.New("too many contact messages sent, try again later")
+var ErrUnknownPermission = errors.New("unknown permission")
+var ErrInvalidRole = errors.New("invalid role")
+var ErrLastAdmin = errors.New("cannot remove the last remaining admin")
 
 // Add other common domain errors
@@ -44,6 +44,13 @@ type UpdateUserNoteData struct {
 	Content *string `json:"content,omitempty"`
 }
 
+// AddEntityNoteData is the body for attaching a note directly to an artwork or course chapter.
+// The entity comes from the route; an empty title defaults to the entity's title.
+type AddEntityNoteData struct {
+	Title   string `json:"title,omitempty" validate:"omitempty,max=255"`
+	Content string `json:"content" validate:"required"`
+}
+
 // Data to add a link to a note
 type AddLinkToNoteData struct {
 	LinkedEntityType     string  `json:"linked_entity_type" validate:"required"`
@@ -2,6 +2,12 @@ package models
 
 import "time"
 
+// Notification action and entity types written by the services.
+const (
+	NotificationActionKudoPortfolioWork = "kudo_portfolio_work"
+	NotificationEntityPortfolioWork     = "portfolio_work"
+)
+
 type Notification struct {
 	ID              string    `json:"notification_id" db:"notification_id"`
 	RecipientUserID string    `json:"recipient_user_id" db:"recipient_user_id"`
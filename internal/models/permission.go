@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Named permissions checked by middleware.RequirePermission and by services for ownership overrides.
+// New ones also need a row in the permissions table.
+const (
+	PermUsersManage        = "users.manage"
+	PermRolesManage        = "roles.manage"
+	PermForumModerate      = "forum.moderate" // Pin, archive and delete any post or comment
+	PermPortfolioModerate  = "portfolio.moderate"
+	PermGalleryEdit        = "gallery.edit"
+	PermCeramicStoryEdit   = "ceramicstory.edit"
+	PermCourseAuthor       = "course.author"
+	PermCourseViewProgress = "course.view_progress"
+	PermEmailsManage       = "emails.manage"
+	PermContactManage      = "contact.manage"
+	PermAuditView          = "audit.view"
+)
+
+// Role is a role with the permissions granted to it.
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// Permission is a named permission that can be granted to roles.
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateRolePermissionsData is the body of PUT /admin/roles/:role_name/permissions; it replaces the whole set.
+type UpdateRolePermissionsData struct {
+	Permissions []string `json:"permissions" validate:"required,dive,required,max=100"`
+}
+
+// RoleChange is an entry of the append-only role change audit log.
+type RoleChange struct {
+	ID             int64     `json:"id"`
+	ActorID        string    `json:"actor_id"`
+	ActorNickname  string    `json:"actor_nickname,omitempty"` // Empty once the account is deleted
+	TargetUserID   string    `json:"target_user_id"`
+	TargetNickname string    `json:"target_nickname,omitempty"`
+	OldRole        string    `json:"old_role"`
+	NewRole        string    `json:"new_role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RoleChangeFilter selects entries for GET /admin/role-changes. Empty fields match everything.
+type RoleChangeFilter struct {
+	Page         int
+	Limit        int
+	ActorID      string
+	TargetUserID string
+}
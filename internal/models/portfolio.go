@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+const (
+	PortfolioSortLatest = "latest"
+	PortfolioSortKudos  = "kudos"
+)
+
+// PortfolioWorkImage is one image of a student's portfolio work
+type PortfolioWorkImage struct {
+	ID           int    `json:"id" db:"id"`
+	WorkID       int64  `json:"work_id" db:"portfolio_work_id"`
+	ImageURL     string `json:"image_url" db:"image_url"`
+	IsThumbnail  bool   `json:"is_thumbnail" db:"is_thumbnail"`
+	Caption      string `json:"caption,omitempty" db:"caption"`
+	DisplayOrder int    `json:"display_order" db:"display_order"`
+}
+
+// PortfolioWork is a piece submitted by a student to the portfolio showcase
+type PortfolioWork struct {
+	ID              int64                `json:"id" db:"id"`
+	UserID          string               `json:"user_id" db:"user_id"`
+	AuthorNickname  string               `json:"author_nickname" db:"-"` // Populated by JOIN
+	Title           string               `json:"title" db:"title"`
+	Description     string               `json:"description,omitempty" db:"description"`
+	Category        string               `json:"category,omitempty" db:"category"`
+	IsEditorsChoice bool                 `json:"is_editors_choice" db:"is_editors_choice"` // Highlighted by an admin
+	KudosCount      int                  `json:"kudos_count" db:"kudos_count"`
+	HasGivenKudo    bool                 `json:"has_given_kudo,omitempty" db:"-"` // For current user, populated in service
+	ThumbnailURL    string               `json:"thumbnail_url,omitempty" db:"-"`
+	Images          []PortfolioWorkImage `json:"images,omitempty" db:"-"` // Only loaded for the detail view
+	CreatedAt       time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// PortfolioImageData describes an image when creating or updating a work
+type PortfolioImageData struct {
+	ImageURL    string `json:"image_url" validate:"required,url"`
+	Caption     string `json:"caption,omitempty" validate:"max=255"`
+	IsThumbnail bool   `json:"is_thumbnail,omitempty"`
+}
+
+// CreatePortfolioWorkData is for submitting a new work
+type CreatePortfolioWorkData struct {
+	Title       string               `json:"title" validate:"required,max=255"`
+	Description string               `json:"description,omitempty"`
+	Category    string               `json:"category,omitempty" validate:"max=100"`
+	Images      []PortfolioImageData `json:"images" validate:"required,min=1,max=20,dive"`
+}
+
+// UpdatePortfolioWorkData is for editing a work. Nil fields are left untouched and a
+// non-empty Images replaces the whole image set.
+type UpdatePortfolioWorkData struct {
+	Title       *string              `json:"title,omitempty" validate:"omitempty,max=255"`
+	Description *string              `json:"description,omitempty"`
+	Category    *string              `json:"category,omitempty" validate:"omitempty,max=100"`
+	Images      []PortfolioImageData `json:"images,omitempty" validate:"omitempty,max=20,dive"`
+}
+
+// PortfolioWorkFilter holds the query options for listing portfolio works.
+type PortfolioWorkFilter struct {
+	Page     int
+	Limit    int
+	Category string
+	Sort     string // PortfolioSortLatest or PortfolioSortKudos
+}
+
+// HighlightPortfolioWorkData is the optional body of the admin highlight route. Omitting highlighted highlights the work.
+type HighlightPortfolioWorkData struct {
+	Highlighted *bool `json:"highlighted,omitempty"`
+}
@@ -2,22 +2,30 @@ package models
 
 import "time"
 
+// Sort options for forum post listings
+const (
+	ForumSortLatest  = "latest"
+	ForumSortHottest = "hottest"
+)
+
 type ForumPost struct {
-	ID             int64     `json:"forum_post_id" db:"forum_post_id"`
-	UserID         string    `json:"user_id" db:"user_id"`
-	AuthorNickname string    `json:"author_nickname" db:"author_nickname"`
-	Title          string    `json:"title" db:"title"`
-	Content        string    `json:"content" db:"content"`
-	CategoryID     int       `json:"category_id" db:"category_id"`
-	CategoryName   string    `json:"category_name" db:"category_name"`
-	Tags           []string  `json:"tags" db:"tags"`
-	IsPinned       bool      `json:"is_pinned" db:"is_pinned"`
-	ViewCount      int       `json:"view_count" db:"view_count"`
-	CommentCount   int       `json:"comment_count" db:"comment_count"`
-	LikeCount      int       `json:"like_count" db:"like_count"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
-	LastActivityAt time.Time `json:"last_activity_at" db:"last_activity_at"`
+	ID             int64          `json:"forum_post_id" db:"forum_post_id"`
+	UserID         string         `json:"user_id" db:"user_id"`
+	AuthorNickname string         `json:"author_nickname" db:"author_nickname"`
+	Title          string         `json:"title" db:"title"`
+	Content        string         `json:"content" db:"content"`
+	CategoryID     int            `json:"category_id" db:"category_id"`
+	CategoryName   string         `json:"category_name" db:"category_name"`
+	Tags           []string       `json:"tags" db:"tags"`
+	IsPinned       bool           `json:"is_pinned" db:"is_pinned"`
+	IsArchived     bool           `json:"is_archived" db:"is_archived"` // Hidden from listings by an admin
+	ViewCount      int            `json:"view_count" db:"view_count"`
+	CommentCount   int            `json:"comment_count" db:"comment_count"`
+	LikeCount      int            `json:"like_count" db:"like_count"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+	LastActivityAt time.Time      `json:"last_activity_at" db:"last_activity_at"`
+	Comments       []ForumComment `json:"comments,omitempty" db:"-"` // Only loaded for the post detail view
 }
 
 type CreateForumPostData struct {
@@ -27,7 +35,68 @@ type CreateForumPostData struct {
 	Tags       []string `json:"tags,omitempty"` // Or []int for tag IDs
 }
 
+// UpdateForumPostData defines the fields an author can change on their post.
+// A nil Tags slice leaves tags untouched, an empty one clears them.
+type UpdateForumPostData struct {
+	Title      *string  `json:"title,omitempty" validate:"omitempty,min=3,max=255"`
+	Content    *string  `json:"content,omitempty" validate:"omitempty,min=10"`
+	CategoryID *int     `json:"category_id,omitempty" validate:"omitempty,gt=0"`
+	Tags       []string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+}
+
+// ForumPostFilter holds the query options for listing forum posts.
+type ForumPostFilter struct {
+	Page       int
+	Limit      int
+	Sort       string // ForumSortLatest or ForumSortHottest
+	Tag        string
+	CategoryID int
+}
+
+type ForumComment struct {
+	ID              int64     `json:"comment_id" db:"id"`
+	PostID          int64     `json:"forum_post_id" db:"post_id"`
+	UserID          string    `json:"user_id" db:"user_id"`
+	AuthorNickname  string    `json:"author_nickname" db:"author_nickname"`
+	ParentCommentID *int64    `json:"parent_comment_id,omitempty" db:"parent_comment_id"`
+	Content         string    `json:"content" db:"content"`
+	LikeCount       int       `json:"like_count" db:"like_count"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateForumCommentData struct {
+	Content         string `json:"content" validate:"required,min=1"`
+	ParentCommentID *int64 `json:"parent_comment_id,omitempty" validate:"omitempty,gt=0"`
+}
+
+type UpdateForumCommentData struct {
+	Content string `json:"content" validate:"required,min=1"`
+}
+
+// ForumTopic is an entry in the forum tag cloud.
+type ForumTopic struct {
+	Tag       string `json:"tag" db:"name"`
+	PostCount int    `json:"post_count" db:"post_count"`
+}
+
+// ToggleResult reports the state after a like/save toggle.
+type ToggleResult struct {
+	Active bool `json:"active"`
+	Count  int  `json:"count"`
+}
+
 type UserSavedPostEntry struct {
 	Post    ForumPost `json:"post"`
 	SavedAt time.Time `json:"saved_at"`
 }
+
+// PinForumPostData is the optional body of the admin pin route. Omitting pinned pins the post.
+type PinForumPostData struct {
+	Pinned *bool `json:"pinned,omitempty"`
+}
+
+// ArchiveForumPostData is the optional body of the admin archive route. Omitting archived archives the post.
+type ArchiveForumPostData struct {
+	Archived *bool `json:"archived,omitempty"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+// Quiz question types
+const (
+	QuestionSingleChoice   = "single_choice"
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionTrueFalse      = "true_false"
+	QuestionShortAnswer    = "short_answer"
+)
+
+// QuizTypeChapter is the user_quiz_attempts.quiz_type for chapter_quizzes
+const QuizTypeChapter = "chapter_quiz"
+
+type QuizOption struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// QuizQuestion is one entry of chapter_quizzes.quiz_data. Which answer field is used depends on Type:
+// CorrectOptionIDs for single/multiple choice, CorrectBool for true/false, AcceptedAnswers for short answer.
+type QuizQuestion struct {
+	ID               string       `json:"id"`
+	Type             string       `json:"type"`
+	Text             string       `json:"text"`
+	Options          []QuizOption `json:"options,omitempty"`
+	Points           int          `json:"points,omitempty"` // Defaults to 1
+	CorrectOptionIDs []string     `json:"correct_option_ids,omitempty"`
+	CorrectBool      *bool        `json:"correct_bool,omitempty"`
+	AcceptedAnswers  []string     `json:"accepted_answers,omitempty"` // Matched case- and whitespace-insensitively
+	Explanation      string       `json:"explanation,omitempty"`      // Shown after submission
+}
+
+// QuizData is the JSON document stored in chapter_quizzes.quiz_data
+type QuizData struct {
+	Questions []QuizQuestion `json:"questions"`
+}
+
+// ChapterQuiz is a graded quiz attached to a course chapter
+type ChapterQuiz struct {
+	ID                   int64          `json:"id" db:"id"`
+	ChapterID            int64          `json:"chapter_id" db:"chapter_id"`
+	Title                string         `json:"title" db:"title"`
+	Questions            []QuizQuestion `json:"questions" db:"-"` // Decoded from quiz_data
+	PassThreshold        int            `json:"pass_threshold" db:"pass_threshold"`
+	MarksChapterComplete bool           `json:"marks_chapter_complete" db:"marks_chapter_complete"`
+	DisplayOrder         int            `json:"display_order" db:"display_order"`
+}
+
+// WithoutAnswers returns a copy safe to send to learners before they submit.
+func (q ChapterQuiz) WithoutAnswers() ChapterQuiz {
+	questions := make([]QuizQuestion, len(q.Questions))
+	for i, question := range q.Questions {
+		question.CorrectOptionIDs = nil
+		question.CorrectBool = nil
+		question.AcceptedAnswers = nil
+		question.Explanation = ""
+		questions[i] = question
+	}
+	q.Questions = questions
+	return q
+}
+
+// DefaultPassThreshold is the pass_threshold of a quiz created without one, as in the column default.
+const DefaultPassThreshold = 60
+
+// CreateQuizData is sent by course authors to add a quiz to a chapter. The questions, including
+// their answers, are checked in the service.
+type CreateQuizData struct {
+	Title                string         `json:"title" validate:"required,max=255"`
+	Questions            []QuizQuestion `json:"questions" validate:"required,min=1"`
+	PassThreshold        *int           `json:"pass_threshold,omitempty" validate:"omitempty,gte=0,lte=100"` // Defaults to DefaultPassThreshold
+	MarksChapterComplete bool           `json:"marks_chapter_complete"`
+	DisplayOrder         int            `json:"display_order" validate:"gte=0"`
+}
+
+// UpdateQuizData changes the non-nil fields of a quiz. Questions, when sent, replace all questions.
+type UpdateQuizData struct {
+	Title                *string        `json:"title,omitempty" validate:"omitempty,max=255"`
+	Questions            []QuizQuestion `json:"questions,omitempty" validate:"omitempty,min=1"`
+	PassThreshold        *int           `json:"pass_threshold,omitempty" validate:"omitempty,gte=0,lte=100"`
+	MarksChapterComplete *bool          `json:"marks_chapter_complete,omitempty"`
+	DisplayOrder         *int           `json:"display_order,omitempty" validate:"omitempty,gte=0"`
+}
+
+// QuizAnswer is a learner's answer to one question
+type QuizAnswer struct {
+	QuestionID string   `json:"question_id" validate:"required"`
+	OptionIDs  []string `json:"option_ids,omitempty"`
+	Bool       *bool    `json:"bool,omitempty"`
+	Text       string   `json:"text,omitempty" validate:"max=1000"`
+}
+
+type SubmitQuizData struct {
+	Answers []QuizAnswer `json:"answers" validate:"required,dive"`
+}
+
+// QuizQuestionResult is the graded outcome of one question
+type QuizQuestionResult struct {
+	QuestionID     string `json:"question_id"`
+	Correct        bool   `json:"correct"`
+	PointsAwarded  int    `json:"points_awarded"`
+	PointsPossible int    `json:"points_possible"`
+	Explanation    string `json:"explanation,omitempty"`
+}
+
+// QuizAttempt is a graded submission, stored in user_quiz_attempts
+type QuizAttempt struct {
+	ID               int64                `json:"id" db:"id"`
+	UserID           string               `json:"user_id" db:"user_id"`
+	QuizID           int64                `json:"quiz_id" db:"quiz_id"`
+	Answers          []QuizAnswer         `json:"answers" db:"-"` // Stored in attempt_data
+	Results          []QuizQuestionResult `json:"results" db:"-"` // Stored in attempt_data
+	PointsAwarded    int                  `json:"points_awarded" db:"-"`
+	PointsPossible   int                  `json:"points_possible" db:"-"`
+	Score            int                  `json:"score" db:"score"` // Percentage, 0-100
+	Passed           bool                 `json:"passed" db:"passed"`
+	ChapterCompleted bool                 `json:"chapter_completed" db:"-"` // Set when this attempt completed the chapter
+	AttemptedAt      time.Time            `json:"attempted_at" db:"attempted_at"`
+}
@@ -1,7 +1,8 @@
 package models
 
-// ErrorResponse is a generic structure for JSON error responses.
+// ErrorResponse is the JSON body of every error response. Build it through AppError.
 type ErrorResponse struct {
+	Code    string `json:"code"` // Stable and machine-readable, see the Code constants
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"` // Optional additional details
 }
@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler handles the admin endpoints of the email outbox and template previews. RequirePermission(emails.manage) is enforced by the router.
+type Handler struct {
+	service   ServiceInterface
+	templates *email.Registry
+}
+
+// NewHandler creates a new outbox handler.
+func NewHandler(service ServiceInterface, templates *email.Registry) *Handler {
+	return &Handler{service: service, templates: templates}
+}
+
+// GetFailedEmails lists dead-lettered emails with their last error. Params: ?page=1&limit=20
+func (h *Handler) GetFailedEmails(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	emails, total, err := h.service.ListFailed(c.Request().Context(), page, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(emails, page, limit, total))
+}
+
+// ReplayEmail queues a dead-lettered email for delivery again.
+func (h *Handler) ReplayEmail(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("email_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid email ID")
+	}
+
+	replayed, err := h.service.Replay(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, replayed)
+}
+
+// GetEmailTemplates lists the registered email templates with their variables and locales.
+func (h *Handler) GetEmailTemplates(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.templates.Templates())
+}
+
+// PreviewEmailTemplate renders a template with its sample data. Params: ?locale=zh-CN&format=json|html|text
+// Without locale the admin's Accept-Language is used. format=html returns the page itself so it can be opened in a browser.
+func (h *Handler) PreviewEmailTemplate(c echo.Context) error {
+	name := c.Param("template_name")
+	if !h.templates.Has(name) {
+		return models.NotFoundError("Email template not found")
+	}
+	locale := c.QueryParam("locale")
+	if locale == "" {
+		locale = c.Request().Header.Get("Accept-Language")
+	}
+
+	rendered, err := h.templates.Preview(name, locale)
+	if err != nil {
+		return err
+	}
+	switch c.QueryParam("format") {
+	case "", "json":
+		return c.JSON(http.StatusOK, rendered)
+	case "html":
+		return c.HTML(http.StatusOK, rendered.HTML)
+	case "text":
+		return c.String(http.StatusOK, rendered.Text)
+	default:
+		return models.BadRequestError("format must be json, html or text")
+	}
+}
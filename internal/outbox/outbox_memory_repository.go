@@ -0,0 +1,187 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultMaxAttempts is the email_outbox.max_attempts column default.
+const defaultMaxAttempts = 8
+
+// MemoryRepository is an in-memory RepositoryInterface for service and worker tests. It returns the same
+// errors as Repository and, like the UPDATEs there, ignores Mark calls for unknown IDs.
+type MemoryRepository struct {
+	mu     sync.Mutex
+	emails map[int64]*memoryEmail
+	lastID int64
+}
+
+type memoryEmail struct {
+	models.OutboxEmail
+	message email.Message
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory outbox.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{emails: map[int64]*memoryEmail{}}
+}
+
+// copyMessage detaches the lists and attachments; empty lists are non-nil like the NOT NULL array columns.
+func copyMessage(m email.Message) email.Message {
+	m.To = append([]string{}, m.To...)
+	m.Cc = append([]string{}, m.Cc...)
+	m.Bcc = append([]string{}, m.Bcc...)
+	if len(m.Attachments) == 0 {
+		m.Attachments = nil
+	} else {
+		m.Attachments = append([]email.Attachment(nil), m.Attachments...)
+	}
+	return m
+}
+
+// view returns e as ListByStatus and Replay return it.
+func view(e *memoryEmail) models.OutboxEmail {
+	out := e.OutboxEmail
+	m := copyMessage(e.message)
+	out.To, out.Cc, out.Bcc = m.To, m.Cc, m.Bcc
+	out.ReplyTo, out.Subject, out.TextBody, out.HTMLBody = m.ReplyTo, m.Subject, m.TextBody, m.HTMLBody
+	out.AttachmentCount = len(m.Attachments)
+	if out.SentAt != nil {
+		sentAt := *out.SentAt
+		out.SentAt = &sentAt
+	}
+	return out
+}
+
+func (r *MemoryRepository) Enqueue(ctx context.Context, msg *email.Message) error {
+	if err := msg.Validate(); err != nil {
+		return fmt.Errorf("outbox.Enqueue: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	now := time.Now()
+	r.emails[r.lastID] = &memoryEmail{
+		OutboxEmail: models.OutboxEmail{
+			ID: r.lastID, Status: models.OutboxStatusPending, MaxAttempts: defaultMaxAttempts,
+			NextAttemptAt: now, CreatedAt: now, UpdatedAt: now,
+		},
+		message: copyMessage(*msg),
+	}
+	return nil
+}
+
+func (r *MemoryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]QueuedEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var due []*memoryEmail
+	for _, e := range r.emails {
+		if (e.Status == models.OutboxStatusPending || e.Status == models.OutboxStatusSending) && !e.NextAttemptAt.After(now) {
+			due = append(due, e)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := []QueuedEmail{}
+	for _, e := range due {
+		e.Status = models.OutboxStatusSending
+		e.Attempts++
+		e.NextAttemptAt = now.Add(time.Duration(int(lease.Seconds())) * time.Second) // The SQL interval has whole seconds
+		e.UpdatedAt = now
+		claimed = append(claimed, QueuedEmail{ID: e.ID, Attempts: e.Attempts, MaxAttempts: e.MaxAttempts, Message: copyMessage(e.message)})
+	}
+	return claimed, nil
+}
+
+// update applies fn to email id, if it exists, and bumps updated_at.
+func (r *MemoryRepository) update(id int64, fn func(e *memoryEmail)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.emails[id]; ok {
+		fn(e)
+		e.UpdatedAt = time.Now()
+	}
+}
+
+func (r *MemoryRepository) MarkSent(ctx context.Context, id int64) error {
+	r.update(id, func(e *memoryEmail) {
+		now := time.Now()
+		e.Status, e.SentAt, e.LastError = models.OutboxStatusSent, &now, ""
+	})
+	return nil
+}
+
+func (r *MemoryRepository) MarkRetry(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	r.update(id, func(e *memoryEmail) {
+		e.Status, e.LastError, e.NextAttemptAt = models.OutboxStatusPending, lastError, nextAttemptAt
+	})
+	return nil
+}
+
+func (r *MemoryRepository) MarkDead(ctx context.Context, id int64, lastError string) error {
+	r.update(id, func(e *memoryEmail) {
+		e.Status, e.LastError = models.OutboxStatusDead, lastError
+	})
+	return nil
+}
+
+func (r *MemoryRepository) ListByStatus(ctx context.Context, status string, page, limit int) ([]models.OutboxEmail, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []models.OutboxEmail
+	for _, e := range r.emails {
+		if e.Status == status {
+			matched = append(matched, view(e))
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].UpdatedAt.Equal(matched[j].UpdatedAt) {
+			return matched[i].UpdatedAt.After(matched[j].UpdatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	emails := []models.OutboxEmail{}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		emails = append(emails, matched[i])
+	}
+	return emails, len(matched), nil
+}
+
+func (r *MemoryRepository) Replay(ctx context.Context, id int64) (*models.OutboxEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.emails[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if e.Status != models.OutboxStatusDead {
+		return nil, models.ErrConflict
+	}
+	now := time.Now()
+	e.Status, e.Attempts, e.NextAttemptAt, e.UpdatedAt = models.OutboxStatusPending, 0, now, now
+	replayed := view(e)
+	return &replayed, nil
+}
@@ -0,0 +1,226 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/email"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is satisfied by *pgxpool.Pool and pgx.Tx, so Enqueue can join the caller's transaction.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Enqueue stores msg for delivery by the Worker. Pass a pgx.Tx to make the email part of
+// the triggering action: it is only sent if that transaction commits.
+func Enqueue(ctx context.Context, db DBTX, msg *email.Message) error {
+	if err := msg.Validate(); err != nil {
+		return fmt.Errorf("outbox.Enqueue: %w", err)
+	}
+	var attachments []byte
+	if len(msg.Attachments) > 0 {
+		var err error
+		if attachments, err = json.Marshal(msg.Attachments); err != nil {
+			return fmt.Errorf("outbox.Enqueue.Attachments: %w", err)
+		}
+	}
+	query := `INSERT INTO email_outbox (to_addresses, cc_addresses, bcc_addresses, reply_to, subject, text_body, html_body, attachments)
+	          VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), $8)`
+	_, err := db.Exec(ctx, query,
+		nonNil(msg.To), nonNil(msg.Cc), nonNil(msg.Bcc), msg.ReplyTo, msg.Subject, msg.TextBody, msg.HTMLBody, attachments)
+	if err != nil {
+		return fmt.Errorf("outbox.Enqueue: %w", err)
+	}
+	return nil
+}
+
+// QueuedEmail is a claimed outbox row together with the message to deliver.
+type QueuedEmail struct {
+	ID          int64
+	Attempts    int // Including the current one
+	MaxAttempts int
+	Message     email.Message
+}
+
+// RepositoryInterface defines methods for the email_outbox table.
+type RepositoryInterface interface {
+	Enqueue(ctx context.Context, msg *email.Message) error
+	// ClaimDue marks up to limit due emails as sending for lease and counts the attempt.
+	// Rows left in sending after their lease (e.g. the process died) become due again.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]QueuedEmail, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkRetry(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, id int64, lastError string) error
+	ListByStatus(ctx context.Context, status string, page, limit int) ([]models.OutboxEmail, int, error)
+	// Replay puts a dead email back in the queue with a fresh attempt budget.
+	Replay(ctx context.Context, id int64) (*models.OutboxEmail, error)
+}
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+const outboxSelect = `SELECT id, to_addresses, cc_addresses, bcc_addresses, COALESCE(reply_to, ''), subject,
+	       COALESCE(text_body, ''), COALESCE(html_body, ''), COALESCE(jsonb_array_length(attachments), 0),
+	       status, attempts, max_attempts, next_attempt_at, COALESCE(last_error, ''), sent_at, created_at, updated_at
+	FROM email_outbox`
+
+func scanOutboxEmail(row pgx.Row) (*models.OutboxEmail, error) {
+	e := &models.OutboxEmail{}
+	err := row.Scan(
+		&e.ID, &e.To, &e.Cc, &e.Bcc, &e.ReplyTo, &e.Subject,
+		&e.TextBody, &e.HTMLBody, &e.AttachmentCount,
+		&e.Status, &e.Attempts, &e.MaxAttempts, &e.NextAttemptAt, &e.LastError, &e.SentAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+	return e, err
+}
+
+func (r *Repository) Enqueue(ctx context.Context, msg *email.Message) error {
+	return Enqueue(ctx, r.db, msg)
+}
+
+func (r *Repository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]QueuedEmail, error) {
+	// SKIP LOCKED lets several workers (and several API instances) claim without blocking each other.
+	query := `UPDATE email_outbox o
+	          SET status = 'sending', attempts = o.attempts + 1, next_attempt_at = NOW() + $2::interval, updated_at = NOW()
+	          FROM (
+	              SELECT id FROM email_outbox
+	              WHERE status IN ('pending', 'sending') AND next_attempt_at <= NOW()
+	              ORDER BY next_attempt_at
+	              LIMIT $1
+	              FOR UPDATE SKIP LOCKED
+	          ) due
+	          WHERE o.id = due.id
+	          RETURNING o.id, o.attempts, o.max_attempts, o.to_addresses, o.cc_addresses, o.bcc_addresses,
+	                    COALESCE(o.reply_to, ''), o.subject, COALESCE(o.text_body, ''), COALESCE(o.html_body, ''), o.attachments`
+	rows, err := r.db.Query(ctx, query, limit, fmt.Sprintf("%d seconds", int(lease.Seconds())))
+	if err != nil {
+		return nil, fmt.Errorf("repository.ClaimDue: %w", err)
+	}
+	defer rows.Close()
+
+	claimed := []QueuedEmail{}
+	for rows.Next() {
+		var q QueuedEmail
+		var attachments []byte
+		m := &q.Message
+		if err := rows.Scan(&q.ID, &q.Attempts, &q.MaxAttempts, &m.To, &m.Cc, &m.Bcc,
+			&m.ReplyTo, &m.Subject, &m.TextBody, &m.HTMLBody, &attachments); err != nil {
+			return nil, fmt.Errorf("repository.ClaimDue.Scan: %w", err)
+		}
+		if len(attachments) > 0 {
+			if err := json.Unmarshal(attachments, &m.Attachments); err != nil {
+				return nil, fmt.Errorf("repository.ClaimDue.Attachments: %w", err)
+			}
+		}
+		claimed = append(claimed, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ClaimDue.Rows: %w", err)
+	}
+	return claimed, nil
+}
+
+func (r *Repository) MarkSent(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE email_outbox SET status = 'sent', sent_at = NOW(), last_error = NULL, updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("repository.MarkSent: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) MarkRetry(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE email_outbox SET status = 'pending', last_error = $2, next_attempt_at = $3, updated_at = NOW() WHERE id = $1`,
+		id, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("repository.MarkRetry: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) MarkDead(ctx context.Context, id int64, lastError string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE email_outbox SET status = 'dead', last_error = $2, updated_at = NOW() WHERE id = $1`, id, lastError)
+	if err != nil {
+		return fmt.Errorf("repository.MarkDead: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListByStatus(ctx context.Context, status string, page, limit int) ([]models.OutboxEmail, int, error) {
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(ctx, outboxSelect+` WHERE status = $1 ORDER BY updated_at DESC LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListByStatus: %w", err)
+	}
+	defer rows.Close()
+
+	emails := []models.OutboxEmail{}
+	for rows.Next() {
+		e, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("repository.ListByStatus.Scan: %w", err)
+		}
+		emails = append(emails, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListByStatus.Rows: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM email_outbox WHERE status = $1`, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListByStatus.Count: %w", err)
+	}
+	return emails, total, nil
+}
+
+func (r *Repository) Replay(ctx context.Context, id int64) (*models.OutboxEmail, error) {
+	query := `UPDATE email_outbox SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+	          WHERE id = $1 AND status = 'dead'
+	          RETURNING id`
+	if err := r.db.QueryRow(ctx, query, id).Scan(&id); err != nil {
+		if utils.IsNoRows(err) {
+			return nil, r.replayMissReason(ctx, id)
+		}
+		return nil, fmt.Errorf("repository.Replay: %w", err)
+	}
+	replayed, err := scanOutboxEmail(r.db.QueryRow(ctx, outboxSelect+` WHERE id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("repository.Replay.Find: %w", err)
+	}
+	return replayed, nil
+}
+
+// replayMissReason tells a missing email (ErrNotFound) apart from one that is not dead (ErrConflict).
+func (r *Repository) replayMissReason(ctx context.Context, id int64) error {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM email_outbox WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("repository.Replay: %w", err)
+	}
+	if exists {
+		return models.ErrConflict
+	}
+	return models.ErrNotFound
+}
+
+// nonNil keeps NOT NULL array columns happy when a list is empty.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
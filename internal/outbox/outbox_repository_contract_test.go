@@ -0,0 +1,191 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+	"jingdezhen-ceramics-backend/pkg/email"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty outbox.
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewRepository(pgtest.NewDB(t)) })
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewMemoryRepository() })
+}
+
+func testRepositoryContract(t *testing.T, newRepo func(t *testing.T) RepositoryInterface) {
+	ctx := context.Background()
+	enqueue := func(t *testing.T, repo RepositoryInterface, subject string) {
+		t.Helper()
+		if err := repo.Enqueue(ctx, &email.Message{To: []string{"li@example.com"}, Subject: subject, TextBody: "Hello"}); err != nil {
+			t.Fatalf("Enqueue(%s): %v", subject, err)
+		}
+	}
+	claimAll := func(t *testing.T, repo RepositoryInterface, lease time.Duration) []QueuedEmail {
+		t.Helper()
+		claimed, err := repo.ClaimDue(ctx, 10, lease)
+		if err != nil {
+			t.Fatalf("ClaimDue: %v", err)
+		}
+		return claimed
+	}
+	list := func(t *testing.T, repo RepositoryInterface, status string) []models.OutboxEmail {
+		t.Helper()
+		emails, total, err := repo.ListByStatus(ctx, status, 1, 10)
+		if err != nil || total != len(emails) {
+			t.Fatalf("ListByStatus(%s) = %d emails, total %d, %v", status, len(emails), total, err)
+		}
+		return emails
+	}
+
+	t.Run("EnqueueAndClaim", func(t *testing.T) {
+		repo := newRepo(t)
+		msg := &email.Message{
+			To: []string{"li@example.com"}, Bcc: []string{"archive@example.com"}, ReplyTo: "contact@example.com",
+			Subject: "Welcome", TextBody: "Hi", HTMLBody: "<p>Hi</p>",
+			Attachments: []email.Attachment{{Filename: "route.pdf", ContentType: "application/pdf", Data: []byte("%PDF")}},
+		}
+		if err := repo.Enqueue(ctx, msg); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+		if err := repo.Enqueue(ctx, &email.Message{Subject: "Nobody", TextBody: "x"}); err == nil {
+			t.Error("Enqueue accepted a message without recipients")
+		}
+
+		pending := list(t, repo, models.OutboxStatusPending)
+		if len(pending) != 1 || pending[0].AttachmentCount != 1 || pending[0].Attempts != 0 || pending[0].MaxAttempts != defaultMaxAttempts {
+			t.Fatalf("pending = %+v, want the one email with an attachment", pending)
+		}
+
+		claimed := claimAll(t, repo, time.Minute)
+		if len(claimed) != 1 || claimed[0].ID != pending[0].ID || claimed[0].Attempts != 1 {
+			t.Fatalf("ClaimDue = %+v, want the email on its first attempt", claimed)
+		}
+		got := claimed[0].Message
+		if !reflect.DeepEqual(got.To, msg.To) || len(got.Cc) != 0 || !reflect.DeepEqual(got.Bcc, msg.Bcc) ||
+			got.ReplyTo != msg.ReplyTo || got.Subject != msg.Subject || got.TextBody != msg.TextBody || got.HTMLBody != msg.HTMLBody ||
+			!reflect.DeepEqual(got.Attachments, msg.Attachments) {
+			t.Errorf("claimed message = %+v, want %+v", got, msg)
+		}
+		if again := claimAll(t, repo, time.Minute); len(again) != 0 {
+			t.Errorf("ClaimDue during the lease = %+v, want nothing", again)
+		}
+		if sending := list(t, repo, models.OutboxStatusSending); len(sending) != 1 {
+			t.Errorf("sending = %+v, want the claimed email", sending)
+		}
+	})
+
+	t.Run("ClaimLimitAndExpiredLease", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, subject := range []string{"a", "b", "c"} {
+			enqueue(t, repo, subject)
+		}
+		claimed, err := repo.ClaimDue(ctx, 2, 0)
+		if err != nil || len(claimed) != 2 {
+			t.Fatalf("ClaimDue(limit 2) = %d emails, %v", len(claimed), err)
+		}
+		// A zero lease has already run out, so every email is due again
+		reclaimed := claimAll(t, repo, time.Minute)
+		attempts := map[string]int{}
+		for _, q := range reclaimed {
+			attempts[q.Message.Subject] = q.Attempts
+		}
+		if len(reclaimed) != 3 || attempts["c"] != 1 || attempts[claimed[0].Message.Subject] != 2 {
+			t.Errorf("second ClaimDue attempts = %v, want the earlier two on attempt 2 and c on 1", attempts)
+		}
+	})
+
+	t.Run("Mark", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, subject := range []string{"sent", "retry", "dead"} {
+			enqueue(t, repo, subject)
+		}
+		ids := map[string]int64{}
+		for _, q := range claimAll(t, repo, time.Minute) {
+			ids[q.Message.Subject] = q.ID
+		}
+		if err := repo.MarkSent(ctx, ids["sent"]); err != nil {
+			t.Fatalf("MarkSent: %v", err)
+		}
+		if err := repo.MarkRetry(ctx, ids["retry"], "421 try later", time.Now().Add(-time.Second)); err != nil {
+			t.Fatalf("MarkRetry: %v", err)
+		}
+		if err := repo.MarkDead(ctx, ids["dead"], "550 no such user"); err != nil {
+			t.Fatalf("MarkDead: %v", err)
+		}
+		if err := repo.MarkSent(ctx, 999); err != nil {
+			t.Errorf("MarkSent(missing) = %v, want it ignored", err)
+		}
+
+		if sent := list(t, repo, models.OutboxStatusSent); len(sent) != 1 || sent[0].SentAt == nil || sent[0].LastError != "" {
+			t.Errorf("sent = %+v, want one email with SentAt", sent)
+		}
+		if pending := list(t, repo, models.OutboxStatusPending); len(pending) != 1 || pending[0].LastError != "421 try later" {
+			t.Errorf("pending = %+v, want the retried email with its error", pending)
+		}
+		retried := claimAll(t, repo, time.Minute)
+		if len(retried) != 1 || retried[0].ID != ids["retry"] || retried[0].Attempts != 2 {
+			t.Errorf("ClaimDue after MarkRetry = %+v, want the retried email on attempt 2", retried)
+		}
+		if dead := list(t, repo, models.OutboxStatusDead); len(dead) != 1 || dead[0].LastError != "550 no such user" {
+			t.Errorf("dead = %+v", dead)
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		repo := newRepo(t)
+		enqueue(t, repo, "dead")
+		enqueue(t, repo, "pending")
+		claimed, err := repo.ClaimDue(ctx, 1, time.Minute)
+		if err != nil || len(claimed) != 1 {
+			t.Fatalf("ClaimDue = %d emails, %v", len(claimed), err)
+		}
+		if err := repo.MarkDead(ctx, claimed[0].ID, "550"); err != nil {
+			t.Fatalf("MarkDead: %v", err)
+		}
+
+		replayed, err := repo.Replay(ctx, claimed[0].ID)
+		if err != nil {
+			t.Fatalf("Replay: %v", err)
+		}
+		if replayed.ID != claimed[0].ID || replayed.Status != models.OutboxStatusPending || replayed.Attempts != 0 || replayed.Subject != claimed[0].Message.Subject {
+			t.Errorf("Replay = %+v, want it pending with a fresh attempt budget", replayed)
+		}
+		if _, err := repo.Replay(ctx, claimed[0].ID); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Replay(pending): error = %v, want ErrConflict", err)
+		}
+		if _, err := repo.Replay(ctx, 999); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Replay(missing): error = %v, want ErrNotFound", err)
+		}
+		if due := claimAll(t, repo, time.Minute); len(due) != 2 {
+			t.Errorf("ClaimDue after Replay = %d emails, want both", len(due))
+		}
+	})
+
+	t.Run("ListPages", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, subject := range []string{"a", "b", "c"} {
+			enqueue(t, repo, subject)
+		}
+		emails, total, err := repo.ListByStatus(ctx, models.OutboxStatusPending, 2, 2)
+		if err != nil || total != 3 || len(emails) != 1 {
+			t.Errorf("ListByStatus(page 2) = %d emails, total %d, %v; want 1 of 3", len(emails), total, err)
+		}
+		if emails, total, _ := repo.ListByStatus(ctx, models.OutboxStatusDead, 1, 10); total != 0 || emails == nil || len(emails) != 0 {
+			t.Errorf("ListByStatus(dead) = %v (total %d), want an empty non-nil slice", emails, total)
+		}
+	})
+}
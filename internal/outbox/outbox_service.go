@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/email"
+)
+
+// ServiceInterface queues emails and exposes failed ones to admins. It embeds email.ServiceInterface,
+// so it can be handed to any service that sends email: "sending" then means queueing for the Worker.
+type ServiceInterface interface {
+	email.ServiceInterface
+	ListFailed(ctx context.Context, page, limit int) ([]models.OutboxEmail, int, error)
+	Replay(ctx context.Context, id int64) (*models.OutboxEmail, error)
+}
+
+// Service provides the email outbox.
+type Service struct {
+	repo RepositoryInterface
+}
+
+// NewService creates a new outbox service.
+func NewService(repo RepositoryInterface) ServiceInterface {
+	return &Service{repo: repo}
+}
+
+func (s *Service) SendEmail(ctx context.Context, to []string, subject, htmlBody, textBody string) error {
+	return s.Send(ctx, &email.Message{To: to, Subject: subject, HTMLBody: htmlBody, TextBody: textBody})
+}
+
+// Send queues msg. It only fails if the message is invalid or the database is unavailable.
+func (s *Service) Send(ctx context.Context, msg *email.Message) error {
+	if err := s.repo.Enqueue(ctx, msg); err != nil {
+		return fmt.Errorf("service.Send: %w", err)
+	}
+	return nil
+}
+
+// ListFailed lists dead-lettered emails, most recently failed first.
+func (s *Service) ListFailed(ctx context.Context, page, limit int) ([]models.OutboxEmail, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	emails, total, err := s.repo.ListByStatus(ctx, models.OutboxStatusDead, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListFailed: %w", err)
+	}
+	return emails, total, nil
+}
+
+// Replay queues a dead email again. It returns models.ErrConflict if the email is not dead.
+func (s *Service) Replay(ctx context.Context, id int64) (*models.OutboxEmail, error) {
+	replayed, err := s.repo.Replay(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.Replay: %w", err)
+	}
+	return replayed, nil
+}
@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"jingdezhen-ceramics-backend/pkg/email"
+)
+
+const (
+	defaultWorkers      = 2
+	defaultPollInterval = 5 * time.Second
+	// A claimed email is retried by another worker if not finished within claimLease.
+	claimLease  = 5 * time.Minute
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+)
+
+// Worker delivers queued emails through sender (the real SMTP service), retrying failures with
+// exponential backoff until an email runs out of attempts and is dead-lettered.
+type Worker struct {
+	repo         RepositoryInterface
+	sender       email.ServiceInterface
+	workers      int
+	pollInterval time.Duration
+	wg           sync.WaitGroup
+}
+
+// NewWorker creates a worker pool of the given size (at least 1; 0 means 2).
+func NewWorker(repo RepositoryInterface, sender email.ServiceInterface, workers int) *Worker {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	return &Worker{
+		repo:         repo,
+		sender:       sender,
+		workers:      workers,
+		pollInterval: defaultPollInterval,
+	}
+}
+
+// Start launches the workers. They stop when ctx is cancelled; use Wait to block until they have.
+func (w *Worker) Start(ctx context.Context) {
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.run(ctx)
+		}()
+	}
+}
+
+// Wait blocks until every worker has returned after ctx was cancelled.
+func (w *Worker) Wait() {
+	w.wg.Wait()
+}
+
+func (w *Worker) run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		// Keep draining while there is work, then wait for the next tick.
+		for w.processOne(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processOne claims and delivers a single email. It reports whether there may be more due.
+func (w *Worker) processOne(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	claimed, err := w.repo.ClaimDue(ctx, 1, claimLease)
+	if err != nil {
+		log.Printf("ERROR: outbox.Worker: claim failed: %v", err)
+		return false
+	}
+	if len(claimed) == 0 {
+		return false
+	}
+	w.deliver(ctx, claimed[0])
+	return true
+}
+
+func (w *Worker) deliver(ctx context.Context, q QueuedEmail) {
+	// Results are recorded even if ctx is cancelled mid-send, so a finished delivery is not repeated.
+	recordCtx := context.WithoutCancel(ctx)
+
+	sendErr := w.sender.Send(ctx, &q.Message)
+	if sendErr == nil {
+		if err := w.repo.MarkSent(recordCtx, q.ID); err != nil {
+			log.Printf("ERROR: outbox.Worker: email %d sent but not marked: %v", q.ID, err)
+		}
+		return
+	}
+
+	if q.Attempts >= q.MaxAttempts {
+		log.Printf("ERROR: outbox.Worker: email %d dead after %d attempts: %v", q.ID, q.Attempts, sendErr)
+		if err := w.repo.MarkDead(recordCtx, q.ID, sendErr.Error()); err != nil {
+			log.Printf("ERROR: outbox.Worker: could not dead-letter email %d: %v", q.ID, err)
+		}
+		return
+	}
+
+	next := time.Now().Add(backoff(q.Attempts))
+	log.Printf("WARN: outbox.Worker: email %d attempt %d failed, retrying at %s: %v", q.ID, q.Attempts, next.Format(time.RFC3339), sendErr)
+	if err := w.repo.MarkRetry(recordCtx, q.ID, sendErr.Error(), next); err != nil {
+		log.Printf("ERROR: outbox.Worker: could not reschedule email %d: %v", q.ID, err)
+	}
+}
+
+// backoff doubles from baseBackoff per attempt up to maxBackoff, with up to 20% jitter so
+// emails that failed together (e.g. during an SMTP outage) do not all retry at once.
+func backoff(attempt int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay)/5+1))
+}
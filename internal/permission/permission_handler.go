@@ -0,0 +1,63 @@
+package permission
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler serves role administration. RequirePermission(roles.manage) is enforced by the router.
+type Handler struct {
+	service  ServiceInterface
+	validate *validator.Validate
+}
+
+// NewHandler creates a new permission handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// GetRoles lists every role with its permissions.
+func (h *Handler) GetRoles(c echo.Context) error {
+	roles, err := h.service.ListRoles(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, roles)
+}
+
+// GetPermissions lists every permission that can be granted.
+func (h *Handler) GetPermissions(c echo.Context) error {
+	permissions, err := h.service.ListPermissions(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, permissions)
+}
+
+// UpdateRolePermissions replaces the permission set of a role.
+func (h *Handler) UpdateRolePermissions(c echo.Context) error {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	var req models.UpdateRolePermissionsData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	role, err := h.service.SetRolePermissions(c.Request().Context(), actorID, c.Param("role_name"), req.Permissions)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, role)
+}
@@ -0,0 +1,113 @@
+package permission
+
+import (
+	"context"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"sync"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It starts with the roles,
+// permissions and grants of the migrations and returns the same errors as Repository.
+type MemoryRepository struct {
+	mu          sync.Mutex
+	roles       map[string]string // name -> description
+	permissions map[string]string // name -> description
+	grants      map[string]map[string]bool
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates a permission repository holding the rows the migrations insert.
+func NewMemoryRepository() *MemoryRepository {
+	r := &MemoryRepository{
+		roles: map[string]string{
+			models.RoleAdmin:      "Full access; always has every permission",
+			models.RoleModerator:  "Moderates the forum, portfolio and contact inbox",
+			models.RoleTeacher:    "Authors courses and follows student progress",
+			models.RoleCurator:    "Edits gallery and ceramic story content",
+			models.RoleNormalUser: "Registered member",
+			models.RoleGuest:      "Not logged in",
+		},
+		permissions: map[string]string{
+			models.PermUsersManage:        "List users and change their roles",
+			models.PermRolesManage:        "Change which permissions each role has",
+			models.PermForumModerate:      "Pin, archive and delete any forum post or comment",
+			models.PermPortfolioModerate:  "Highlight and delete any portfolio work",
+			models.PermGalleryEdit:        "Create, edit and delete artworks and artists",
+			models.PermCeramicStoryEdit:   "Create, edit and delete ceramic story entries",
+			models.PermCourseAuthor:       "Create, edit and delete courses, chapters and quizzes",
+			models.PermCourseViewProgress: "View the student progress dashboard",
+			models.PermEmailsManage:       "Inspect and replay outgoing email, preview templates",
+			models.PermContactManage:      "Read and answer contact form messages",
+			models.PermAuditView:          "Search and export the audit log",
+		},
+		grants: map[string]map[string]bool{
+			models.RoleModerator: {models.PermForumModerate: true, models.PermPortfolioModerate: true, models.PermContactManage: true},
+			models.RoleTeacher:   {models.PermCourseAuthor: true, models.PermCourseViewProgress: true},
+			models.RoleCurator:   {models.PermGalleryEdit: true, models.PermCeramicStoryEdit: true},
+		},
+	}
+	r.grants[models.RoleAdmin] = map[string]bool{}
+	for name := range r.permissions {
+		r.grants[models.RoleAdmin][name] = true
+	}
+	return r
+}
+
+// AddRole adds a role without permissions.
+func (r *MemoryRepository) AddRole(name, description string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles[name] = description
+}
+
+func (r *MemoryRepository) ListRoles(ctx context.Context) ([]models.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	roles := make([]models.Role, 0, len(r.roles))
+	for name, description := range r.roles {
+		role := models.Role{Name: name, Description: description, Permissions: []string{}}
+		for permission := range r.grants[name] {
+			role.Permissions = append(role.Permissions, permission)
+		}
+		sort.Strings(role.Permissions)
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *MemoryRepository) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	permissions := make([]models.Permission, 0, len(r.permissions))
+	for name, description := range r.permissions {
+		permissions = append(permissions, models.Permission{Name: name, Description: description})
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (r *MemoryRepository) SetRolePermissions(ctx context.Context, role string, permissions []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[role]; !ok {
+		return models.ErrNotFound
+	}
+	// Like the SQL, a repeated name counts once, so it fails the same way as an unknown one
+	set := map[string]bool{}
+	for _, permission := range permissions {
+		if _, ok := r.permissions[permission]; ok {
+			set[permission] = true
+		}
+	}
+	if len(set) != len(permissions) {
+		return models.ErrUnknownPermission
+	}
+	r.grants[role] = set
+	return nil
+}
@@ -0,0 +1,108 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines methods for the roles, permissions and role_permissions tables.
+type RepositoryInterface interface {
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	// SetRolePermissions replaces the permissions of role. It returns models.ErrNotFound for an
+	// unknown role and models.ErrUnknownPermission if any permission does not exist.
+	SetRolePermissions(ctx context.Context, role string, permissions []string) error
+}
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+func (r *Repository) ListRoles(ctx context.Context) ([]models.Role, error) {
+	query := `SELECT r.name, r.description,
+	                 ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role = r.name ORDER BY rp.permission)
+	          FROM roles r
+	          ORDER BY r.name`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListRoles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []models.Role{}
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.Name, &role.Description, &role.Permissions); err != nil {
+			return nil, fmt.Errorf("repository.ListRoles.Scan: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListRoles.Rows: %w", err)
+	}
+	return roles, nil
+}
+
+func (r *Repository) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	rows, err := r.db.Query(ctx, `SELECT name, description FROM permissions ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListPermissions: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := []models.Permission{}
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			return nil, fmt.Errorf("repository.ListPermissions.Scan: %w", err)
+		}
+		permissions = append(permissions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListPermissions.Rows: %w", err)
+	}
+	return permissions, nil
+}
+
+func (r *Repository) SetRolePermissions(ctx context.Context, role string, permissions []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.SetRolePermissions.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, role).Scan(&exists); err != nil {
+		return fmt.Errorf("repository.SetRolePermissions.Role: %w", err)
+	}
+	if !exists {
+		return models.ErrNotFound
+	}
+
+	var known int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM permissions WHERE name = ANY($1)`, permissions).Scan(&known); err != nil {
+		return fmt.Errorf("repository.SetRolePermissions.Permissions: %w", err)
+	}
+	if known != len(permissions) {
+		return models.ErrUnknownPermission
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role = $1`, role); err != nil {
+		return fmt.Errorf("repository.SetRolePermissions.Delete: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO role_permissions (role, permission) SELECT $1, unnest($2::text[])`, role, permissions); err != nil {
+		return fmt.Errorf("repository.SetRolePermissions.Insert: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.SetRolePermissions.Commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets a freshly migrated repository.
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewRepository(pgtest.NewDB(t)) })
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewMemoryRepository() })
+}
+
+func testRepositoryContract(t *testing.T, newRepo func(t *testing.T) RepositoryInterface) {
+	ctx := context.Background()
+	permissionsOf := func(t *testing.T, repo RepositoryInterface, name string) []string {
+		t.Helper()
+		roles, err := repo.ListRoles(ctx)
+		if err != nil {
+			t.Fatalf("ListRoles: %v", err)
+		}
+		for _, role := range roles {
+			if role.Name == name {
+				return role.Permissions
+			}
+		}
+		t.Fatalf("ListRoles has no role %s", name)
+		return nil
+	}
+
+	t.Run("MigratedRows", func(t *testing.T) {
+		repo := newRepo(t)
+		roles, err := repo.ListRoles(ctx)
+		if err != nil {
+			t.Fatalf("ListRoles: %v", err)
+		}
+		var names []string
+		for _, role := range roles {
+			names = append(names, role.Name)
+		}
+		want := []string{models.RoleAdmin, models.RoleCurator, models.RoleGuest, models.RoleModerator, models.RoleNormalUser, models.RoleTeacher}
+		if !slices.Equal(names, want) {
+			t.Errorf("ListRoles names = %v, want %v", names, want)
+		}
+
+		permissions, err := repo.ListPermissions(ctx)
+		if err != nil {
+			t.Fatalf("ListPermissions: %v", err)
+		}
+		var all []string
+		for _, p := range permissions {
+			if p.Description == "" {
+				t.Errorf("permission %s has no description", p.Name)
+			}
+			all = append(all, p.Name)
+		}
+		if len(all) != 11 || !slices.IsSorted(all) || !slices.Contains(all, models.PermAuditView) {
+			t.Errorf("ListPermissions = %v, want the 11 migrated permissions sorted by name", all)
+		}
+		if got := permissionsOf(t, repo, models.RoleAdmin); !slices.Equal(got, all) {
+			t.Errorf("admin permissions = %v, want every permission", got)
+		}
+		if got := permissionsOf(t, repo, models.RoleCurator); !slices.Equal(got, []string{models.PermCeramicStoryEdit, models.PermGalleryEdit}) {
+			t.Errorf("curator permissions = %v", got)
+		}
+		if got := permissionsOf(t, repo, models.RoleGuest); len(got) != 0 {
+			t.Errorf("guest permissions = %v, want none", got)
+		}
+	})
+
+	t.Run("SetRolePermissions", func(t *testing.T) {
+		repo := newRepo(t)
+		grant := []string{models.PermForumModerate, models.PermCourseAuthor}
+		if err := repo.SetRolePermissions(ctx, models.RoleTeacher, grant); err != nil {
+			t.Fatalf("SetRolePermissions: %v", err)
+		}
+		if got := permissionsOf(t, repo, models.RoleTeacher); !slices.Equal(got, []string{models.PermCourseAuthor, models.PermForumModerate}) {
+			t.Errorf("teacher permissions = %v, want the replaced set", got)
+		}
+
+		if err := repo.SetRolePermissions(ctx, models.RoleTeacher, nil); err != nil {
+			t.Fatalf("SetRolePermissions(nil): %v", err)
+		}
+		if got := permissionsOf(t, repo, models.RoleTeacher); len(got) != 0 {
+			t.Errorf("teacher permissions = %v, want none", got)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.SetRolePermissions(ctx, "potter", []string{models.PermForumModerate}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("SetRolePermissions(unknown role): error = %v, want ErrNotFound", err)
+		}
+		if err := repo.SetRolePermissions(ctx, models.RoleCurator, []string{models.PermGalleryEdit, "kiln.fire"}); !errors.Is(err, models.ErrUnknownPermission) {
+			t.Errorf("SetRolePermissions(unknown permission): error = %v, want ErrUnknownPermission", err)
+		}
+		if got := permissionsOf(t, repo, models.RoleCurator); len(got) != 2 {
+			t.Errorf("curator permissions after a failed update = %v, want them unchanged", got)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/audit"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// cacheTTL bounds how long a permission change made on another API instance takes to apply here.
+const cacheTTL = time.Minute
+
+// Checker answers whether a role has a permission. Service implements it; other services take it
+// to let e.g. moderators override ownership checks.
+type Checker interface {
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+// ServiceInterface defines the role and permission administration.
+type ServiceInterface interface {
+	Checker
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	// SetRolePermissions replaces the permissions of role on behalf of actorID. The admin role cannot be
+	// changed (models.ErrForbidden).
+	SetRolePermissions(ctx context.Context, actorID, role string, permissions []string) (*models.Role, error)
+}
+
+// Service checks permissions against a cached copy of role_permissions.
+type Service struct {
+	repo     RepositoryInterface
+	auditLog audit.Recorder
+
+	mu       sync.RWMutex
+	grants   map[string]map[string]bool // role -> permission set
+	loadedAt time.Time
+}
+
+// NewService creates a new permission service.
+func NewService(repo RepositoryInterface, auditLog audit.Recorder) ServiceInterface {
+	return &Service{repo: repo, auditLog: auditLog}
+}
+
+// HasPermission reports whether role grants permission. Admin has every permission without a lookup,
+// so a newly added permission can never lock admins out.
+func (s *Service) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	if role == models.RoleAdmin {
+		return true, nil
+	}
+	grants, err := s.cachedGrants(ctx)
+	if err != nil {
+		return false, fmt.Errorf("service.HasPermission: %w", err)
+	}
+	return grants[role][permission], nil
+}
+
+func (s *Service) cachedGrants(ctx context.Context) (map[string]map[string]bool, error) {
+	s.mu.RLock()
+	grants, loadedAt := s.grants, s.loadedAt
+	s.mu.RUnlock()
+	if grants != nil && time.Since(loadedAt) < cacheTTL {
+		return grants, nil
+	}
+
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grants = make(map[string]map[string]bool, len(roles))
+	for _, role := range roles {
+		grants[role.Name] = make(map[string]bool, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			grants[role.Name][permission] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.grants, s.loadedAt = grants, time.Now()
+	s.mu.Unlock()
+	return grants, nil
+}
+
+// invalidate makes the next check reload from the database.
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.grants = nil
+	s.mu.Unlock()
+}
+
+func (s *Service) ListRoles(ctx context.Context) ([]models.Role, error) {
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListRoles: %w", err)
+	}
+	return roles, nil
+}
+
+func (s *Service) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	permissions, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListPermissions: %w", err)
+	}
+	return permissions, nil
+}
+
+func (s *Service) SetRolePermissions(ctx context.Context, actorID, role string, permissions []string) (*models.Role, error) {
+	if role == models.RoleAdmin {
+		return nil, models.ErrForbidden
+	}
+	unique := make([]string, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+	sort.Strings(unique)
+
+	before, err := s.findRole(ctx, role)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetRolePermissions: %w", err)
+	}
+	if err := s.repo.SetRolePermissions(ctx, role, unique); err != nil {
+		return nil, fmt.Errorf("service.SetRolePermissions: %w", err)
+	}
+	s.invalidate()
+
+	after, err := s.findRole(ctx, role)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetRolePermissions: %w", err)
+	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID: actorID, Action: audit.ActionRolePermissionsUpdate, EntityType: audit.EntityRole, EntityID: role,
+		Before: before, After: after,
+	})
+	return after, nil
+}
+
+// findRole returns models.ErrNotFound for an unknown role.
+func (s *Service) findRole(ctx context.Context, role string) (*models.Role, error) {
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		if r.Name == role {
+			return &r, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
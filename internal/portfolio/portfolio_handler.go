@@ -0,0 +1,142 @@
+package portfolio
+
+import (
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler handles HTTP requests for the student portfolio.
+type Handler struct {
+	service  ServiceInterface
+	validate *validator.Validate
+}
+
+// NewHandler creates a new portfolio handler.
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// --- Public Routes ---
+
+// GetWorks lists portfolio works. Params: ?page=1&limit=20&category=...&sort=latest|kudos
+func (h *Handler) GetWorks(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	filter := models.PortfolioWorkFilter{
+		Page:     page,
+		Limit:    limit,
+		Category: c.QueryParam("category"),
+		Sort:     c.QueryParam("sort"),
+	}
+
+	works, total, err := h.service.ListWorks(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(works, page, limit, total))
+}
+
+// GetWorkByID returns the work detail. has_given_kudo is only populated when a valid JWT was sent.
+func (h *Handler) GetWorkByID(c echo.Context) error {
+	workID, err := strconv.ParseInt(c.Param("work_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid work ID")
+	}
+	viewerID, _ := utils.GetUserIDFromContext(c) // Empty for guests
+
+	work, err := h.service.GetWorkDetail(c.Request().Context(), workID, viewerID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, work)
+}
+
+// --- Protected Routes ---
+
+func (h *Handler) CreateWork(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+
+	var req models.CreatePortfolioWorkData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	work, err := h.service.CreateWork(c.Request().Context(), userID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, work)
+}
+
+func (h *Handler) UpdateWork(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	workID, err := strconv.ParseInt(c.Param("work_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid work ID")
+	}
+
+	var req models.UpdatePortfolioWorkData
+	if err := c.Bind(&req); err != nil {
+		return models.InvalidBodyError(err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return models.ValidationError(err)
+	}
+
+	work, err := h.service.UpdateWork(c.Request().Context(), userID, workID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, work)
+}
+
+func (h *Handler) DeleteWork(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	workID, err := strconv.ParseInt(c.Param("work_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid work ID")
+	}
+
+	err = h.service.DeleteWork(c.Request().Context(), userID, utils.GetUserRoleFromContext(c), workID)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LeaveKudo gives one kudo per user per work; a second attempt returns 409.
+func (h *Handler) LeaveKudo(c echo.Context) error {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		return models.UnauthorizedError(err.Error())
+	}
+	workID, err := strconv.ParseInt(c.Param("work_id"), 10, 64)
+	if err != nil {
+		return models.BadRequestError("Invalid work ID")
+	}
+
+	result, err := h.service.LeaveKudo(c.Request().Context(), userID, workID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,244 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository. Authors must be registered with AddUser, like the users rows the foreign keys point at, and
+// the notifications AddKudo sends are kept for Notifications.
+type MemoryRepository struct {
+	mu            sync.Mutex
+	works         map[int64]*models.PortfolioWork
+	images        map[int64][]models.PortfolioWorkImage
+	kudos         map[kudoKey]bool
+	nicknames     map[string]string
+	notifications []models.Notification
+	lastID        int64
+	lastImageID   int
+}
+
+type kudoKey struct {
+	userID string
+	workID int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory portfolio repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		works:     map[int64]*models.PortfolioWork{},
+		images:    map[int64][]models.PortfolioWorkImage{},
+		kudos:     map[kudoKey]bool{},
+		nicknames: map[string]string{},
+	}
+}
+
+// AddUser registers userID with the nickname shown as AuthorNickname on their works.
+func (r *MemoryRepository) AddUser(userID, nickname string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nicknames[userID] = nickname
+}
+
+// Notifications returns the notifications sent by AddKudo, oldest first.
+func (r *MemoryRepository) Notifications() []models.Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.Notification(nil), r.notifications...)
+}
+
+// view returns the work as workSelect does: with the author's nickname and thumbnail but without images.
+func (r *MemoryRepository) view(work *models.PortfolioWork) *models.PortfolioWork {
+	out := *work
+	out.AuthorNickname = r.nicknames[work.UserID]
+	out.ThumbnailURL = ""
+	images := append([]models.PortfolioWorkImage(nil), r.images[work.ID]...)
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].IsThumbnail != images[j].IsThumbnail {
+			return images[i].IsThumbnail
+		}
+		if images[i].DisplayOrder != images[j].DisplayOrder {
+			return images[i].DisplayOrder < images[j].DisplayOrder
+		}
+		return images[i].ID < images[j].ID
+	})
+	if len(images) > 0 {
+		out.ThumbnailURL = images[0].ImageURL
+	}
+	return &out
+}
+
+func (r *MemoryRepository) replaceWorkImages(workID int64, images []models.PortfolioImageData) {
+	stored := make([]models.PortfolioWorkImage, 0, len(images))
+	for i, image := range images {
+		r.lastImageID++
+		stored = append(stored, models.PortfolioWorkImage{
+			ID: r.lastImageID, WorkID: workID, ImageURL: image.ImageURL,
+			IsThumbnail: image.IsThumbnail, Caption: image.Caption, DisplayOrder: i,
+		})
+	}
+	r.images[workID] = stored
+}
+
+// --- Works ---
+
+func (r *MemoryRepository) ListWorks(ctx context.Context, filter models.PortfolioWorkFilter) ([]models.PortfolioWork, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []*models.PortfolioWork{}
+	for _, work := range r.works {
+		if filter.Category == "" || work.Category == filter.Category {
+			matched = append(matched, work)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if a.IsEditorsChoice != b.IsEditorsChoice {
+			return a.IsEditorsChoice
+		}
+		if filter.Sort == models.PortfolioSortKudos && a.KudosCount != b.KudosCount {
+			return a.KudosCount > b.KudosCount
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+
+	works := []models.PortfolioWork{}
+	offset := (filter.Page - 1) * filter.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	for i := offset; i < len(matched) && i < offset+filter.Limit; i++ {
+		works = append(works, *r.view(matched[i]))
+	}
+	return works, len(matched), nil
+}
+
+func (r *MemoryRepository) FindWorkByID(ctx context.Context, workID int64) (*models.PortfolioWork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	work, ok := r.works[workID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.view(work), nil
+}
+
+func (r *MemoryRepository) ListWorkImages(ctx context.Context, workID int64) ([]models.PortfolioWorkImage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Images are stored in display_order, which replaceWorkImages assigns in ID order
+	return append([]models.PortfolioWorkImage{}, r.images[workID]...), nil
+}
+
+func (r *MemoryRepository) CreateWork(ctx context.Context, userID string, data models.CreatePortfolioWorkData) (*models.PortfolioWork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.nicknames[userID]; !ok {
+		return nil, fmt.Errorf("repository.CreateWork: user %q does not exist", userID) // The users foreign key
+	}
+	r.lastID++
+	now := time.Now()
+	work := &models.PortfolioWork{
+		ID: r.lastID, UserID: userID, Title: data.Title, Description: data.Description,
+		Category: data.Category, CreatedAt: now, UpdatedAt: now,
+	}
+	r.works[work.ID] = work
+	r.replaceWorkImages(work.ID, data.Images)
+	return r.view(work), nil
+}
+
+func (r *MemoryRepository) UpdateWork(ctx context.Context, workID int64, data models.UpdatePortfolioWorkData) (*models.PortfolioWork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	work, ok := r.works[workID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if data.Title != nil {
+		work.Title = *data.Title
+	}
+	if data.Description != nil {
+		work.Description = *data.Description
+	}
+	if data.Category != nil {
+		work.Category = *data.Category
+	}
+	work.UpdatedAt = time.Now()
+	if len(data.Images) > 0 {
+		r.replaceWorkImages(workID, data.Images)
+	}
+	return r.view(work), nil
+}
+
+func (r *MemoryRepository) DeleteWork(ctx context.Context, workID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.works[workID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.works, workID)
+	delete(r.images, workID)
+	for key := range r.kudos {
+		if key.workID == workID {
+			delete(r.kudos, key)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) SetEditorsChoice(ctx context.Context, workID int64, highlighted bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	work, ok := r.works[workID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	work.IsEditorsChoice = highlighted
+	return nil
+}
+
+// --- Kudos ---
+
+func (r *MemoryRepository) HasGivenKudo(ctx context.Context, userID string, workID int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.kudos[kudoKey{userID, workID}], nil
+}
+
+func (r *MemoryRepository) AddKudo(ctx context.Context, userID string, work *models.PortfolioWork) (*models.ToggleResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.works[work.ID]
+	if _, known := r.nicknames[userID]; !ok || !known {
+		return nil, fmt.Errorf("repository.AddKudo: user %q or work %d does not exist", userID, work.ID) // The foreign keys
+	}
+	key := kudoKey{userID, work.ID}
+	if r.kudos[key] {
+		return nil, models.ErrConflict
+	}
+	r.kudos[key] = true
+	stored.KudosCount++
+
+	r.notifications = append(r.notifications, models.Notification{
+		ID:              strconv.Itoa(len(r.notifications) + 1),
+		RecipientUserID: work.UserID,
+		ActorUserID:     userID,
+		ActionType:      models.NotificationActionKudoPortfolioWork,
+		EntityType:      models.NotificationEntityPortfolioWork,
+		EntityID:        int(work.ID),
+		Message:         fmt.Sprintf("Your work %q received a kudo", work.Title),
+		CreatedAt:       time.Now(),
+	})
+	return &models.ToggleResult{Active: true, Count: stored.KudosCount}, nil
+}
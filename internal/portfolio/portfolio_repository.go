@@ -0,0 +1,320 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RepositoryInterface defines the methods for interacting with portfolio storage.
+type RepositoryInterface interface {
+	ListWorks(ctx context.Context, filter models.PortfolioWorkFilter) ([]models.PortfolioWork, int, error)
+	FindWorkByID(ctx context.Context, workID int64) (*models.PortfolioWork, error)
+	ListWorkImages(ctx context.Context, workID int64) ([]models.PortfolioWorkImage, error)
+	CreateWork(ctx context.Context, userID string, data models.CreatePortfolioWorkData) (*models.PortfolioWork, error)
+	UpdateWork(ctx context.Context, workID int64, data models.UpdatePortfolioWorkData) (*models.PortfolioWork, error)
+	DeleteWork(ctx context.Context, workID int64) error
+	SetEditorsChoice(ctx context.Context, workID int64, highlighted bool) error
+
+	// Kudos
+	HasGivenKudo(ctx context.Context, userID string, workID int64) (bool, error)
+	AddKudo(ctx context.Context, userID string, work *models.PortfolioWork) (*models.ToggleResult, error)
+}
+
+// Repository provides access to the portfolio storage.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new portfolio repository.
+func NewRepository(db *pgxpool.Pool) RepositoryInterface {
+	return &Repository{db: db}
+}
+
+// workSelect is shared by every query returning models.PortfolioWork so the Scan order stays in one place.
+const workSelect = `
+	SELECT pw.id, pw.user_id, COALESCE(u.nickname, ''), pw.title, COALESCE(pw.description, ''),
+	       COALESCE(pw.category, ''), COALESCE(pw.is_editors_choice, FALSE), COALESCE(pw.kudos_count, 0),
+	       COALESCE((SELECT pwi.image_url FROM portfolio_work_images pwi WHERE pwi.portfolio_work_id = pw.id
+	                 ORDER BY pwi.is_thumbnail DESC, pwi.display_order ASC, pwi.id ASC LIMIT 1), ''),
+	       pw.created_at, pw.updated_at
+	FROM portfolio_works pw
+	JOIN users u ON u.id = pw.user_id
+`
+
+func scanWork(row pgx.Row) (*models.PortfolioWork, error) {
+	var work models.PortfolioWork
+	err := row.Scan(
+		&work.ID, &work.UserID, &work.AuthorNickname, &work.Title, &work.Description,
+		&work.Category, &work.IsEditorsChoice, &work.KudosCount, &work.ThumbnailURL,
+		&work.CreatedAt, &work.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+// --- Works ---
+
+func (r *Repository) ListWorks(ctx context.Context, filter models.PortfolioWorkFilter) ([]models.PortfolioWork, int, error) {
+	whereClause := ""
+	var args []interface{}
+	argIdx := 1
+	if filter.Category != "" {
+		whereClause = fmt.Sprintf(" WHERE pw.category = $%d", argIdx)
+		args = append(args, filter.Category)
+		argIdx++
+	}
+
+	// Highlighted works always come first, the chosen sort applies below them.
+	orderBy := " ORDER BY COALESCE(pw.is_editors_choice, FALSE) DESC, pw.created_at DESC"
+	if filter.Sort == models.PortfolioSortKudos {
+		orderBy = " ORDER BY COALESCE(pw.is_editors_choice, FALSE) DESC, COALESCE(pw.kudos_count, 0) DESC, pw.created_at DESC"
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+	query := workSelect + whereClause + orderBy + fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	rows, err := r.db.Query(ctx, query, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListWorks: %w", err)
+	}
+	defer rows.Close()
+
+	works := []models.PortfolioWork{}
+	for rows.Next() {
+		work, err := scanWork(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("repository.ListWorks.Scan: %w", err)
+		}
+		works = append(works, *work)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListWorks.Rows: %w", err)
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM portfolio_works pw" + whereClause
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListWorks.Count: %w", err)
+	}
+	return works, total, nil
+}
+
+func (r *Repository) FindWorkByID(ctx context.Context, workID int64) (*models.PortfolioWork, error) {
+	work, err := scanWork(r.db.QueryRow(ctx, workSelect+" WHERE pw.id = $1", workID))
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.FindWorkByID: %w", err)
+	}
+	return work, nil
+}
+
+func (r *Repository) ListWorkImages(ctx context.Context, workID int64) ([]models.PortfolioWorkImage, error) {
+	query := `SELECT id, portfolio_work_id, image_url, COALESCE(is_thumbnail, FALSE), COALESCE(caption, ''), COALESCE(display_order, 0)
+	          FROM portfolio_work_images WHERE portfolio_work_id = $1 ORDER BY display_order ASC, id ASC`
+	rows, err := r.db.Query(ctx, query, workID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListWorkImages: %w", err)
+	}
+	defer rows.Close()
+
+	images := []models.PortfolioWorkImage{}
+	for rows.Next() {
+		var image models.PortfolioWorkImage
+		if err := rows.Scan(&image.ID, &image.WorkID, &image.ImageURL, &image.IsThumbnail, &image.Caption, &image.DisplayOrder); err != nil {
+			return nil, fmt.Errorf("repository.ListWorkImages.Scan: %w", err)
+		}
+		images = append(images, image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListWorkImages.Rows: %w", err)
+	}
+	return images, nil
+}
+
+func (r *Repository) CreateWork(ctx context.Context, userID string, data models.CreatePortfolioWorkData) (*models.PortfolioWork, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateWork.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	var workID int64
+	now := time.Now()
+	query := `INSERT INTO portfolio_works (user_id, title, description, category, created_at, updated_at)
+	          VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $5) RETURNING id`
+	err = tx.QueryRow(ctx, query, userID, data.Title, data.Description, data.Category, now).Scan(&workID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateWork: %w", err)
+	}
+
+	if err := replaceWorkImages(ctx, tx, workID, data.Images); err != nil {
+		return nil, fmt.Errorf("repository.CreateWork.Images: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.CreateWork.Commit: %w", err)
+	}
+	return r.FindWorkByID(ctx, workID)
+}
+
+func (r *Repository) UpdateWork(ctx context.Context, workID int64, data models.UpdatePortfolioWorkData) (*models.PortfolioWork, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateWork.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var setClauses []string
+	var args []interface{}
+	argIdx := 1
+
+	if data.Title != nil {
+		setClauses = append(setClauses, fmt.Sprintf("title = $%d", argIdx))
+		args = append(args, *data.Title)
+		argIdx++
+	}
+	if data.Description != nil {
+		setClauses = append(setClauses, fmt.Sprintf("description = NULLIF($%d, '')", argIdx))
+		args = append(args, *data.Description)
+		argIdx++
+	}
+	if data.Category != nil {
+		setClauses = append(setClauses, fmt.Sprintf("category = NULLIF($%d, '')", argIdx))
+		args = append(args, *data.Category)
+		argIdx++
+	}
+
+	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", argIdx))
+	args = append(args, time.Now())
+	argIdx++
+
+	args = append(args, workID)
+	query := fmt.Sprintf(`UPDATE portfolio_works SET %s WHERE id = $%d`, strings.Join(setClauses, ", "), argIdx)
+	cmdTag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateWork: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	if len(data.Images) > 0 {
+		if err := replaceWorkImages(ctx, tx, workID, data.Images); err != nil {
+			return nil, fmt.Errorf("repository.UpdateWork.Images: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.UpdateWork.Commit: %w", err)
+	}
+	return r.FindWorkByID(ctx, workID)
+}
+
+// replaceWorkImages stores exactly the given images for the work, in the given order.
+// The service guarantees exactly one of them is flagged as the thumbnail.
+func replaceWorkImages(ctx context.Context, tx pgx.Tx, workID int64, images []models.PortfolioImageData) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM portfolio_work_images WHERE portfolio_work_id = $1`, workID); err != nil {
+		return err
+	}
+	for i, image := range images {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO portfolio_work_images (portfolio_work_id, image_url, is_thumbnail, caption, display_order)
+			 VALUES ($1, $2, $3, NULLIF($4, ''), $5)`,
+			workID, image.ImageURL, image.IsThumbnail, image.Caption, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) DeleteWork(ctx context.Context, workID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM portfolio_works WHERE id = $1`, workID)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteWork: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// SetEditorsChoice flags or unflags a work as highlighted. updated_at is left alone since
+// highlighting is not an edit by the owner.
+func (r *Repository) SetEditorsChoice(ctx context.Context, workID int64, highlighted bool) error {
+	cmdTag, err := r.db.Exec(ctx, `UPDATE portfolio_works SET is_editors_choice = $1 WHERE id = $2`, highlighted, workID)
+	if err != nil {
+		return fmt.Errorf("repository.SetEditorsChoice: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// --- Kudos ---
+
+func (r *Repository) HasGivenKudo(ctx context.Context, userID string, workID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM portfolio_work_kudos WHERE user_id = $1 AND portfolio_work_id = $2)`
+	if err := r.db.QueryRow(ctx, query, userID, workID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("repository.HasGivenKudo: %w", err)
+	}
+	return exists, nil
+}
+
+// AddKudo records the user's kudo, bumps the cached kudos_count and notifies the owner, all in
+// one transaction so the count and notifications never drift from the kudos table.
+// Returns models.ErrConflict if the user already gave a kudo to this work.
+func (r *Repository) AddKudo(ctx context.Context, userID string, work *models.PortfolioWork) (*models.ToggleResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.AddKudo.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx,
+		`INSERT INTO portfolio_work_kudos (user_id, portfolio_work_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		userID, work.ID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.AddKudo: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return nil, models.ErrConflict
+	}
+
+	result := &models.ToggleResult{Active: true}
+	err = tx.QueryRow(ctx,
+		`UPDATE portfolio_works SET kudos_count = COALESCE(kudos_count, 0) + 1 WHERE id = $1 RETURNING kudos_count`,
+		work.ID).Scan(&result.Count)
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.AddKudo.Count: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO notifications (recipient_user_id, actor_user_id, action_type, entity_type, entity_id, message)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		work.UserID, userID, models.NotificationActionKudoPortfolioWork, models.NotificationEntityPortfolioWork,
+		work.ID, fmt.Sprintf("Your work %q received a kudo", work.Title))
+	if err != nil {
+		return nil, fmt.Errorf("repository.AddKudo.Notification: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.AddKudo.Commit: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,261 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty repository, a function creating a user
+// (returning the ID and nickname) and one counting the kudo notifications a user received.
+type fixture struct {
+	repo          RepositoryInterface
+	addUser       func() (string, string)
+	notifications func(recipientID string) int
+}
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		db := pgtest.NewDB(t)
+		return fixture{
+			repo: NewRepository(db),
+			addUser: func() (string, string) {
+				u := factory.User(t, db)
+				return u.ID, u.Nickname
+			},
+			notifications: func(recipientID string) int {
+				var n int
+				err := db.QueryRow(context.Background(),
+					`SELECT COUNT(*) FROM notifications WHERE recipient_user_id = $1 AND action_type = $2`,
+					recipientID, models.NotificationActionKudoPortfolioWork).Scan(&n)
+				if err != nil {
+					t.Fatalf("count notifications: %v", err)
+				}
+				return n
+			},
+		}
+	})
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) fixture {
+		repo := NewMemoryRepository()
+		lastUserID := 0
+		return fixture{
+			repo: repo,
+			addUser: func() (string, string) {
+				lastUserID++
+				id, nickname := strconv.Itoa(lastUserID), "user"+strconv.Itoa(lastUserID)
+				repo.AddUser(id, nickname)
+				return id, nickname
+			},
+			notifications: func(recipientID string) int {
+				n := 0
+				for _, notification := range repo.Notifications() {
+					if notification.RecipientUserID == recipientID && notification.ActionType == models.NotificationActionKudoPortfolioWork {
+						n++
+					}
+				}
+				return n
+			},
+		}
+	})
+}
+
+func testRepositoryContract(t *testing.T, newFixture func(t *testing.T) fixture) {
+	ctx := context.Background()
+	image := func(url string, thumbnail bool) models.PortfolioImageData {
+		return models.PortfolioImageData{ImageURL: "https://example.com/" + url, IsThumbnail: thumbnail}
+	}
+	create := func(t *testing.T, f fixture, userID, title, category string) *models.PortfolioWork {
+		t.Helper()
+		work, err := f.repo.CreateWork(ctx, userID, models.CreatePortfolioWorkData{
+			Title: title, Category: category, Images: []models.PortfolioImageData{image(title+".jpg", true)},
+		})
+		if err != nil {
+			t.Fatalf("CreateWork(%s): %v", title, err)
+		}
+		return work
+	}
+	titles := func(works []models.PortfolioWork) []string {
+		titles := []string{}
+		for _, w := range works {
+			titles = append(titles, w.Title)
+		}
+		return titles
+	}
+	list := func(t *testing.T, f fixture, filter models.PortfolioWorkFilter) ([]string, int) {
+		t.Helper()
+		if filter.Page == 0 {
+			filter.Page, filter.Limit = 1, 10
+		}
+		works, total, err := f.repo.ListWorks(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListWorks: %v", err)
+		}
+		return titles(works), total
+	}
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		f := newFixture(t)
+		userID, nickname := f.addUser()
+		created, err := f.repo.CreateWork(ctx, userID, models.CreatePortfolioWorkData{
+			Title: "Moon jar", Description: "Wheel thrown in two halves", Category: "Throwing",
+			Images: []models.PortfolioImageData{
+				image("side.jpg", false),
+				{ImageURL: "https://example.com/front.jpg", Caption: "Front", IsThumbnail: true},
+			},
+		})
+		if err != nil {
+			t.Fatalf("CreateWork: %v", err)
+		}
+		if created.ID == 0 || created.UserID != userID || created.AuthorNickname != nickname || created.Title != "Moon jar" ||
+			created.Description != "Wheel thrown in two halves" || created.Category != "Throwing" || created.IsEditorsChoice ||
+			created.KudosCount != 0 || created.ThumbnailURL != "https://example.com/front.jpg" || created.CreatedAt.IsZero() {
+			t.Errorf("CreateWork = %+v", created)
+		}
+
+		found, err := f.repo.FindWorkByID(ctx, created.ID)
+		if err != nil || !reflect.DeepEqual(found, created) {
+			t.Errorf("FindWorkByID = %+v, %v; want %+v", found, err, created)
+		}
+		images, err := f.repo.ListWorkImages(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("ListWorkImages: %v", err)
+		}
+		if len(images) != 2 || images[0].ImageURL != "https://example.com/side.jpg" || images[0].DisplayOrder != 0 ||
+			images[1].Caption != "Front" || !images[1].IsThumbnail || images[1].DisplayOrder != 1 || images[1].WorkID != created.ID {
+			t.Errorf("ListWorkImages = %+v", images)
+		}
+		if images, err := f.repo.ListWorkImages(ctx, created.ID+100); err != nil || images == nil || len(images) != 0 {
+			t.Errorf("ListWorkImages(missing) = %v, %v; want an empty non-nil slice", images, err)
+		}
+		if _, err := f.repo.FindWorkByID(ctx, created.ID+100); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindWorkByID(missing): error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		f := newFixture(t)
+		userID, _ := f.addUser()
+		work := create(t, f, userID, "Bowl", "Glazing")
+
+		title, category := "Tea bowl", ""
+		updated, err := f.repo.UpdateWork(ctx, work.ID, models.UpdatePortfolioWorkData{Title: &title, Category: &category})
+		if err != nil {
+			t.Fatalf("UpdateWork: %v", err)
+		}
+		if updated.Title != "Tea bowl" || updated.Category != "" || updated.ThumbnailURL != work.ThumbnailURL || updated.UpdatedAt.Before(work.UpdatedAt) {
+			t.Errorf("UpdateWork = %+v, want the new title, no category and the old images", updated)
+		}
+		updated, err = f.repo.UpdateWork(ctx, work.ID, models.UpdatePortfolioWorkData{
+			Images: []models.PortfolioImageData{image("a.jpg", false), image("b.jpg", true)},
+		})
+		if err != nil || updated.Title != "Tea bowl" || updated.ThumbnailURL != "https://example.com/b.jpg" {
+			t.Errorf("UpdateWork(images) = %+v, %v", updated, err)
+		}
+		if images, _ := f.repo.ListWorkImages(ctx, work.ID); len(images) != 2 {
+			t.Errorf("ListWorkImages after replacing = %+v, want the two new images", images)
+		}
+		if _, err := f.repo.UpdateWork(ctx, work.ID+100, models.UpdatePortfolioWorkData{Title: &title}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateWork(missing): error = %v, want ErrNotFound", err)
+		}
+
+		if err := f.repo.DeleteWork(ctx, work.ID); err != nil {
+			t.Fatalf("DeleteWork: %v", err)
+		}
+		if _, err := f.repo.FindWorkByID(ctx, work.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindWorkByID after delete: error = %v, want ErrNotFound", err)
+		}
+		if images, _ := f.repo.ListWorkImages(ctx, work.ID); len(images) != 0 {
+			t.Errorf("ListWorkImages after delete = %+v, want none", images)
+		}
+		if err := f.repo.DeleteWork(ctx, work.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteWork(missing): error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		f := newFixture(t)
+		userID, _ := f.addUser()
+		fanID, _ := f.addUser()
+		create(t, f, userID, "oldest", "Throwing")
+		liked := create(t, f, userID, "liked", "Glazing")
+		chosen := create(t, f, userID, "chosen", "Throwing")
+		create(t, f, userID, "newest", "Throwing")
+		if _, err := f.repo.AddKudo(ctx, fanID, liked); err != nil {
+			t.Fatalf("AddKudo: %v", err)
+		}
+		if err := f.repo.SetEditorsChoice(ctx, chosen.ID, true); err != nil {
+			t.Fatalf("SetEditorsChoice: %v", err)
+		}
+		if err := f.repo.SetEditorsChoice(ctx, chosen.ID+100, true); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("SetEditorsChoice(missing): error = %v, want ErrNotFound", err)
+		}
+
+		if got, total := list(t, f, models.PortfolioWorkFilter{}); total != 4 || !reflect.DeepEqual(got, []string{"chosen", "newest", "liked", "oldest"}) {
+			t.Errorf("ListWorks(latest) = %v (total %d)", got, total)
+		}
+		if got, _ := list(t, f, models.PortfolioWorkFilter{Page: 1, Limit: 10, Sort: models.PortfolioSortKudos}); !reflect.DeepEqual(got, []string{"chosen", "liked", "newest", "oldest"}) {
+			t.Errorf("ListWorks(kudos) = %v", got)
+		}
+		if got, total := list(t, f, models.PortfolioWorkFilter{Page: 1, Limit: 10, Category: "Throwing"}); total != 3 || !reflect.DeepEqual(got, []string{"chosen", "newest", "oldest"}) {
+			t.Errorf("ListWorks(Throwing) = %v (total %d)", got, total)
+		}
+		if got, total := list(t, f, models.PortfolioWorkFilter{Page: 2, Limit: 3}); total != 4 || !reflect.DeepEqual(got, []string{"oldest"}) {
+			t.Errorf("ListWorks(page 2) = %v (total %d)", got, total)
+		}
+		if got, total := list(t, f, models.PortfolioWorkFilter{Page: 1, Limit: 10, Category: "Painting"}); total != 0 || len(got) != 0 {
+			t.Errorf("ListWorks(Painting) = %v (total %d), want none", got, total)
+		}
+	})
+
+	t.Run("Kudos", func(t *testing.T) {
+		f := newFixture(t)
+		ownerID, _ := f.addUser()
+		fanID, _ := f.addUser()
+		otherID, _ := f.addUser()
+		work := create(t, f, ownerID, "Vase", "")
+
+		if given, err := f.repo.HasGivenKudo(ctx, fanID, work.ID); err != nil || given {
+			t.Errorf("HasGivenKudo before = %v, %v", given, err)
+		}
+		for i, userID := range []string{fanID, otherID} {
+			result, err := f.repo.AddKudo(ctx, userID, work)
+			if err != nil || !result.Active || result.Count != i+1 {
+				t.Errorf("AddKudo %d = %+v, %v", i, result, err)
+			}
+		}
+		if _, err := f.repo.AddKudo(ctx, fanID, work); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("AddKudo twice: error = %v, want ErrConflict", err)
+		}
+		if given, err := f.repo.HasGivenKudo(ctx, fanID, work.ID); err != nil || !given {
+			t.Errorf("HasGivenKudo after = %v, %v", given, err)
+		}
+		if found, _ := f.repo.FindWorkByID(ctx, work.ID); found.KudosCount != 2 {
+			t.Errorf("KudosCount = %d, want 2", found.KudosCount)
+		}
+		if n := f.notifications(ownerID); n != 2 {
+			t.Errorf("owner has %d kudo notifications, want 2", n)
+		}
+
+		missing := *work
+		missing.ID += 100
+		if _, err := f.repo.AddKudo(ctx, fanID, &missing); err == nil {
+			t.Error("AddKudo accepted a work that does not exist")
+		}
+		if _, err := f.repo.CreateWork(ctx, "999999", models.CreatePortfolioWorkData{Title: "x", Images: []models.PortfolioImageData{image("x.jpg", true)}}); err == nil {
+			t.Error("CreateWork accepted an author that does not exist")
+		}
+	})
+}
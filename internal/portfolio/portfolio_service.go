@@ -0,0 +1,193 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/audit"
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/permission"
+	"strconv"
+	"strings"
+)
+
+// ServiceInterface defines the methods for portfolio business logic.
+type ServiceInterface interface {
+	// ListWorks lists works with highlighted (editor's choice) works first.
+	ListWorks(ctx context.Context, filter models.PortfolioWorkFilter) ([]models.PortfolioWork, int, error)
+	// GetWorkDetail returns the work with all its images.
+	// viewerID is empty for guests; otherwise HasGivenKudo reflects the viewer's state.
+	GetWorkDetail(ctx context.Context, workID int64, viewerID string) (*models.PortfolioWork, error)
+	CreateWork(ctx context.Context, userID string, data models.CreatePortfolioWorkData) (*models.PortfolioWork, error)
+	UpdateWork(ctx context.Context, userID string, workID int64, data models.UpdatePortfolioWorkData) (*models.PortfolioWork, error)
+	DeleteWork(ctx context.Context, userID, userRole string, workID int64) error
+
+	// LeaveKudo gives the work one kudo from the user and notifies the owner.
+	LeaveKudo(ctx context.Context, userID string, workID int64) (*models.ToggleResult, error)
+	// SetHighlighted is used by moderators to pin a work to the top of the listing. actorID is recorded in the audit log.
+	SetHighlighted(ctx context.Context, actorID string, workID int64, highlighted bool) (*models.PortfolioWork, error)
+}
+
+// Service provides business logic for the portfolio.
+type Service struct {
+	repo        RepositoryInterface
+	permissions permission.Checker // portfolio.moderate lets a role delete other users' works
+	auditLog    audit.Recorder     // Deletions and highlighting
+}
+
+// NewService creates a new portfolio service.
+func NewService(repo RepositoryInterface, permissions permission.Checker, auditLog audit.Recorder) ServiceInterface {
+	return &Service{repo: repo, permissions: permissions, auditLog: auditLog}
+}
+
+func (s *Service) ListWorks(ctx context.Context, filter models.PortfolioWorkFilter) ([]models.PortfolioWork, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	} // Default/max limit
+	if filter.Sort != models.PortfolioSortKudos {
+		filter.Sort = models.PortfolioSortLatest
+	}
+	filter.Category = strings.TrimSpace(filter.Category)
+
+	works, total, err := s.repo.ListWorks(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.ListWorks: %w", err)
+	}
+	return works, total, nil
+}
+
+func (s *Service) GetWorkDetail(ctx context.Context, workID int64, viewerID string) (*models.PortfolioWork, error) {
+	work, err := s.repo.FindWorkByID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetWorkDetail: %w", err)
+	}
+
+	images, err := s.repo.ListWorkImages(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetWorkDetail.Images: %w", err)
+	}
+	work.Images = images
+
+	if viewerID != "" {
+		hasGivenKudo, err := s.repo.HasGivenKudo(ctx, viewerID, workID)
+		if err != nil {
+			return nil, fmt.Errorf("service.GetWorkDetail.HasGivenKudo: %w", err)
+		}
+		work.HasGivenKudo = hasGivenKudo
+	}
+	return work, nil
+}
+
+func (s *Service) CreateWork(ctx context.Context, userID string, data models.CreatePortfolioWorkData) (*models.PortfolioWork, error) {
+	data.Category = strings.TrimSpace(data.Category)
+	data.Images = normalizeThumbnail(data.Images)
+
+	work, err := s.repo.CreateWork(ctx, userID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.CreateWork: %w", err)
+	}
+	return work, nil
+}
+
+func (s *Service) UpdateWork(ctx context.Context, userID string, workID int64, data models.UpdatePortfolioWorkData) (*models.PortfolioWork, error) {
+	work, err := s.repo.FindWorkByID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateWork: %w", err)
+	}
+	if work.UserID != userID {
+		return nil, models.ErrForbidden
+	}
+
+	if data.Category != nil {
+		category := strings.TrimSpace(*data.Category)
+		data.Category = &category
+	}
+	data.Images = normalizeThumbnail(data.Images)
+
+	updated, err := s.repo.UpdateWork(ctx, workID, data)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateWork: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteWork removes a work. Only the owner or a role with portfolio.moderate may do so.
+func (s *Service) DeleteWork(ctx context.Context, userID, userRole string, workID int64) error {
+	work, err := s.repo.FindWorkByID(ctx, workID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteWork: %w", err)
+	}
+	if work.UserID != userID {
+		allowed, err := s.permissions.HasPermission(ctx, userRole, models.PermPortfolioModerate)
+		if err != nil {
+			return fmt.Errorf("service.DeleteWork: %w", err)
+		}
+		if !allowed {
+			return models.ErrForbidden
+		}
+	}
+	if err := s.repo.DeleteWork(ctx, workID); err != nil {
+		return fmt.Errorf("service.DeleteWork: %w", err)
+	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID: userID, Action: audit.ActionPortfolioWorkDelete,
+		EntityType: audit.EntityPortfolioWork, EntityID: strconv.FormatInt(workID, 10), Before: work,
+	})
+	return nil
+}
+
+func (s *Service) LeaveKudo(ctx context.Context, userID string, workID int64) (*models.ToggleResult, error) {
+	work, err := s.repo.FindWorkByID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("service.LeaveKudo: %w", err)
+	}
+	if work.UserID == userID {
+		return nil, models.ErrSelfKudo
+	}
+
+	result, err := s.repo.AddKudo(ctx, userID, work)
+	if err != nil {
+		return nil, fmt.Errorf("service.LeaveKudo: %w", err)
+	}
+	return result, nil
+}
+
+func (s *Service) SetHighlighted(ctx context.Context, actorID string, workID int64, highlighted bool) (*models.PortfolioWork, error) {
+	before, err := s.repo.FindWorkByID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetHighlighted: %w", err)
+	}
+	if err := s.repo.SetEditorsChoice(ctx, workID, highlighted); err != nil {
+		return nil, fmt.Errorf("service.SetHighlighted: %w", err)
+	}
+	work, err := s.repo.FindWorkByID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("service.SetHighlighted: %w", err)
+	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID: actorID, Action: audit.ActionPortfolioWorkHighlight,
+		EntityType: audit.EntityPortfolioWork, EntityID: strconv.FormatInt(workID, 10), Before: before, After: work,
+	})
+	return work, nil
+}
+
+// normalizeThumbnail makes sure exactly one image is the thumbnail: the first one flagged,
+// or the first image if none was.
+func normalizeThumbnail(images []models.PortfolioImageData) []models.PortfolioImageData {
+	if len(images) == 0 {
+		return images
+	}
+	thumbnailIdx := 0
+	for i, image := range images {
+		if image.IsThumbnail {
+			thumbnailIdx = i
+			break
+		}
+	}
+	for i := range images {
+		images[i].IsThumbnail = i == thumbnailIdx
+	}
+	return images
+}
@@ -0,0 +1,67 @@
+// Package ratelimit implements token buckets kept in memory or in Postgres.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: it holds up to Burst tokens and refills Requests tokens every Per.
+// Every request takes one token, so Burst requests can arrive at once and Requests/Per is sustained.
+type Limit struct {
+	Name     string // Keeps the buckets of different limits apart, e.g. "forum-write"
+	Requests int
+	Per      time.Duration
+	Burst    int // Defaults to Requests
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// refillRate is in tokens per second.
+func (l Limit) refillRate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the outcome of one Take.
+type Result struct {
+	Allowed    bool
+	Limit      int           // Bucket capacity
+	Remaining  int           // Whole tokens left after this request
+	ResetAfter time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next token, zero when Allowed
+}
+
+// Store takes a token from the bucket of key under limit.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take refills a bucket that held tokens at last, then takes one token if there is one.
+// It returns the new token count; both stores keep their state through it.
+func take(tokens float64, last, now time.Time, limit Limit) (float64, Result) {
+	capacity, rate := limit.capacity(), limit.refillRate()
+	if elapsed := now.Sub(last).Seconds(); elapsed > 0 {
+		tokens = math.Min(capacity, tokens+elapsed*rate)
+	}
+
+	result := Result{Limit: int(capacity)}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.ResetAfter = secondsToDuration((capacity - tokens) / rate)
+	return tokens, result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type memoryBucket struct {
+	tokens  float64
+	last    time.Time
+	expires time.Time // Full again from here on, so the bucket can be dropped
+}
+
+// MemoryStore keeps buckets in process memory. Limits apply per instance; use PostgresStore
+// when several API instances must share them.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}, now: time.Now}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: limit.capacity(), last: now}
+		m.buckets[key] = bucket
+	}
+	tokens, result := take(bucket.tokens, bucket.last, now, limit)
+	bucket.tokens, bucket.last, bucket.expires = tokens, now, now.Add(result.ResetAfter)
+	return result, nil
+}
+
+// sweep drops full buckets at most once per memorySweepInterval, so memory does not grow with every IP seen.
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < memorySweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, bucket := range m.buckets {
+		if now.After(bucket.expires) {
+			delete(m.buckets, key)
+		}
+	}
+}
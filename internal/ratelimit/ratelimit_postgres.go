@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresPurgeInterval = 10 * time.Minute
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so limits hold across API instances.
+// Each Take costs one short transaction that locks only its own bucket row.
+type PostgresStore struct {
+	db        *pgxpool.Pool
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewPostgresStore creates a store on the rate_limit_buckets table.
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	p.purgeExpired(ctx)
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit.Take.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at)
+		 VALUES ($1, $2, clock_timestamp(), clock_timestamp())
+		 ON CONFLICT (key) DO NOTHING`, key, limit.capacity())
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit.Take.Insert: %w", err)
+	}
+
+	// clock_timestamp() rather than NOW(): the row lock may be waited for, and the refill must count that wait.
+	var tokens float64
+	var last, now time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT tokens, updated_at, clock_timestamp() FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`, key,
+	).Scan(&tokens, &last, &now)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit.Take.Select: %w", err)
+	}
+
+	tokens, result := take(tokens, last, now, limit)
+	_, err = tx.Exec(ctx,
+		`UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3, expires_at = $4 WHERE key = $1`,
+		key, tokens, now, now.Add(result.ResetAfter))
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit.Take.Update: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Result{}, fmt.Errorf("ratelimit.Take.Commit: %w", err)
+	}
+	return result, nil
+}
+
+// purgeExpired deletes full buckets at most once per postgresPurgeInterval per instance.
+func (p *PostgresStore) purgeExpired(ctx context.Context) {
+	p.mu.Lock()
+	if time.Since(p.lastPurge) < postgresPurgeInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastPurge = time.Now()
+	p.mu.Unlock()
+
+	if _, err := p.db.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE expires_at < NOW()`); err != nil {
+		log.Printf("WARN: ratelimit.PostgresStore: purge failed: %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package seed loads fixture data (the ceramic story timeline, forum categories and a sample gallery)
+// into the database. Every row is upserted by its slug, so seeding again updates rows in place.
+//
+// Fixtures live in fixtures/<profile>/<kind>.json. The base profile is always loaded first; the chosen
+// profile then adds rows or replaces base rows with the same slug.
+package seed
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"jingdezhen-ceramics-backend/internal/models"
+	"path"
+)
+
+// Profiles selectable with cmd/seed -profile.
+const (
+	ProfileDev  = "dev"  // A handful of artworks for local development
+	ProfileDemo = "demo" // A fuller sample gallery for demos
+	ProfileTest = "test" // Small, stable data that integration tests can assert on
+)
+
+const baseProfile = "base"
+
+// fixtureVersion is the file format understood by this code; bump it when a fixture field changes meaning.
+const fixtureVersion = 1
+
+//go:embed fixtures
+var embedded embed.FS
+
+// Fixtures returns the embedded fixture files, rooted at the profile directories.
+func Fixtures() fs.FS {
+	sub, err := fs.Sub(embedded, "fixtures")
+	if err != nil {
+		panic(err) // The directory is embedded at build time
+	}
+	return sub
+}
+
+// Artist is an artists row. Slug is the upsert key.
+type Artist struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	Bio  string `json:"bio,omitempty"`
+}
+
+// Artwork is an artworks row. ArtistSlug refers to an artist in the fixtures or already in the database.
+type Artwork struct {
+	Slug               string `json:"slug"`
+	Title              string `json:"title"`
+	ArtistSlug         string `json:"artist_slug,omitempty"`
+	ArtistNameOverride string `json:"artist_name_override,omitempty"`
+	ThumbnailURL       string `json:"thumbnail_url"`
+	Description        string `json:"description,omitempty"`
+	Period             string `json:"period,omitempty"`
+	Dimensions         string `json:"dimensions,omitempty"`
+	Category           string `json:"category,omitempty"`
+	Utensil            string `json:"utensil,omitempty"`
+	Introduction       string `json:"introduction,omitempty"`
+	CreationYear       *int   `json:"creation_year,omitempty"`
+	Materials          string `json:"materials,omitempty"`
+}
+
+// ForumCategory is a forum_categories row.
+type ForumCategory struct {
+	Slug         string `json:"slug"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	DisplayOrder int    `json:"display_order"`
+}
+
+// Data is everything one profile seeds.
+type Data struct {
+	CeramicStories  []models.CeramicStory
+	ForumCategories []ForumCategory
+	Artists         []Artist
+	Artworks        []Artwork
+}
+
+type fixtureFile[T any] struct {
+	Version int `json:"version"`
+	Items   []T `json:"items"`
+}
+
+// Load reads the base fixtures and those of profile from fsys (normally Fixtures()) and checks them.
+func Load(fsys fs.FS, profile string) (*Data, error) {
+	if profile == baseProfile {
+		return nil, fmt.Errorf("seed: %q is loaded with every profile, choose dev, demo or test", profile)
+	}
+	if _, err := fs.Stat(fsys, profile); err != nil {
+		return nil, fmt.Errorf("seed: unknown profile %q", profile)
+	}
+
+	data := &Data{}
+	var err error
+	for _, dir := range []string{baseProfile, profile} {
+		if data.CeramicStories, err = merge(fsys, dir, "ceramic_stories.json", data.CeramicStories,
+			func(s models.CeramicStory) string { return s.Slug }); err != nil {
+			return nil, err
+		}
+		if data.ForumCategories, err = merge(fsys, dir, "forum_categories.json", data.ForumCategories,
+			func(c ForumCategory) string { return c.Slug }); err != nil {
+			return nil, err
+		}
+		if data.Artists, err = merge(fsys, dir, "artists.json", data.Artists,
+			func(a Artist) string { return a.Slug }); err != nil {
+			return nil, err
+		}
+		if data.Artworks, err = merge(fsys, dir, "artworks.json", data.Artworks,
+			func(a Artwork) string { return a.Slug }); err != nil {
+			return nil, err
+		}
+	}
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// merge appends the items of dir/name to items, replacing those with the same slug. A missing file adds nothing.
+func merge[T any](fsys fs.FS, dir, name string, items []T, slug func(T) string) ([]T, error) {
+	file := path.Join(dir, name)
+	raw, err := fs.ReadFile(fsys, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+
+	var f fixtureFile[T]
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("seed: %s: %w", file, err)
+	}
+	if f.Version != fixtureVersion {
+		return nil, fmt.Errorf("seed: %s: unsupported fixture version %d, want %d", file, f.Version, fixtureVersion)
+	}
+
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		index[slug(item)] = i
+	}
+	seen := map[string]bool{}
+	for _, item := range f.Items {
+		s := slug(item)
+		if s == "" {
+			return nil, fmt.Errorf("seed: %s: item without slug", file)
+		}
+		if seen[s] {
+			return nil, fmt.Errorf("seed: %s: duplicate slug %q", file, s)
+		}
+		seen[s] = true
+		if i, ok := index[s]; ok {
+			items[i] = item
+		} else {
+			index[s] = len(items)
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (d *Data) validate() error {
+	orders := map[int]string{}
+	for _, s := range d.CeramicStories {
+		if s.DynastyName == "" || s.Description == "" {
+			return fmt.Errorf("seed: ceramic story %q needs dynasty_name and description", s.Slug)
+		}
+		if s.StartYear != nil && s.EndYear != nil && *s.StartYear > *s.EndYear {
+			return fmt.Errorf("seed: ceramic story %q: %w", s.Slug, models.ErrInvalidYearRange)
+		}
+		if other, ok := orders[s.DisplayOrder]; ok {
+			return fmt.Errorf("seed: ceramic stories %q and %q share display_order %d", other, s.Slug, s.DisplayOrder)
+		}
+		orders[s.DisplayOrder] = s.Slug
+	}
+	for _, c := range d.ForumCategories {
+		if c.Name == "" {
+			return fmt.Errorf("seed: forum category %q needs a name", c.Slug)
+		}
+	}
+	for _, a := range d.Artists {
+		if a.Name == "" {
+			return fmt.Errorf("seed: artist %q needs a name", a.Slug)
+		}
+	}
+	for _, a := range d.Artworks {
+		if a.Title == "" || a.ThumbnailURL == "" {
+			return fmt.Errorf("seed: artwork %q needs title and thumbnail_url", a.Slug)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Counts is how many rows of one kind were inserted and how many already existed and were updated.
+type Counts struct {
+	Inserted int
+	Updated  int
+}
+
+func (c *Counts) add(inserted bool) {
+	if inserted {
+		c.Inserted++
+	} else {
+		c.Updated++
+	}
+}
+
+// Result reports what Apply did per table.
+type Result struct {
+	CeramicStories  Counts
+	ForumCategories Counts
+	Artists         Counts
+	Artworks        Counts
+}
+
+// Run loads profile from the embedded fixtures and applies it. Integration tests call it with ProfileTest.
+func Run(ctx context.Context, db *pgxpool.Pool, profile string) (*Result, error) {
+	data, err := Load(Fixtures(), profile)
+	if err != nil {
+		return nil, err
+	}
+	return Apply(ctx, db, data)
+}
+
+// Apply upserts data in one transaction, so a failing row leaves the database as it was.
+// The schema must be migrated first.
+func Apply(ctx context.Context, db *pgxpool.Pool, data *Data) (*Result, error) {
+	result := &Result{}
+	err := pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		// xmax is 0 only for rows this statement inserted, which tells inserts and updates apart.
+		for _, s := range data.CeramicStories {
+			var inserted bool
+			err := tx.QueryRow(ctx, `
+				INSERT INTO ceramic_stories (slug, dynasty_name, period, start_year, end_year, description,
+				                             characteristics_craft, characteristics_art, image_url, takeaways, display_order)
+				VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11)
+				ON CONFLICT (slug) DO UPDATE SET
+				    dynasty_name = EXCLUDED.dynasty_name, period = EXCLUDED.period,
+				    start_year = EXCLUDED.start_year, end_year = EXCLUDED.end_year, description = EXCLUDED.description,
+				    characteristics_craft = EXCLUDED.characteristics_craft, characteristics_art = EXCLUDED.characteristics_art,
+				    image_url = EXCLUDED.image_url, takeaways = EXCLUDED.takeaways, display_order = EXCLUDED.display_order
+				RETURNING (xmax = 0)`,
+				s.Slug, s.DynastyName, s.Period, s.StartYear, s.EndYear, s.Description,
+				s.CharacteristicsCraft, s.CharacteristicsArt, s.ImageURL, s.Takeaways, s.DisplayOrder,
+			).Scan(&inserted)
+			if err != nil {
+				return fmt.Errorf("ceramic story %q: %w", s.Slug, err)
+			}
+			result.CeramicStories.add(inserted)
+		}
+
+		for _, c := range data.ForumCategories {
+			var inserted bool
+			err := tx.QueryRow(ctx, `
+				INSERT INTO forum_categories (slug, name, description, display_order)
+				VALUES ($1, $2, NULLIF($3, ''), $4)
+				ON CONFLICT (slug) DO UPDATE SET
+				    name = EXCLUDED.name, description = EXCLUDED.description, display_order = EXCLUDED.display_order
+				RETURNING (xmax = 0)`,
+				c.Slug, c.Name, c.Description, c.DisplayOrder,
+			).Scan(&inserted)
+			if err != nil {
+				return fmt.Errorf("forum category %q: %w", c.Slug, err)
+			}
+			result.ForumCategories.add(inserted)
+		}
+
+		artistIDs := make(map[string]int64, len(data.Artists))
+		for _, a := range data.Artists {
+			var id int64
+			var inserted bool
+			err := tx.QueryRow(ctx, `
+				INSERT INTO artists (slug, name, bio)
+				VALUES ($1, $2, NULLIF($3, ''))
+				ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name, bio = EXCLUDED.bio, updated_at = NOW()
+				RETURNING id, (xmax = 0)`,
+				a.Slug, a.Name, a.Bio,
+			).Scan(&id, &inserted)
+			if err != nil {
+				return fmt.Errorf("artist %q: %w", a.Slug, err)
+			}
+			artistIDs[a.Slug] = id
+			result.Artists.add(inserted)
+		}
+
+		for _, a := range data.Artworks {
+			var artistID *int64
+			if a.ArtistSlug != "" {
+				id, ok := artistIDs[a.ArtistSlug]
+				if !ok {
+					err := tx.QueryRow(ctx, `SELECT id FROM artists WHERE slug = $1`, a.ArtistSlug).Scan(&id)
+					if err != nil {
+						return fmt.Errorf("artwork %q: artist %q: %w", a.Slug, a.ArtistSlug, err)
+					}
+					artistIDs[a.ArtistSlug] = id
+				}
+				artistID = &id
+			}
+
+			var inserted bool
+			err := tx.QueryRow(ctx, `
+				INSERT INTO artworks (slug, title, artist_id, artist_name_override, thumbnail_url, description, period,
+				                      dimensions, category, utensil, introduction, creation_year, materials)
+				VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''),
+				        NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''), $12, NULLIF($13, ''))
+				ON CONFLICT (slug) DO UPDATE SET
+				    title = EXCLUDED.title, artist_id = EXCLUDED.artist_id, artist_name_override = EXCLUDED.artist_name_override,
+				    thumbnail_url = EXCLUDED.thumbnail_url, description = EXCLUDED.description, period = EXCLUDED.period,
+				    dimensions = EXCLUDED.dimensions, category = EXCLUDED.category, utensil = EXCLUDED.utensil,
+				    introduction = EXCLUDED.introduction, creation_year = EXCLUDED.creation_year,
+				    materials = EXCLUDED.materials, updated_at = NOW()
+				RETURNING (xmax = 0)`,
+				a.Slug, a.Title, artistID, a.ArtistNameOverride, a.ThumbnailURL, a.Description, a.Period,
+				a.Dimensions, a.Category, a.Utensil, a.Introduction, a.CreationYear, a.Materials,
+			).Scan(&inserted)
+			if err != nil {
+				return fmt.Errorf("artwork %q: %w", a.Slug, err)
+			}
+			result.Artworks.add(inserted)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package seed
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func TestRunIsIdempotent(t *testing.T) {
+	db := pgtest.NewDB(t)
+	ctx := context.Background()
+	data, err := Load(Fixtures(), ProfileTest)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if _, err := Run(ctx, db, ProfileTest); err != nil {
+		t.Fatalf("first Run: %v", err)
+	}
+	second, err := Run(ctx, db, ProfileTest)
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	for name, c := range map[string]struct {
+		got  Counts
+		rows int
+	}{
+		"ceramic stories":  {second.CeramicStories, len(data.CeramicStories)},
+		"forum categories": {second.ForumCategories, len(data.ForumCategories)},
+		"artists":          {second.Artists, len(data.Artists)},
+		"artworks":         {second.Artworks, len(data.Artworks)},
+	} {
+		if c.got.Inserted != 0 || c.got.Updated != c.rows {
+			t.Errorf("second Run %s = %+v, want 0 inserted and %d updated", name, c.got, c.rows)
+		}
+	}
+
+	var artworks int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM artworks`).Scan(&artworks); err != nil {
+		t.Fatal(err)
+	}
+	if artworks != len(data.Artworks) {
+		t.Errorf("artworks table has %d rows after two runs, want %d", artworks, len(data.Artworks))
+	}
+}
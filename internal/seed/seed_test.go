@@ -0,0 +1,90 @@
+package seed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"jingdezhen-ceramics-backend/internal/models"
+)
+
+func file(items string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(`{"version": 1, "items": [` + items + `]}`)}
+}
+
+func TestLoadEmbeddedProfiles(t *testing.T) {
+	for _, profile := range []string{ProfileDev, ProfileDemo, ProfileTest} {
+		data, err := Load(Fixtures(), profile)
+		if err != nil {
+			t.Errorf("Load(%q): %v", profile, err)
+			continue
+		}
+		if len(data.CeramicStories) == 0 || len(data.ForumCategories) == 0 || len(data.Artworks) == 0 {
+			t.Errorf("Load(%q) = %d stories, %d categories, %d artworks, want base and profile rows",
+				profile, len(data.CeramicStories), len(data.ForumCategories), len(data.Artworks))
+		}
+	}
+}
+
+func TestLoadMergesProfileOverBase(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base/artists.json": file(`{"slug": "wang", "name": "Wang"}, {"slug": "li", "name": "Li"}`),
+		"test/artists.json": file(`{"slug": "li", "name": "Li Renamed"}, {"slug": "zhao", "name": "Zhao"}`),
+	}
+	data, err := Load(fsys, ProfileTest)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []Artist{{Slug: "wang", Name: "Wang"}, {Slug: "li", Name: "Li Renamed"}, {Slug: "zhao", Name: "Zhao"}}
+	if len(data.Artists) != len(want) {
+		t.Fatalf("artists = %+v, want %+v", data.Artists, want)
+	}
+	for i := range want {
+		if data.Artists[i] != want[i] {
+			t.Errorf("artists[%d] = %+v, want %+v", i, data.Artists[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{"base profile", baseProfile, fstest.MapFS{"base/artists.json": file(``)}, "loaded with every profile"},
+		{"unknown profile", "staging", fstest.MapFS{"test/artists.json": file(``)}, "unknown profile"},
+		{"other version", ProfileTest, fstest.MapFS{
+			"test/artists.json": {Data: []byte(`{"version": 2, "items": []}`)},
+		}, "unsupported fixture version 2"},
+		{"broken JSON", ProfileTest, fstest.MapFS{"test/artists.json": {Data: []byte(`{"version": 1,`)}}, "artists.json"},
+		{"missing slug", ProfileTest, fstest.MapFS{"test/artists.json": file(`{"name": "Wang"}`)}, "item without slug"},
+		{"duplicate slug", ProfileTest, fstest.MapFS{
+			"test/artists.json": file(`{"slug": "wang", "name": "Wang"}, {"slug": "wang", "name": "Wang"}`),
+		}, `duplicate slug "wang"`},
+		{"artist without name", ProfileTest, fstest.MapFS{"test/artists.json": file(`{"slug": "wang"}`)}, "needs a name"},
+		{"artwork without thumbnail", ProfileTest, fstest.MapFS{
+			"test/artworks.json": file(`{"slug": "vase", "title": "Vase"}`),
+		}, "needs title and thumbnail_url"},
+		{"shared display order", ProfileTest, fstest.MapFS{
+			"base/ceramic_stories.json": file(`{"slug": "ming", "dynasty_name": "Ming", "description": "d", "display_order": 1}`),
+			"test/ceramic_stories.json": file(`{"slug": "qing", "dynasty_name": "Qing", "description": "d", "display_order": 1}`),
+		}, "share display_order 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.files, tt.profile)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	years := fstest.MapFS{"test/ceramic_stories.json": file(
+		`{"slug": "ming", "dynasty_name": "Ming", "description": "d", "start_year": 1644, "end_year": 1368}`)}
+	if _, err := Load(years, ProfileTest); !errors.Is(err, models.ErrInvalidYearRange) {
+		t.Errorf("Load with reversed years: error = %v, want ErrInvalidYearRange", err)
+	}
+}
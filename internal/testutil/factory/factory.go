@@ -0,0 +1,215 @@
+// Package factory inserts valid rows for integration tests. Each builder fills every required column
+// with unique values; pass functions to change fields before the insert:
+//
+//	admin := factory.User(t, db, func(u *models.User) { u.Role = models.RoleAdmin })
+//
+// Rows are written with plain SQL rather than through the repositories under test, and a failed
+// insert fails the test.
+package factory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var seq atomic.Int64
+
+// next returns a number unique within the test binary, for unique names and slugs.
+func next() int64 {
+	return seq.Add(1)
+}
+
+// User inserts a verified normal user with a unique nickname and email.
+func User(t testing.TB, db *pgxpool.Pool, opts ...func(*models.User)) *models.User {
+	t.Helper()
+	n := next()
+	u := &models.User{
+		Nickname:      fmt.Sprintf("user%d", n),
+		Email:         fmt.Sprintf("user%d@example.com", n),
+		Role:          models.RoleNormalUser,
+		EmailVerified: true,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	var id int64
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO users (nickname, email, role, avatar_url, email_verified)
+		 VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		 RETURNING id, created_at, updated_at`,
+		u.Nickname, u.Email, u.Role, u.AvatarURL, u.EmailVerified,
+	).Scan(&id, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		t.Fatalf("factory.User: %v", err)
+	}
+	u.ID = strconv.FormatInt(id, 10)
+	return u
+}
+
+// Note inserts a note of userID on a unique artwork entity.
+func Note(t testing.TB, db *pgxpool.Pool, userID string, opts ...func(*models.UserNote)) *models.UserNote {
+	t.Helper()
+	n := next()
+	entityType, entityID := "artwork", int(n)
+	note := &models.UserNote{
+		UserID:     userID,
+		Title:      fmt.Sprintf("Note %d", n),
+		Content:    "Observations about the glaze.",
+		EntityType: &entityType,
+		EntityID:   &entityID,
+	}
+	for _, opt := range opts {
+		opt(note)
+	}
+
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO user_notes (user_id, title, content, entity_type, entity_id)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at, updated_at`,
+		note.UserID, note.Title, note.Content, note.EntityType, note.EntityID,
+	).Scan(&note.ID, &note.CreatedAt, &note.UpdatedAt)
+	if err != nil {
+		t.Fatalf("factory.Note: %v", err)
+	}
+	return note
+}
+
+// Story inserts a ceramic story with a unique slug and display order.
+func Story(t testing.TB, db *pgxpool.Pool, opts ...func(*models.CeramicStory)) *models.CeramicStory {
+	t.Helper()
+	n := next()
+	start, end := 1000+int(n), 1100+int(n)
+	s := &models.CeramicStory{
+		DynastyName:  fmt.Sprintf("Dynasty %d", n),
+		Slug:         fmt.Sprintf("dynasty-%d", n),
+		Period:       "Test period",
+		StartYear:    &start,
+		EndYear:      &end,
+		Description:  "A period of fine porcelain.",
+		DisplayOrder: int(n),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO ceramic_stories (dynasty_name, slug, period, start_year, end_year, description,
+		                              characteristics_craft, characteristics_art, image_url, takeaways, display_order)
+		 VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11)
+		 RETURNING id`,
+		s.DynastyName, s.Slug, s.Period, s.StartYear, s.EndYear, s.Description,
+		s.CharacteristicsCraft, s.CharacteristicsArt, s.ImageURL, s.Takeaways, s.DisplayOrder,
+	).Scan(&s.ID)
+	if err != nil {
+		t.Fatalf("factory.Story: %v", err)
+	}
+	return s
+}
+
+// Artist inserts an artist with a unique name.
+func Artist(t testing.TB, db *pgxpool.Pool, opts ...func(*models.Artist)) *models.Artist {
+	t.Helper()
+	n := next()
+	a := &models.Artist{Name: fmt.Sprintf("Artist %d", n), Slug: fmt.Sprintf("artist-%d", n), Bio: "Works in Jingdezhen."}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO artists (slug, name, bio) VALUES ($1, $2, NULLIF($3, ''))
+		 RETURNING id, created_at, updated_at`,
+		a.Slug, a.Name, a.Bio,
+	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		t.Fatalf("factory.Artist: %v", err)
+	}
+	return a
+}
+
+// Artwork inserts an artwork. Without an ArtistID set by an option it gets a new artist.
+func Artwork(t testing.TB, db *pgxpool.Pool, opts ...func(*models.Artwork)) *models.Artwork {
+	t.Helper()
+	n := next()
+	year := 1700
+	a := &models.Artwork{
+		Title:        fmt.Sprintf("Artwork %d", n),
+		Slug:         fmt.Sprintf("artwork-%d", n),
+		ThumbnailURL: fmt.Sprintf("/test/artwork-%d.jpg", n),
+		CreationYear: &year,
+		Materials:    "Porcelain",
+		Category:     "blue and white",
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.ArtistID == nil {
+		artist := Artist(t, db)
+		a.ArtistID = &artist.ID
+		a.ArtistName = artist.Name
+	}
+
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO artworks (slug, title, artist_id, artist_name_override, thumbnail_url, description,
+		                       creation_year, dimensions, materials, category, introduction)
+		 VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''))
+		 RETURNING id, created_at, updated_at`,
+		a.Slug, a.Title, a.ArtistID, a.ArtistNameOverride, a.ThumbnailURL, a.Description,
+		a.CreationYear, a.Dimensions, a.Materials, a.Category, a.Introduction,
+	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		t.Fatalf("factory.Artwork: %v", err)
+	}
+	return a
+}
+
+// ForumCategory inserts a forum category with a unique name.
+func ForumCategory(t testing.TB, db *pgxpool.Pool) *models.ForumCategory {
+	t.Helper()
+	n := next()
+	c := &models.ForumCategory{Name: fmt.Sprintf("Category %d", n)}
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO forum_categories (slug, name) VALUES ($1, $2) RETURNING id`,
+		fmt.Sprintf("category-%d", n), c.Name,
+	).Scan(&c.ID)
+	if err != nil {
+		t.Fatalf("factory.ForumCategory: %v", err)
+	}
+	return c
+}
+
+// ForumPost inserts a post of userID. Without a CategoryID set by an option it gets a new category.
+func ForumPost(t testing.TB, db *pgxpool.Pool, userID string, opts ...func(*models.ForumPost)) *models.ForumPost {
+	t.Helper()
+	n := next()
+	p := &models.ForumPost{
+		UserID:  userID,
+		Title:   fmt.Sprintf("Post %d", n),
+		Content: "How do I keep celadon from crazing?",
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.CategoryID == 0 {
+		category := ForumCategory(t, db)
+		p.CategoryID, p.CategoryName = category.ID, category.Name
+	}
+
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO forum_posts (user_id, category_id, title, content, is_pinned, is_archived)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, created_at, updated_at, last_activity_at`,
+		p.UserID, p.CategoryID, p.Title, p.Content, p.IsPinned, p.IsArchived,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt, &p.LastActivityAt)
+	if err != nil {
+		t.Fatalf("factory.ForumPost: %v", err)
+	}
+	return p
+}
@@ -0,0 +1,261 @@
+// Package pgtest gives integration tests a real, migrated Postgres database of their own.
+//
+// On first use it starts a throwaway server from the local Postgres binaries (initdb and postgres from
+// PG_BIN, PATH or /usr/lib/postgresql/*/bin). The server listens on a Unix socket only, so tests need no
+// network and never touch a developer's database. All migrations are applied once to a template
+// database; every NewDB call then clones it, which takes milliseconds and isolates tests completely.
+//
+// Set TEST_DATABASE_URL to use an existing server instead (e.g. a CI service container); its user
+// needs CREATEDB. Without either, tests using pgtest are skipped, as they are with go test -short.
+//
+// Packages using pgtest stop the server after their tests with:
+//
+//	func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+package pgtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/internal/migrate"
+	"jingdezhen-ceramics-backend/internal/migrations"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	startOnce sync.Once
+	srv       *server
+	startErr  error
+	dbCounter atomic.Int64
+)
+
+// Main runs the tests and then stops the Postgres server if one was started. Use it from TestMain.
+func Main(m *testing.M) int {
+	code := m.Run()
+	if srv != nil {
+		srv.stop()
+	}
+	return code
+}
+
+// NewDB returns a pool on a fresh database with every migration applied. The database is dropped when
+// the test ends.
+func NewDB(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("pgtest: skipping database test in -short mode")
+	}
+	startOnce.Do(func() { srv, startErr = start() })
+	if startErr != nil {
+		if errors.Is(startErr, errUnavailable) {
+			t.Skip(startErr.Error())
+		}
+		t.Fatalf("pgtest: %v", startErr)
+	}
+
+	ctx := context.Background()
+	name := fmt.Sprintf("pgtest_%d_%d", os.Getpid(), dbCounter.Add(1))
+	if err := srv.exec(ctx, fmt.Sprintf(`CREATE DATABASE %s TEMPLATE %s`, name, srv.template)); err != nil {
+		t.Fatalf("pgtest: create database: %v", err)
+	}
+
+	pool, err := srv.pool(ctx, name)
+	if err != nil {
+		t.Fatalf("pgtest: connect: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		if err := srv.exec(context.Background(), fmt.Sprintf(`DROP DATABASE IF EXISTS %s WITH (FORCE)`, name)); err != nil {
+			t.Logf("pgtest: drop database %s: %v", name, err)
+		}
+	})
+	return pool
+}
+
+var errUnavailable = errors.New("pgtest: no Postgres available (install it, set PG_BIN or TEST_DATABASE_URL)")
+
+type server struct {
+	cmd      *exec.Cmd // nil for TEST_DATABASE_URL
+	dataDir  string
+	dsn      string // The database name in it is replaced per connection
+	template string // Per test binary, since packages are tested in parallel against TEST_DATABASE_URL
+}
+
+func (s *server) connect(ctx context.Context, db string) (*pgx.Conn, error) {
+	cfg, err := pgx.ParseConfig(s.dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Database = db
+	return pgx.ConnectConfig(ctx, cfg)
+}
+
+func (s *server) pool(ctx context.Context, db string) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(s.dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConnConfig.Database = db
+	return pgxpool.NewWithConfig(ctx, cfg)
+}
+
+// exec runs a statement on the maintenance database; CREATE and DROP DATABASE cannot run in a pool transaction.
+func (s *server) exec(ctx context.Context, sql string) error {
+	conn, err := s.connect(ctx, "postgres")
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	_, err = conn.Exec(ctx, sql)
+	return err
+}
+
+func start() (*server, error) {
+	s, err := startServer()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.prepareTemplate(); err != nil {
+		s.stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+func startServer() (*server, error) {
+	template := fmt.Sprintf("pgtest_template_%d", os.Getpid())
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		if _, err := pgx.ParseConfig(url); err != nil {
+			return nil, fmt.Errorf("TEST_DATABASE_URL: %w", err)
+		}
+		return &server{dsn: url, template: template}, nil
+	}
+
+	bin, err := findBinaries()
+	if err != nil {
+		return nil, err
+	}
+	if os.Geteuid() == 0 {
+		return nil, fmt.Errorf("%w: postgres refuses to run as root", errUnavailable)
+	}
+
+	// A short path: Unix socket paths are limited to about 100 bytes.
+	dir, err := os.MkdirTemp("", "pgtest")
+	if err != nil {
+		return nil, err
+	}
+	s := &server{dataDir: dir, template: template}
+	dataDir := filepath.Join(dir, "data")
+
+	initdb := exec.Command(filepath.Join(bin, "initdb"), "-D", dataDir, "-U", "postgres",
+		"--auth=trust", "--encoding=UTF8", "--locale=C", "--no-sync")
+	if out, err := initdb.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb: %v\n%s", err, out)
+	}
+
+	logFile, err := os.Create(filepath.Join(dir, "postgres.log"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	defer logFile.Close()
+	// Durability settings are off: the data is thrown away anyway.
+	s.cmd = exec.Command(filepath.Join(bin, "postgres"), "-D", dataDir, "-k", dir, "-p", "5432",
+		"-c", "listen_addresses=", "-c", "fsync=off", "-c", "synchronous_commit=off", "-c", "full_page_writes=off")
+	s.cmd.Stdout, s.cmd.Stderr = logFile, logFile
+	if err := s.cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("start postgres: %w", err)
+	}
+	s.dsn = fmt.Sprintf("host=%s port=5432 user=postgres sslmode=disable", dir)
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		conn, err := s.connect(ctx, "postgres")
+		cancel()
+		if err == nil {
+			conn.Close(context.Background())
+			return s, nil
+		}
+		if time.Now().After(deadline) {
+			s.stop()
+			logs, _ := os.ReadFile(filepath.Join(dir, "postgres.log"))
+			return nil, fmt.Errorf("postgres did not become ready: %v\n%s", err, logs)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func findBinaries() (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		return dir, nil
+	}
+	if path, err := exec.LookPath("initdb"); err == nil {
+		return filepath.Dir(path), nil
+	}
+	// Debian and Ubuntu keep the server binaries off PATH; prefer the newest version.
+	matches, _ := filepath.Glob("/usr/lib/postgresql/*/bin/initdb")
+	if len(matches) > 0 {
+		sort.Strings(matches)
+		return filepath.Dir(matches[len(matches)-1]), nil
+	}
+	return "", errUnavailable
+}
+
+// prepareTemplate builds the migrated template database that NewDB clones.
+func (s *server) prepareTemplate() error {
+	ctx := context.Background()
+	if err := s.exec(ctx, `CREATE DATABASE `+s.template); err != nil {
+		return fmt.Errorf("create template: %w", err)
+	}
+
+	pool, err := s.pool(ctx, s.template)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	migrator, err := migrate.New(pool, migrations.FS, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *server) stop() {
+	if s.cmd == nil {
+		// Shared server: only clean up after ourselves.
+		if err := s.exec(context.Background(), `DROP DATABASE IF EXISTS `+s.template); err != nil {
+			fmt.Fprintf(os.Stderr, "pgtest: drop template: %v\n", err)
+		}
+		return
+	}
+	if s.cmd.Process != nil {
+		_ = s.cmd.Process.Signal(os.Interrupt) // Fast shutdown
+		done := make(chan struct{})
+		go func() { _ = s.cmd.Wait(); close(done) }()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			_ = s.cmd.Process.Kill()
+		}
+	}
+	if s.dataDir != "" {
+		os.RemoveAll(s.dataDir)
+	}
+}
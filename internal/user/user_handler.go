@@ -16,7 +16,7 @@ type Handler struct {
 }
 
 // NewHandler creates a new user handler.
-// The AdminHandler can be this same handler, with routes protected by AdminRequired middleware.
+// The admin routes are on this same handler, protected by RequirePermission(users.manage).
 func NewHandler(service ServiceInterface) *Handler {
 	return &Handler{
 		service:  service,
@@ -28,16 +28,12 @@ func NewHandler(service ServiceInterface) *Handler {
 func (h *Handler) GetProfile(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 
 	user, err := h.service.GetUserProfile(c.Request().Context(), userID)
 	if err != nil {
-		if err == models.ErrNotFound {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "User profile not found"})
-		}
-		c.Logger().Error("Handler.GetProfile: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to retrieve profile"})
+		return err
 	}
 	return c.JSON(http.StatusOK, user)
 }
@@ -45,57 +41,35 @@ func (h *Handler) GetProfile(c echo.Context) error {
 func (h *Handler) UpdateProfile(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 
 	var req models.UserUpdateData
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid request body: " + err.Error()})
+		return models.InvalidBodyError(err)
 	}
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Validation failed: " + err.Error()})
+		return models.ValidationError(err)
 	}
 
 	user, err := h.service.UpdateUserProfile(c.Request().Context(), userID, req)
 	if err != nil {
-		if err == models.ErrNotFound {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "User profile not found"})
-		}
-		c.Logger().Error("Handler.UpdateProfile: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to update profile"})
+		return err
 	}
 	return c.JSON(http.StatusOK, user)
 }
 
-func (h *Handler) SubmitContactForm(c echo.Context) error {
-	var req models.ContactFormData
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid request body: " + err.Error()})
-	}
-	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Validation failed: " + err.Error()})
-	}
-
-	err := h.service.HandleContactSubmission(c.Request().Context(), req)
-	if err != nil {
-		c.Logger().Error("Handler.SubmitContactForm: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to submit contact form"})
-	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "Contact form submitted successfully"})
-}
-
 // --- User Notes Routes (within /profile group) ---
 func (h *Handler) GetUserNotes(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 
 	page, limit := utils.GetPageLimit(c)
 	notes, total, err := h.service.ListUserNotes(c.Request().Context(), userID, page, limit)
 	if err != nil {
-		c.Logger().Error("Handler.GetUserNotes: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to retrieve notes"})
+		return err
 	}
 	return c.JSON(http.StatusOK, models.NewPaginatedResponse(notes, page, limit, total))
 }
@@ -103,21 +77,20 @@ func (h *Handler) GetUserNotes(c echo.Context) error {
 func (h *Handler) CreateUserNote(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 
 	var req models.CreateUserNoteData
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid request: " + err.Error()})
+		return models.InvalidBodyError(err)
 	}
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Validation failed: " + err.Error()})
+		return models.ValidationError(err)
 	}
 
 	note, err := h.service.CreateUserNote(c.Request().Context(), userID, req)
 	if err != nil {
-		c.Logger().Error("Handler.CreateUserNote: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to create note"})
+		return err
 	}
 	return c.JSON(http.StatusCreated, note)
 }
@@ -125,28 +98,24 @@ func (h *Handler) CreateUserNote(c echo.Context) error {
 func (h *Handler) UpdateUserNote(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 	noteID, err := strconv.Atoi(c.Param("note_id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid note ID"})
+		return models.BadRequestError("Invalid note ID")
 	}
 
 	var req models.UpdateUserNoteData
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid request: " + err.Error()})
+		return models.InvalidBodyError(err)
 	}
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Validation failed: " + err.Error()})
+		return models.ValidationError(err)
 	}
 
 	note, err := h.service.UpdateUserNote(c.Request().Context(), userID, noteID, req)
 	if err != nil {
-		if err == models.ErrNotFound {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "Note not found or not owned by user"})
-		}
-		c.Logger().Error("Handler.UpdateUserNote: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to update note"})
+		return err
 	}
 	return c.JSON(http.StatusOK, note)
 }
@@ -154,20 +123,16 @@ func (h *Handler) UpdateUserNote(c echo.Context) error {
 func (h *Handler) DeleteUserNote(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 	noteID, err := strconv.Atoi(c.Param("note_id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid note ID"})
+		return models.BadRequestError("Invalid note ID")
 	}
 
 	err = h.service.DeleteUserNote(c.Request().Context(), userID, noteID)
 	if err != nil {
-		if err == models.ErrNotFound {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "Note not found or not owned by user"})
-		}
-		c.Logger().Error("Handler.DeleteUserNote: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to delete note"})
+		return err
 	}
 	return c.NoContent(http.StatusNoContent)
 }
@@ -175,21 +140,20 @@ func (h *Handler) DeleteUserNote(c echo.Context) error {
 func (h *Handler) AddLinkToNote(c echo.Context) error {
 	noteID, err := strconv.Atoi(c.Param("note_id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid note ID"})
+		return models.BadRequestError("Invalid note ID")
 	}
 
 	var req models.AddLinkToNoteData
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid request: " + err.Error()})
+		return models.InvalidBodyError(err)
 	}
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Validation failed: " + err.Error()})
+		return models.ValidationError(err)
 	}
 
 	note, err := h.service.AddLinkToNote(c.Request().Context(), noteID, req)
 	if err != nil {
-		c.Logger().Error("Handler.AddLinkToNote: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to add link to note"})
+		return err
 	}
 	return c.JSON(http.StatusCreated, note)
 }
@@ -197,20 +161,16 @@ func (h *Handler) AddLinkToNote(c echo.Context) error {
 func (h *Handler) RemoveLinkFromNote(c echo.Context) error {
 	noteID, err := strconv.Atoi(c.Param("note_id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid note ID"})
+		return models.BadRequestError("Invalid note ID")
 	}
 	linkID, err := strconv.Atoi(c.Param("link_id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid note ID"})
+		return models.BadRequestError("Invalid note ID")
 	}
 
 	err = h.service.RemoveLinkFromNote(c.Request().Context(), noteID, linkID)
 	if err != nil {
-		if err == models.ErrNotFound {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "Link not found"})
-		}
-		c.Logger().Error("Handler.RemoveLinkFromNote: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to remove link from note"})
+		return err
 	}
 	return c.NoContent(http.StatusNoContent)
 }
@@ -218,31 +178,24 @@ func (h *Handler) RemoveLinkFromNote(c echo.Context) error {
 func (h *Handler) PublishNoteToForum(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 	noteID, err := strconv.Atoi(c.Param("note_id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid note ID"})
+		return models.BadRequestError("Invalid note ID")
 	}
 
 	var req models.ForumPostPublishDetails
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid request: " + err.Error()})
+		return models.InvalidBodyError(err)
 	}
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Validation failed: " + err.Error()})
+		return models.ValidationError(err)
 	}
 
 	forumPost, err := h.service.PublishNoteToForum(c.Request().Context(), userID, noteID, req)
 	if err != nil {
-		if err == models.ErrNotFound {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "Note not found or not owned by user"})
-		}
-		if err == models.ErrConflict {
-			return c.JSON(http.StatusConflict, models.ErrorResponse{Message: "Note already published"})
-		}
-		c.Logger().Error("Handler.PublishNoteToForum: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to publish note to forum"})
+		return err
 	}
 	return c.JSON(http.StatusCreated, forumPost)
 }
@@ -251,14 +204,13 @@ func (h *Handler) PublishNoteToForum(c echo.Context) error {
 func (h *Handler) GetNotifications(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 
 	page, limit := utils.GetPageLimit(c)
 	notifications, total, err := h.service.GetNotifications(c.Request().Context(), userID, page, limit)
 	if err != nil {
-		c.Logger().Error("Handler.GetNotifications: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to retrieve notifications"})
+		return err
 	}
 	return c.JSON(http.StatusOK, models.NewPaginatedResponse(notifications, page, limit, total))
 }
@@ -267,14 +219,13 @@ func (h *Handler) GetNotifications(c echo.Context) error {
 func (h *Handler) GetFavoriteArtworks(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 
 	page, limit := utils.GetPageLimit(c)
 	favArtworks, total, err := h.service.GetFavArtworks(c.Request().Context(), userID, page, limit)
 	if err != nil {
-		c.Logger().Error("Handler.GetFavArtworks: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to retrieve favorite artworks"})
+		return err
 	}
 	return c.JSON(http.StatusOK, models.NewPaginatedResponse(favArtworks, page, limit, total))
 }
@@ -283,50 +234,79 @@ func (h *Handler) GetFavoriteArtworks(c echo.Context) error {
 func (h *Handler) GetSavedForumPosts(c echo.Context) error {
 	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: err.Error()})
+		return models.UnauthorizedError(err.Error())
 	}
 
 	page, limit := utils.GetPageLimit(c)
 	savedForumPosts, total, err := h.service.GetSavedForumPosts(c.Request().Context(), userID, page, limit)
 	if err != nil {
-		c.Logger().Error("Handler.GetSavedForumPosts: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to retrieve saved forum posts"})
+		return err
 	}
 	return c.JSON(http.StatusOK, models.NewPaginatedResponse(savedForumPosts, page, limit, total))
 }
 
 // --- Admin User Management Routes ---
-// These methods are part of the same *user.Handler but will be protected by AdminRequired middleware in router.go
+// These methods are part of the same *user.Handler but are protected by RequirePermission in router.go
 func (h *Handler) AdminListUsers(c echo.Context) error {
 	page, limit := utils.GetPageLimit(c)
 	users, total, err := h.service.AdminListUsers(c.Request().Context(), page, limit)
 	if err != nil {
-		c.Logger().Error("Handler.AdminListUsers: ", err)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to list users"})
+		return err
 	}
 	return c.JSON(http.StatusOK, models.NewPaginatedResponse(users, page, limit, total))
 }
 
 func (h *Handler) AdminUpdateUserRole(c echo.Context) error {
 	targetUserID := c.Param("user_id")
+	if _, err := strconv.Atoi(targetUserID); err != nil {
+		return models.BadRequestError("Invalid user ID")
+	}
 	var req struct {
-		Role string `json:"role" validate:"required,oneof=admin normal_user"`
+		Role string `json:"role" validate:"required"` // Any role of the roles table except guest
 	}
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Invalid request body: " + err.Error()})
+		return models.InvalidBodyError(err)
 	}
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "Validation failed: " + err.Error()})
+		return models.ValidationError(err)
 	}
 
-	err := h.service.AdminUpdateUserRole(c.Request().Context(), targetUserID, req.Role)
+	actorID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
-		if err == models.ErrNotFound {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "Target user not found"})
+		return models.UnauthorizedError(err.Error())
+	}
+
+	change, err := h.service.AdminUpdateUserRole(c.Request().Context(), actorID, targetUserID, req.Role)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return c.JSON(http.StatusOK, map[string]string{"message": "User already has this role"})
+	}
+	return c.JSON(http.StatusOK, change)
+}
+
+// AdminListRoleChanges returns the role change audit log, newest first. Params: ?page=1&limit=20&actor_id=&user_id=
+func (h *Handler) AdminListRoleChanges(c echo.Context) error {
+	page, limit := utils.GetPageLimit(c)
+	filter := models.RoleChangeFilter{
+		Page:         page,
+		Limit:        limit,
+		ActorID:      c.QueryParam("actor_id"),
+		TargetUserID: c.QueryParam("user_id"),
+	}
+	for _, id := range []string{filter.ActorID, filter.TargetUserID} {
+		if id == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(id); err != nil {
+			return models.BadRequestError("Invalid user ID")
 		}
-		c.Logger().Error("Handler.AdminUpdateUserRole: ", err)
-		// Check for specific service errors if any (e.g., invalid role error from service)
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: "Failed to update user role"})
 	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "User role updated successfully"})
+
+	changes, total, err := h.service.AdminListRoleChanges(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.NewPaginatedResponse(changes, page, limit, total))
 }
@@ -0,0 +1,491 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jingdezhen-ceramics-backend/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory RepositoryInterface for service tests. It returns the same errors as
+// Repository (models.ErrNotFound, models.ErrConflict, models.ErrInvalidRole, models.ErrLastAdmin) and leaves the same fields
+// empty in each result. Notifications, favorite artworks and saved posts live in other packages' tables;
+// tests add them with the Add methods.
+type MemoryRepository struct {
+	mu            sync.Mutex
+	users         map[string]models.User // PasswordHash included
+	roles         map[string]bool        // Rows of the roles table
+	notes         map[int]models.UserNote
+	links         map[int]models.UserNoteLink
+	roleChanges   []models.RoleChange
+	notifications []models.Notification
+	favArtworks   map[string][]models.UserFavArtworkEntry
+	savedPosts    map[string][]models.UserSavedPostEntry
+
+	lastUserID, lastNoteID, lastLinkID, lastNotificationID int
+	lastRoleChangeID                                       int64
+}
+
+var _ RepositoryInterface = (*MemoryRepository)(nil)
+
+// NewMemoryRepository creates an empty in-memory user repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		users: map[string]models.User{},
+		roles: map[string]bool{
+			models.RoleAdmin: true, models.RoleModerator: true, models.RoleTeacher: true,
+			models.RoleCurator: true, models.RoleNormalUser: true, models.RoleGuest: true,
+		},
+		notes:       map[int]models.UserNote{},
+		links:       map[int]models.UserNoteLink{},
+		favArtworks: map[string][]models.UserFavArtworkEntry{},
+		savedPosts:  map[string][]models.UserSavedPostEntry{},
+	}
+}
+
+// AddRole adds a row to the roles table, which starts with the roles of the migrations.
+func (r *MemoryRepository) AddRole(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles[name] = true
+}
+
+// AddNotification stores n for its recipient, filling in the ID and CreatedAt when empty.
+func (r *MemoryRepository) AddNotification(n models.Notification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastNotificationID++
+	if n.ID == "" {
+		n.ID = strconv.Itoa(r.lastNotificationID)
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	r.notifications = append(r.notifications, n)
+}
+
+// AddFavArtwork marks artwork as a favorite of userID, most recent first.
+func (r *MemoryRepository) AddFavArtwork(userID string, artwork models.Artwork) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry := models.UserFavArtworkEntry{Artwork: artwork, FavoritedAt: time.Now()}
+	r.favArtworks[userID] = append([]models.UserFavArtworkEntry{entry}, r.favArtworks[userID]...)
+}
+
+// AddSavedForumPost saves post for userID, most recent first.
+func (r *MemoryRepository) AddSavedForumPost(userID string, post models.ForumPost) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry := models.UserSavedPostEntry{Post: post, SavedAt: time.Now()}
+	r.savedPosts[userID] = append([]models.UserSavedPostEntry{entry}, r.savedPosts[userID]...)
+}
+
+// pageOf returns the page of items and their total, like LIMIT/OFFSET with a COUNT.
+func pageOf[T any](items []T, page, limit int) ([]T, int) {
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := offset + limit
+	if limit < 0 || end > len(items) {
+		end = len(items)
+	}
+	return append(make([]T, 0, end-offset), items[offset:end]...), len(items)
+}
+
+// userLess orders users newest first, ties broken by the higher id.
+func userLess(a, b models.User) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	idA, _ := strconv.Atoi(a.ID)
+	idB, _ := strconv.Atoi(b.ID)
+	return idA > idB
+}
+
+func withoutPassword(u models.User) *models.User {
+	u.PasswordHash = ""
+	return &u
+}
+
+func (r *MemoryRepository) FindByID(ctx context.Context, userID string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return withoutPassword(u), nil
+}
+
+func (r *MemoryRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			return &u, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *MemoryRepository) FindByNickname(ctx context.Context, nickname string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Nickname == nickname {
+			return &u, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, user *models.User, passwordHash string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// The unique index on users.email is case-sensitive
+	for _, u := range r.users {
+		if u.Email == user.Email {
+			return nil, models.ErrConflict
+		}
+	}
+
+	r.lastUserID++
+	now := time.Now()
+	user.ID = strconv.Itoa(r.lastUserID)
+	user.CreatedAt, user.UpdatedAt = now, now
+	stored := *user
+	stored.PasswordHash = passwordHash
+	stored.EmailVerified = false // New accounts start unverified
+	r.users[stored.ID] = stored
+	return user, nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, userID string, data models.UserUpdateData) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if data.Nickname == nil && data.AvatarURL == nil {
+		return withoutPassword(u), nil
+	}
+	if data.Nickname != nil {
+		u.Nickname = *data.Nickname
+	}
+	if data.AvatarURL != nil {
+		u.AvatarURL = *data.AvatarURL
+	}
+	u.UpdatedAt = time.Now()
+	r.users[userID] = u
+	return withoutPassword(u), nil
+}
+
+func (r *MemoryRepository) ListAll(ctx context.Context, page, limit int) ([]models.User, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := make([]models.User, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, *withoutPassword(u))
+	}
+	sort.Slice(users, func(i, j int) bool { return userLess(users[i], users[j]) })
+	users, total := pageOf(users, page, limit)
+	return users, total, nil
+}
+
+func (r *MemoryRepository) UpdateRole(ctx context.Context, actorID, userID string, newRole string) (*models.RoleChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.roles[newRole] || newRole == models.RoleGuest {
+		return nil, fmt.Errorf("%w: %q", models.ErrInvalidRole, newRole)
+	}
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if u.Role == newRole {
+		return nil, nil
+	}
+	if u.Role == models.RoleAdmin || newRole == models.RoleAdmin {
+		if actor, ok := r.users[actorID]; !ok || actor.Role != models.RoleAdmin {
+			return nil, models.ErrForbidden
+		}
+	}
+	if u.Role == models.RoleAdmin {
+		admins := 0
+		for _, other := range r.users {
+			if other.Role == models.RoleAdmin {
+				admins++
+			}
+		}
+		if admins <= 1 {
+			return nil, models.ErrLastAdmin
+		}
+	}
+
+	r.lastRoleChangeID++
+	change := models.RoleChange{
+		ID: r.lastRoleChangeID, ActorID: actorID, TargetUserID: userID,
+		OldRole: u.Role, NewRole: newRole, CreatedAt: time.Now(),
+	}
+	r.roleChanges = append(r.roleChanges, change)
+	u.Role = newRole
+	u.UpdatedAt = change.CreatedAt
+	r.users[userID] = u
+	return &change, nil
+}
+
+func (r *MemoryRepository) ListRoleChanges(ctx context.Context, filter models.RoleChangeFilter) ([]models.RoleChange, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changes := []models.RoleChange{}
+	// Newest first
+	for i := len(r.roleChanges) - 1; i >= 0; i-- {
+		rc := r.roleChanges[i]
+		if (filter.ActorID != "" && rc.ActorID != filter.ActorID) ||
+			(filter.TargetUserID != "" && rc.TargetUserID != filter.TargetUserID) {
+			continue
+		}
+		// Nicknames are looked up when listing, as the SQL joins them
+		rc.ActorNickname = r.users[rc.ActorID].Nickname
+		rc.TargetNickname = r.users[rc.TargetUserID].Nickname
+		changes = append(changes, rc)
+	}
+	changes, total := pageOf(changes, filter.Page, filter.Limit)
+	return changes, total, nil
+}
+
+func (r *MemoryRepository) SetEmailVerified(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	u.EmailVerified = true
+	u.UpdatedAt = time.Now()
+	r.users[userID] = u
+	return nil
+}
+
+func (r *MemoryRepository) UpdatePasswordHash(ctx context.Context, userID string, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	u.PasswordHash = passwordHash
+	u.UpdatedAt = time.Now()
+	r.users[userID] = u
+	return nil
+}
+
+// --- User Notes Methods ---
+
+func (r *MemoryRepository) GetUserNoteByID(ctx context.Context, noteID int, userID string) (*models.UserNote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	note, ok := r.notes[noteID]
+	if !ok || note.UserID != userID {
+		return nil, models.ErrNotFound
+	}
+	return &note, nil
+}
+
+func (r *MemoryRepository) GetLinksForNote(ctx context.Context, noteID int) ([]models.UserNoteLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	links := []models.UserNoteLink{}
+	for _, link := range r.links {
+		if link.UserNoteID == noteID {
+			links = append(links, link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+	return links, nil
+}
+
+func (r *MemoryRepository) ListUserNotes(ctx context.Context, userID string, page, limit int) ([]models.UserNote, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	notes := []models.UserNote{}
+	for _, note := range r.notes {
+		if note.UserID == userID {
+			// The list view leaves out the content and forum post
+			note.Content, note.ForumPostID = "", nil
+			notes = append(notes, note)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		if !notes[i].UpdatedAt.Equal(notes[j].UpdatedAt) {
+			return notes[i].UpdatedAt.After(notes[j].UpdatedAt)
+		}
+		return notes[i].ID > notes[j].ID
+	})
+	notes, total := pageOf(notes, page, limit)
+	return notes, total, nil
+}
+
+func (r *MemoryRepository) CreateUserNote(ctx context.Context, userID string, data models.CreateUserNoteData) (*models.UserNote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[userID]; !ok {
+		return nil, fmt.Errorf("repository.CreateUserNote: user %s does not exist", userID)
+	}
+	if data.EntityType == nil || data.EntityID == nil {
+		return nil, errors.New("repository.CreateUserNote: entity_type and entity_id are required")
+	}
+	for _, note := range r.notes {
+		if note.UserID == userID && *note.EntityType == *data.EntityType && *note.EntityID == *data.EntityID {
+			return nil, models.ErrConflict
+		}
+	}
+
+	r.lastNoteID++
+	entityType, entityID := *data.EntityType, *data.EntityID
+	now := time.Now()
+	note := models.UserNote{
+		ID:         r.lastNoteID,
+		UserID:     userID,
+		Title:      data.Title,
+		Content:    data.Content,
+		EntityType: &entityType,
+		EntityID:   &entityID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	r.notes[note.ID] = note
+	return &note, nil
+}
+
+func (r *MemoryRepository) UpdateUserNote(ctx context.Context, noteID int, userID string, data models.UpdateUserNoteData) (*models.UserNote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	note, ok := r.notes[noteID]
+	if !ok || note.UserID != userID {
+		return nil, models.ErrNotFound
+	}
+	if data.Title != nil {
+		note.Title = *data.Title
+	}
+	if data.Content != nil {
+		note.Content = *data.Content
+	}
+	note.UpdatedAt = time.Now()
+	r.notes[noteID] = note
+	return &note, nil
+}
+
+func (r *MemoryRepository) DeleteUserNote(ctx context.Context, noteID int, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	note, ok := r.notes[noteID]
+	if !ok || note.UserID != userID {
+		return models.ErrNotFound
+	}
+	delete(r.notes, noteID)
+	// ON DELETE CASCADE
+	for id, link := range r.links {
+		if link.UserNoteID == noteID {
+			delete(r.links, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) AddLinkToNote(ctx context.Context, noteID int, data models.AddLinkToNoteData) (*models.UserNoteLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.notes[noteID]; !ok {
+		return nil, fmt.Errorf("repository.AddLinkToNote: note %d does not exist", noteID)
+	}
+	set := 0
+	for _, id := range []bool{data.LinkedEntityIDInt != nil, data.LinkedEntityIDUUID != nil, data.LinkedEntityIDString != nil} {
+		if id {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("repository.AddLinkToNote: exactly one linked entity id is required")
+	}
+
+	r.lastLinkID++
+	link := models.UserNoteLink{
+		ID:                   r.lastLinkID,
+		UserNoteID:           noteID,
+		LinkedEntityType:     data.LinkedEntityType,
+		LinkedEntityIDInt:    data.LinkedEntityIDInt,
+		LinkedEntityIDUUID:   data.LinkedEntityIDUUID,
+		LinkedEntityIDString: data.LinkedEntityIDString,
+		LinkDescription:      data.LinkDescription,
+		CreatedAt:            time.Now(),
+	}
+	r.links[link.ID] = link
+	return &link, nil
+}
+
+func (r *MemoryRepository) RemoveLinkFromNote(ctx context.Context, noteID, linkID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link, ok := r.links[linkID]
+	if !ok || link.UserNoteID != noteID {
+		return models.ErrNotFound
+	}
+	delete(r.links, linkID)
+	return nil
+}
+
+func (r *MemoryRepository) MarkNoteAsPublished(ctx context.Context, noteID int, forumPostID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	note, ok := r.notes[noteID]
+	if !ok {
+		return nil // Like an UPDATE matching no rows
+	}
+	postID := int(forumPostID)
+	note.IsPublishedToForum = true
+	note.ForumPostID = &postID
+	note.UpdatedAt = time.Now()
+	r.notes[noteID] = note
+	return nil
+}
+
+// --- Other Profile Data Methods ---
+
+func (r *MemoryRepository) GetNotifications(ctx context.Context, userID string, page, limit int) ([]models.Notification, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	notifications := []models.Notification{}
+	for _, n := range r.notifications {
+		if n.RecipientUserID == userID {
+			notifications = append(notifications, n)
+		}
+	}
+	sort.SliceStable(notifications, func(i, j int) bool { return notifications[i].CreatedAt.After(notifications[j].CreatedAt) })
+	notifications, total := pageOf(notifications, page, limit)
+	return notifications, total, nil
+}
+
+func (r *MemoryRepository) GetFavArtworks(ctx context.Context, userID string, page, limit int) ([]models.UserFavArtworkEntry, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries, total := pageOf(r.favArtworks[userID], page, limit)
+	return entries, total, nil
+}
+
+func (r *MemoryRepository) GetSavedForumPosts(ctx context.Context, userID string, page, limit int) ([]models.UserSavedPostEntry, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries, total := pageOf(r.savedPosts[userID], page, limit)
+	return entries, total, nil
+}
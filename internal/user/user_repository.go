@@ -2,12 +2,15 @@ package user
 
 import (
 	"context"
-	"database/sql" // For sql.ErrNoRows
+	"database/sql" // For sql.NullString
+	"errors"
 	"fmt"
 	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/pkg/utils"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	// "github.com/Masterminds/squirrel" // Optional: for SQL query building
 )
@@ -20,7 +23,16 @@ type RepositoryInterface interface {
 	Create(ctx context.Context, user *models.User, passwordHash string) (*models.User, error) // Assuming you might add direct user creation
 	Update(ctx context.Context, userID string, updateData models.UserUpdateData) (*models.User, error)
 	ListAll(ctx context.Context, page, limit int) ([]models.User, int, error) // For admin: list users
-	UpdateRole(ctx context.Context, userID string, newRole string) error      // For admin: update role
+	// UpdateRole sets the role of userID and records the change in user_role_changes, both in one transaction.
+	// It returns models.ErrInvalidRole unless newRole is in the roles table (guest, which only describes
+	// anonymous visitors, never is), models.ErrNotFound for a missing user, models.ErrForbidden if the
+	// change grants or removes admin and actorID is not an admin, and models.ErrLastAdmin if it would
+	// leave no admin.
+	// A change to the current role is a no-op and returns (nil, nil).
+	UpdateRole(ctx context.Context, actorID, userID string, newRole string) (*models.RoleChange, error)
+	ListRoleChanges(ctx context.Context, filter models.RoleChangeFilter) ([]models.RoleChange, int, error)
+	SetEmailVerified(ctx context.Context, userID string) error
+	UpdatePasswordHash(ctx context.Context, userID string, passwordHash string) error
 
 	// User Notes specific methods
 	GetUserNoteByID(ctx context.Context, noteID int, userID string) (*models.UserNote, error)
@@ -31,7 +43,7 @@ type RepositoryInterface interface {
 	DeleteUserNote(ctx context.Context, noteID int, userID string) error
 	AddLinkToNote(ctx context.Context, noteID int, data models.AddLinkToNoteData) (*models.UserNoteLink, error)
 	RemoveLinkFromNote(ctx context.Context, noteID, linkID int) error
-	MarkNoteAsPublished(ctx context.Context, noteID int, forumPostID int) error
+	MarkNoteAsPublished(ctx context.Context, noteID int, forumPostID int64) error
 
 	// Other profile data
 	GetNotifications(ctx context.Context, userID string, page, limit int) ([]models.Notification, int, error)
@@ -49,12 +61,13 @@ func NewRepository(db *pgxpool.Pool) RepositoryInterface {
 
 func (r *Repository) FindByID(ctx context.Context, userID string) (*models.User, error) {
 	user := &models.User{}
-	query := `SELECT id, nickname, email, role, avatar_url, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, COALESCE(nickname, ''), COALESCE(email, ''), role, COALESCE(avatar_url, ''), email_verified, created_at, updated_at
+	          FROM users WHERE id = $1`
 	err := r.db.QueryRow(ctx, query, userID).Scan(
-		&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows || strings.Contains(err.Error(), "no rows in result set") { // pgx might return different error
+		if utils.IsNoRows(err) {
 			return nil, models.ErrNotFound // Define this error in models
 		}
 		return nil, fmt.Errorf("repository.FindByID: %w", err)
@@ -66,12 +79,15 @@ func (r *Repository) FindByEmail(ctx context.Context, email string) (*models.Use
 	// Similar to FindByID, but queries by email
 	// Important for checking if email exists during signup if you implement it
 	user := &models.User{}
-	query := `SELECT id, nickname, email, role, avatar_url, password_hash, created_at, updated_at FROM users WHERE email = $1`
+	// Emails are matched case-insensitively; password_hash is empty for accounts created through an OAuth provider
+	query := `SELECT id, COALESCE(nickname, ''), COALESCE(email, ''), role, COALESCE(avatar_url, ''), email_verified,
+	                 COALESCE(password_hash, ''), created_at, updated_at
+	          FROM users WHERE LOWER(email) = LOWER($1)`
 	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.EmailVerified, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows || strings.Contains(err.Error(), "no rows in result set") {
+		if utils.IsNoRows(err) {
 			return nil, models.ErrNotFound
 		}
 		return nil, fmt.Errorf("repository.FindByEmail: %w", err)
@@ -81,12 +97,14 @@ func (r *Repository) FindByEmail(ctx context.Context, email string) (*models.Use
 
 func (r *Repository) FindByNickname(ctx context.Context, nickname string) (*models.User, error) {
 	user := &models.User{}
-	query := `SELECT id, nickname, email, role, avatar_url, password_hash, created_at, updated_at FROM users WHERE nickname = $1`
+	query := `SELECT id, COALESCE(nickname, ''), COALESCE(email, ''), role, COALESCE(avatar_url, ''), email_verified,
+	                 COALESCE(password_hash, ''), created_at, updated_at
+	          FROM users WHERE nickname = $1`
 	err := r.db.QueryRow(ctx, query, nickname).Scan(
-		&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.EmailVerified, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows || strings.Contains(err.Error(), "no rows in result set") {
+		if utils.IsNoRows(err) {
 			return nil, models.ErrNotFound
 		}
 		return nil, fmt.Errorf("repository.FindByNickname: %w", err)
@@ -105,7 +123,10 @@ func (r *Repository) Create(ctx context.Context, user *models.User, passwordHash
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
-		// Handle potential duplicate email error (unique constraint)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation: email already registered
+			return nil, models.ErrConflict
+		}
 		return nil, fmt.Errorf("repository.CreateUser: %w", err)
 	}
 	return user, nil
@@ -140,14 +161,17 @@ func (r *Repository) Update(ctx context.Context, userID string, data models.User
 	args = append(args, userID) // For WHERE clause
 
 	query := fmt.Sprintf(`UPDATE users SET %s WHERE id = $%d
-	                     RETURNING id, nickname, email, role, avatar_url, created_at, updated_at`,
+	                     RETURNING id, COALESCE(nickname, ''), COALESCE(email, ''), role, COALESCE(avatar_url, ''), email_verified, created_at, updated_at`,
 		strings.Join(setClauses, ", "), argIdx)
 
 	updatedUser := &models.User{}
 	err := r.db.QueryRow(ctx, query, args...).Scan(
-		&updatedUser.ID, &updatedUser.Nickname, &updatedUser.Email, &updatedUser.Role, &updatedUser.AvatarURL, &updatedUser.CreatedAt, &updatedUser.UpdatedAt,
+		&updatedUser.ID, &updatedUser.Nickname, &updatedUser.Email, &updatedUser.Role, &updatedUser.AvatarURL, &updatedUser.EmailVerified, &updatedUser.CreatedAt, &updatedUser.UpdatedAt,
 	)
 	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
 		return nil, fmt.Errorf("repository.UpdateUser: %w", err)
 	}
 	return updatedUser, nil
@@ -156,7 +180,8 @@ func (r *Repository) Update(ctx context.Context, userID string, data models.User
 // --- Admin specific methods ---
 func (r *Repository) ListAll(ctx context.Context, page, limit int) ([]models.User, int, error) {
 	offset := (page - 1) * limit
-	query := `SELECT id, nickname, email, role, avatar_url, created_at, updated_at FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	query := `SELECT id, COALESCE(nickname, ''), COALESCE(email, ''), role, COALESCE(avatar_url, ''), email_verified, created_at, updated_at
+	          FROM users ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`
 	rows, err := r.db.Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("repository.ListAllUsers: %w", err)
@@ -166,7 +191,7 @@ func (r *Repository) ListAll(ctx context.Context, page, limit int) ([]models.Use
 	users := []models.User{}
 	for rows.Next() {
 		var user models.User
-		if err := rows.Scan(&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Nickname, &user.Email, &user.Role, &user.AvatarURL, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt); err != nil {
 			return nil, 0, fmt.Errorf("repository.ListAllUsers.Scan: %w", err)
 		}
 		users = append(users, user)
@@ -181,11 +206,157 @@ func (r *Repository) ListAll(ctx context.Context, page, limit int) ([]models.Use
 	return users, total, nil
 }
 
-func (r *Repository) UpdateRole(ctx context.Context, userID string, newRole string) error {
-	query := `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.Exec(ctx, query, newRole, time.Now(), userID)
+// roleChangeLockKey serializes role changes so two admins demoting each other cannot both pass the
+// last-admin check.
+const roleChangeLockKey = 7318240019
+
+func (r *Repository) UpdateRole(ctx context.Context, actorID, userID string, newRole string) (*models.RoleChange, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateUserRole.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, roleChangeLockKey); err != nil {
+		return nil, fmt.Errorf("repository.UpdateUserRole.Lock: %w", err)
+	}
+
+	var roleExists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, newRole).Scan(&roleExists)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateUserRole.Role: %w", err)
+	}
+	if !roleExists || newRole == models.RoleGuest {
+		return nil, fmt.Errorf("%w: %q", models.ErrInvalidRole, newRole)
+	}
+
+	var oldRole string
+	err = tx.QueryRow(ctx, `SELECT role FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&oldRole)
+	if err != nil {
+		if utils.IsNoRows(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("repository.UpdateUserRole.Select: %w", err)
+	}
+	if oldRole == newRole {
+		return nil, nil
+	}
+	if oldRole == models.RoleAdmin || newRole == models.RoleAdmin {
+		// users.manage alone must not be a way to become admin or depose one
+		var actorRole string
+		err := tx.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, actorID).Scan(&actorRole)
+		if err != nil && !utils.IsNoRows(err) {
+			return nil, fmt.Errorf("repository.UpdateUserRole.Actor: %w", err)
+		}
+		if actorRole != models.RoleAdmin {
+			return nil, models.ErrForbidden
+		}
+	}
+	if oldRole == models.RoleAdmin {
+		var admins int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE role = $1`, models.RoleAdmin).Scan(&admins); err != nil {
+			return nil, fmt.Errorf("repository.UpdateUserRole.CountAdmins: %w", err)
+		}
+		if admins <= 1 {
+			return nil, models.ErrLastAdmin
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`, newRole, time.Now(), userID); err != nil {
+		return nil, fmt.Errorf("repository.UpdateUserRole.Update: %w", err)
+	}
+
+	change := &models.RoleChange{ActorID: actorID, TargetUserID: userID, OldRole: oldRole, NewRole: newRole}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO user_role_changes (actor_id, target_user_id, old_role, new_role)
+		 VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		actorID, userID, oldRole, newRole,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("repository.UpdateUserRole.Audit: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.UpdateUserRole.Commit: %w", err)
+	}
+	return change, nil
+}
+
+func (r *Repository) ListRoleChanges(ctx context.Context, filter models.RoleChangeFilter) ([]models.RoleChange, int, error) {
+	var where []string
+	var args []interface{}
+	argIdx := 1
+
+	if filter.ActorID != "" {
+		where = append(where, fmt.Sprintf("rc.actor_id = $%d", argIdx))
+		args = append(args, filter.ActorID)
+		argIdx++
+	}
+	if filter.TargetUserID != "" {
+		where = append(where, fmt.Sprintf("rc.target_user_id = $%d", argIdx))
+		args = append(args, filter.TargetUserID)
+		argIdx++
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM user_role_changes rc " + whereClause
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListRoleChanges.Count: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT rc.id, rc.actor_id, COALESCE(a.nickname, ''), rc.target_user_id, COALESCE(t.nickname, ''),
+	                             rc.old_role, rc.new_role, rc.created_at
+	                      FROM user_role_changes rc
+	                      LEFT JOIN users a ON a.id = rc.actor_id
+	                      LEFT JOIN users t ON t.id = rc.target_user_id
+	                      %s
+	                      ORDER BY rc.created_at DESC, rc.id DESC
+	                      LIMIT $%d OFFSET $%d`, whereClause, argIdx, argIdx+1)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository.ListRoleChanges: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.RoleChange{}
+	for rows.Next() {
+		var rc models.RoleChange
+		if err := rows.Scan(&rc.ID, &rc.ActorID, &rc.ActorNickname, &rc.TargetUserID, &rc.TargetNickname,
+			&rc.OldRole, &rc.NewRole, &rc.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("repository.ListRoleChanges.Scan: %w", err)
+		}
+		changes = append(changes, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("repository.ListRoleChanges.Rows: %w", err)
+	}
+	return changes, total, nil
+}
+
+func (r *Repository) SetEmailVerified(ctx context.Context, userID string) error {
+	cmdTag, err := r.db.Exec(ctx, `UPDATE users SET email_verified = TRUE, updated_at = $1 WHERE id = $2`, time.Now(), userID)
 	if err != nil {
-		return fmt.Errorf("repository.UpdateUserRole: %w", err)
+		return fmt.Errorf("repository.SetEmailVerified: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) UpdatePasswordHash(ctx context.Context, userID string, passwordHash string) error {
+	cmdTag, err := r.db.Exec(ctx, `UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3`, passwordHash, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("repository.UpdatePasswordHash: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return models.ErrNotFound
 	}
 	return nil
 }
@@ -200,7 +371,7 @@ func (r *Repository) GetUserNoteByID(ctx context.Context, noteID int, userID str
 		&note.IsPublishedToForum, &note.ForumPostID, &note.CreatedAt, &note.UpdatedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows || strings.Contains(err.Error(), "no rows") {
+		if utils.IsNoRows(err) {
 			return nil, models.ErrNotFound
 		}
 		return nil, fmt.Errorf("repository.GetUserNoteByID: %w", err)
@@ -210,8 +381,9 @@ func (r *Repository) GetUserNoteByID(ctx context.Context, noteID int, userID str
 
 func (r *Repository) GetLinksForNote(ctx context.Context, noteID int) ([]models.UserNoteLink, error) {
 	links := []models.UserNoteLink{}
-	query := `SELECT id, user_note_id, linked_entity_type, linked_entity_id_int, linked_entity_id_uuid, linked_entity_id_string, linked_description, created_at
-	          FROM user_note_links WHERE user_note_id = $1`
+	query := `SELECT id, user_note_id, linked_entity_type, linked_entity_id_int, linked_entity_id_uuid::text, linked_entity_id_string,
+	                 COALESCE(link_description, ''), created_at
+	          FROM user_note_links WHERE user_note_id = $1 ORDER BY id`
 	rows, err := r.db.Query(ctx, query, noteID)
 	if err != nil {
 		return nil, fmt.Errorf("repository.GetLinksForNote: %w", err)
@@ -219,11 +391,14 @@ func (r *Repository) GetLinksForNote(ctx context.Context, noteID int) ([]models.
 	defer rows.Close()
 	for rows.Next() {
 		var link models.UserNoteLink
-		if err := rows.Scan(&link.UserNoteID, &link.LinkedEntityType, &link.LinkedEntityIDInt, &link.LinkedEntityIDUUID, &link.LinkedEntityIDString, &link.LinkDescription, &link.CreatedAt); err != nil {
+		if err := rows.Scan(&link.ID, &link.UserNoteID, &link.LinkedEntityType, &link.LinkedEntityIDInt, &link.LinkedEntityIDUUID, &link.LinkedEntityIDString, &link.LinkDescription, &link.CreatedAt); err != nil {
 			return nil, fmt.Errorf("repository.GetLinksForNote.Scan: %w", err)
 		}
 		links = append(links, link)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.GetLinksForNote.Rows: %w", err)
+	}
 	return links, nil
 }
 
@@ -269,6 +444,10 @@ func (r *Repository) CreateUserNote(ctx context.Context, userID string, data mod
 	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
 	err := r.db.QueryRow(ctx, query, note.UserID, note.Title, note.Content, note.EntityType, note.EntityID, note.CreatedAt, note.UpdatedAt).Scan(&note.ID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation: one note per user per entity
+			return nil, models.ErrConflict
+		}
 		return nil, fmt.Errorf("repository.CreateUserNote: %w", err)
 	}
 	return &note, nil
@@ -347,7 +526,7 @@ func (r *Repository) RemoveLinkFromNote(ctx context.Context, noteID, linkID int)
 	return nil
 }
 
-func (r *Repository) MarkNoteAsPublished(ctx context.Context, noteID int, forumPostID int) error {
+func (r *Repository) MarkNoteAsPublished(ctx context.Context, noteID int, forumPostID int64) error {
 	query := `UPDATE user_notes SET is_published_to_forum = TRUE, forum_post_id = $1, updated_at = $2 WHERE id = $3`
 	_, err := r.db.Exec(ctx, query, forumPostID, time.Now(), noteID)
 	if err != nil {
@@ -0,0 +1,319 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+// The contract tests run against every RepositoryInterface implementation so MemoryRepository keeps
+// behaving like the Postgres one. Each subtest gets an empty repository.
+
+func TestRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewRepository(pgtest.NewDB(t)) })
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) RepositoryInterface { return NewMemoryRepository() })
+}
+
+func testRepositoryContract(t *testing.T, newRepo func(t *testing.T) RepositoryInterface) {
+	ctx := context.Background()
+	create := func(t *testing.T, repo RepositoryInterface, nickname, role string) *models.User {
+		t.Helper()
+		u, err := repo.Create(ctx, &models.User{Nickname: nickname, Email: nickname + "@example.com", Role: role}, "hash-"+nickname)
+		if err != nil {
+			t.Fatalf("Create(%s): %v", nickname, err)
+		}
+		return u
+	}
+	note := func(t *testing.T, repo RepositoryInterface, userID string, entityID int) *models.UserNote {
+		t.Helper()
+		n, err := repo.CreateUserNote(ctx, userID, models.CreateUserNoteData{
+			Title: "Glaze", Content: "Celadon crackle.", EntityType: ptr("artwork"), EntityID: ptr(entityID),
+		})
+		if err != nil {
+			t.Fatalf("CreateUserNote: %v", err)
+		}
+		return n
+	}
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		u := create(t, repo, "potter", models.RoleNormalUser)
+		if u.ID == "" || u.CreatedAt.IsZero() {
+			t.Fatalf("Create = %+v: want ID and timestamps set", u)
+		}
+
+		byID, err := repo.FindByID(ctx, u.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if byID.Nickname != "potter" || byID.Role != models.RoleNormalUser || byID.EmailVerified || byID.PasswordHash != "" {
+			t.Errorf("FindByID = %+v: want an unverified potter without password hash", byID)
+		}
+		byEmail, err := repo.FindByEmail(ctx, "POTTER@example.com")
+		if err != nil {
+			t.Fatalf("FindByEmail: %v", err)
+		}
+		if byEmail.ID != u.ID || byEmail.PasswordHash != "hash-potter" {
+			t.Errorf("FindByEmail = %+v: want potter with password hash", byEmail)
+		}
+		byNickname, err := repo.FindByNickname(ctx, "potter")
+		if err != nil {
+			t.Fatalf("FindByNickname: %v", err)
+		}
+		if byNickname.ID != u.ID {
+			t.Errorf("FindByNickname id = %s, want %s", byNickname.ID, u.ID)
+		}
+
+		if _, err := repo.Create(ctx, &models.User{Nickname: "other", Email: "potter@example.com", Role: models.RoleNormalUser}, "x"); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Create with taken email: error = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, err := repo.FindByID(ctx, "42"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindByID: error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.FindByEmail(ctx, "nobody@example.com"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindByEmail: error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.FindByNickname(ctx, "nobody"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("FindByNickname: error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.Update(ctx, "42", models.UserUpdateData{Nickname: ptr("ghost")}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Update: error = %v, want ErrNotFound", err)
+		}
+		if err := repo.SetEmailVerified(ctx, "42"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("SetEmailVerified: error = %v, want ErrNotFound", err)
+		}
+		if err := repo.UpdatePasswordHash(ctx, "42", "x"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdatePasswordHash: error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.UpdateRole(ctx, "1", "42", models.RoleAdmin); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateRole: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		u := create(t, repo, "potter", models.RoleNormalUser)
+
+		got, err := repo.Update(ctx, u.ID, models.UserUpdateData{Nickname: ptr("glazer")})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if got.Nickname != "glazer" || got.Email != u.Email || got.PasswordHash != "" {
+			t.Errorf("Update = %+v: want only the nickname changed", got)
+		}
+		if _, err := repo.FindByNickname(ctx, "potter"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("old nickname still found: %v", err)
+		}
+
+		unchanged, err := repo.Update(ctx, u.ID, models.UserUpdateData{})
+		if err != nil || unchanged.Nickname != "glazer" {
+			t.Errorf("empty Update = (%+v, %v), want the current user", unchanged, err)
+		}
+	})
+
+	t.Run("VerificationAndPassword", func(t *testing.T) {
+		repo := newRepo(t)
+		u := create(t, repo, "potter", models.RoleNormalUser)
+		if err := repo.SetEmailVerified(ctx, u.ID); err != nil {
+			t.Fatalf("SetEmailVerified: %v", err)
+		}
+		if err := repo.UpdatePasswordHash(ctx, u.ID, "new-hash"); err != nil {
+			t.Fatalf("UpdatePasswordHash: %v", err)
+		}
+		got, err := repo.FindByEmail(ctx, u.Email)
+		if err != nil || !got.EmailVerified || got.PasswordHash != "new-hash" {
+			t.Errorf("FindByEmail = (%+v, %v), want verified with the new hash", got, err)
+		}
+	})
+
+	t.Run("ListAll", func(t *testing.T) {
+		repo := newRepo(t)
+		first := create(t, repo, "first", models.RoleNormalUser)
+		second := create(t, repo, "second", models.RoleNormalUser)
+		third := create(t, repo, "third", models.RoleNormalUser)
+
+		users, total, err := repo.ListAll(ctx, 1, 2)
+		if err != nil {
+			t.Fatalf("ListAll: %v", err)
+		}
+		if total != 3 || len(users) != 2 || users[0].ID != third.ID || users[1].ID != second.ID {
+			t.Errorf("ListAll page 1 = %+v (total %d), want third and second", users, total)
+		}
+		users, _, err = repo.ListAll(ctx, 2, 2)
+		if err != nil || len(users) != 1 || users[0].ID != first.ID || users[0].PasswordHash != "" {
+			t.Errorf("ListAll page 2 = (%+v, %v), want first without password hash", users, err)
+		}
+	})
+
+	t.Run("UpdateRole", func(t *testing.T) {
+		repo := newRepo(t)
+		admin := create(t, repo, "admin", models.RoleAdmin)
+		member := create(t, repo, "member", models.RoleNormalUser)
+
+		for _, role := range []string{"superuser", models.RoleGuest} {
+			if _, err := repo.UpdateRole(ctx, admin.ID, member.ID, role); !errors.Is(err, models.ErrInvalidRole) {
+				t.Errorf("UpdateRole to %q: error = %v, want ErrInvalidRole", role, err)
+			}
+		}
+		if _, err := repo.UpdateRole(ctx, admin.ID, admin.ID, models.RoleNormalUser); !errors.Is(err, models.ErrLastAdmin) {
+			t.Fatalf("demoting the last admin: error = %v, want ErrLastAdmin", err)
+		}
+		// Only an admin may grant or remove admin, whatever else the actor is allowed to do
+		moderator := create(t, repo, "moderator", models.RoleModerator)
+		if _, err := repo.UpdateRole(ctx, moderator.ID, member.ID, models.RoleAdmin); !errors.Is(err, models.ErrForbidden) {
+			t.Errorf("non-admin granting admin: error = %v, want ErrForbidden", err)
+		}
+		if _, err := repo.UpdateRole(ctx, moderator.ID, admin.ID, models.RoleNormalUser); !errors.Is(err, models.ErrForbidden) {
+			t.Errorf("non-admin removing admin: error = %v, want ErrForbidden", err)
+		}
+		change, err := repo.UpdateRole(ctx, admin.ID, member.ID, models.RoleAdmin)
+		if err != nil {
+			t.Fatalf("UpdateRole: %v", err)
+		}
+		if change.ID == 0 || change.OldRole != models.RoleNormalUser || change.NewRole != models.RoleAdmin {
+			t.Errorf("UpdateRole = %+v", change)
+		}
+		if change, err := repo.UpdateRole(ctx, admin.ID, member.ID, models.RoleAdmin); change != nil || err != nil {
+			t.Errorf("UpdateRole to the current role = (%+v, %v), want (nil, nil)", change, err)
+		}
+		// With two admins one may step down
+		if _, err := repo.UpdateRole(ctx, member.ID, admin.ID, models.RoleModerator); err != nil {
+			t.Fatalf("demoting one of two admins: %v", err)
+		}
+
+		changes, total, err := repo.ListRoleChanges(ctx, models.RoleChangeFilter{Page: 1, Limit: 10})
+		if err != nil {
+			t.Fatalf("ListRoleChanges: %v", err)
+		}
+		if total != 2 || len(changes) != 2 || changes[0].TargetUserID != admin.ID || changes[0].ActorNickname != "member" ||
+			changes[1].TargetNickname != "member" {
+			t.Errorf("ListRoleChanges = %+v (total %d), want newest first with nicknames", changes, total)
+		}
+		changes, total, err = repo.ListRoleChanges(ctx, models.RoleChangeFilter{Page: 1, Limit: 10, ActorID: admin.ID})
+		if err != nil || total != 1 || len(changes) != 1 || changes[0].TargetUserID != member.ID {
+			t.Errorf("ListRoleChanges by actor = %+v (total %d, err %v)", changes, total, err)
+		}
+	})
+
+	t.Run("Notes", func(t *testing.T) {
+		repo := newRepo(t)
+		owner := create(t, repo, "owner", models.RoleNormalUser)
+		stranger := create(t, repo, "stranger", models.RoleNormalUser)
+		first := note(t, repo, owner.ID, 1)
+		second := note(t, repo, owner.ID, 2)
+
+		got, err := repo.GetUserNoteByID(ctx, first.ID, owner.ID)
+		if err != nil {
+			t.Fatalf("GetUserNoteByID: %v", err)
+		}
+		if got.Content != "Celadon crackle." || *got.EntityType != "artwork" || *got.EntityID != 1 || got.IsPublishedToForum {
+			t.Errorf("GetUserNoteByID = %+v", got)
+		}
+		if _, err := repo.GetUserNoteByID(ctx, first.ID, stranger.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetUserNoteByID of another user's note: error = %v, want ErrNotFound", err)
+		}
+		_, err = repo.CreateUserNote(ctx, owner.ID, models.CreateUserNoteData{Title: "Again", Content: "x", EntityType: ptr("artwork"), EntityID: ptr(1)})
+		if !errors.Is(err, models.ErrConflict) {
+			t.Errorf("second note on one entity: error = %v, want ErrConflict", err)
+		}
+		// Another user may note the same entity
+		note(t, repo, stranger.ID, 1)
+
+		if _, err := repo.UpdateUserNote(ctx, first.ID, stranger.ID, models.UpdateUserNoteData{Title: ptr("Mine")}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("UpdateUserNote by another user: error = %v, want ErrNotFound", err)
+		}
+		updated, err := repo.UpdateUserNote(ctx, first.ID, owner.ID, models.UpdateUserNoteData{Title: ptr("Revised")})
+		if err != nil {
+			t.Fatalf("UpdateUserNote: %v", err)
+		}
+		if updated.Title != "Revised" || updated.Content != first.Content {
+			t.Errorf("UpdateUserNote = %+v: want only the title changed", updated)
+		}
+
+		// Recently updated first; the list leaves out the content
+		notes, total, err := repo.ListUserNotes(ctx, owner.ID, 1, 10)
+		if err != nil {
+			t.Fatalf("ListUserNotes: %v", err)
+		}
+		if total != 2 || len(notes) != 2 || notes[0].ID != first.ID || notes[1].ID != second.ID || notes[0].Content != "" {
+			t.Errorf("ListUserNotes = %+v (total %d)", notes, total)
+		}
+
+		if err := repo.DeleteUserNote(ctx, first.ID, stranger.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("DeleteUserNote by another user: error = %v, want ErrNotFound", err)
+		}
+		if err := repo.DeleteUserNote(ctx, first.ID, owner.ID); err != nil {
+			t.Fatalf("DeleteUserNote: %v", err)
+		}
+		if _, err := repo.GetUserNoteByID(ctx, first.ID, owner.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetUserNoteByID after delete: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("NoteLinks", func(t *testing.T) {
+		repo := newRepo(t)
+		owner := create(t, repo, "owner", models.RoleNormalUser)
+		n := note(t, repo, owner.ID, 1)
+
+		byInt, err := repo.AddLinkToNote(ctx, n.ID, models.AddLinkToNoteData{LinkedEntityType: "forum_post", LinkedEntityIDInt: ptr(3)})
+		if err != nil {
+			t.Fatalf("AddLinkToNote: %v", err)
+		}
+		byString, err := repo.AddLinkToNote(ctx, n.ID, models.AddLinkToNoteData{
+			LinkedEntityType: "engage_article_paragraph", LinkedEntityIDString: ptr("p4"), LinkDescription: "Kiln temperatures",
+		})
+		if err != nil {
+			t.Fatalf("AddLinkToNote: %v", err)
+		}
+		if _, err := repo.AddLinkToNote(ctx, n.ID, models.AddLinkToNoteData{LinkedEntityType: "artwork"}); err == nil {
+			t.Error("AddLinkToNote without a linked id succeeded")
+		}
+
+		links, err := repo.GetLinksForNote(ctx, n.ID)
+		if err != nil {
+			t.Fatalf("GetLinksForNote: %v", err)
+		}
+		if len(links) != 2 || links[0].ID != byInt.ID || *links[0].LinkedEntityIDInt != 3 ||
+			links[1].ID != byString.ID || links[1].LinkDescription != "Kiln temperatures" {
+			t.Errorf("GetLinksForNote = %+v", links)
+		}
+
+		if err := repo.RemoveLinkFromNote(ctx, n.ID+1, byInt.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("RemoveLinkFromNote with the wrong note: error = %v, want ErrNotFound", err)
+		}
+		if err := repo.RemoveLinkFromNote(ctx, n.ID, byInt.ID); err != nil {
+			t.Fatalf("RemoveLinkFromNote: %v", err)
+		}
+		if err := repo.RemoveLinkFromNote(ctx, n.ID, byInt.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("second RemoveLinkFromNote: error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("EmptyProfileLists", func(t *testing.T) {
+		repo := newRepo(t)
+		u := create(t, repo, "potter", models.RoleNormalUser)
+
+		notifications, total, err := repo.GetNotifications(ctx, u.ID, 1, 10)
+		if err != nil || notifications == nil || len(notifications) != 0 || total != 0 {
+			t.Errorf("GetNotifications = (%v, %d, %v), want empty", notifications, total, err)
+		}
+		favs, total, err := repo.GetFavArtworks(ctx, u.ID, 1, 10)
+		if err != nil || favs == nil || len(favs) != 0 || total != 0 {
+			t.Errorf("GetFavArtworks = (%v, %d, %v), want empty", favs, total, err)
+		}
+		saved, total, err := repo.GetSavedForumPosts(ctx, u.ID, 1, 10)
+		if err != nil || saved == nil || len(saved) != 0 || total != 0 {
+			t.Errorf("GetSavedForumPosts = (%v, %d, %v), want empty", saved, total, err)
+		}
+	})
+}
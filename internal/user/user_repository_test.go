@@ -0,0 +1,176 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/models"
+	"jingdezhen-ceramics-backend/internal/testutil/factory"
+	"jingdezhen-ceramics-backend/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+
+func ptr[T any](v T) *T { return &v }
+
+func TestRepositoryCreateAndFind(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, &models.User{Nickname: "potter", Email: "Potter@Example.com", Role: models.RoleNormalUser}, "hash")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID == "" || created.CreatedAt.IsZero() {
+		t.Fatalf("Create = %+v: want ID and timestamps set", created)
+	}
+
+	byID, err := repo.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if byID.Nickname != "potter" || byID.EmailVerified {
+		t.Errorf("FindByID = %+v: want an unverified potter", byID)
+	}
+	byEmail, err := repo.FindByEmail(ctx, "potter@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail is case-insensitive: %v", err)
+	}
+	if byEmail.ID != created.ID || byEmail.PasswordHash != "hash" {
+		t.Errorf("FindByEmail = %+v", byEmail)
+	}
+	byNickname, err := repo.FindByNickname(ctx, "potter")
+	if err != nil {
+		t.Fatalf("FindByNickname: %v", err)
+	}
+	if byNickname.ID != created.ID {
+		t.Errorf("FindByNickname id = %s, want %s", byNickname.ID, created.ID)
+	}
+
+	if _, err := repo.Create(ctx, &models.User{Nickname: "other", Email: "Potter@Example.com", Role: models.RoleNormalUser}, "hash"); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("Create with taken email: error = %v, want ErrConflict", err)
+	}
+}
+
+func TestRepositoryFindMissing(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.FindByID(ctx, "999999"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("FindByID: error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.FindByEmail(ctx, "nobody@example.com"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("FindByEmail: error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.FindByNickname(ctx, "nobody"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("FindByNickname: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRepositoryUpdate(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	u := factory.User(t, db)
+	ctx := context.Background()
+
+	got, err := repo.Update(ctx, u.ID, models.UserUpdateData{AvatarURL: ptr("https://example.com/a.png")})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got.AvatarURL != "https://example.com/a.png" || got.Nickname != u.Nickname || !got.EmailVerified {
+		t.Errorf("Update = %+v: want only the avatar changed", got)
+	}
+
+	if _, err := repo.Update(ctx, "999999", models.UserUpdateData{Nickname: ptr("ghost")}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Update of a missing user: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRepositoryUpdateRole(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	admin := factory.User(t, db, func(u *models.User) { u.Role = models.RoleAdmin })
+	member := factory.User(t, db)
+	ctx := context.Background()
+
+	if _, err := repo.UpdateRole(ctx, admin.ID, admin.ID, models.RoleNormalUser); !errors.Is(err, models.ErrLastAdmin) {
+		t.Fatalf("demoting the last admin: error = %v, want ErrLastAdmin", err)
+	}
+
+	change, err := repo.UpdateRole(ctx, admin.ID, member.ID, models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("UpdateRole: %v", err)
+	}
+	if change.OldRole != models.RoleNormalUser || change.NewRole != models.RoleAdmin {
+		t.Errorf("UpdateRole change = %+v", change)
+	}
+	if change, err := repo.UpdateRole(ctx, admin.ID, member.ID, models.RoleAdmin); change != nil || err != nil {
+		t.Errorf("UpdateRole to the current role = (%+v, %v), want (nil, nil)", change, err)
+	}
+	if _, err := repo.UpdateRole(ctx, admin.ID, "999999", models.RoleAdmin); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("UpdateRole of a missing user: error = %v, want ErrNotFound", err)
+	}
+
+	changes, total, err := repo.ListRoleChanges(ctx, models.RoleChangeFilter{TargetUserID: member.ID, Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListRoleChanges: %v", err)
+	}
+	if total != 1 || len(changes) != 1 || changes[0].ActorNickname != admin.Nickname {
+		t.Errorf("ListRoleChanges = %+v (total %d)", changes, total)
+	}
+}
+
+func TestRepositoryUserNotes(t *testing.T) {
+	db := pgtest.NewDB(t)
+	repo := NewRepository(db)
+	owner := factory.User(t, db)
+	stranger := factory.User(t, db)
+	note := factory.Note(t, db, owner.ID)
+	ctx := context.Background()
+
+	got, err := repo.GetUserNoteByID(ctx, note.ID, owner.ID)
+	if err != nil {
+		t.Fatalf("GetUserNoteByID: %v", err)
+	}
+	if got.Title != note.Title || got.Content != note.Content {
+		t.Errorf("GetUserNoteByID = %+v, want %+v", got, note)
+	}
+	if _, err := repo.GetUserNoteByID(ctx, note.ID, stranger.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetUserNoteByID of another user's note: error = %v, want ErrNotFound", err)
+	}
+
+	// One note per user and entity
+	_, err = repo.CreateUserNote(ctx, owner.ID, models.CreateUserNoteData{
+		Title: "Again", Content: "Same artwork", EntityType: note.EntityType, EntityID: note.EntityID,
+	})
+	if !errors.Is(err, models.ErrConflict) {
+		t.Errorf("CreateUserNote for the same entity: error = %v, want ErrConflict", err)
+	}
+
+	updated, err := repo.UpdateUserNote(ctx, note.ID, owner.ID, models.UpdateUserNoteData{Content: ptr("Revised")})
+	if err != nil {
+		t.Fatalf("UpdateUserNote: %v", err)
+	}
+	if updated.Content != "Revised" || updated.Title != note.Title {
+		t.Errorf("UpdateUserNote = %+v: want only the content changed", updated)
+	}
+
+	notes, total, err := repo.ListUserNotes(ctx, owner.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("ListUserNotes: %v", err)
+	}
+	if total != 1 || len(notes) != 1 || notes[0].ID != note.ID {
+		t.Errorf("ListUserNotes = %+v (total %d)", notes, total)
+	}
+
+	if err := repo.DeleteUserNote(ctx, note.ID, stranger.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DeleteUserNote by another user: error = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteUserNote(ctx, note.ID, owner.ID); err != nil {
+		t.Errorf("DeleteUserNote: %v", err)
+	}
+}
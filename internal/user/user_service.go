@@ -4,18 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"jingdezhen-ceramics-backend/internal/audit"
 	"jingdezhen-ceramics-backend/internal/forum" // For publishing notes
 	"jingdezhen-ceramics-backend/internal/models"
-	"jingdezhen-ceramics-backend/pkg/email"
 	// "golang.org/x/crypto/bcrypt" // If handling password hashing here
-	"log" // For contact form simulation
+	"log"
 )
 
 // ServiceInterface defines methods for user business logic.
 type ServiceInterface interface {
 	GetUserProfile(ctx context.Context, userID string) (*models.User, error)
 	UpdateUserProfile(ctx context.Context, userID string, data models.UserUpdateData) (*models.User, error)
-	HandleContactSubmission(ctx context.Context, data models.ContactFormData) error
 
 	// User Notes
 	ListUserNotes(ctx context.Context, userID string, page, limit int) ([]models.UserNote, int, error)
@@ -38,29 +37,38 @@ type ServiceInterface interface {
 
 	// Admin
 	AdminListUsers(ctx context.Context, page, limit int) ([]models.User, int, error)
-	AdminUpdateUserRole(ctx context.Context, targetUserID string, newRole string) error
+	// AdminUpdateUserRole changes the role of targetUserID and returns the audit entry, or nil if the
+	// user already had newRole. A change ends all sessions of the user. Errors: models.ErrInvalidRole, models.ErrNotFound, models.ErrForbidden
+	// (only an admin may grant or remove admin), models.ErrLastAdmin.
+	AdminUpdateUserRole(ctx context.Context, actorID, targetUserID string, newRole string) (*models.RoleChange, error)
+	AdminListRoleChanges(ctx context.Context, filter models.RoleChangeFilter) ([]models.RoleChange, int, error)
+}
+
+// SessionRevoker ends every session of a user. auth.RepositoryInterface implements it.
+type SessionRevoker interface {
+	RevokeAllForUser(ctx context.Context, userID string) error
 }
 
 type Service struct {
 	userRepo RepositoryInterface
 	// For simplicity, userNote specific methods are on RepositoryInterface for now.
 	// In a larger system, userNoteRepo might be a separate RepositoryInterface.
-	forumSvc   forum.ServiceInterface // Injected for publishing notes
-	emailSvc   email.ServiceInterface // For sending contact emails
-	adminEmail string
+	forumSvc forum.ServiceInterface // Injected for publishing notes
+	auditLog audit.Recorder         // Role changes
+	sessions SessionRevoker         // Ended on a role change so old tokens stop carrying the old role
 }
 
 func NewService(
 	userRepo RepositoryInterface,
 	forumSvc forum.ServiceInterface,
-	emailSvc email.ServiceInterface,
-	adminEmailFromConfig string,
+	auditLog audit.Recorder,
+	sessions SessionRevoker,
 ) ServiceInterface {
 	return &Service{
-		userRepo:   userRepo,
-		forumSvc:   forumSvc,
-		emailSvc:   emailSvc,
-		adminEmail: adminEmailFromConfig,
+		userRepo: userRepo,
+		forumSvc: forumSvc,
+		auditLog: auditLog,
+		sessions: sessions,
 	}
 }
 
@@ -92,30 +100,6 @@ func (s *Service) UpdateUserProfile(ctx context.Context, userID string, data mod
 	return updatedUser, nil
 }
 
-func (s *Service) HandleContactSubmission(ctx context.Context, data models.ContactFormData) error {
-	// 1. Sanitize inputs
-	log.Printf("Contact Form Submitted: Name: %s, Email: %s, Subject: %s, Message: %s",
-		data.Name, data.Email, data.Subject, data.Message)
-
-	adminEmail := "admin@yourplatform.com" // Get from config
-	emailSubject := fmt.Sprintf("New Contact Form Submission: %s", data.Subject)
-	emailBody := fmt.Sprintf(
-		"You have received a new message from the contact form:\n\nName: %s\nEmail: %s\nSubject: %s\n\nMessage:\n%s",
-		data.Name, data.Email, data.Subject, data.Message,
-	)
-
-	// 2. Send an email to the admin using an email service
-	err := s.emailSvc.SendEmail(ctx, adminEmail, emailSubject, emailBody)
-	if err != nil {
-		log.Printf("ERROR sending contact email: %v", err)
-		// Decide if this should be a user-facing error or just logged
-		return fmt.Errorf("failed to send contact message: %w", err)
-	}
-	log.Printf("SIMULATED: Email sent to %s, Subject: %s", adminEmail, emailSubject)
-
-	return nil // Simulate success
-}
-
 // --- User Notes ---
 func (s *Service) ListUserNotes(ctx context.Context, userID string, page, limit int) ([]models.UserNote, int, error) {
 	if page < 1 {
@@ -283,16 +267,34 @@ func (s *Service) AdminListUsers(ctx context.Context, page, limit int) ([]models
 	return s.userRepo.ListAll(ctx, page, limit)
 }
 
-func (s *Service) AdminUpdateUserRole(ctx context.Context, targetUserID string, newRole string) error {
-	// Add validation for newRole if it's not a predefined valid role
-	if newRole != models.RoleAdmin && newRole != models.RoleNormalUser {
-		return fmt.Errorf("service.AdminUpdateUserRole: invalid role '%s'", newRole)
-	}
-	// Check if targetUserID exists
-	_, err := s.userRepo.FindByID(ctx, targetUserID)
+func (s *Service) AdminUpdateUserRole(ctx context.Context, actorID, targetUserID string, newRole string) (*models.RoleChange, error) {
+	change, err := s.userRepo.UpdateRole(ctx, actorID, targetUserID, newRole)
 	if err != nil {
-		return fmt.Errorf("service.AdminUpdateUserRole: target user not found: %w", err)
+		return nil, fmt.Errorf("service.AdminUpdateUserRole: %w", err)
+	}
+	if change != nil {
+		s.auditLog.Record(ctx, audit.Event{
+			ActorID: actorID, Action: audit.ActionUserRoleChange, EntityType: audit.EntityUser, EntityID: targetUserID,
+			Before: map[string]string{"role": change.OldRole}, After: map[string]string{"role": change.NewRole},
+		})
+		// Access tokens carry the role, so the user has to log in again to pick up the new one
+		if err := s.sessions.RevokeAllForUser(ctx, targetUserID); err != nil {
+			return nil, fmt.Errorf("service.AdminUpdateUserRole: role changed but sessions not revoked: %w", err)
+		}
 	}
+	return change, nil
+}
 
-	return s.userRepo.UpdateRole(ctx, targetUserID, newRole)
+func (s *Service) AdminListRoleChanges(ctx context.Context, filter models.RoleChangeFilter) ([]models.RoleChange, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	changes, total, err := s.userRepo.ListRoleChanges(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service.AdminListRoleChanges: %w", err)
+	}
+	return changes, total, nil
 }
@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"jingdezhen-ceramics-backend/internal/audit"
+	"jingdezhen-ceramics-backend/internal/models"
+)
+
+type recordedEvents []audit.Event
+
+func (r *recordedEvents) Record(ctx context.Context, event audit.Event) { *r = append(*r, event) }
+
+// revokedSessions records the users whose sessions were revoked.
+type revokedSessions []string
+
+func (r *revokedSessions) RevokeAllForUser(ctx context.Context, userID string) error {
+	*r = append(*r, userID)
+	return nil
+}
+
+func TestServiceUpdateUserProfileNicknameTaken(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	svc := NewService(repo, nil, &recordedEvents{}, &revokedSessions{})
+	potter, _ := repo.Create(ctx, &models.User{Nickname: "potter", Email: "potter@example.com", Role: models.RoleNormalUser}, "")
+	glazer, _ := repo.Create(ctx, &models.User{Nickname: "glazer", Email: "glazer@example.com", Role: models.RoleNormalUser}, "")
+
+	if _, err := svc.UpdateUserProfile(ctx, glazer.ID, models.UserUpdateData{Nickname: ptr("potter")}); !errors.Is(err, models.ErrNicknameTaken) {
+		t.Errorf("taking another user's nickname: error = %v, want ErrNicknameTaken", err)
+	}
+	// Keeping one's own nickname is fine
+	if _, err := svc.UpdateUserProfile(ctx, potter.ID, models.UserUpdateData{Nickname: ptr("potter")}); err != nil {
+		t.Errorf("keeping the own nickname: %v", err)
+	}
+	if _, err := svc.UpdateUserProfile(ctx, "42", models.UserUpdateData{Nickname: ptr("ghost")}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("missing user: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestServiceAdminUpdateUserRole(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	events, revoked := &recordedEvents{}, &revokedSessions{}
+	svc := NewService(repo, nil, events, revoked)
+	admin, _ := repo.Create(ctx, &models.User{Nickname: "admin", Email: "admin@example.com", Role: models.RoleAdmin}, "")
+	member, _ := repo.Create(ctx, &models.User{Nickname: "member", Email: "member@example.com", Role: models.RoleNormalUser}, "")
+
+	if _, err := svc.AdminUpdateUserRole(ctx, admin.ID, member.ID, "superuser"); !errors.Is(err, models.ErrInvalidRole) {
+		t.Errorf("unknown role: error = %v, want ErrInvalidRole", err)
+	}
+	if _, err := svc.AdminUpdateUserRole(ctx, admin.ID, admin.ID, models.RoleNormalUser); !errors.Is(err, models.ErrLastAdmin) {
+		t.Errorf("demoting the last admin: error = %v, want ErrLastAdmin", err)
+	}
+	moderator, _ := repo.Create(ctx, &models.User{Nickname: "moderator", Email: "moderator@example.com", Role: models.RoleModerator}, "")
+	if _, err := svc.AdminUpdateUserRole(ctx, moderator.ID, member.ID, models.RoleAdmin); !errors.Is(err, models.ErrForbidden) {
+		t.Errorf("non-admin granting admin: error = %v, want ErrForbidden", err)
+	}
+	// Roles come from the roles table, so one added there can be assigned without a code change
+	repo.AddRole("glaze_expert")
+	if _, err := svc.AdminUpdateUserRole(ctx, admin.ID, member.ID, "glaze_expert"); err != nil {
+		t.Fatalf("AdminUpdateUserRole to a new role: %v", err)
+	}
+	if _, err := svc.AdminUpdateUserRole(ctx, admin.ID, member.ID, models.RoleModerator); err != nil {
+		t.Fatalf("AdminUpdateUserRole: %v", err)
+	}
+	// A no-op change is not audited
+	if _, err := svc.AdminUpdateUserRole(ctx, admin.ID, member.ID, models.RoleModerator); err != nil {
+		t.Fatalf("AdminUpdateUserRole to the current role: %v", err)
+	}
+
+	if len(*events) != 2 || (*events)[1].Action != audit.ActionUserRoleChange || (*events)[1].EntityID != member.ID {
+		t.Errorf("audit events = %+v, want two role changes of the member", *events)
+	}
+	// Every actual change ends the member's sessions; rejected and no-op changes do not
+	if want := []string{member.ID, member.ID}; !slices.Equal(*revoked, want) {
+		t.Errorf("revoked sessions = %v, want %v", *revoked, want)
+	}
+}
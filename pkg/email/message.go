@@ -0,0 +1,238 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message is an outgoing email. At least one of TextBody and HTMLBody must be set;
+// with both, clients pick the best part of a multipart/alternative body.
+type Message struct {
+	To          []string
+	Cc          []string
+	Bcc         []string // Receives the message but never appears in its headers
+	ReplyTo     string
+	Subject     string // May contain any UTF-8, e.g. Chinese dynasty names
+	TextBody    string
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent with a Message. ContentType defaults to application/octet-stream.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Recipients returns every envelope recipient (To, Cc and Bcc).
+func (m *Message) Recipients() []string {
+	all := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	all = append(all, m.To...)
+	all = append(all, m.Cc...)
+	return append(all, m.Bcc...)
+}
+
+// Validate checks the message can be built and delivered.
+func (m *Message) Validate() error {
+	if len(m.Recipients()) == 0 {
+		return errors.New("email: message has no recipients")
+	}
+	if m.TextBody == "" && m.HTMLBody == "" {
+		return errors.New("email: message has no body")
+	}
+	for _, addr := range m.Recipients() {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("email: invalid recipient %q: %w", addr, err)
+		}
+	}
+	if m.ReplyTo != "" {
+		if _, err := mail.ParseAddress(m.ReplyTo); err != nil {
+			return fmt.Errorf("email: invalid reply-to %q: %w", m.ReplyTo, err)
+		}
+	}
+	return nil
+}
+
+// Build renders the message as RFC 5322 bytes with MIME bodies, ready for the SMTP DATA command.
+// Plain messages are a single part, messages with both bodies are multipart/alternative and
+// messages with attachments wrap that in multipart/mixed.
+func (m *Message) Build(from string, now time.Time) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("email: invalid sender %q: %w", from, err)
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", fromAddr.String())
+	if len(m.To) > 0 {
+		writeHeader(&buf, "To", formatAddressList(m.To))
+	}
+	if len(m.Cc) > 0 {
+		writeHeader(&buf, "Cc", formatAddressList(m.Cc))
+	}
+	if m.ReplyTo != "" {
+		writeHeader(&buf, "Reply-To", formatAddressList([]string{m.ReplyTo}))
+	}
+	writeHeader(&buf, "Subject", mime.BEncoding.Encode("UTF-8", m.Subject))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", newMessageID(fromAddr.Address))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if len(m.Attachments) == 0 {
+		if err := m.writeBody(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	var body bytes.Buffer
+	if err := m.writeBody(&body); err != nil {
+		return nil, err
+	}
+	bodyHeader, bodyContent := splitPart(body.Bytes())
+	part, err := mixed.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(bodyContent); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range m.Attachments {
+		if err := writeAttachment(mixed, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the Content-Type header, a blank line and the text and/or HTML body.
+func (m *Message) writeBody(buf *bytes.Buffer) error {
+	if m.TextBody == "" || m.HTMLBody == "" {
+		contentType, body := "text/plain; charset=UTF-8", m.TextBody
+		if m.TextBody == "" {
+			contentType, body = "text/html; charset=UTF-8", m.HTMLBody
+		}
+		writeHeader(buf, "Content-Type", contentType)
+		writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		return writeQuotedPrintable(buf, body)
+	}
+
+	alternative := multipart.NewWriter(buf)
+	writeHeader(buf, "Content-Type", "multipart/alternative; boundary="+alternative.Boundary())
+	buf.WriteString("\r\n")
+	for _, p := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", m.TextBody}, // Least preferred part first (RFC 2046)
+		{"text/html; charset=UTF-8", m.HTMLBody},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", p.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		part, err := alternative.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if err := writeQuotedPrintable(part, p.body); err != nil {
+			return err
+		}
+	}
+	return alternative.Close()
+}
+
+func writeAttachment(w *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	filename := mime.BEncoding.Encode("UTF-8", attachment.Filename)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", fmt.Sprintf("%s; name=%q", contentType, filename))
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	header.Set("Content-Transfer-Encoding", "base64")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > 76 { // RFC 2045 line length
+		if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = part.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// splitPart separates the headers written by writeBody from the content that follows the blank line.
+func splitPart(raw []byte) (textproto.MIMEHeader, []byte) {
+	header := textproto.MIMEHeader{}
+	headerBlock, content, _ := bytes.Cut(raw, []byte("\r\n\r\n"))
+	for _, line := range strings.Split(string(headerBlock), "\r\n") {
+		if name, value, ok := strings.Cut(line, ": "); ok {
+			header.Set(name, value)
+		}
+	}
+	return header, content
+}
+
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+// formatAddressList re-encodes addresses so display names with non-ASCII characters are RFC 2047 encoded.
+// Callers validate first, so unparsable entries cannot reach here.
+func formatAddressList(addrs []string) string {
+	formatted := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			continue
+		}
+		formatted = append(formatted, parsed.String())
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func newMessageID(fromAddress string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(fromAddress, "@"); ok && d != "" {
+		domain = d
+	}
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf) // crypto/rand.Read never returns an error on supported platforms
+	return "<" + hex.EncodeToString(buf) + "@" + domain + ">"
+}
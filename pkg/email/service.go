@@ -3,5 +3,7 @@ package email
 import "context"
 
 type ServiceInterface interface {
+	// SendEmail is a shorthand for Send with only To, Subject and the two bodies set.
 	SendEmail(ctx context.Context, to []string, subject, htmlBody, textBody string) error
+	Send(ctx context.Context, msg *Message) error
 }
@@ -2,43 +2,169 @@ package email
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/mail"
 	"net/smtp"
-	// "jingdezhen-ceramics-backend/internal/config" // If SMTP settings are in config
+	"time"
+)
+
+// TLS modes for SMTPService, set through Config.SMTPTLSMode
+const (
+	TLSModeNone     = "none"     // Plain connection, only sensible for a local relay
+	TLSModeSTARTTLS = "starttls" // Upgrade after connecting, usually port 587. The default
+	TLSModeImplicit = "tls"      // TLS from the first byte, usually port 465
 )
 
 type SMTPService struct {
-	auth      smtp.Auth
 	smtpHost  string
 	smtpPort  string
+	user      string
+	password  string
 	fromEmail string
+	tlsMode   string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+}
+
+// SMTPOption customizes an SMTPService, mostly so tests can point it at smtptest.Server.
+type SMTPOption func(*SMTPService)
+
+// WithTLSConfig replaces the default TLS config (which verifies the server certificate against smtpHost).
+func WithTLSConfig(cfg *tls.Config) SMTPOption {
+	return func(s *SMTPService) { s.tlsConfig = cfg }
 }
 
-// NewSMTPService creates a basic SMTP email sender
-// In a real app, smtpHost, port, user, pass, fromEmail come from config
-func NewSMTPService(host, port, user, password, from string) ServiceInterface {
-	auth := smtp.PlainAuth("", user, password, host)
-	return &SMTPService{
-		auth:      auth,
+// WithTimeout bounds a whole delivery when ctx has no earlier deadline. Defaults to 30 seconds.
+func WithTimeout(timeout time.Duration) SMTPOption {
+	return func(s *SMTPService) { s.timeout = timeout }
+}
+
+// NewSMTPService creates an SMTP email sender. An empty tlsMode means TLSModeSTARTTLS.
+// Authentication is skipped when user is empty.
+func NewSMTPService(host, port, user, password, from, tlsMode string, opts ...SMTPOption) *SMTPService {
+	if tlsMode == "" {
+		tlsMode = TLSModeSTARTTLS
+	}
+	s := &SMTPService{
 		smtpHost:  host,
 		smtpPort:  port,
+		user:      user,
+		password:  password,
 		fromEmail: from,
+		tlsMode:   tlsMode,
+		tlsConfig: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12},
+		timeout:   30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *SMTPService) SendEmail(ctx context.Context, to []string, subject, htmlBody, textBody string) error {
-	// For HTML emails, you need to set MIME headers
-	// This is a simplified text-only example
-	msg := []byte("To: " + to[0] + "\r\n" + // Simplified for one recipient
-		"From: " + s.fromEmail + "\r\n" +
-		"Subject: " + subject + "\r\n" +
-		"\r\n" +
-		textBody + "\r\n")
-
-	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
-	err := smtp.SendMail(addr, s.auth, s.fromEmail, to, msg)
+	return s.Send(ctx, &Message{To: to, Subject: subject, HTMLBody: htmlBody, TextBody: textBody})
+}
+
+func (s *SMTPService) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Build(s.fromEmail, time.Now())
 	if err != nil {
-		return fmt.Errorf("smtp.SendMail failed: %w", err)
+		return err
+	}
+	sender, err := mail.ParseAddress(s.fromEmail)
+	if err != nil {
+		return fmt.Errorf("email: invalid sender %q: %w", s.fromEmail, err)
+	}
+
+	if _, ok := ctx.Deadline(); !ok && s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("smtp connect failed: %w", err)
+	}
+	defer client.Close()
+
+	if err := s.deliver(client, sender.Address, msg.Recipients(), data); err != nil {
+		return fmt.Errorf("smtp send failed: %w", err)
 	}
 	return nil
 }
+
+// dial connects and, depending on tlsMode, negotiates TLS. The connection deadline follows ctx.
+func (s *SMTPService) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.smtpHost, s.smtpPort)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if s.tlsMode == TLSModeImplicit {
+		tlsConn := tls.Client(conn, s.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, s.smtpHost)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if s.tlsMode == TLSModeSTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("server %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func (s *SMTPService) deliver(client *smtp.Client, from string, recipients []string, data []byte) error {
+	if s.user != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", s.user, s.password, s.smtpHost)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		addr, err := mail.ParseAddress(rcpt)
+		if err != nil {
+			return err
+		}
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,257 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"jingdezhen-ceramics-backend/pkg/email/smtptest"
+)
+
+const testSender = "Jingdezhen Ceramics <noreply@example.com>"
+
+func newServer(t *testing.T, opts ...smtptest.Option) *smtptest.Server {
+	t.Helper()
+	server, err := smtptest.NewServer(opts...)
+	if err != nil {
+		t.Fatalf("smtptest.NewServer: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// selfSignedTLS returns a server config for 127.0.0.1 and a client config that trusts it.
+func selfSignedTLS(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "smtptest"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: pool, ServerName: "127.0.0.1", MinVersion: tls.VersionTLS12}
+	return server, client
+}
+
+// onlyMessage returns the single message server received.
+func onlyMessage(t *testing.T, server *smtptest.Server) smtptest.Message {
+	t.Helper()
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(messages))
+	}
+	return messages[0]
+}
+
+// part is a MIME part with its content read; quoted-printable is already decoded by multipart.Reader.
+type part struct {
+	header  textproto.MIMEHeader
+	content []byte
+}
+
+// readParts reads the parts of a multipart body with the given Content-Type header.
+func readParts(t *testing.T, contentType string, body io.Reader) []part {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		t.Fatalf("Content-Type %q is not multipart (%v)", contentType, err)
+	}
+	var parts []part
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		content, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		parts = append(parts, part{header: p.Header, content: content})
+	}
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSMTPServiceSend(t *testing.T) {
+	server := newServer(t)
+	svc := NewSMTPService(server.Host, server.Port, "", "", testSender, TLSModeNone)
+
+	subject := "明代青花瓷 — your visit to Jingdezhen"
+	msg := &Message{
+		To:       []string{"Li Wei <li@example.com>"},
+		Cc:       []string{"curator@example.com"},
+		Bcc:      []string{"archive@example.com"},
+		ReplyTo:  "contact@example.com",
+		Subject:  subject,
+		TextBody: "Welcome to the kiln tour.",
+		HTMLBody: "<p>Welcome to the <b>kiln</b> tour.</p>",
+		Attachments: []Attachment{
+			{Filename: "路线.pdf", ContentType: "application/pdf", Data: bytes.Repeat([]byte("%PDF-1.7 "), 20)},
+		},
+	}
+	if err := svc.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	received := onlyMessage(t, server)
+	if received.From != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %q", received.From)
+	}
+	wantRcpt := []string{"li@example.com", "curator@example.com", "archive@example.com"}
+	if !reflect.DeepEqual(received.To, wantRcpt) {
+		t.Errorf("RCPT TO = %v, want %v", received.To, wantRcpt)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(received.Data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v\n%s", err, received.Data)
+	}
+	headerBlock, _, _ := bytes.Cut(received.Data, []byte("\r\n\r\n"))
+	if parsed.Header.Get("Bcc") != "" || bytes.Contains(headerBlock, []byte("archive@example.com")) {
+		t.Errorf("Bcc recipient leaked into the headers:\n%s", headerBlock)
+	}
+	if got, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); err != nil || got != subject {
+		t.Errorf("Subject = %q (%v), want %q", got, err, subject)
+	}
+	if !isASCII(headerBlock) {
+		t.Errorf("headers contain raw non-ASCII bytes:\n%s", headerBlock)
+	}
+	if parsed.Header.Get("Reply-To") != "<contact@example.com>" || parsed.Header.Get("Message-Id") == "" {
+		t.Errorf("Reply-To = %q, Message-Id = %q", parsed.Header.Get("Reply-To"), parsed.Header.Get("Message-Id"))
+	}
+
+	mixed := readParts(t, parsed.Header.Get("Content-Type"), parsed.Body)
+	if len(mixed) != 2 {
+		t.Fatalf("multipart/mixed has %d parts, want body and attachment", len(mixed))
+	}
+
+	alternative := readParts(t, mixed[0].header.Get("Content-Type"), bytes.NewReader(mixed[0].content))
+	if len(alternative) != 2 {
+		t.Fatalf("multipart/alternative has %d parts, want 2", len(alternative))
+	}
+	for i, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", msg.TextBody},
+		{"text/html; charset=UTF-8", msg.HTMLBody},
+	} {
+		if got := alternative[i].header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("alternative part %d Content-Type = %q, want %q", i, got, want.contentType)
+		}
+		if got := string(alternative[i].content); got != want.body {
+			t.Errorf("alternative part %d body = %q, want %q", i, got, want.body)
+		}
+	}
+
+	attachment := mixed[1]
+	_, params, err := mime.ParseMediaType(attachment.header.Get("Content-Disposition"))
+	if err != nil {
+		t.Fatalf("Content-Disposition: %v", err)
+	}
+	if filename, _ := new(mime.WordDecoder).DecodeHeader(params["filename"]); filename != "路线.pdf" {
+		t.Errorf("attachment filename = %q", filename)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(attachment.content), "\r\n", ""))
+	if err != nil || !bytes.Equal(data, msg.Attachments[0].Data) {
+		t.Errorf("attachment data = %q (%v), want %q", data, err, msg.Attachments[0].Data)
+	}
+}
+
+func TestSMTPServiceSendSingleBody(t *testing.T) {
+	server := newServer(t)
+	svc := NewSMTPService(server.Host, server.Port, "", "", testSender, TLSModeNone)
+
+	if err := svc.SendEmail(context.Background(), []string{"li@example.com"}, "Hello", "", "Plain text only"); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(onlyMessage(t, server).Data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if got := parsed.Header.Get("Content-Type"); got != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type = %q, want a single text/plain body", got)
+	}
+}
+
+func TestSMTPServiceTLSModes(t *testing.T) {
+	serverTLS, clientTLS := selfSignedTLS(t)
+	tests := []struct {
+		name    string
+		option  smtptest.Option
+		tlsMode string
+	}{
+		{"starttls", smtptest.WithSTARTTLS(serverTLS), TLSModeSTARTTLS},
+		{"implicit", smtptest.WithImplicitTLS(serverTLS), TLSModeImplicit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, tt.option)
+			svc := NewSMTPService(server.Host, server.Port, "mailer", "secret", testSender, tt.tlsMode, WithTLSConfig(clientTLS))
+
+			if err := svc.SendEmail(context.Background(), []string{"li@example.com"}, "Hello", "<p>Hi</p>", "Hi"); err != nil {
+				t.Fatalf("SendEmail: %v", err)
+			}
+			received := onlyMessage(t, server)
+			if !received.TLS || received.AuthUser != "mailer" {
+				t.Errorf("TLS = %v, AuthUser = %q, want an authenticated TLS session", received.TLS, received.AuthUser)
+			}
+		})
+	}
+
+	t.Run("starttls not offered", func(t *testing.T) {
+		server := newServer(t)
+		svc := NewSMTPService(server.Host, server.Port, "mailer", "secret", testSender, TLSModeSTARTTLS, WithTLSConfig(clientTLS))
+
+		err := svc.SendEmail(context.Background(), []string{"li@example.com"}, "Hello", "", "Hi")
+		if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+			t.Errorf("error = %v, want a missing STARTTLS error", err)
+		}
+		if n := len(server.Messages()); n != 0 {
+			t.Errorf("server received %d messages in plain text", n)
+		}
+	})
+}
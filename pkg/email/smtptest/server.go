@@ -0,0 +1,212 @@
+// Package smtptest provides an in-process SMTP server for exercising email.SMTPService
+// without a real mail relay, in the spirit of net/http/httptest.
+package smtptest
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Message is one mail transaction accepted by the server.
+type Message struct {
+	From     string   // MAIL FROM address
+	To       []string // RCPT TO addresses, including Bcc recipients
+	Data     []byte   // Raw message as sent after DATA, dot-unstuffed
+	AuthUser string   // Username from AUTH PLAIN, empty if the client did not authenticate
+	TLS      bool     // Whether the transaction happened over TLS
+}
+
+// Server accepts SMTP connections on 127.0.0.1 and records every delivered message.
+type Server struct {
+	Host string
+	Port string
+
+	listener    net.Listener
+	starttls    *tls.Config
+	implicitTLS bool
+
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithSTARTTLS advertises STARTTLS and upgrades with cfg when the client asks.
+func WithSTARTTLS(cfg *tls.Config) Option {
+	return func(s *Server) { s.starttls = cfg }
+}
+
+// WithImplicitTLS makes the listener speak TLS from the first byte.
+func WithImplicitTLS(cfg *tls.Config) Option {
+	return func(s *Server) {
+		s.starttls = cfg
+		s.implicitTLS = true
+	}
+}
+
+// NewServer starts a server on a random local port. Call Close when done.
+func NewServer(opts ...Option) (*Server, error) {
+	s := &Server{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	if s.implicitTLS {
+		listener = tls.NewListener(listener, s.starttls)
+	}
+	s.listener = listener
+	s.Host, s.Port, _ = net.SplitHostPort(listener.Addr().String())
+
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Messages returns a copy of the messages received so far.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Close stops accepting connections and waits for open sessions to end.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // Listener closed
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle runs one SMTP session. It implements only what net/smtp.Client uses.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	isTLS := s.implicitTLS
+	var current Message
+	var authUser string
+
+	_ = tp.PrintfLine("220 smtptest ESMTP ready")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			lines := []string{"smtptest greets you", "8BITMIME", "AUTH PLAIN"}
+			if s.starttls != nil && !isTLS {
+				lines = append(lines, "STARTTLS")
+			}
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				_ = tp.PrintfLine("250%s%s", sep, l)
+			}
+		case "STARTTLS":
+			if s.starttls == nil || isTLS {
+				_ = tp.PrintfLine("502 STARTTLS not available")
+				continue
+			}
+			_ = tp.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.starttls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(tlsConn)
+			isTLS = true
+			current = Message{}
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mechanism, "PLAIN") {
+				_ = tp.PrintfLine("504 Unrecognized authentication type")
+				continue
+			}
+			if initial == "" {
+				_ = tp.PrintfLine("334 ")
+				if initial, err = tp.ReadLine(); err != nil {
+					return
+				}
+			}
+			authUser = parsePlainAuth(initial)
+			_ = tp.PrintfLine("235 Authentication successful")
+		case "MAIL":
+			current = Message{From: trimPath(arg, "FROM:"), AuthUser: authUser, TLS: isTLS}
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			current.To = append(current.To, trimPath(arg, "TO:"))
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			current = Message{}
+			_ = tp.PrintfLine("250 OK: queued")
+		case "RSET":
+			current = Message{}
+			_ = tp.PrintfLine("250 OK")
+		case "NOOP":
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// trimPath turns "FROM:<a@b.c> SIZE=10" into "a@b.c".
+func trimPath(arg, prefix string) string {
+	if len(arg) >= len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+		arg = arg[len(prefix):]
+	}
+	arg, _, _ = strings.Cut(strings.TrimSpace(arg), " ")
+	return strings.Trim(arg, "<>")
+}
+
+// parsePlainAuth extracts the username from a base64 "\x00user\x00password" response.
+func parsePlainAuth(encoded string) string {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(string(decoded), "\x00")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,337 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Names of the built-in templates
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+	TemplateContactForm   = "contact_form"
+	TemplateContactReply  = "contact_reply"
+)
+
+// DefaultLocale is used when no supported locale matches. Every template must exist in it.
+const DefaultLocale = "en"
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// TemplateData holds the variables of one template. Every key listed in the definition's Required must be set.
+type TemplateData map[string]any
+
+// TemplateDefinition declares a template and the variables it needs. Sample is used for previews
+// and for the startup validation.
+type TemplateDefinition struct {
+	Name     string
+	Required []string
+	Sample   TemplateData
+}
+
+// DefaultTemplateDefinitions are the templates shipped in pkg/email/templates.
+var DefaultTemplateDefinitions = []TemplateDefinition{
+	{
+		Name:     TemplateVerifyEmail,
+		Required: []string{"Nickname", "Link", "ExpiresInHours"},
+		Sample:   TemplateData{"Nickname": "青花", "Link": "https://example.com/verify-email?token=sample", "ExpiresInHours": 24},
+	},
+	{
+		Name:     TemplateResetPassword,
+		Required: []string{"Nickname", "Link", "ExpiresInHours"},
+		Sample:   TemplateData{"Nickname": "青花", "Link": "https://example.com/reset-password?token=sample", "ExpiresInHours": 1},
+	},
+	{
+		Name:     TemplateContactForm,
+		Required: []string{"Name", "Email", "Subject", "Message"},
+		Sample: TemplateData{
+			"Name": "Li Wei", "Email": "li.wei@example.com", "Subject": "明代青花瓷 workshop",
+			"Message": "Hello,\nis the Ming blue-and-white workshop open to beginners?",
+		},
+	},
+	{
+		Name:     TemplateContactReply,
+		Required: []string{"Name", "Subject", "Reply", "OriginalMessage"},
+		Sample: TemplateData{
+			"Name": "Li Wei", "Subject": "Re: 明代青花瓷 workshop",
+			"Reply":           "Hi Li Wei,\nyes, no prior experience is needed.",
+			"OriginalMessage": "Hello,\nis the Ming blue-and-white workshop open to beginners?",
+		},
+	},
+}
+
+// Rendered is a template rendered for one locale.
+type Rendered struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// TemplateInfo describes a registered template for the admin preview listing.
+type TemplateInfo struct {
+	Name     string   `json:"name"`
+	Required []string `json:"required"`
+	Locales  []string `json:"locales"`
+}
+
+type localizedTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template // Also defines "subject"
+}
+
+// Registry holds parsed html/template and text/template pairs per template and locale.
+//
+// Files live at <locale>/<name>.html.tmpl and <locale>/<name>.txt.tmpl. Each text file defines
+// "subject" and "content", each HTML file defines "content"; both are rendered inside
+// layouts/base.{html,txt}.tmpl, whose blocks (such as "footer") a locale can override in
+// <locale>/partials.{html,txt}.tmpl.
+type Registry struct {
+	definitions map[string]TemplateDefinition
+	templates   map[string]map[string]localizedTemplate // name -> locale -> pair
+	locales     []string
+}
+
+// NewDefaultRegistry loads the embedded templates with DefaultTemplateDefinitions.
+func NewDefaultRegistry() (*Registry, error) {
+	sub, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(sub, DefaultTemplateDefinitions)
+}
+
+// NewRegistry parses and validates every definition in every locale directory of fsys. It fails if a
+// template is missing in DefaultLocale, uses a variable not listed in Required, or never uses one
+// that is listed, so template mistakes stop the server at startup instead of surfacing in production mail.
+func NewRegistry(fsys fs.FS, definitions []TemplateDefinition) (*Registry, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("email templates: %w", err)
+	}
+	r := &Registry{
+		definitions: make(map[string]TemplateDefinition, len(definitions)),
+		templates:   make(map[string]map[string]localizedTemplate, len(definitions)),
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != "layouts" {
+			r.locales = append(r.locales, entry.Name())
+		}
+	}
+	if !slices.Contains(r.locales, DefaultLocale) {
+		// Checked like any other locale so every template is reported missing from it
+		r.locales = append(r.locales, DefaultLocale)
+	}
+	sort.Strings(r.locales)
+
+	var problems []error
+	for _, def := range definitions {
+		r.definitions[def.Name] = def
+		r.templates[def.Name] = map[string]localizedTemplate{}
+		for _, locale := range r.locales {
+			tmpl, found, err := parseLocalized(fsys, locale, def.Name)
+			if err != nil {
+				problems = append(problems, err)
+				continue
+			}
+			if !found {
+				if locale == DefaultLocale {
+					problems = append(problems, fmt.Errorf("%s: missing in default locale %s", def.Name, DefaultLocale))
+				}
+				continue
+			}
+			if err := validateVariables(tmpl, def, locale); err != nil {
+				problems = append(problems, err)
+				continue
+			}
+			r.templates[def.Name][locale] = tmpl
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("email templates: %w", errors.Join(problems...))
+	}
+	return r, nil
+}
+
+// parseLocalized builds the layout + partials + template pair. found is false when the locale has no such template.
+func parseLocalized(fsys fs.FS, locale, name string) (localizedTemplate, bool, error) {
+	htmlFile, textFile := path.Join(locale, name+".html.tmpl"), path.Join(locale, name+".txt.tmpl")
+	if _, err := fs.Stat(fsys, textFile); err != nil {
+		return localizedTemplate{}, false, nil
+	}
+
+	htmlFiles := []string{"layouts/base.html.tmpl"}
+	textFiles := []string{"layouts/base.txt.tmpl"}
+	if _, err := fs.Stat(fsys, path.Join(locale, "partials.html.tmpl")); err == nil {
+		htmlFiles = append(htmlFiles, path.Join(locale, "partials.html.tmpl"))
+	}
+	if _, err := fs.Stat(fsys, path.Join(locale, "partials.txt.tmpl")); err == nil {
+		textFiles = append(textFiles, path.Join(locale, "partials.txt.tmpl"))
+	}
+
+	html, err := htmltemplate.New(name).Option("missingkey=error").ParseFS(fsys, append(htmlFiles, htmlFile)...)
+	if err != nil {
+		return localizedTemplate{}, false, fmt.Errorf("%s/%s: %w", locale, name, err)
+	}
+	text, err := texttemplate.New(name).Option("missingkey=error").ParseFS(fsys, append(textFiles, textFile)...)
+	if err != nil {
+		return localizedTemplate{}, false, fmt.Errorf("%s/%s: %w", locale, name, err)
+	}
+	if text.Lookup("subject") == nil {
+		return localizedTemplate{}, false, fmt.Errorf("%s/%s: text template does not define \"subject\"", locale, name)
+	}
+	return localizedTemplate{html: html, text: text}, true, nil
+}
+
+// validateVariables renders the sample data, which must succeed, then renders once per required
+// variable with that variable left out, which must fail (missingkey=error) or the variable is unused.
+func validateVariables(tmpl localizedTemplate, def TemplateDefinition, locale string) error {
+	sample := TemplateData{}
+	for _, key := range def.Required {
+		value, ok := def.Sample[key]
+		if !ok {
+			return fmt.Errorf("%s: sample data lacks required variable %s", def.Name, key)
+		}
+		sample[key] = value
+	}
+	if _, err := tmpl.render(sample, locale); err != nil {
+		return fmt.Errorf("%s/%s: uses variables not declared as required: %w", locale, def.Name, err)
+	}
+	for _, key := range def.Required {
+		without := TemplateData{}
+		for k, v := range sample {
+			if k != key {
+				without[k] = v
+			}
+		}
+		if _, err := tmpl.render(without, locale); err == nil {
+			return fmt.Errorf("%s/%s: required variable %s is never used", locale, def.Name, key)
+		}
+	}
+	return nil
+}
+
+func (t localizedTemplate) render(data TemplateData, locale string) (*Rendered, error) {
+	// Locale is available to every template, e.g. for <html lang>.
+	withLocale := TemplateData{"Locale": locale}
+	for k, v := range data {
+		withLocale[k] = v
+	}
+
+	var subject, html, text bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", withLocale); err != nil {
+		return nil, err
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", withLocale); err != nil {
+		return nil, err
+	}
+	if err := t.text.ExecuteTemplate(&text, "layout", withLocale); err != nil {
+		return nil, err
+	}
+	return &Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimLeft(text.String(), "\n"),
+	}, nil
+}
+
+// Render renders name in the best matching locale, falling back to DefaultLocale.
+func (r *Registry) Render(name, locale string, data TemplateData) (*Rendered, error) {
+	def, ok := r.definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("email template %q is not registered", name)
+	}
+	for _, key := range def.Required {
+		if _, ok := data[key]; !ok {
+			return nil, fmt.Errorf("email template %q: missing variable %s", name, key)
+		}
+	}
+	resolved := r.resolveLocale(name, locale)
+	rendered, err := r.templates[name][resolved].render(data, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("email template %q (%s): %w", name, resolved, err)
+	}
+	return rendered, nil
+}
+
+// Message renders name and wraps it in a Message addressed to to.
+func (r *Registry) Message(name, locale string, data TemplateData, to ...string) (*Message, error) {
+	rendered, err := r.Render(name, locale, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{To: to, Subject: rendered.Subject, HTMLBody: rendered.HTML, TextBody: rendered.Text}, nil
+}
+
+// Preview renders name with its sample data.
+func (r *Registry) Preview(name, locale string) (*Rendered, error) {
+	def, ok := r.definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("email template %q is not registered", name)
+	}
+	return r.Render(name, locale, def.Sample)
+}
+
+// Has reports whether name is a registered template.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.definitions[name]
+	return ok
+}
+
+// Templates lists every registered template with the locales it is available in, sorted by name.
+func (r *Registry) Templates() []TemplateInfo {
+	infos := make([]TemplateInfo, 0, len(r.definitions))
+	for name, def := range r.definitions {
+		info := TemplateInfo{Name: name, Required: def.Required, Locales: []string{}}
+		for _, locale := range r.locales {
+			if _, ok := r.templates[name][locale]; ok {
+				info.Locales = append(info.Locales, locale)
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// MatchLocale picks the first supported locale from an Accept-Language header or a single tag
+// such as "zh-CN" or "zh". Quality values are ignored; clients list their preference first.
+func (r *Registry) MatchLocale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		for _, locale := range r.locales {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+		primary, _, _ := strings.Cut(tag, "-")
+		for _, locale := range r.locales {
+			localePrimary, _, _ := strings.Cut(locale, "-")
+			if strings.EqualFold(primary, localePrimary) {
+				return locale
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// resolveLocale returns locale if name exists in it, otherwise DefaultLocale.
+func (r *Registry) resolveLocale(name, locale string) string {
+	matched := r.MatchLocale(locale)
+	if _, ok := r.templates[name][matched]; ok {
+		return matched
+	}
+	return DefaultLocale
+}
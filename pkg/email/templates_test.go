@@ -0,0 +1,94 @@
+package email
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewDefaultRegistry(t *testing.T) {
+	registry, err := NewDefaultRegistry()
+	if err != nil {
+		t.Fatalf("NewDefaultRegistry: %v", err)
+	}
+	for _, def := range DefaultTemplateDefinitions {
+		for _, locale := range []string{DefaultLocale, "zh-CN"} {
+			rendered, err := registry.Preview(def.Name, locale)
+			if err != nil {
+				t.Errorf("Preview(%s, %s): %v", def.Name, locale, err)
+				continue
+			}
+			if rendered.Subject == "" || !strings.Contains(rendered.HTML, `lang="`+locale+`"`) {
+				t.Errorf("Preview(%s, %s) = subject %q, HTML without the locale", def.Name, locale, rendered.Subject)
+			}
+		}
+	}
+}
+
+// templateFS returns a minimal template tree with the given files added.
+func templateFS(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{
+		"layouts/base.html.tmpl": {Data: []byte(`{{define "layout"}}{{template "content" .}}{{end}}`)},
+		"layouts/base.txt.tmpl":  {Data: []byte(`{{define "layout"}}{{template "content" .}}{{end}}`)},
+	}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+func TestNewRegistryRejects(t *testing.T) {
+	greeting := TemplateDefinition{Name: "greeting", Required: []string{"Name"}, Sample: TemplateData{"Name": "Li"}}
+	tests := []struct {
+		name    string
+		files   map[string]string
+		def     TemplateDefinition
+		wantErr string
+	}{
+		{
+			"missing in default locale",
+			map[string]string{
+				"en/partials.txt.tmpl":     `{{define "footer"}}Jingdezhen{{end}}`,
+				"zh-CN/greeting.html.tmpl": `{{define "content"}}你好 {{.Name}}{{end}}`,
+				"zh-CN/greeting.txt.tmpl":  `{{define "subject"}}你好{{end}}{{define "content"}}你好 {{.Name}}{{end}}`,
+			},
+			greeting,
+			"missing in default locale en",
+		},
+		{
+			"no default locale directory",
+			map[string]string{
+				"zh-CN/greeting.html.tmpl": `{{define "content"}}你好 {{.Name}}{{end}}`,
+				"zh-CN/greeting.txt.tmpl":  `{{define "subject"}}你好{{end}}{{define "content"}}你好 {{.Name}}{{end}}`,
+			},
+			greeting,
+			"missing in default locale en",
+		},
+		{
+			"undeclared variable",
+			map[string]string{
+				"en/greeting.html.tmpl": `{{define "content"}}Hello {{.Name}} from {{.City}}{{end}}`,
+				"en/greeting.txt.tmpl":  `{{define "subject"}}Hello{{end}}{{define "content"}}Hello {{.Name}}{{end}}`,
+			},
+			greeting,
+			"uses variables not declared as required",
+		},
+		{
+			"declared but unused variable",
+			map[string]string{
+				"en/greeting.html.tmpl": `{{define "content"}}Hello{{end}}`,
+				"en/greeting.txt.tmpl":  `{{define "subject"}}Hello{{end}}{{define "content"}}Hello{{end}}`,
+			},
+			greeting,
+			"required variable Name is never used",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRegistry(templateFS(tt.files), []TemplateDefinition{tt.def})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRegistry error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
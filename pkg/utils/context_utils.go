@@ -42,3 +42,9 @@ func GetPageLimit(c echo.Context) (page int, limit int) {
 	}
 	return page, limit
 }
+
+// GetUserRoleFromContext retrieves userRole from Echo context (set by JWT middleware)
+func GetUserRoleFromContext(c echo.Context) string {
+	userRole, _ := c.Get("userRole").(string)
+	return userRole
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike makes s match literally in a LIKE or ILIKE pattern written with ESCAPE '\'.
+// A search for "%" or "_" would otherwise match everything.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// ContainsPattern is the ILIKE pattern for values containing s, for use with ESCAPE '\'.
+func ContainsPattern(s string) string {
+	return "%" + EscapeLike(s) + "%"
+}
+
+// IsNoRows reports whether err, possibly wrapped, means a single-row query found nothing.
+func IsNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+// IsUniqueViolation reports whether err, possibly wrapped, is a clash on a unique constraint.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
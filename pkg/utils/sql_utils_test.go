@@ -0,0 +1,17 @@
+package utils
+
+import "testing"
+
+func TestContainsPattern(t *testing.T) {
+	tests := map[string]string{
+		"celadon":    "%celadon%",
+		"100%":       `%100\%%`,
+		"blue_white": `%blue\_white%`,
+		`C:\kiln`:    `%C:\\kiln%`,
+	}
+	for in, want := range tests {
+		if got := ContainsPattern(in); got != want {
+			t.Errorf("ContainsPattern(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package utils
+
+import "strings"
+
+// NormalizeTags trims, lowercases and de-duplicates tag names while keeping their order.
+// Forum posts and artworks share the tags table, so both normalize the same way.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+var digitsOnlyPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// NewValidator returns a validator that also knows the "alphanumdash" tag used for slugs.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	// Only fails if the tag is registered twice or the func is nil, neither of which can happen here.
+	_ = validate.RegisterValidation("alphanumdash", validateAlphanumDash)
+	return validate
+}
+
+// validateAlphanumDash accepts lowercase letters, digits and single inner dashes ("ming-dynasty").
+// All-digit values are rejected since lookups by ID or slug would read them as an ID.
+func validateAlphanumDash(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return slugPattern.MatchString(value) && !digitsOnlyPattern.MatchString(value)
+}